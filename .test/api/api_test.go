@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,39 +13,47 @@ import (
 
 	"github.com/zeroberto/go-ms-template/api"
 	"github.com/zeroberto/go-ms-template/api/rest"
+	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver"
+	"github.com/zeroberto/go-ms-template/filter"
 	"github.com/zeroberto/go-ms-template/usecase"
 
 	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/patch"
 )
 
 func TestCreate(t *testing.T) {
 	expected := api.Response{
 		Code: 201,
 		Path: 1,
+		ETag: `"1"`,
 	}
 
 	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
 	createExampleMock = func(example *model.Example) (*model.Example, error) {
+		example.Version = 1
 		return example, nil
 	}
 
 	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
 		ECUC: ecuc,
 	}
-	got := eapi.Create(model.Example{ID: 1})
+	got := eapi.Create(context.Background(), model.Example{ID: 1})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Create() failed, expected %v, got %v", expected, got)
 	}
 }
 
 func TestCreateWhenECUCReturnsErrorThenFailure(t *testing.T) {
 	expected := api.Response{
-		Code: 400,
-		Body: api.ResponseBody{
-			Code:    400,
-			Message: "error",
-			Time:    currentTime,
+		Code: 500,
+		Body: api.Problem{
+			Type:   api.ProblemTypeBlank,
+			Title:  "Internal Server Error",
+			Status: 500,
+			Detail: "An unexpected error occurred",
+			Time:   currentTime,
 		},
 	}
 
@@ -56,37 +66,176 @@ func TestCreateWhenECUCReturnsErrorThenFailure(t *testing.T) {
 		ECUC: ecuc,
 		TS:   &timeStampMock{},
 	}
-	got := eapi.Create(model.Example{ID: 1})
+	got := eapi.Create(context.Background(), model.Example{ID: 1})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Create() failed, expected %v, got %v", expected, got)
 	}
 }
 
+func TestCreateWhenNameAlreadyExistsThenConflict(t *testing.T) {
+	expected := api.Response{
+		Code: 409,
+		Body: api.Problem{
+			Type:   api.ProblemTypeConflict,
+			Title:  "Conflict",
+			Status: 409,
+			Detail: "Example already exists",
+			Time:   currentTime,
+		},
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
+	createExampleMock = func(example *model.Example) (*model.Example, error) {
+		return nil, &usecase.ConflictError{Cause: errors.New("Example already exists")}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC: ecuc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Create(context.Background(), model.Example{ID: 1})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Create() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestCreateWhenDatabaseUnavailableThenServiceUnavailable(t *testing.T) {
+	expected := 503
+
+	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
+	createExampleMock = func(example *model.Example) (*model.Example, error) {
+		return nil, usecase.Wrap(&dataservice.Error{Cause: &dbdriver.UnavailableError{Cause: errors.New("error")}})
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC: ecuc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Create(context.Background(), model.Example{ID: 1})
+
+	if expected != got.Code {
+		t.Errorf("Create() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
 func TestUpdate(t *testing.T) {
 	expected := api.Response{
 		Code: 204,
+		ETag: `"4"`,
 	}
 
+	var gotVersion int64
 	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
 	updateExampleMock = func(example *model.Example) (*model.Example, error) {
-		return example, nil
+		gotVersion = example.Version
+		return &model.Example{ID: example.ID, Version: example.Version + 1}, nil
 	}
 
 	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
-		ECUC: ecuc,
+		ECUC:           ecuc,
+		RequireIfMatch: true,
 	}
-	got := eapi.Update(1, model.Example{})
+	got := eapi.Update(context.Background(), 1, model.Example{}, api.Preconditions{IfMatch: `"3"`})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Update() failed, expected %v, got %v", expected, got)
 	}
+	if gotVersion != 3 {
+		t.Errorf("Update() failed, expected %v, got %v", 3, gotVersion)
+	}
+}
+
+func TestUpdateWhenIfMatchIsRequiredAndMissingThenPreconditionRequired(t *testing.T) {
+	expected := api.Response{
+		Code: 428,
+		Body: api.Problem{
+			Type:   api.ProblemTypePreconditionRequired,
+			Title:  "Precondition Required",
+			Status: 428,
+			Detail: "The If-Match header is required to change the resource",
+			Time:   currentTime,
+		},
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC:           &exampleCreationUseCaseMock{},
+		TS:             &timeStampMock{},
+		RequireIfMatch: true,
+	}
+	got := eapi.Update(context.Background(), 1, model.Example{}, api.Preconditions{})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Update() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestUpdateWhenIfMatchAndNotExistsThenPreconditionFailed(t *testing.T) {
+	expected := 412
+
+	updateExampleMock = func(example *model.Example) (*model.Example, error) {
+		return nil, &usecase.NotExistsError{ID: 1}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC: &exampleCreationUseCaseMock{},
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Update(context.Background(), 1, model.Example{}, api.Preconditions{IfMatch: "*"})
+
+	if expected != got.Code {
+		t.Errorf("Update() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
+func TestUpdateWhenIfNoneMatchAndExistsThenPreconditionFailed(t *testing.T) {
+	expected := 412
+
+	getExampleMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: ID}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC:           &exampleCreationUseCaseMock{},
+		ERUC:           &exampleReadUseCaseMock{},
+		TS:             &timeStampMock{},
+		RequireIfMatch: true,
+	}
+	got := eapi.Update(context.Background(), 1, model.Example{}, api.Preconditions{IfNoneMatch: "*"})
+
+	if expected != got.Code {
+		t.Errorf("Update() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
+func TestUpdateWhenIfNoneMatchAndNotExistsThenCreate(t *testing.T) {
+	expected := 201
+
+	getExampleMock = func(ID int64) (*model.Example, error) {
+		return nil, &usecase.NotExistsError{ID: ID}
+	}
+	createExampleMock = func(example *model.Example) (*model.Example, error) {
+		return example, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC:           &exampleCreationUseCaseMock{},
+		ERUC:           &exampleReadUseCaseMock{},
+		RequireIfMatch: true,
+	}
+	got := eapi.Update(context.Background(), 1, model.Example{}, api.Preconditions{IfNoneMatch: "*"})
+
+	if expected != got.Code {
+		t.Errorf("Update() failed, expected %v, got %v", expected, got.Code)
+	}
 }
 
 func TestUpdateWhenNotExistsThenCreateSuccess(t *testing.T) {
 	expected := api.Response{
 		Code: 201,
 		Path: 1,
+		ETag: `"1"`,
 	}
 
 	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
@@ -95,26 +244,29 @@ func TestUpdateWhenNotExistsThenCreateSuccess(t *testing.T) {
 	}
 	createExampleMock = func(example *model.Example) (*model.Example, error) {
 		example.ID = 1
+		example.Version = 1
 		return example, nil
 	}
 
 	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
 		ECUC: ecuc,
 	}
-	got := eapi.Update(1, model.Example{})
+	got := eapi.Update(context.Background(), 1, model.Example{}, api.Preconditions{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Update() failed, expected %v, got %v", expected, got)
 	}
 }
 
 func TestUpdateWhenECUCReturnsErrorThenFailure(t *testing.T) {
 	expected := api.Response{
-		Code: 400,
-		Body: api.ResponseBody{
-			Code:    400,
-			Message: "error",
-			Time:    currentTime,
+		Code: 500,
+		Body: api.Problem{
+			Type:   api.ProblemTypeBlank,
+			Title:  "Internal Server Error",
+			Status: 500,
+			Detail: "An unexpected error occurred",
+			Time:   currentTime,
 		},
 	}
 
@@ -127,9 +279,9 @@ func TestUpdateWhenECUCReturnsErrorThenFailure(t *testing.T) {
 		ECUC: ecuc,
 		TS:   &timeStampMock{},
 	}
-	got := eapi.Update(1, model.Example{})
+	got := eapi.Update(context.Background(), 1, model.Example{}, api.Preconditions{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Update() failed, expected %v, got %v", expected, got)
 	}
 }
@@ -137,21 +289,22 @@ func TestUpdateWhenECUCReturnsErrorThenFailure(t *testing.T) {
 func TestPartialUpdate(t *testing.T) {
 	expected := api.Response{
 		Code: 204,
+		ETag: `"2"`,
 	}
 
 	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
-	updateExamplePropertiesMock = func(ID int64, properties map[string]interface{}) (*model.Example, error) {
-		return &model.Example{}, nil
+	patchExampleMock = func(ID int64, version int64, examplePatch patch.Patch) (*model.Example, error) {
+		return &model.Example{Version: 2}, nil
 	}
 
 	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
 		ECUC: ecuc,
 	}
-	got := eapi.PartialUpdate(1, map[string]interface{}{
+	got := eapi.PartialUpdate(context.Background(), 1, patch.MergePatch{Value: map[string]interface{}{
 		"Name": "test",
-	})
+	}}, api.Preconditions{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("PartialUpdate() failed, expected %v, got %v", expected, got)
 	}
 }
@@ -159,15 +312,17 @@ func TestPartialUpdate(t *testing.T) {
 func TestPartialUpdateWhenIDNotExistsThenFailure(t *testing.T) {
 	expected := api.Response{
 		Code: 404,
-		Body: api.ResponseBody{
-			Code:    404,
-			Message: "No examples found for ID 1",
-			Time:    currentTime,
+		Body: api.Problem{
+			Type:   api.ProblemTypeNotFound,
+			Title:  "Not Found",
+			Status: 404,
+			Detail: "No examples found for ID 1",
+			Time:   currentTime,
 		},
 	}
 
 	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
-	updateExamplePropertiesMock = func(ID int64, properties map[string]interface{}) (*model.Example, error) {
+	patchExampleMock = func(ID int64, version int64, examplePatch patch.Patch) (*model.Example, error) {
 		return nil, &usecase.NotExistsError{ID: ID}
 	}
 
@@ -175,27 +330,29 @@ func TestPartialUpdateWhenIDNotExistsThenFailure(t *testing.T) {
 		ECUC: ecuc,
 		TS:   &timeStampMock{},
 	}
-	got := eapi.PartialUpdate(1, map[string]interface{}{
+	got := eapi.PartialUpdate(context.Background(), 1, patch.MergePatch{Value: map[string]interface{}{
 		"Name": "test",
-	})
+	}}, api.Preconditions{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("PartialUpdate() failed, expected %v, got %v", expected, got)
 	}
 }
 
 func TestPartialUpdateWhenECUCReturnsErrorThenFailure(t *testing.T) {
 	expected := api.Response{
-		Code: 400,
-		Body: api.ResponseBody{
-			Code:    400,
-			Message: "error",
-			Time:    currentTime,
+		Code: 500,
+		Body: api.Problem{
+			Type:   api.ProblemTypeBlank,
+			Title:  "Internal Server Error",
+			Status: 500,
+			Detail: "An unexpected error occurred",
+			Time:   currentTime,
 		},
 	}
 
 	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
-	updateExamplePropertiesMock = func(ID int64, properties map[string]interface{}) (*model.Example, error) {
+	patchExampleMock = func(ID int64, version int64, examplePatch patch.Patch) (*model.Example, error) {
 		return nil, &usecase.Error{Cause: errors.New("error")}
 	}
 
@@ -203,11 +360,45 @@ func TestPartialUpdateWhenECUCReturnsErrorThenFailure(t *testing.T) {
 		ECUC: ecuc,
 		TS:   &timeStampMock{},
 	}
-	got := eapi.PartialUpdate(1, map[string]interface{}{
+	got := eapi.PartialUpdate(context.Background(), 1, patch.MergePatch{Value: map[string]interface{}{
 		"Name": "test",
-	})
+	}}, api.Preconditions{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("PartialUpdate() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestPartialUpdateWhenPropertyIsInvalidThenUnprocessableEntity(t *testing.T) {
+	expected := api.Response{
+		Code: 422,
+		Body: api.Problem{
+			Type:   api.ProblemTypeValidation,
+			Title:  "Unprocessable Entity",
+			Status: 422,
+			Detail: "Property Wrong does not exist or cannot be updated",
+			Time:   currentTime,
+			Errors: []api.FieldError{{Field: "Wrong", Message: "does not exist or cannot be updated"}},
+		},
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
+	patchExampleMock = func(ID int64, version int64, examplePatch patch.Patch) (*model.Example, error) {
+		return nil, &usecase.ValidationError{
+			Message: "Property Wrong does not exist or cannot be updated",
+			Fields:  []usecase.FieldError{{Field: "Wrong", Message: "does not exist or cannot be updated"}},
+		}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC: ecuc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.PartialUpdate(context.Background(), 1, patch.MergePatch{Value: map[string]interface{}{
+		"Wrong": "test",
+	}}, api.Preconditions{})
+
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("PartialUpdate() failed, expected %v, got %v", expected, got)
 	}
 }
@@ -217,38 +408,184 @@ func TestGet(t *testing.T) {
 		model.Example{ID: 1},
 		model.Example{ID: 2},
 	}
+	total := int64(5)
 	expected := api.Response{
 		Code: 200,
-		Body: examples,
+		Body: api.Page{
+			Items:    examples,
+			Next:     api.EncodeCursor(api.Cursor{AfterID: 2}),
+			Previous: api.EncodeCursor(api.Cursor{BeforeID: 1}),
+			Total:    &total,
+		},
 	}
 
 	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
-	listExamplesMock = func() ([]model.Example, error) {
-		return examples, nil
+	listExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		return &model.ExamplePage{Examples: examples, HasNext: true, HasPrevious: true, Total: &total}, nil
 	}
 
 	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
 		ERUC: eruc,
 	}
-	got := eapi.Get()
+	got := eapi.Get(context.Background(), api.PageParams{})
+
+	if !reflect.DeepEqual(expected.Body, got.Body) {
+		t.Errorf("Get() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestGetThenPageValidators(t *testing.T) {
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	examples := []model.Example{
+		{ID: 1, Version: 1, UpdatedAt: updatedAt},
+		{ID: 2, Version: 1, UpdatedAt: updatedAt.Add(-time.Hour)},
+	}
+
+	listExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		return &model.ExamplePage{Examples: examples}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{ERUC: &exampleReadUseCaseMock{}}
+	got := eapi.Get(context.Background(), api.PageParams{})
+	examples[1].Version = 2
+	changed := eapi.Get(context.Background(), api.PageParams{})
+
+	if !strings.HasPrefix(got.ETag, `W/"`) {
+		t.Errorf("Get() failed, expected weak ETag, got %v", got.ETag)
+	}
+	if got.ETag == changed.ETag {
+		t.Errorf("Get() failed, expected ETag to change with the version, got %v", changed.ETag)
+	}
+	if !updatedAt.Equal(got.LastModified) {
+		t.Errorf("Get() failed, expected %v, got %v", updatedAt, got.LastModified)
+	}
+}
+
+func TestGetWhenPageParamsThenPageRequest(t *testing.T) {
+	tests := []struct {
+		pageParams api.PageParams
+		expected   model.PageRequest
+	}{
+		{api.PageParams{}, model.PageRequest{Limit: 10}},
+		{api.PageParams{Limit: 500, Offset: 4, Total: true}, model.PageRequest{Limit: 50, Offset: 4, CountTotal: true}},
+		{api.PageParams{Cursor: api.EncodeCursor(api.Cursor{AfterID: 7})}, model.PageRequest{Limit: 10, AfterID: 7}},
+		{api.PageParams{Cursor: api.EncodeCursor(api.Cursor{Offset: 20})}, model.PageRequest{Limit: 10, Offset: 20}},
+		{
+			api.PageParams{Filter: "useful==true", Sort: "-name"},
+			model.PageRequest{
+				Limit:  10,
+				Filter: filter.Comparison{Field: "useful", Operator: filter.Equal, Value: true},
+				Sort:   []filter.Order{{Field: "name", Descending: true}},
+			},
+		},
+	}
+
+	var got model.PageRequest
+	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
+	listExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		got = pageRequest
+		return &model.ExamplePage{}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC:            eruc,
+		DefaultPageSize: 10,
+		MaxPageSize:     50,
+	}
+	for _, test := range tests {
+		eapi.Get(context.Background(), test.pageParams)
+
+		if !reflect.DeepEqual(test.expected, got) {
+			t.Errorf("Get() failed, expected %v, got %v", test.expected, got)
+		}
+	}
+}
+
+func TestGetWhenSortedThenOffsetCursors(t *testing.T) {
+	expected := api.Page{
+		Items:    []model.Example{{ID: 5}, {ID: 2}},
+		Next:     api.EncodeCursor(api.Cursor{Offset: 4}),
+		Previous: api.EncodeCursor(api.Cursor{Offset: 0}),
+	}
+
+	listExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		return &model.ExamplePage{Examples: []model.Example{{ID: 5}, {ID: 2}}, HasNext: true, HasPrevious: true}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{ERUC: &exampleReadUseCaseMock{}}
+	got := eapi.Get(context.Background(), api.PageParams{Limit: 2, Offset: 2, Sort: "name"})
+
+	if !reflect.DeepEqual(expected, got.Body) {
+		t.Errorf("Get() failed, expected %v, got %v", expected, got.Body)
+	}
+}
+
+func TestGetWhenFilterIsInvalidThenBadRequest(t *testing.T) {
+	expected := api.Response{
+		Code: 400,
+		Body: api.Problem{
+			Type:   api.ProblemTypeInvalidParameter,
+			Title:  "Bad Request",
+			Status: 400,
+			Detail: `Invalid filter: unknown field "color" at position 13`,
+			Time:   currentTime,
+			Errors: []api.FieldError{{Field: "filter", Message: `unknown field "color" at position 13`}},
+		},
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: &exampleReadUseCaseMock{},
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Get(context.Background(), api.PageParams{Filter: "useful==true;color==red"})
 
 	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Get() failed, expected %v, got %v", expected, got)
 	}
 }
 
+func TestGetWhenSortIsInvalidThenBadRequest(t *testing.T) {
+	expected := []api.FieldError{{Field: "sort", Message: `unknown field "color" at position 5`}}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: &exampleReadUseCaseMock{},
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Get(context.Background(), api.PageParams{Sort: "name,color"})
+
+	if problem, ok := got.Body.(api.Problem); !ok || got.Code != 400 || !reflect.DeepEqual(expected, problem.Errors) {
+		t.Errorf("Get() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestGetWhenCursorIsInvalidThenBadRequest(t *testing.T) {
+	expected := []api.FieldError{{Field: "cursor", Message: "is not a valid cursor"}}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: &exampleReadUseCaseMock{},
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Get(context.Background(), api.PageParams{Cursor: "%"})
+
+	if problem, ok := got.Body.(api.Problem); !ok || got.Code != 400 || !reflect.DeepEqual(expected, problem.Errors) {
+		t.Errorf("Get() failed, expected %v, got %v", expected, got)
+	}
+}
+
 func TestGetWhenERUCReturnsErrorThenFailure(t *testing.T) {
 	expected := api.Response{
-		Code: 400,
-		Body: api.ResponseBody{
-			Code:    400,
-			Message: "error",
-			Time:    currentTime,
+		Code: 500,
+		Body: api.Problem{
+			Type:   api.ProblemTypeBlank,
+			Title:  "Internal Server Error",
+			Status: 500,
+			Detail: "An unexpected error occurred",
+			Time:   currentTime,
 		},
 	}
 
 	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
-	listExamplesMock = func() ([]model.Example, error) {
+	listExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
 		return nil, &usecase.Error{Cause: errors.New("error")}
 	}
 
@@ -256,41 +593,46 @@ func TestGetWhenERUCReturnsErrorThenFailure(t *testing.T) {
 		ERUC: eruc,
 		TS:   &timeStampMock{},
 	}
-	got := eapi.Get()
+	got := eapi.Get(context.Background(), api.PageParams{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Get() failed, expected %v, got %v", expected, got)
 	}
 }
 
 func TestGetByID(t *testing.T) {
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
 	expected := api.Response{
-		Code: 200,
-		Body: model.Example{ID: 1},
+		Code:         200,
+		ETag:         `"2"`,
+		LastModified: updatedAt,
+		Body:         model.Example{ID: 1, Version: 2, UpdatedAt: updatedAt},
 	}
 
 	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
 	getExampleMock = func(ID int64) (*model.Example, error) {
-		return &model.Example{ID: 1}, nil
+		return &model.Example{ID: 1, Version: 2, UpdatedAt: updatedAt}, nil
 	}
 
 	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
 		ERUC: eruc,
 	}
-	got := eapi.GetByID(1)
+	got := eapi.GetByID(context.Background(), 1)
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("GetByID() failed, expected %v, got %v", expected, got)
 	}
 }
 
 func TestGetByIDWhenERUCReturnsErrorThenFailure(t *testing.T) {
 	expected := api.Response{
-		Code: 400,
-		Body: api.ResponseBody{
-			Code:    400,
-			Message: "error",
-			Time:    currentTime,
+		Code: 500,
+		Body: api.Problem{
+			Type:   api.ProblemTypeBlank,
+			Title:  "Internal Server Error",
+			Status: 500,
+			Detail: "An unexpected error occurred",
+			Time:   currentTime,
 		},
 	}
 
@@ -303,29 +645,98 @@ func TestGetByIDWhenERUCReturnsErrorThenFailure(t *testing.T) {
 		ERUC: eruc,
 		TS:   &timeStampMock{},
 	}
-	got := eapi.GetByID(1)
+	got := eapi.GetByID(context.Background(), 1)
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("GetByID() failed, expected %v, got %v", expected, got)
 	}
 }
 
+func TestGetActive(t *testing.T) {
+	expected := api.Page{Items: []model.Example{{ID: 1}}}
+
+	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
+	listActiveExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		return &model.ExamplePage{Examples: []model.Example{{ID: 1}}}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: eruc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.GetActive(context.Background(), api.PageParams{})
+
+	if got.Code != 200 || !reflect.DeepEqual(expected, got.Body) {
+		t.Errorf("GetActive() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestGetByName(t *testing.T) {
+	expected := api.Response{
+		Code: 200,
+		ETag: `"2"`,
+		Body: model.Example{ID: 1, Name: "test", Version: 2},
+	}
+
+	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
+	getExampleByNameMock = func(name string) (*model.Example, error) {
+		return &model.Example{ID: 1, Name: name, Version: 2}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: eruc,
+	}
+	got := eapi.GetByName(context.Background(), "test")
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("GetByName() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestGetByNameWhenNameNotExistsThenNotFound(t *testing.T) {
+	expected := api.Response{
+		Code: 404,
+		Body: api.Problem{
+			Type:   api.ProblemTypeNotFound,
+			Title:  "Not Found",
+			Status: 404,
+			Detail: "No examples found for name test",
+			Time:   currentTime,
+		},
+	}
+
+	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
+	getExampleByNameMock = func(name string) (*model.Example, error) {
+		return nil, &usecase.NotExistsError{Name: name}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: eruc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.GetByName(context.Background(), "test")
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("GetByName() failed, expected %v, got %v", expected, got)
+	}
+}
+
 func TestDelete(t *testing.T) {
 	expected := api.Response{
 		Code: 204,
 	}
 
 	var ermuc usecase.ExampleRemovalUseCase = &exampleRemovalUseCaseMock{}
-	deleteExampleMock = func(ID int64) error {
+	deleteExampleMock = func(ID int64, version int64) error {
 		return nil
 	}
 
 	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
 		ERMUC: ermuc,
 	}
-	got := eapi.Delete(1)
+	got := eapi.Delete(context.Background(), 1, api.Preconditions{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Delete() failed, expected %v, got %v", expected, got)
 	}
 }
@@ -333,15 +744,17 @@ func TestDelete(t *testing.T) {
 func TestDeleteWhenIDNotExistsThenFailure(t *testing.T) {
 	expected := api.Response{
 		Code: 404,
-		Body: api.ResponseBody{
-			Code:    404,
-			Message: "No examples found for ID 1",
-			Time:    currentTime,
+		Body: api.Problem{
+			Type:   api.ProblemTypeNotFound,
+			Title:  "Not Found",
+			Status: 404,
+			Detail: "No examples found for ID 1",
+			Time:   currentTime,
 		},
 	}
 
 	var ermuc usecase.ExampleRemovalUseCase = &exampleRemovalUseCaseMock{}
-	deleteExampleMock = func(ID int64) error {
+	deleteExampleMock = func(ID int64, version int64) error {
 		return &usecase.NotExistsError{ID: ID}
 	}
 
@@ -349,25 +762,87 @@ func TestDeleteWhenIDNotExistsThenFailure(t *testing.T) {
 		ERMUC: ermuc,
 		TS:    &timeStampMock{},
 	}
-	got := eapi.Delete(1)
+	got := eapi.Delete(context.Background(), 1, api.Preconditions{})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Delete() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestDeleteWhenIfMatchThenVersion(t *testing.T) {
+	expected := int64(5)
+
+	var got int64
+	deleteExampleMock = func(ID int64, version int64) error {
+		got = version
+		return nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{ERMUC: &exampleRemovalUseCaseMock{}}
+	eapi.Delete(context.Background(), 1, api.Preconditions{IfMatch: `"5"`})
 
 	if expected != got {
 		t.Errorf("Delete() failed, expected %v, got %v", expected, got)
 	}
 }
 
+func TestDeleteWhenIfMatchIsStaleThenPreconditionFailed(t *testing.T) {
+	expected := api.Response{
+		Code: 412,
+		Body: api.Problem{
+			Type:   api.ProblemTypePreconditionFailed,
+			Title:  "Precondition Failed",
+			Status: 412,
+			Detail: "Example 1 is no longer at version 5",
+			Time:   currentTime,
+		},
+	}
+
+	deleteExampleMock = func(ID int64, version int64) error {
+		return &usecase.PreconditionFailedError{Cause: &dataservice.StaleVersionError{ID: ID, Version: version}}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERMUC: &exampleRemovalUseCaseMock{},
+		TS:    &timeStampMock{},
+	}
+	got := eapi.Delete(context.Background(), 1, api.Preconditions{IfMatch: `"5"`})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Delete() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestDeleteWhenIfMatchIsNotStrongETagThenPreconditionFailed(t *testing.T) {
+	expected := 412
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERMUC: &exampleRemovalUseCaseMock{},
+		TS:    &timeStampMock{},
+	}
+	for _, ifMatch := range []string{`W/"5"`, "5", `"a"`} {
+		got := eapi.Delete(context.Background(), 1, api.Preconditions{IfMatch: ifMatch})
+
+		if expected != got.Code {
+			t.Errorf("Delete(%s) failed, expected %v, got %v", ifMatch, expected, got.Code)
+		}
+	}
+}
+
 func TestDeleteWhenERMUCReturnsErrorThenFailure(t *testing.T) {
 	expected := api.Response{
-		Code: 400,
-		Body: api.ResponseBody{
-			Code:    400,
-			Message: "error",
-			Time:    currentTime,
+		Code: 500,
+		Body: api.Problem{
+			Type:   api.ProblemTypeBlank,
+			Title:  "Internal Server Error",
+			Status: 500,
+			Detail: "An unexpected error occurred",
+			Time:   currentTime,
 		},
 	}
 
 	var ermuc usecase.ExampleRemovalUseCase = &exampleRemovalUseCaseMock{}
-	deleteExampleMock = func(ID int64) error {
+	deleteExampleMock = func(ID int64, version int64) error {
 		return &usecase.Error{Cause: errors.New("error")}
 	}
 
@@ -375,28 +850,459 @@ func TestDeleteWhenERMUCReturnsErrorThenFailure(t *testing.T) {
 		ERMUC: ermuc,
 		TS:    &timeStampMock{},
 	}
-	got := eapi.Delete(1)
+	got := eapi.Delete(context.Background(), 1, api.Preconditions{})
 
-	if expected != got {
+	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("Delete() failed, expected %v, got %v", expected, got)
 	}
 }
 
+func TestDeleteLogically(t *testing.T) {
+	expected := api.Response{
+		Code: 204,
+	}
+
+	var ermuc usecase.ExampleRemovalUseCase = &exampleRemovalUseCaseMock{}
+	var gotVersion int64
+	deleteExampleLogicallyMock = func(ID int64, version int64) error {
+		gotVersion = version
+		return nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERMUC: ermuc,
+	}
+	got := eapi.DeleteLogically(context.Background(), 1, api.Preconditions{IfMatch: `"3"`})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("DeleteLogically() failed, expected %v, got %v", expected, got)
+	}
+	if gotVersion != 3 {
+		t.Errorf("DeleteLogically() failed, expected %v, got %v", 3, gotVersion)
+	}
+}
+
+func TestDeleteLogicallyWhenIfMatchAndNotExistsThenPreconditionFailed(t *testing.T) {
+	var ermuc usecase.ExampleRemovalUseCase = &exampleRemovalUseCaseMock{}
+	deleteExampleLogicallyMock = func(ID int64, version int64) error {
+		return &usecase.NotExistsError{ID: ID}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERMUC: ermuc,
+		TS:    &timeStampMock{},
+	}
+	got := eapi.DeleteLogically(context.Background(), 1, api.Preconditions{IfMatch: `"3"`})
+
+	if got.Code != 412 {
+		t.Errorf("DeleteLogically() failed, expected %v, got %v", 412, got.Code)
+	}
+}
+
+func TestGetWhenContextCanceledThenClientClosedRequest(t *testing.T) {
+	expected := rest.StatusClientClosedRequest
+
+	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
+	listExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		return nil, &usecase.Error{Cause: &dataservice.Error{Cause: context.Canceled}}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: eruc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Get(context.Background(), api.PageParams{})
+
+	if expected != got.Code {
+		t.Errorf("Get() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
+func TestGetWhenContextDeadlineExceededThenGatewayTimeout(t *testing.T) {
+	expected := 504
+
+	var eruc usecase.ExampleReadUseCase = &exampleReadUseCaseMock{}
+	listExamplesMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		return nil, &usecase.Error{Cause: &dataservice.Error{Cause: context.DeadlineExceeded}}
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERUC: eruc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Get(context.Background(), api.PageParams{})
+
+	if expected != got.Code {
+		t.Errorf("Get() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	expected := api.Response{
+		Code: 200,
+		Body: api.BatchResponse{Results: []api.BatchResult{
+			{Status: 201, ID: 5, ETag: `"1"`},
+			{Status: 412, ID: 2, Problem: &api.Problem{
+				Type:   api.ProblemTypePreconditionFailed,
+				Title:  "Precondition Failed",
+				Status: 412,
+				Detail: "No examples found for ID 2",
+				Time:   currentTime,
+			}},
+			{Status: 412, ID: 3, Problem: &api.Problem{
+				Type:   api.ProblemTypePreconditionFailed,
+				Title:  "Precondition Failed",
+				Status: 412,
+				Detail: "abc is not a strong entity tag",
+				Time:   currentTime,
+			}},
+		}},
+	}
+	expectedItems := []model.BatchItem{
+		{Operation: model.BatchCreate, Example: model.Example{Name: "a"}},
+		{Operation: model.BatchUpdate, Example: model.Example{ID: 2, Name: "b", Version: 2}},
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &exampleCreationUseCaseMock{}
+	var gotItems []model.BatchItem
+	saveExamplesMock = func(items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+		gotItems = items
+		return []model.BatchResult{
+			{Operation: model.BatchCreate, ID: 5, Version: 1},
+			{Operation: model.BatchUpdate, ID: 2, Err: &usecase.NotExistsError{ID: 2}},
+		}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC: ecuc,
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Batch(context.Background(), api.BatchRequest{
+		Mode: "bestEffort",
+		Items: []api.BatchItem{
+			{Operation: "create", Example: model.Example{Name: "a"}},
+			{Operation: "update", ID: 2, IfMatch: `"2"`, Example: model.Example{Name: "b"}},
+			{Operation: "delete", ID: 3, IfMatch: "abc"},
+		},
+	})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Batch() failed, expected %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual(expectedItems, gotItems) {
+		t.Errorf("Batch() failed, expected %v, got %v", expectedItems, gotItems)
+	}
+}
+
+func TestBatchWhenAllOrNothingAndIfMatchIsMissingThenAborted(t *testing.T) {
+	expected := []int{424, 428}
+
+	saveExamplesMock = func(items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+		t.Errorf("SaveExamples() should not be called")
+		return nil, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC:           &exampleCreationUseCaseMock{},
+		TS:             &timeStampMock{},
+		RequireIfMatch: true,
+	}
+	got := eapi.Batch(context.Background(), api.BatchRequest{
+		Items: []api.BatchItem{
+			{Operation: "create", Example: model.Example{Name: "a"}},
+			{Operation: "update", ID: 2, Example: model.Example{Name: "b"}},
+		},
+	})
+
+	results := got.Body.(api.BatchResponse).Results
+	for i, result := range results {
+		if expected[i] != result.Status {
+			t.Errorf("Batch() failed, expected %v, got %v", expected[i], result.Status)
+		}
+	}
+	if results[0].Problem.Type != api.ProblemTypeBatchAborted {
+		t.Errorf("Batch() failed, expected %v, got %v", api.ProblemTypeBatchAborted, results[0].Problem.Type)
+	}
+}
+
+func TestBatchWhenItemAbortedThenIndexOfBatch(t *testing.T) {
+	expected := "Not applied as item 2 of the batch failed"
+
+	saveExamplesMock = func(items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+		return []model.BatchResult{
+			{Operation: model.BatchDelete, ID: 1, Err: &usecase.BatchAbortedError{Index: 1}},
+			{Operation: model.BatchCreate, Err: &usecase.ConflictError{Cause: errors.New("Example already exists")}},
+		}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ECUC: &exampleCreationUseCaseMock{},
+		TS:   &timeStampMock{},
+	}
+	got := eapi.Batch(context.Background(), api.BatchRequest{
+		Mode: "bestEffort",
+		Items: []api.BatchItem{
+			{Operation: "delete", ID: 1},
+			{Operation: "update", IfMatch: "abc"},
+			{Operation: "create", Example: model.Example{Name: "a"}},
+		},
+	})
+
+	results := got.Body.(api.BatchResponse).Results
+	if results[0].Problem == nil || expected != results[0].Problem.Detail {
+		t.Errorf("Batch() failed, expected %v, got %v", expected, results[0].Problem)
+	}
+	if results[2].Status != 409 {
+		t.Errorf("Batch() failed, expected %v, got %v", 409, results[2].Status)
+	}
+}
+
+func TestBatchWhenOnlyDeletesThenRemovalUseCase(t *testing.T) {
+	expected := api.BatchResponse{Results: []api.BatchResult{{Status: 204, ID: 1}}}
+
+	deleteExamplesMock = func(items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+		return []model.BatchResult{{Operation: model.BatchDelete, ID: 1}}, nil
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		ERMUC: &exampleRemovalUseCaseMock{},
+		TS:    &timeStampMock{},
+	}
+	got := eapi.Batch(context.Background(), api.BatchRequest{
+		Items: []api.BatchItem{{Operation: "delete", ID: 1}},
+	})
+
+	if !reflect.DeepEqual(expected, got.Body) {
+		t.Errorf("Batch() failed, expected %v, got %v", expected, got.Body)
+	}
+}
+
+func TestBatchWhenInvalidThenUnprocessableEntity(t *testing.T) {
+	tests := map[string]api.BatchRequest{
+		"empty":     {},
+		"too large": {Items: make([]api.BatchItem, 3)},
+		"mode":      {Mode: "sometimes", Items: make([]api.BatchItem, 1)},
+	}
+
+	var eapi api.ExampleAPI = &rest.ExampleAPIRest{
+		TS:           &timeStampMock{},
+		MaxBatchSize: 2,
+	}
+
+	for name, batch := range tests {
+		got := eapi.Batch(context.Background(), batch)
+
+		if got.Code != 422 {
+			t.Errorf("Batch(%s) failed, expected %v, got %v", name, 422, got.Code)
+		}
+	}
+}
+
+func TestIssueAPIKey(t *testing.T) {
+	expiresAt := currentTime.Add(time.Hour)
+	expected := api.Response{
+		Code: 201,
+		Path: 1,
+		Body: api.IssuedAPIKey{
+			APIKey: api.APIKey{ID: 1, Name: "client", Prefix: "0123456789ab", Scopes: []string{"examples:read"}, CreatedAt: currentTime, ExpiresAt: &expiresAt},
+			Key:    "gms_0123456789ab_secret",
+		},
+	}
+
+	issueAPIKeyMock = func(apiKey *model.APIKey) (*model.APIKey, string, error) {
+		apiKey.ID, apiKey.Prefix, apiKey.Hash, apiKey.CreatedAt = 1, "0123456789ab", []byte("hash"), currentTime
+		return apiKey, "gms_0123456789ab_secret", nil
+	}
+	var akapi api.APIKeyAPI = &rest.APIKeyAPIRest{AKUC: &apiKeyUseCaseMock{}, TS: &timeStampMock{}}
+
+	got := akapi.Issue(context.Background(), api.APIKeyRequest{Name: "client", Scopes: []string{"examples:read"}, ExpiresAt: &expiresAt})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Issue() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestListAPIKeysThenNoSecrets(t *testing.T) {
+	expected := api.Response{
+		Code: 200,
+		Body: api.Page{Items: []api.APIKey{{ID: 1, Name: "client", Prefix: "0123456789ab", Scopes: []string{}, RevokedAt: &currentTime}}},
+	}
+
+	listAPIKeysMock = func() ([]model.APIKey, error) {
+		return []model.APIKey{{ID: 1, Name: "client", Prefix: "0123456789ab", Salt: []byte("salt"), Hash: []byte("hash"), RevokedAt: currentTime}}, nil
+	}
+	var akapi api.APIKeyAPI = &rest.APIKeyAPIRest{AKUC: &apiKeyUseCaseMock{}, TS: &timeStampMock{}}
+
+	got := akapi.List(context.Background())
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("List() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestRevokeAPIKeyWhenNotExistsThenNotFound(t *testing.T) {
+	expected := 404
+
+	revokeAPIKeyMock = func(ID int64) error {
+		return &usecase.NotExistsError{ID: ID, Resource: "API keys"}
+	}
+	var akapi api.APIKeyAPI = &rest.APIKeyAPIRest{AKUC: &apiKeyUseCaseMock{}, TS: &timeStampMock{}}
+
+	got := akapi.Revoke(context.Background(), 1)
+
+	if got.Code != expected {
+		t.Errorf("Revoke() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
+func TestRotateAPIKeyWhenRevokedThenConflict(t *testing.T) {
+	expected := 409
+
+	rotateAPIKeyMock = func(ID int64) (*model.APIKey, string, error) {
+		return nil, "", &usecase.ConflictError{Cause: &dataservice.RevokedError{ID: ID}}
+	}
+	var akapi api.APIKeyAPI = &rest.APIKeyAPIRest{AKUC: &apiKeyUseCaseMock{}, TS: &timeStampMock{}}
+
+	got := akapi.Rotate(context.Background(), 1)
+
+	if got.Code != expected {
+		t.Errorf("Rotate() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
 var currentTime time.Time = time.Now()
 
 var timeStamp chrono.TimeStamp = &provider.TimeStampImpl{}
 
+func TestRunWhenCompletedThenReplayedResponse(t *testing.T) {
+	problem := api.NewProblem(api.ProblemTypeConflict, 409, "Example already exists", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	expected := api.Response{Code: 409, Body: problem, Replayed: true}
+
+	beginRequestMock = func(key string, fingerprint string) (*model.IdempotentRequest, string, error) {
+		return &model.IdempotentRequest{Completed: true, Code: 409, BodyType: "api.Problem", Body: []byte(`{"type":"/problems/conflict","title":"Conflict","status":409,"detail":"Example already exists","time":"2020-01-02T03:04:05Z"}`)}, "", nil
+	}
+	iapi := &rest.IdempotencyAPIRest{IUC: &idempotencyUseCaseMock{}, TS: &timeStampMock{}}
+
+	got := iapi.Run(context.Background(), "key", "fingerprint", func(ctx context.Context) api.Response {
+		t.Errorf("Run() failed, expected %v, got %v", "no run", "run")
+		return api.Response{}
+	})
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Run() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestRunWhenCreatedThenResponseKept(t *testing.T) {
+	expected := model.IdempotentRequest{Key: "key", Fingerprint: "fingerprint", Code: 201, Path: 4, ETag: `"1"`}
+
+	var got model.IdempotentRequest
+	beginRequestMock = func(key string, fingerprint string) (*model.IdempotentRequest, string, error) {
+		return nil, "reservation", nil
+	}
+	completeRequestMock = func(request *model.IdempotentRequest) error {
+		got = *request
+		return nil
+	}
+	abandonRequestMock = func(key string, reservation string) error {
+		t.Errorf("Run() failed, expected %v, got %v", "no abandon", key)
+		return nil
+	}
+	iapi := &rest.IdempotencyAPIRest{IUC: &idempotencyUseCaseMock{}, TS: &timeStampMock{}}
+
+	response := iapi.Run(context.Background(), "key", "fingerprint", func(ctx context.Context) api.Response {
+		return api.Response{Code: 201, Path: 4, ETag: `"1"`}
+	})
+
+	if response.Replayed || !reflect.DeepEqual(expected, got) {
+		t.Errorf("Run() failed, expected %v, got %v %v", expected, response, got)
+	}
+}
+
+func TestRunWhenServerErrorThenKeyAbandoned(t *testing.T) {
+	for _, code := range []int{500, 503, rest.StatusClientClosedRequest} {
+		abandoned := ""
+		beginRequestMock = func(key string, fingerprint string) (*model.IdempotentRequest, string, error) {
+			return nil, "reservation", nil
+		}
+		completeRequestMock = func(request *model.IdempotentRequest) error {
+			t.Errorf("Run(%d) failed, expected %v, got %v", code, "no completion", request)
+			return nil
+		}
+		abandonRequestMock = func(key string, reservation string) error {
+			abandoned = key + " " + reservation
+			return nil
+		}
+		iapi := &rest.IdempotencyAPIRest{IUC: &idempotencyUseCaseMock{}, TS: &timeStampMock{}}
+
+		got := iapi.Run(context.Background(), "key", "fingerprint", func(ctx context.Context) api.Response {
+			return api.Response{Code: code}
+		})
+
+		if got.Code != code || abandoned != "key reservation" {
+			t.Errorf("Run(%d) failed, expected %v, got %v %v", code, "key reservation", got.Code, abandoned)
+		}
+	}
+}
+
+func TestRunWhenInProgressThenConflict(t *testing.T) {
+	expected := 409
+
+	beginRequestMock = func(key string, fingerprint string) (*model.IdempotentRequest, string, error) {
+		return nil, "", &usecase.ConflictError{Cause: errors.New("A request with the Idempotency-Key is in progress")}
+	}
+	iapi := &rest.IdempotencyAPIRest{IUC: &idempotencyUseCaseMock{}, TS: &timeStampMock{}}
+
+	got := iapi.Run(context.Background(), "key", "fingerprint", func(ctx context.Context) api.Response {
+		return api.Response{Code: 201}
+	})
+
+	if got.Code != expected {
+		t.Errorf("Run() failed, expected %v, got %v", expected, got.Code)
+	}
+}
+
+var beginRequestMock func(key string, fingerprint string) (*model.IdempotentRequest, string, error)
+
+var completeRequestMock func(request *model.IdempotentRequest) error
+
+var abandonRequestMock func(key string, reservation string) error
+
+type idempotencyUseCaseMock struct{}
+
+func (iuc *idempotencyUseCaseMock) BeginRequest(ctx context.Context, key string, fingerprint string) (*model.IdempotentRequest, string, error) {
+	return beginRequestMock(key, fingerprint)
+}
+
+func (iuc *idempotencyUseCaseMock) CompleteRequest(ctx context.Context, request *model.IdempotentRequest) error {
+	return completeRequestMock(request)
+}
+
+func (iuc *idempotencyUseCaseMock) AbandonRequest(ctx context.Context, key string, reservation string) error {
+	return abandonRequestMock(key, reservation)
+}
+
 var createExampleMock func(example *model.Example) (*model.Example, error)
 
 var updateExampleMock func(example *model.Example) (*model.Example, error)
 
-var updateExamplePropertiesMock func(ID int64, properties map[string]interface{}) (*model.Example, error)
+var patchExampleMock func(ID int64, version int64, examplePatch patch.Patch) (*model.Example, error)
+
+var saveExamplesMock func(items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error)
 
-var listExamplesMock func() ([]model.Example, error)
+var deleteExamplesMock func(items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error)
+
+var listExamplesMock func(pageRequest model.PageRequest) (*model.ExamplePage, error)
 
 var getExampleMock func(ID int64) (*model.Example, error)
 
-var deleteExampleMock func(ID int64) error
+var listActiveExamplesMock func(pageRequest model.PageRequest) (*model.ExamplePage, error)
+
+var getExampleByNameMock func(name string) (*model.Example, error)
+
+var deleteExampleLogicallyMock func(ID int64, version int64) error
+
+var deleteExampleMock func(ID int64, version int64) error
 
 type exampleCreationUseCaseMock struct{}
 
@@ -406,40 +1312,78 @@ type exampleRemovalUseCaseMock struct{}
 
 type timeStampMock struct{}
 
-func (ecuc *exampleCreationUseCaseMock) CreateExample(example *model.Example) (*model.Example, error) {
+func (ecuc *exampleCreationUseCaseMock) CreateExample(ctx context.Context, example *model.Example) (*model.Example, error) {
 	return createExampleMock(example)
 }
 
-func (ecuc *exampleCreationUseCaseMock) UpdateExample(example *model.Example) (*model.Example, error) {
+func (ecuc *exampleCreationUseCaseMock) UpdateExample(ctx context.Context, example *model.Example) (*model.Example, error) {
 	return updateExampleMock(example)
 }
 
-func (ecuc *exampleCreationUseCaseMock) UpdateExampleProperties(ID int64, properties map[string]interface{}) (*model.Example, error) {
-	return updateExamplePropertiesMock(ID, properties)
+func (ecuc *exampleCreationUseCaseMock) PatchExample(ctx context.Context, ID int64, version int64, examplePatch patch.Patch) (*model.Example, error) {
+	return patchExampleMock(ID, version, examplePatch)
 }
 
-func (eruc *exampleReadUseCaseMock) ListExamples() ([]model.Example, error) {
-	return listExamplesMock()
+func (ecuc *exampleCreationUseCaseMock) SaveExamples(ctx context.Context, items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+	return saveExamplesMock(items, mode)
 }
 
-func (eruc *exampleReadUseCaseMock) ListActiveExamples() ([]model.Example, error) {
-	return nil, nil
+func (eruc *exampleReadUseCaseMock) ListExamples(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	return listExamplesMock(pageRequest)
 }
 
-func (eruc *exampleReadUseCaseMock) GetExample(ID int64) (*model.Example, error) {
+func (eruc *exampleReadUseCaseMock) ListActiveExamples(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	return listActiveExamplesMock(pageRequest)
+}
+
+func (eruc *exampleReadUseCaseMock) GetExample(ctx context.Context, ID int64) (*model.Example, error) {
 	return getExampleMock(ID)
 }
 
-func (eruc *exampleReadUseCaseMock) GetExampleByName(name string) (*model.Example, error) {
-	return nil, nil
+func (eruc *exampleReadUseCaseMock) GetExampleByName(ctx context.Context, name string) (*model.Example, error) {
+	return getExampleByNameMock(name)
+}
+
+func (ermuc *exampleRemovalUseCaseMock) DeleteExample(ctx context.Context, ID int64, version int64) error {
+	return deleteExampleMock(ID, version)
+}
+
+func (ermuc *exampleRemovalUseCaseMock) DeleteExampleLogically(ctx context.Context, ID int64, version int64) error {
+	return deleteExampleLogicallyMock(ID, version)
+}
+
+func (ermuc *exampleRemovalUseCaseMock) DeleteExamples(ctx context.Context, items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+	return deleteExamplesMock(items, mode)
+}
+
+var issueAPIKeyMock func(apiKey *model.APIKey) (*model.APIKey, string, error)
+
+var listAPIKeysMock func() ([]model.APIKey, error)
+
+var revokeAPIKeyMock func(ID int64) error
+
+var rotateAPIKeyMock func(ID int64) (*model.APIKey, string, error)
+
+type apiKeyUseCaseMock struct{}
+
+func (akuc *apiKeyUseCaseMock) IssueAPIKey(ctx context.Context, apiKey *model.APIKey) (*model.APIKey, string, error) {
+	return issueAPIKeyMock(apiKey)
+}
+
+func (akuc *apiKeyUseCaseMock) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	return listAPIKeysMock()
+}
+
+func (akuc *apiKeyUseCaseMock) RotateAPIKey(ctx context.Context, ID int64) (*model.APIKey, string, error) {
+	return rotateAPIKeyMock(ID)
 }
 
-func (ermuc *exampleRemovalUseCaseMock) DeleteExample(ID int64) error {
-	return deleteExampleMock(ID)
+func (akuc *apiKeyUseCaseMock) RevokeAPIKey(ctx context.Context, ID int64) error {
+	return revokeAPIKeyMock(ID)
 }
 
-func (ermuc *exampleRemovalUseCaseMock) DeleteExampleLogically(ID int64, deactivationDatetime time.Time) error {
-	return nil
+func (akuc *apiKeyUseCaseMock) AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	return nil, errors.New("not supported")
 }
 
 func (tp *timeStampMock) GetCurrentTime() time.Time {
@@ -0,0 +1,398 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+var currentTime = time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+
+var rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+var ecKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+var hmacSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestVerifyWhenHS256ThenPrincipal(t *testing.T) {
+	expected := &auth.Principal{
+		Subject: "alice",
+		Scopes:  []string{"examples:read", "examples:write"},
+		Claims: map[string]interface{}{
+			"sub": "alice", "scope": "examples:read examples:write", "iss": "issuer", "aud": "audience",
+			"exp": float64(currentTime.Add(time.Minute).Unix()),
+		},
+	}
+
+	token := sign(t, auth.HS256, "", hmacSecret, claims(nil))
+	got, err := verifier(auth.HMACSecret(hmacSecret)).Verify(context.Background(), token)
+
+	if err != nil {
+		t.Errorf("Verify() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Verify() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestVerifyWhenRS256AndES256ThenPrincipal(t *testing.T) {
+	keys := jwks(t)
+
+	for _, token := range []string{
+		sign(t, auth.RS256, "rsa", rsaKey, claims(nil)),
+		sign(t, auth.ES256, "ec", ecKey, claims(nil)),
+	} {
+		got, err := verifier(keys).Verify(context.Background(), token)
+
+		if err != nil || got.Subject != "alice" {
+			t.Errorf("Verify() failed, expected %v, got %v %v", "alice", got, err)
+		}
+	}
+}
+
+func TestVerifyWhenScpArrayThenScopes(t *testing.T) {
+	expected := []string{"a", "b"}
+
+	token := sign(t, auth.HS256, "", hmacSecret, claims(map[string]interface{}{"scope": nil, "scp": []string{"a", "b"}}))
+	got, err := verifier(auth.HMACSecret(hmacSecret)).Verify(context.Background(), token)
+
+	if err != nil || !reflect.DeepEqual(expected, got.Scopes) {
+		t.Errorf("Verify() failed, expected %v, got %v %v", expected, got, err)
+	}
+}
+
+func TestVerifyWhenClaimsAreInvalidThenError(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"expired":         {"exp": currentTime.Add(-time.Minute).Unix()},
+		"without exp":     {"exp": nil},
+		"not valid yet":   {"nbf": currentTime.Add(time.Minute).Unix()},
+		"other issuer":    {"iss": "other"},
+		"other audience":  {"aud": []string{"other"}},
+		"within leeway":   {"exp": currentTime.Add(-time.Second).Unix()},
+		"audience listed": {"aud": []string{"other", "audience"}},
+	}
+	for name, changes := range tests {
+		token := sign(t, auth.HS256, "", hmacSecret, claims(changes))
+		verifier := verifier(auth.HMACSecret(hmacSecret))
+		verifier.Leeway = 5 * time.Second
+		_, err := verifier.Verify(context.Background(), token)
+
+		valid := name == "within leeway" || name == "audience listed"
+		var authErr *auth.Error
+		if valid != (err == nil) || (err != nil && !errors.As(err, &authErr)) {
+			t.Errorf("Verify(%s) failed, expected valid %v, got %v", name, valid, err)
+		}
+	}
+}
+
+func TestVerifyWhenSignatureIsInvalidThenError(t *testing.T) {
+	rsaPublicKey, _ := json.Marshal(rsaKey.PublicKey)
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tests := map[string]string{
+		"other secret":        sign(t, auth.HS256, "", []byte("other"), claims(nil)),
+		"other key":           sign(t, auth.ES256, "ec", other, claims(nil)),
+		"public key as HMAC":  sign(t, auth.HS256, "rsa", rsaPublicKey, claims(nil)),
+		"unsigned":            sign(t, "none", "", nil, claims(nil)),
+		"malformed":           "a.b",
+		"unknown key":         sign(t, auth.RS256, "unknown", rsaKey, claims(nil)),
+		"algorithm of EC key": sign(t, auth.RS256, "ec", rsaKey, claims(nil)),
+	}
+	for name, token := range tests {
+		_, err := verifier(jwks(t)).Verify(context.Background(), token)
+
+		if err == nil {
+			t.Errorf("Verify(%s) failed, expected %v, got %v", name, "an error", err)
+		}
+	}
+}
+
+func TestReadJWKS(t *testing.T) {
+	data, _ := json.Marshal(jwks(t))
+	dir, _ := ioutil.TempDir("", "jwks")
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "jwks.json")
+	ioutil.WriteFile(fileName, data, 0600)
+
+	keys, err := auth.ReadJWKS(fileName)
+	if err != nil {
+		t.Fatalf("ReadJWKS() failed, error %v", err)
+	}
+	got, err := verifier(keys).Verify(context.Background(), sign(t, auth.RS256, "rsa", rsaKey, claims(nil)))
+
+	if err != nil || got.Subject != "alice" {
+		t.Errorf("ReadJWKS() failed, expected %v, got %v %v", "alice", got, err)
+	}
+}
+
+func TestRemoteJWKSWhenKeyIsUnknownThenFetchedAgain(t *testing.T) {
+	expected := 2
+
+	published := &auth.JWKS{Keys: jwks(t).Keys[:1]}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fetches++
+		json.NewEncoder(writer).Encode(published)
+	}))
+	defer server.Close()
+
+	ts := &timeStampMock{}
+	remote := &auth.RemoteJWKS{URL: server.URL, RefreshInterval: time.Hour, TS: ts}
+	verifier := verifier(remote)
+	verifier.TS = ts
+
+	if _, err := verifier.Verify(context.Background(), sign(t, auth.RS256, "rsa", rsaKey, claims(nil))); err != nil {
+		t.Errorf("Verify() failed, error %v", err)
+	}
+	published = jwks(t)
+	ts.now = currentTime.Add(time.Minute)
+	_, err := verifier.Verify(context.Background(), sign(t, auth.ES256, "ec", ecKey, claims(map[string]interface{}{"exp": currentTime.Add(time.Hour).Unix()})))
+
+	if err != nil {
+		t.Errorf("Verify() failed, error %v", err)
+	}
+	if expected != fetches {
+		t.Errorf("Verify() failed, expected %v fetches, got %v", expected, fetches)
+	}
+}
+
+func TestRemoteJWKSWhenIssuerDownThenFetchesBackedOff(t *testing.T) {
+	expected := []int{1, 1, 2, 2, 3}
+
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fetches++
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ts := &timeStampMock{}
+	remote := &auth.RemoteJWKS{URL: server.URL, TS: ts}
+	var got []int
+	for _, elapsed := range []time.Duration{0, 500 * time.Millisecond, time.Second, 2 * time.Second, 3 * time.Second} {
+		ts.now = currentTime.Add(elapsed)
+		if _, err := remote.Key(context.Background(), "rsa", auth.RS256); err == nil {
+			t.Errorf("Key() failed, expected %v, got %v", "an error", err)
+		}
+		got = append(got, fetches)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Key() failed, expected %v fetches, got %v", expected, got)
+	}
+}
+
+func TestRemoteJWKSWhenFetchingThenLastJWKSUsed(t *testing.T) {
+	fetching := make(chan struct{})
+	published := make(chan struct{})
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fetches++
+		if fetches > 1 {
+			close(fetching)
+			<-published
+		}
+		json.NewEncoder(writer).Encode(jwks(t))
+	}))
+	defer server.Close()
+	defer close(published)
+
+	ts := &timeStampMock{}
+	remote := &auth.RemoteJWKS{URL: server.URL, RefreshInterval: time.Minute, TS: ts}
+	if _, err := remote.Key(context.Background(), "rsa", auth.RS256); err != nil {
+		t.Errorf("Key() failed, error %v", err)
+	}
+	ts.now = currentTime.Add(time.Hour)
+	go remote.Key(context.Background(), "rsa", auth.RS256)
+	<-fetching
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := remote.Key(context.Background(), "rsa", auth.RS256)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Key() failed, expected %v, got %v", "the last JWKS", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Key() failed, expected %v, got %v", "the last JWKS", "waiting for the fetch")
+	}
+}
+
+func TestHasScopes(t *testing.T) {
+	principal := &auth.Principal{Scopes: []string{"a", "b"}}
+
+	if !principal.HasScopes() || !principal.HasScopes("a", "b") || principal.HasScopes("a", "c") {
+		t.Errorf("HasScopes() failed, expected %v, got %v", "only the granted scopes", principal.Scopes)
+	}
+}
+
+func TestAPIKeyVerifierWhenCachedThenNotAuthenticatedAgain(t *testing.T) {
+	expected := &auth.Principal{
+		Subject: "apikey:1",
+		Scopes:  []string{"examples:read"},
+		Claims:  map[string]interface{}{"name": "client", "prefix": "0123456789ab"},
+	}
+
+	calls := 0
+	authenticateAPIKeyMock = func(key string) (*model.APIKey, error) {
+		calls++
+		return &model.APIKey{ID: 1, Name: "client", Prefix: "0123456789ab", Scopes: []string{"examples:read"}, ExpiresAt: currentTime.Add(time.Hour)}, nil
+	}
+	ts := &timeStampMock{}
+	verifier := &auth.APIKeyVerifier{AKUC: &apiKeyUseCaseMock{}, TTL: 2 * time.Hour, TS: ts}
+
+	got, err := verifier.Verify(context.Background(), "key")
+	verifier.Verify(context.Background(), "key")
+
+	if err != nil || !reflect.DeepEqual(expected, got) {
+		t.Errorf("Verify() failed, expected %v, got %v %v", expected, got, err)
+	}
+	if calls != 1 {
+		t.Errorf("Verify() failed, expected %v, got %v", 1, calls)
+	}
+	ts.now = currentTime.Add(time.Hour)
+	verifier.Verify(context.Background(), "key")
+	if calls != 2 {
+		t.Errorf("Verify() failed, expected %v, got %v", "the cache to expire with the key", calls)
+	}
+}
+
+func TestAPIKeyVerifierWhenKeyIsInvalidThenError(t *testing.T) {
+	authenticateAPIKeyMock = func(key string) (*model.APIKey, error) {
+		if key == "unavailable" {
+			return nil, &usecase.UnavailableError{Cause: errors.New("the database is unavailable")}
+		}
+		return nil, &usecase.InvalidCredentialsError{Reason: "unknown API key"}
+	}
+	verifier := &auth.APIKeyVerifier{AKUC: &apiKeyUseCaseMock{}, TTL: time.Minute, TS: &timeStampMock{}}
+
+	_, err := verifier.Verify(context.Background(), "invalid")
+	var authErr *auth.Error
+	if !errors.As(err, &authErr) {
+		t.Errorf("Verify() failed, expected %v, got %v", "auth.Error", err)
+	}
+	_, err = verifier.Verify(context.Background(), "unavailable")
+	if err == nil || errors.As(err, &authErr) {
+		t.Errorf("Verify() failed, expected %v, got %v", "an error other than auth.Error", err)
+	}
+}
+
+func verifier(keys auth.KeySet) *auth.Verifier {
+	return &auth.Verifier{Keys: keys, Issuer: "issuer", Audience: "audience", TS: &timeStampMock{}}
+}
+
+func claims(changes map[string]interface{}) map[string]interface{} {
+	values := map[string]interface{}{
+		"sub":   "alice",
+		"scope": "examples:read examples:write",
+		"iss":   "issuer",
+		"aud":   "audience",
+		"exp":   currentTime.Add(time.Minute).Unix(),
+	}
+	for k, v := range changes {
+		if v == nil {
+			delete(values, k)
+			continue
+		}
+		values[k] = v
+	}
+	return values
+}
+
+func jwks(t *testing.T) *auth.JWKS {
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+	return &auth.JWKS{Keys: []auth.JWK{
+		{KeyType: "RSA", KeyID: "rsa", Use: "sig", N: encode(rsaKey.N), E: encode(big.NewInt(int64(rsaKey.E)))},
+		{KeyType: "EC", KeyID: "ec", Curve: "P-256", X: encode(ecKey.X), Y: encode(ecKey.Y)},
+	}}
+}
+
+// sign is responsible for creating the token the issuer would create with the key
+func sign(t *testing.T, algorithm string, keyID string, key interface{}, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "kid": keyID, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(input))
+
+	var signature []byte
+	switch algorithm {
+	case auth.HS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case auth.RS256:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, hash[:]); err != nil {
+			t.Fatalf("sign() failed, error %v", err)
+		}
+	case auth.ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), hash[:])
+		if err != nil {
+			t.Fatalf("sign() failed, error %v", err)
+		}
+		signature = make([]byte, 64)
+		copy(signature[32-len(r.Bytes()):32], r.Bytes())
+		copy(signature[64-len(s.Bytes()):], s.Bytes())
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+var authenticateAPIKeyMock func(key string) (*model.APIKey, error)
+
+type apiKeyUseCaseMock struct{}
+
+func (akuc *apiKeyUseCaseMock) IssueAPIKey(ctx context.Context, apiKey *model.APIKey) (*model.APIKey, string, error) {
+	return nil, "", errors.New("not supported")
+}
+
+func (akuc *apiKeyUseCaseMock) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	return nil, errors.New("not supported")
+}
+
+func (akuc *apiKeyUseCaseMock) RotateAPIKey(ctx context.Context, ID int64) (*model.APIKey, string, error) {
+	return nil, "", errors.New("not supported")
+}
+
+func (akuc *apiKeyUseCaseMock) RevokeAPIKey(ctx context.Context, ID int64) error {
+	return errors.New("not supported")
+}
+
+func (akuc *apiKeyUseCaseMock) AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	return authenticateAPIKeyMock(key)
+}
+
+type timeStampMock struct {
+	now time.Time
+}
+
+func (tp *timeStampMock) GetCurrentTime() time.Time {
+	if tp.now.IsZero() {
+		return currentTime
+	}
+	return tp.now
+}
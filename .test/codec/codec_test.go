@@ -0,0 +1,297 @@
+package codec
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/codec"
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept   string
+		listing  bool
+		expected string
+	}{
+		{accept: "", expected: "application/json"},
+		{accept: "*/*", expected: "application/json"},
+		{accept: "application/*", expected: "application/json"},
+		{accept: "text/*", expected: "application/xml"},
+		{accept: "text/*", listing: true, expected: "application/xml"},
+		{accept: "text/csv, */*;q=0.1", listing: true, expected: "text/csv"},
+		{accept: "text/csv, */*;q=0.1", expected: "application/json"},
+		{accept: "application/json;q=0.5, application/x-yaml", expected: "application/yaml"},
+		{accept: "*/*, application/json;q=0", expected: "application/xml"},
+		{accept: "APPLICATION/VND.MSGPACK", expected: "application/msgpack"},
+		{accept: "text/html", expected: ""},
+	}
+
+	for _, test := range tests {
+		got := ""
+		if current, ok := codec.Default.Negotiate(test.accept, test.listing); ok {
+			got = current.MediaTypes()[0]
+		}
+
+		if got != test.expected {
+			t.Errorf("Negotiate(%q, %v) failed, expected %v, got %v", test.accept, test.listing, test.expected, got)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := map[string]string{
+		"":                   "application/json",
+		"text/xml":           "application/xml",
+		"application/x-yaml": "application/yaml",
+		"text/csv":           "",
+	}
+
+	for mediaType, expected := range tests {
+		got := ""
+		if current, ok := codec.Default.Lookup(mediaType); ok {
+			got = current.MediaTypes()[0]
+		}
+
+		if got != expected {
+			t.Errorf("Lookup(%q) failed, expected %v, got %v", mediaType, expected, got)
+		}
+	}
+}
+
+func TestMessagePackEncodeWhenExampleThenSortedMap(t *testing.T) {
+	expected := []byte{0x82, 0xa2, 'I', 'D', 0xcd, 0x01, 0x2c, 0xa4, 'N', 'a', 'm', 'e', 0xa1, 'a'}
+
+	var got bytes.Buffer
+	err := codec.MessagePack{}.Encode(&got, map[string]interface{}{"Name": "a", "ID": 300})
+
+	if err != nil || !bytes.Equal(expected, got.Bytes()) {
+		t.Errorf("Encode() failed, expected %v, got %v %v", expected, got.Bytes(), err)
+	}
+}
+
+func TestMessagePackDecodeWhenTimestampThenTime(t *testing.T) {
+	expected := model.Example{ID: -1, Name: "a", CreatedAt: time.Unix(1577934245, 0).UTC()}
+
+	data := []byte{0x83, 0xa2, 'I', 'D', 0xff, 0xa4, 'N', 'a', 'm', 'e', 0xa1, 'a',
+		0xa9, 'C', 'r', 'e', 'a', 't', 'e', 'd', 'A', 't', 0xd6, 0xff, 0x5e, 0x0d, 0x5d, 0xa5}
+	var got model.Example
+	err := codec.MessagePack{}.Decode(bytes.NewReader(data), &got)
+
+	if err != nil || !reflect.DeepEqual(expected, got) {
+		t.Errorf("Decode() failed, expected %v, got %v %v", expected, got, err)
+	}
+}
+
+func TestMessagePackDecodeWhenFormatThenValue(t *testing.T) {
+	tests := map[string]struct {
+		data     []byte
+		expected interface{}
+	}{
+		"nil":              {data: []byte{0xc0}, expected: nil},
+		"false":            {data: []byte{0xc2}, expected: false},
+		"true":             {data: []byte{0xc3}, expected: true},
+		"positive fixint":  {data: []byte{0x05}, expected: 5.0},
+		"negative fixint":  {data: []byte{0xff}, expected: -1.0},
+		"uint8":            {data: []byte{0xcc, 0xff}, expected: 255.0},
+		"uint16":           {data: []byte{0xcd, 0x01, 0x2c}, expected: 300.0},
+		"uint32":           {data: []byte{0xce, 0x00, 0x01, 0x00, 0x00}, expected: 65536.0},
+		"uint64":           {data: []byte{0xcf, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}, expected: 4294967296.0},
+		"int8":             {data: []byte{0xd0, 0x80}, expected: -128.0},
+		"int16":            {data: []byte{0xd1, 0xff, 0x00}, expected: -256.0},
+		"int32":            {data: []byte{0xd2, 0xff, 0xff, 0x00, 0x00}, expected: -65536.0},
+		"int64":            {data: []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00}, expected: -4294967296.0},
+		"float32":          {data: []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}, expected: 1.5},
+		"float64":          {data: []byte{0xcb, 0x3f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, expected: 1.5},
+		"fixstr":           {data: []byte{0xa1, 'a'}, expected: "a"},
+		"str8":             {data: []byte{0xd9, 0x01, 'a'}, expected: "a"},
+		"str16":            {data: []byte{0xda, 0x00, 0x01, 'a'}, expected: "a"},
+		"str32":            {data: []byte{0xdb, 0x00, 0x00, 0x00, 0x01, 'a'}, expected: "a"},
+		"bin8":             {data: []byte{0xc4, 0x01, 'a'}, expected: "YQ=="},
+		"bin16":            {data: []byte{0xc5, 0x00, 0x01, 'a'}, expected: "YQ=="},
+		"bin32":            {data: []byte{0xc6, 0x00, 0x00, 0x00, 0x01, 'a'}, expected: "YQ=="},
+		"fixarray":         {data: []byte{0x91, 0x01}, expected: []interface{}{1.0}},
+		"array16":          {data: []byte{0xdc, 0x00, 0x01, 0x01}, expected: []interface{}{1.0}},
+		"array32":          {data: []byte{0xdd, 0x00, 0x00, 0x00, 0x01, 0x01}, expected: []interface{}{1.0}},
+		"fixmap":           {data: []byte{0x81, 0xa1, 'a', 0x01}, expected: map[string]interface{}{"a": 1.0}},
+		"map16":            {data: []byte{0xde, 0x00, 0x01, 0xa1, 'a', 0x01}, expected: map[string]interface{}{"a": 1.0}},
+		"map32":            {data: []byte{0xdf, 0x00, 0x00, 0x00, 0x01, 0xa1, 'a', 0x01}, expected: map[string]interface{}{"a": 1.0}},
+		"integer key":      {data: []byte{0x81, 0x01, 0xa1, 'a'}, expected: map[string]interface{}{"1": "a"}},
+		"timestamp32":      {data: []byte{0xd6, 0xff, 0x5e, 0x0d, 0x5d, 0xa5}, expected: "2020-01-02T03:04:05Z"},
+		"timestamp64":      {data: []byte{0xd7, 0xff, 0x00, 0x00, 0x00, 0x04, 0x5e, 0x0d, 0x5d, 0xa5}, expected: "2020-01-02T03:04:05.000000001Z"},
+		"timestamp96":      {data: []byte{0xc7, 0x0c, 0xff, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x5e, 0x0d, 0x5d, 0xa5}, expected: "2020-01-02T03:04:05.000000001Z"},
+		"negative seconds": {data: []byte{0xc7, 0x0c, 0xff, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, expected: "1969-12-31T23:59:59Z"},
+	}
+
+	for name, test := range tests {
+		var got interface{}
+		err := codec.MessagePack{}.Decode(bytes.NewReader(test.data), &got)
+
+		if err != nil || !reflect.DeepEqual(test.expected, got) {
+			t.Errorf("Decode(%s) failed, expected %v, got %v %v", name, test.expected, got, err)
+		}
+	}
+}
+
+func TestMessagePackEncodeThenDecoded(t *testing.T) {
+	tests := map[string]struct {
+		value  interface{}
+		format byte
+	}{
+		"nil":             {value: nil, format: 0xc0},
+		"true":            {value: true, format: 0xc3},
+		"positive fixint": {value: 127.0, format: 0x7f},
+		"negative fixint": {value: -32.0, format: 0xe0},
+		"uint8":           {value: 255.0, format: 0xcc},
+		"uint16":          {value: 65535.0, format: 0xcd},
+		"uint32":          {value: 4294967295.0, format: 0xce},
+		"uint64":          {value: 4294967296.0, format: 0xcf},
+		"int8":            {value: -128.0, format: 0xd0},
+		"int16":           {value: -32768.0, format: 0xd1},
+		"int32":           {value: -2147483648.0, format: 0xd2},
+		"int64":           {value: -2147483649.0, format: 0xd3},
+		"float64":         {value: 1.5, format: 0xcb},
+		"fixstr":          {value: strings.Repeat("a", 31), format: 0xbf},
+		"str8":            {value: strings.Repeat("a", 255), format: 0xd9},
+		"str16":           {value: strings.Repeat("a", 65535), format: 0xda},
+		"str32":           {value: strings.Repeat("a", 65536), format: 0xdb},
+		"fixarray":        {value: make([]interface{}, 15), format: 0x9f},
+		"array16":         {value: make([]interface{}, 65535), format: 0xdc},
+		"array32":         {value: make([]interface{}, 65536), format: 0xdd},
+		"fixmap":          {value: makeMap(15), format: 0x8f},
+		"map16":           {value: makeMap(65535), format: 0xde},
+		"map32":           {value: makeMap(65536), format: 0xdf},
+	}
+
+	for name, test := range tests {
+		var data bytes.Buffer
+		err := codec.MessagePack{}.Encode(&data, test.value)
+		var got interface{}
+		if err == nil {
+			err = codec.MessagePack{}.Decode(bytes.NewReader(data.Bytes()), &got)
+		}
+
+		if err != nil || data.Bytes()[0] != test.format || !reflect.DeepEqual(test.value, got) {
+			t.Errorf("Encode(%s) failed, expected 0x%x, got 0x%x %v", name, test.format, data.Bytes()[0], err)
+		}
+	}
+}
+
+func TestMessagePackDecodeWhenMalformedThenError(t *testing.T) {
+	deep := bytes.Repeat([]byte{0x91}, 100)
+	tests := map[string][]byte{
+		"truncated string":  {0xa5, 'a'},
+		"huge array":        {0xdd, 0xff, 0xff, 0xff, 0xff},
+		"too deep":          append(deep, 0xc0),
+		"array key":         {0x81, 0x90, 0xc0},
+		"trailing data":     {0xc0, 0xc0},
+		"unknown extension": {0xd4, 0x01, 0x00},
+		"unused format":     {0xc1},
+		"huge string":       {0xdb, 0xff, 0xff, 0xff, 0xff},
+		"truncated str8":    {0xd9, 0x02, 'a'},
+		"truncated bin16":   {0xc5, 0x00, 0x02, 'a'},
+		"truncated float32": {0xca, 0x3f, 0xc0},
+		"truncated float64": {0xcb, 0x3f, 0xf8, 0x00},
+		"truncated uint64":  {0xcf, 0x00, 0x00},
+		"truncated int32":   {0xd2, 0xff},
+		"truncated array16": {0xdc, 0x00},
+		"truncated array32": {0xdd, 0x00, 0x00, 0x00, 0x01},
+		"truncated map16":   {0xde, 0x00, 0x01, 0xa1, 'a'},
+		"truncated map32":   {0xdf, 0x00, 0x00, 0x00},
+		"huge map":          {0xdf, 0xff, 0xff, 0xff, 0xff},
+		"map key":           {0x81, 0x80, 0xc0},
+		"timestamp32":       {0xd6, 0xff, 0x5e, 0x0d},
+		"timestamp64":       {0xd7, 0xff, 0x00, 0x00, 0x00, 0x04},
+		"timestamp96":       {0xc7, 0x0c, 0xff, 0x00, 0x00, 0x00, 0x01},
+		"timestamp length":  {0xc7, 0x03, 0xff, 0x00, 0x00, 0x00},
+	}
+
+	for name, data := range tests {
+		var got interface{}
+		if err := (codec.MessagePack{}).Decode(bytes.NewReader(data), &got); err == nil {
+			t.Errorf("Decode(%s) failed, expected %v, got %v", name, "an error", got)
+		}
+	}
+}
+
+func TestXMLDecodeWhenBatchThenTyped(t *testing.T) {
+	expected := api.BatchRequest{Mode: "atomic", Items: []api.BatchItem{
+		{Operation: "delete", ID: 2},
+		{Operation: "create", Example: model.Example{Name: "a", Useful: true}},
+	}}
+
+	data := `<batchRequest><mode>atomic</mode><items>` +
+		`<item><id>2</id><op>delete</op></item>` +
+		`<item><op>create</op><example><Name>a</Name><Useful>true</Useful></example></item>` +
+		`</items></batchRequest>`
+	var got api.BatchRequest
+	err := codec.XML{}.Decode(strings.NewReader(data), &got)
+
+	if err != nil || !reflect.DeepEqual(expected, got) {
+		t.Errorf("Decode() failed, expected %v, got %v %v", expected, got, err)
+	}
+}
+
+func TestXMLDecodeWhenInvalidNumberThenError(t *testing.T) {
+	var got model.Example
+	err := codec.XML{}.Decode(strings.NewReader("<example><ID>one</ID></example>"), &got)
+
+	if err == nil {
+		t.Errorf("Decode() failed, expected %v, got %v", "an error", got)
+	}
+}
+
+func TestXMLEncodeWhenNameIsNotXMLNameThenEntry(t *testing.T) {
+	expected := `<response><entry key="a b">1</entry><items><item>x</item></items></response>`
+
+	var got bytes.Buffer
+	err := codec.XML{}.Encode(&got, map[string]interface{}{"a b": 1, "items": []string{"x"}, "none": nil})
+
+	if err != nil || !strings.Contains(got.String(), expected) {
+		t.Errorf("Encode() failed, expected %v, got %v %v", expected, got.String(), err)
+	}
+}
+
+func TestXMLEncodeWhenVersionedTypeThenRootWithoutVersion(t *testing.T) {
+	expected := `<example><created_at>0001-01-01T00:00:00Z</created_at><id>1</id><name>a</name>`
+
+	var got bytes.Buffer
+	err := codec.XML{}.Encode(&got, api.ExampleV2{ID: 1, Name: "a"})
+
+	if err != nil || !strings.Contains(got.String(), expected) || strings.Contains(got.String(), "deactivated_at") {
+		t.Errorf("Encode() failed, expected %v, got %v %v", expected, got.String(), err)
+	}
+}
+
+func TestCSVEncodeWhenNestedThenJSONCell(t *testing.T) {
+	expected := "id,name,prefix,scopes,createdAt,expiresAt,revokedAt\n1,a,,\"[\"\"x\"\",\"\"y\"\"]\",0001-01-01T00:00:00Z,,\n"
+
+	var got bytes.Buffer
+	err := codec.CSV{}.Encode(&got, api.Page{Items: []api.APIKey{{ID: 1, Name: "a", Scopes: []string{"x", "y"}}}})
+
+	if err != nil || got.String() != expected {
+		t.Errorf("Encode() failed, expected %v, got %v %v", expected, got.String(), err)
+	}
+}
+
+func TestCSVEncodeWhenNotListingThenError(t *testing.T) {
+	var got bytes.Buffer
+	err := codec.CSV{}.Encode(&got, model.Example{})
+
+	if err == nil {
+		t.Errorf("Encode() failed, expected %v, got %v", "an error", got.String())
+	}
+}
+
+func makeMap(length int) map[string]interface{} {
+	fields := make(map[string]interface{}, length)
+	for i := 0; i < length; i++ {
+		fields[strconv.Itoa(i)] = float64(i)
+	}
+	return fields
+}
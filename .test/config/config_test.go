@@ -1,17 +1,130 @@
 package config
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/zeroberto/go-ms-template/config"
 )
 
 func TestReadConfig(t *testing.T) {
 	expectedAppConfig := config.AppConfig{
+		ServerConfig: config.ServerConfig{
+			Address:         ":1",
+			ShutdownTimeout: time.Second,
+			RequestTimeout:  2 * time.Second,
+			RouteTimeouts: map[string]time.Duration{
+				"listExamples": 3 * time.Second,
+			},
+			RequireIfMatch: true,
+			CacheControl: map[string]string{
+				"getExample": "max-age=4",
+			},
+			MaxBatchSize: 5,
+			Versions: map[string]config.VersionConfig{
+				"v1": {
+					Deprecation: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+					Sunset:      time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+				},
+			},
+			Listeners: []config.ListenerConfig{
+				{
+					Address: ":2",
+					TLS: config.TLSConfig{
+						CertFile:       "certFile",
+						KeyFile:        "keyFile",
+						ClientCAFile:   "clientCaFile",
+						ClientAuth:     "verifyIfGiven",
+						MinVersion:     "1.3",
+						CipherSuites:   []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+						ReloadInterval: 16 * time.Second,
+					},
+					HTTP2: true,
+				},
+				{Address: ":3", H2C: true},
+			},
+		},
+		MiddlewareConfig: config.MiddlewareConfig{
+			RequestIDHeader: "X-Trace",
+			AccessLog:       true,
+			RecoverPanics:   true,
+			MaxBodySize:     6,
+		},
+		SecurityConfig: config.SecurityConfig{
+			CORS: config.CORSConfig{
+				AllowedOrigins:   []string{"https://*.example.com"},
+				AllowedMethods:   []string{"GET"},
+				AllowedHeaders:   []string{"Authorization"},
+				ExposedHeaders:   []string{"ETag"},
+				AllowCredentials: true,
+				MaxAge:           14 * time.Second,
+			},
+			Headers: config.SecurityHeadersConfig{
+				HSTSMaxAge:            15 * time.Second,
+				HSTSIncludeSubdomains: true,
+				ContentTypeNosniff:    true,
+				FrameOptions:          "DENY",
+				ContentSecurityPolicy: "default-src 'none'",
+				ReferrerPolicy:        "no-referrer",
+			},
+		},
+		RateLimitConfig: config.RateLimitConfig{
+			Keys:    []string{"subject", "ip"},
+			Default: config.RateLimit{Rate: 0.5, Burst: 7},
+			Routes: map[string]config.RateLimit{
+				"listExamples": {Rate: 8, Burst: 9},
+			},
+			MaxInFlight: 10,
+		},
+		AuthConfig: config.AuthConfig{
+			Enabled:             true,
+			Realm:               "realm",
+			Issuer:              "issuer",
+			Audience:            "audience",
+			Leeway:              11 * time.Second,
+			HMACSecretFile:      "hmacSecretFile",
+			JWKSFile:            "jwksFile",
+			JWKSURL:             "jwksUrl",
+			JWKSRefreshInterval: 12 * time.Second,
+			APIKeys:             true,
+			APIKeyCacheTTL:      13 * time.Second,
+			Scopes: map[string][]string{
+				"listExamples": {"examples:read"},
+			},
+			ClientCertificateScopes: map[string][]string{
+				"CN=client": {"examples:read"},
+			},
+		},
+		PaginationConfig: config.PaginationConfig{
+			DefaultPageSize: 2,
+			MaxPageSize:     3,
+		},
+		IdempotencyConfig: config.IdempotencyConfig{
+			Store: "memory",
+			TTL:   24 * time.Hour,
+			Lease: time.Minute,
+			Wait:  7 * time.Second,
+		},
+		StreamConfig: config.StreamConfig{
+			ReplaySize:       17,
+			SubscriberBuffer: 18,
+			MaxSubscribers:   22,
+			KeepAlive:        19 * time.Second,
+		},
+		HealthConfig: config.HealthConfig{
+			Timeout:    20 * time.Second,
+			Timeouts:   map[string]time.Duration{"sql database": 21 * time.Second},
+			DrainDelay: 22 * time.Second,
+			CacheTTL:   23 * time.Second,
+		},
 		SQLDBConfig: config.SQLDBConfig{
-			Type: "test",
-			Host: "host",
-			Port: 1,
+			Type:         "test",
+			Host:         "host",
+			Port:         1,
+			Database:     "database",
+			User:         "user",
+			PasswordFile: "passwordFile",
 		},
 	}
 
@@ -22,7 +135,7 @@ func TestReadConfig(t *testing.T) {
 		t.Errorf("ReadConfig() failed, error %v", err)
 	}
 
-	if expectedAppConfig != *appConfig {
+	if !reflect.DeepEqual(expectedAppConfig, *appConfig) {
 		t.Errorf("ReadConfig() failed, expected %v, got %v", expectedAppConfig, appConfig)
 	}
 }
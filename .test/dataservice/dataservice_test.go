@@ -0,0 +1,238 @@
+package dataservice
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/zeroberto/go-ms-template/dataservice/exampledata/datamysql"
+	idempotencydatamysql "github.com/zeroberto/go-ms-template/dataservice/idempotencydata/datamysql"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver/sqldbdriver"
+	"github.com/zeroberto/go-ms-template/filter"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+func TestFindPageWhenFilteredAndSortedByAnyFieldThenQueried(t *testing.T) {
+	values := map[filter.Type]interface{}{
+		filter.String: "a",
+		filter.Int:    int64(1),
+		filter.Bool:   true,
+		filter.Time:   time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC),
+	}
+	queried := errors.New("queried")
+
+	for field, fieldType := range model.ExampleFields {
+		sqldQueryMock = func(query string, args ...interface{}) (*sql.Rows, error) {
+			return nil, queried
+		}
+		ds := &datamysql.ExampleDataServiceMySQL{SQLD: &sqlDriverMock{}}
+
+		_, err := ds.FindPage(context.Background(), model.PageRequest{
+			Limit:  1,
+			Filter: filter.Comparison{Field: field, Operator: filter.Equal, Value: values[fieldType]},
+			Sort:   []filter.Order{{Field: field}},
+		})
+
+		if !errors.Is(err, queried) {
+			t.Errorf("FindPage(%s) failed, expected %v, got %v", field, queried, err)
+		}
+	}
+}
+
+func TestFindPageWhenFilteredByDeactivationThenSameExamplesAsMatch(t *testing.T) {
+	deactivatedAt := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	examples := []model.Example{{ID: 1}, {ID: 2, DeactivatedAt: deactivatedAt}}
+	// rows holds the values of the columns of the Examples as MySQL provides them
+	rows := []map[string]interface{}{
+		{"deactivated_at": nil, "(deactivated_at IS NULL)": true},
+		{"deactivated_at": deactivatedAt, "(deactivated_at IS NULL)": false},
+	}
+	expressions := []string{
+		"active==true",
+		"active==false",
+		"deactivatedAt==2020-07-01T10:00:00Z",
+		"deactivatedAt!=2020-07-01T10:00:00Z",
+		"deactivatedAt=lt=2021-01-01",
+		"deactivatedAt=ge=2020-01-01",
+	}
+
+	for _, expression := range expressions {
+		parsed, err := filter.Parse(expression, model.ExampleFields)
+		if err != nil {
+			t.Fatalf("Parse(%s) failed, error %v", expression, err)
+		}
+		var query string
+		var args []interface{}
+		sqldQueryMock = func(q string, a ...interface{}) (*sql.Rows, error) {
+			query, args = q, a
+			return nil, errors.New("queried")
+		}
+		ds := &datamysql.ExampleDataServiceMySQL{SQLD: &sqlDriverMock{}}
+		ds.FindPage(context.Background(), model.PageRequest{Limit: 1, Filter: parsed})
+
+		condition := strings.TrimPrefix(query[:strings.Index(query, " ORDER BY")], "SELECT * FROM example WHERE "+datamysql.AllExamplesCondition+" AND ")
+		for i, example := range examples {
+			expected := filter.Match(parsed, example.FilterValue)
+			got := evaluate(t, condition, args[0], rows[i])
+
+			if expected != got {
+				t.Errorf("FindPage(%s) failed on Example %d, expected %v, got %v", expression, example.ID, expected, got)
+			}
+		}
+	}
+}
+
+// evaluate is responsible for evaluating a condition "column operator ?" against a row as
+// MySQL does, no comparison being satisfied by NULL
+func evaluate(t *testing.T, condition string, arg interface{}, row map[string]interface{}) bool {
+	separator := strings.LastIndex(strings.TrimSuffix(condition, " ?"), " ")
+	column, operator := condition[:separator], strings.TrimSuffix(condition[separator+1:], " ?")
+	value, ok := row[column]
+	if !ok {
+		t.Fatalf("evaluate() failed, expected a column, got %v", column)
+	}
+	var result int
+	switch x := value.(type) {
+	case nil:
+		return false
+	case bool:
+		if x != arg.(bool) {
+			result = 1
+		}
+	case time.Time:
+		y := arg.(time.Time)
+		switch {
+		case x.Before(y):
+			result = -1
+		case x.After(y):
+			result = 1
+		}
+	}
+	return map[string]bool{
+		"=":  result == 0,
+		"<>": result != 0,
+		"<":  result < 0,
+		"<=": result <= 0,
+		">":  result > 0,
+		">=": result >= 0,
+	}[operator]
+}
+
+func TestReleaseThenOnlyReservationDeleted(t *testing.T) {
+	expected := []interface{}{"key", "reservation"}
+
+	var query string
+	var got []interface{}
+	sqldPrepareAndExecuteMock = func(q string, args ...interface{}) (sql.Result, error) {
+		query, got = q, args
+		return nil, nil
+	}
+	ds := &idempotencydatamysql.IdempotencyDataServiceMySQL{SQLD: &sqlDriverMock{}}
+
+	err := ds.Release(context.Background(), "key", "reservation")
+
+	if err != nil || query != idempotencydatamysql.ReleaseIdempotentRequest || !reflect.DeepEqual(expected, got) {
+		t.Errorf("Release() failed, expected %v, got %v %v", expected, got, err)
+	}
+}
+
+func TestCreateWhenNameDuplicatedThenConflictError(t *testing.T) {
+	ds := &datamysql.ExampleDataServiceMySQL{SQLD: &sqldbdriver.SQLDBDriver{DB: sql.OpenDB(&duplicateConnector{})}}
+
+	_, err := ds.Create(context.Background(), &model.Example{Name: "test"})
+
+	var conflictErr *usecase.ConflictError
+	if !errors.As(usecase.Wrap(err), &conflictErr) {
+		t.Errorf("Create() failed, expected %T, got %v", conflictErr, err)
+	}
+}
+
+var sqldPrepareAndExecuteMock func(query string, args ...interface{}) (sql.Result, error)
+
+var sqldQueryMock func(query string, args ...interface{}) (*sql.Rows, error)
+
+type sqlDriverMock struct{}
+
+func (sqld *sqlDriverMock) Execute(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
+func (sqld *sqlDriverMock) PrepareAndExecute(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return sqldPrepareAndExecuteMock(query, args...)
+}
+
+func (sqld *sqlDriverMock) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return sqldQueryMock(query, args...)
+}
+
+func (sqld *sqlDriverMock) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (sqld *sqlDriverMock) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (sqld *sqlDriverMock) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (sqld *sqlDriverMock) EndTransaction(ctx context.Context) error {
+	return nil
+}
+
+func (sqld *sqlDriverMock) Rollback(ctx context.Context) error {
+	return nil
+}
+
+// duplicateConnector opens connections whose statements fail as the violations of the unique
+// keys of MySQL
+type duplicateConnector struct{}
+
+func (connector *duplicateConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &duplicateConn{}, nil
+}
+
+func (connector *duplicateConnector) Driver() driver.Driver {
+	return nil
+}
+
+type duplicateConn struct{}
+
+func (conn *duplicateConn) Prepare(query string) (driver.Stmt, error) {
+	return &duplicateStmt{}, nil
+}
+
+func (conn *duplicateConn) Close() error {
+	return nil
+}
+
+func (conn *duplicateConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type duplicateStmt struct{}
+
+func (stmt *duplicateStmt) Close() error {
+	return nil
+}
+
+func (stmt *duplicateStmt) NumInput() int {
+	return -1
+}
+
+func (stmt *duplicateStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'test' for key 'example_name_UNIQUE'"}
+}
+
+func (stmt *duplicateStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
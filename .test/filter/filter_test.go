@@ -0,0 +1,219 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/filter"
+)
+
+var fields = filter.Fields{
+	"id":        filter.Int,
+	"name":      filter.String,
+	"useful":    filter.Bool,
+	"createdAt": filter.Time,
+}
+
+var columns = filter.Columns{
+	"id":        "id",
+	"name":      "name",
+	"useful":    "useful",
+	"createdAt": "created_at",
+}
+
+func TestParse(t *testing.T) {
+	expected := filter.Or{Operands: []filter.Expression{
+		filter.And{Operands: []filter.Expression{
+			filter.Comparison{Field: "name", Operator: filter.Prefix, Value: "foo"},
+			filter.Comparison{Field: "useful", Operator: filter.Equal, Value: true},
+		}},
+		filter.Comparison{Field: "createdAt", Operator: filter.GreaterOrEqual, Value: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}}
+
+	got, err := filter.Parse("name=prefix:foo;useful==true,createdAt=ge=2026-01-01", fields)
+
+	if err != nil {
+		t.Errorf("Parse() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Parse() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseWhenParenthesesAndQuotesThenGrouped(t *testing.T) {
+	expected := filter.And{Operands: []filter.Expression{
+		filter.Or{Operands: []filter.Expression{
+			filter.Comparison{Field: "id", Operator: filter.LessThan, Value: int64(3)},
+			filter.Comparison{Field: "name", Operator: filter.Equal, Value: `a "b", c`},
+		}},
+		filter.Comparison{Field: "useful", Operator: filter.NotEqual, Value: false},
+	}}
+
+	got, err := filter.Parse(` ( id=lt=3 , name=="a \"b\", c" ) ; useful!=false `, fields)
+
+	if err != nil {
+		t.Errorf("Parse() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Parse() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseWhenEmptyThenNil(t *testing.T) {
+	got, err := filter.Parse("  ", fields)
+
+	if got != nil || err != nil {
+		t.Errorf("Parse() failed, expected %v, got %v, %v", nil, got, err)
+	}
+}
+
+func TestParseWhenInvalidThenErrorAtToken(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   filter.Error
+	}{
+		{"unknown==1", filter.Error{Message: "unknown field", Token: "unknown", Position: 0}},
+		{"id==1;name=like=a", filter.Error{Message: "unknown operator", Token: "=like=", Position: 10}},
+		{"useful=gt=true", filter.Error{Message: "operator not supported by field useful", Token: "=gt=", Position: 6}},
+		{"id==1;useful==maybe", filter.Error{Message: "invalid value for field useful", Token: "maybe", Position: 14}},
+		{"createdAt=ge=yesterday", filter.Error{Message: "invalid value for field createdAt", Token: "yesterday", Position: 13}},
+		{"id==1)", filter.Error{Message: "unexpected token", Token: ")", Position: 5}},
+		{"(id==1", filter.Error{Message: "unclosed parenthesis", Token: "(", Position: 0}},
+		{"name=='foo", filter.Error{Message: "unclosed quote", Token: "'", Position: 6}},
+		{"id 1", filter.Error{Message: "expected operator", Token: "1", Position: 3}},
+		{"id==1;", filter.Error{Message: "unexpected end of expression, expected field", Position: 6}},
+	}
+
+	for _, test := range tests {
+		_, got := filter.Parse(test.expression, fields)
+
+		if gotErr, ok := got.(*filter.Error); !ok || test.expected != *gotErr {
+			t.Errorf("Parse(%s) failed, expected %v, got %v", test.expression, &test.expected, got)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	expected := []filter.Order{{Field: "name"}, {Field: "createdAt", Descending: true}, {Field: "id"}}
+
+	got, err := filter.ParseSort("name, -createdAt,+id", fields)
+
+	if err != nil {
+		t.Errorf("ParseSort() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ParseSort() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseSortWhenInvalidThenErrorAtToken(t *testing.T) {
+	tests := []struct {
+		sort     string
+		expected filter.Error
+	}{
+		{"name,-other", filter.Error{Message: "unknown field", Token: "other", Position: 5}},
+		{"name,name", filter.Error{Message: "duplicate sort field", Token: "name", Position: 5}},
+		{"name,,id", filter.Error{Message: "missing sort field", Position: 5}},
+	}
+
+	for _, test := range tests {
+		_, got := filter.ParseSort(test.sort, fields)
+
+		if gotErr, ok := got.(*filter.Error); !ok || test.expected != *gotErr {
+			t.Errorf("ParseSort(%s) failed, expected %v, got %v", test.sort, &test.expected, got)
+		}
+	}
+}
+
+func TestToSQL(t *testing.T) {
+	expectedCondition := "((name LIKE ? ESCAPE '!' AND useful = ?) OR id <> ?)"
+	expectedArgs := []interface{}{"50!%!_off%", true, int64(3)}
+
+	expression, _ := filter.Parse("name=prefix:50%_off;useful==true,id!=3", fields)
+	gotCondition, gotArgs, err := filter.ToSQL(expression, columns)
+
+	if err != nil {
+		t.Errorf("ToSQL() failed, error %v", err)
+	}
+	if expectedCondition != gotCondition {
+		t.Errorf("ToSQL() failed, expected %v, got %v", expectedCondition, gotCondition)
+	}
+	if !reflect.DeepEqual(expectedArgs, gotArgs) {
+		t.Errorf("ToSQL() failed, expected %v, got %v", expectedArgs, gotArgs)
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	expected := []string{"name ASC", "created_at DESC"}
+
+	got, err := filter.OrderBy([]filter.Order{{Field: "name"}, {Field: "createdAt", Descending: true}}, columns)
+
+	if err != nil {
+		t.Errorf("OrderBy() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("OrderBy() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	values := func(field string) interface{} {
+		return map[string]interface{}{
+			"id":        int64(3),
+			"name":      "foobar",
+			"useful":    true,
+			"createdAt": time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		}[field]
+	}
+	tests := map[string]bool{
+		"name=prefix:foo;useful==true":                  true,
+		"name=contains:oba;id=ge=3":                     true,
+		"name=prefix:bar,createdAt=lt=2026-01-01":       false,
+		"id==2,(useful!=false;createdAt=gt=2026-01-31)": true,
+	}
+
+	for expression, expected := range tests {
+		parsed, _ := filter.Parse(expression, fields)
+		got := filter.Match(parsed, values)
+
+		if expected != got {
+			t.Errorf("Match(%s) failed, expected %v, got %v", expression, expected, got)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := func(field string) interface{} {
+		return map[string]interface{}{"name": "a", "id": int64(1)}[field]
+	}
+	b := func(field string) interface{} {
+		return map[string]interface{}{"name": "a", "id": int64(2)}[field]
+	}
+
+	got := filter.Compare([]filter.Order{{Field: "name"}, {Field: "id", Descending: true}}, a, b)
+
+	if got <= 0 {
+		t.Errorf("Compare() failed, expected %v, got %v", "a positive number", got)
+	}
+}
+
+func TestCompareWhenValueIsNilThenFirstInAscendingOrder(t *testing.T) {
+	expected := []int{-1, 1}
+
+	a := func(field string) interface{} {
+		return nil
+	}
+	b := func(field string) interface{} {
+		return time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	got := []int{
+		filter.Compare([]filter.Order{{Field: "createdAt"}}, a, b),
+		filter.Compare([]filter.Order{{Field: "createdAt", Descending: true}}, a, b),
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Compare() failed, expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,1588 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/api/rest"
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/codec"
+	"github.com/zeroberto/go-ms-template/dataservice/idempotencydata/datamemory"
+	"github.com/zeroberto/go-ms-template/handler/httphandler"
+	"github.com/zeroberto/go-ms-template/handler/middleware"
+	"github.com/zeroberto/go-ms-template/health"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/openapi"
+	"github.com/zeroberto/go-ms-template/patch"
+	"github.com/zeroberto/go-ms-template/usecase/idempotency"
+	"github.com/zeroberto/go-ms-template/usecase/stream"
+)
+
+func TestServeHTTPWhenGetExamplesThenSuccess(t *testing.T) {
+	expected := `{"items":[{"ID":1,"Name":"test","Useful":false,"CreatedAt":"0001-01-01T00:00:00Z","DeactivatedAt":"0001-01-01T00:00:00Z","Version":0,"UpdatedAt":"0001-01-01T00:00:00Z"}]}`
+
+	getMock = func(pageParams api.PageParams) api.Response {
+		return api.Response{Code: http.StatusOK, Body: api.Page{Items: []model.Example{{ID: 1, Name: "test"}}}}
+	}
+
+	got := serve(http.MethodGet, "/examples", "")
+
+	if got.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusOK, got.Code)
+	}
+	if strings.TrimSpace(got.Body.String()) != expected {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got.Body.String())
+	}
+	if got.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "application/json", got.Header().Get("Content-Type"))
+	}
+}
+
+func TestServeHTTPWhenGetExamplesPageThenLinks(t *testing.T) {
+	expectedPageParams := api.PageParams{Limit: 2, Offset: 4, Total: true, Filter: "useful==true", Sort: "-name"}
+	expectedLink := `</examples?filter=useful%3D%3Dtrue&limit=2&sort=-name&total=true>; rel="first", </examples?cursor=p&filter=useful%3D%3Dtrue&limit=2&sort=-name&total=true>; rel="prev", </examples?cursor=n&filter=useful%3D%3Dtrue&limit=2&sort=-name&total=true>; rel="next"`
+
+	var gotPageParams api.PageParams
+	getMock = func(pageParams api.PageParams) api.Response {
+		gotPageParams = pageParams
+		return api.Response{Code: http.StatusOK, Body: api.Page{Items: []model.Example{}, Next: "n", Previous: "p"}}
+	}
+
+	got := serve(http.MethodGet, "/examples?limit=2&offset=4&total=true&filter=useful%3D%3Dtrue&sort=-name", "")
+
+	if expectedPageParams != gotPageParams {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expectedPageParams, gotPageParams)
+	}
+	if got.Header().Get("Link") != expectedLink {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expectedLink, got.Header().Get("Link"))
+	}
+}
+
+func TestServeHTTPWhenPageParamsAreInvalidThenBadRequest(t *testing.T) {
+	for _, query := range []string{"limit=a", "offset=a", "total=a"} {
+		got := serve(http.MethodGet, "/examples?"+query, "")
+
+		if got.Code != http.StatusBadRequest {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", query, http.StatusBadRequest, got.Code)
+		}
+	}
+}
+
+func TestServeHTTPWhenPostExampleThenLocation(t *testing.T) {
+	expected := model.Example{Name: "test", Useful: true}
+
+	var got model.Example
+	createMock = func(example model.Example) api.Response {
+		got = example
+		return api.Response{Code: http.StatusCreated, Path: 7}
+	}
+
+	recorder := serve(http.MethodPost, "/examples", `{"Name":"test","Useful":true}`)
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusCreated, recorder.Code)
+	}
+	if recorder.Header().Get("Location") != "/examples/7" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "/examples/7", recorder.Header().Get("Location"))
+	}
+	if expected != got {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenPostInvalidBodyThenBadRequest(t *testing.T) {
+	got := serve(http.MethodPost, "/examples", `{"Name":`)
+
+	if got.Code != http.StatusBadRequest {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusBadRequest, got.Code)
+	}
+}
+
+func TestServeHTTPWhenBodyExceedsLimitThenRequestEntityTooLarge(t *testing.T) {
+	handler := middleware.MaxBodySize(4, &timeStampMock{})(&httphandler.RestHTTPHandler{EAPI: &exampleAPIMock{}, TS: &timeStampMock{}})
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader(`{"Name":"test"}`))
+	request.ContentLength = -1
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge || !strings.Contains(recorder.Body.String(), "at most 4 bytes") {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", http.StatusRequestEntityTooLarge, recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestServeHTTPWhenGetExampleByIDThenSuccess(t *testing.T) {
+	var got int64
+	getByIDMock = func(ID int64) api.Response {
+		got = ID
+		return api.Response{Code: http.StatusOK, Body: model.Example{ID: ID}}
+	}
+
+	recorder := serve(http.MethodGet, "/examples/3", "")
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusOK, recorder.Code)
+	}
+	if got != 3 {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", 3, got)
+	}
+}
+
+func TestServeHTTPWhenPutExampleThenIDFromPath(t *testing.T) {
+	expected := model.Example{ID: 3, Name: "test"}
+
+	var got model.Example
+	updateMock = func(ID int64, example model.Example, preconditions api.Preconditions) api.Response {
+		got = example
+		return api.Response{Code: http.StatusNoContent}
+	}
+
+	recorder := serve(http.MethodPut, "/examples/3", `{"Name":"test"}`)
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusNoContent, recorder.Code)
+	}
+	if expected != got {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenPatchExampleThenMergePatch(t *testing.T) {
+	expected := patch.MergePatch{Value: map[string]interface{}{"Useful": true}}
+
+	var got patch.Patch
+	partialUpdateMock = func(ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response {
+		got = examplePatch
+		return api.Response{Code: http.StatusNoContent}
+	}
+
+	for _, contentType := range []string{patch.MergePatchType, "application/json; charset=utf-8"} {
+		recorder := servePatch(contentType, `{"Useful":true}`)
+
+		if recorder.Code != http.StatusNoContent {
+			t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusNoContent, recorder.Code)
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestServeHTTPWhenPatchExampleThenJSONPatch(t *testing.T) {
+	expected := patch.JSONPatch{{Op: "replace", Path: "/Name", Value: json.RawMessage(`"test"`)}}
+
+	var got patch.Patch
+	partialUpdateMock = func(ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response {
+		got = examplePatch
+		return api.Response{Code: http.StatusNoContent}
+	}
+
+	recorder := servePatch(patch.JSONPatchType, `[{"op":"replace","path":"/Name","value":"test"}]`)
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusNoContent, recorder.Code)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenPatchExampleWithUnsupportedMediaTypeThenUnsupportedMediaType(t *testing.T) {
+	partialUpdateMock = func(ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response {
+		t.Errorf("PartialUpdate() should not be called")
+		return api.Response{}
+	}
+
+	got := servePatch("text/plain", `{"Useful":true}`)
+
+	if got.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusUnsupportedMediaType, got.Code)
+	}
+	if got.Header().Get("Accept-Patch") != httphandler.AcceptPatch {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", httphandler.AcceptPatch, got.Header().Get("Accept-Patch"))
+	}
+}
+
+func TestServeHTTPWhenPatchExampleIsMalformedThenBadRequest(t *testing.T) {
+	got := servePatch(patch.JSONPatchType, `[{"op":"swap","path":"/Name"}]`)
+
+	if got.Code != http.StatusBadRequest {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusBadRequest, got.Code)
+	}
+}
+
+func TestServeHTTPWhenBatchExamplesThenResults(t *testing.T) {
+	expectedBatch := api.BatchRequest{Mode: "bestEffort", Items: []api.BatchItem{{Operation: "delete", ID: 3, IfMatch: `"1"`}}}
+	expected := `{"results":[{"status":204,"id":3}]}`
+
+	var gotBatch api.BatchRequest
+	batchMock = func(batch api.BatchRequest) api.Response {
+		gotBatch = batch
+		return api.Response{Code: http.StatusOK, Body: api.BatchResponse{Results: []api.BatchResult{{Status: http.StatusNoContent, ID: 3}}}}
+	}
+
+	got := serve(http.MethodPost, "/examples:batch", `{"mode":"bestEffort","items":[{"op":"delete","id":3,"ifMatch":"\"1\""}]}`)
+
+	if got.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusOK, got.Code)
+	}
+	if !reflect.DeepEqual(expectedBatch, gotBatch) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expectedBatch, gotBatch)
+	}
+	if strings.TrimSpace(got.Body.String()) != expected {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got.Body.String())
+	}
+}
+
+func TestServeHTTPWhenGetActiveExamplesThenActivePage(t *testing.T) {
+	expected := api.PageParams{Limit: 2}
+
+	var got api.PageParams
+	getActiveMock = func(pageParams api.PageParams) api.Response {
+		got = pageParams
+		return api.Response{Code: http.StatusOK, Body: api.Page{Items: []model.Example{}}}
+	}
+
+	recorder := serve(http.MethodGet, "/examples/active?limit=2", "")
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusOK, recorder.Code)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenGetExampleByNameThenUnescapedName(t *testing.T) {
+	expected := "a/b c"
+
+	var got string
+	getByNameMock = func(name string) api.Response {
+		got = name
+		return api.Response{Code: http.StatusOK, Body: model.Example{Name: name}}
+	}
+
+	recorder := serve(http.MethodGet, "/examples/name/a%2Fb%20c", "")
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusOK, recorder.Code)
+	}
+	if expected != got {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenDeleteExampleLogicallyThenDeactivated(t *testing.T) {
+	var got int64
+	deleteLogicallyMock = func(ID int64, preconditions api.Preconditions) api.Response {
+		got = ID
+		return api.Response{Code: http.StatusNoContent}
+	}
+	deleteMock = func(ID int64, preconditions api.Preconditions) api.Response {
+		t.Errorf("Delete() should not be called")
+		return api.Response{}
+	}
+
+	recorder := serve(http.MethodDelete, "/examples/3?mode=logical", "")
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusNoContent, recorder.Code)
+	}
+	if got != 3 {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", 3, got)
+	}
+}
+
+func TestServeHTTPWhenDeleteModeIsInvalidThenBadRequest(t *testing.T) {
+	got := serve(http.MethodDelete, "/examples/3?mode=soft", "")
+
+	if got.Code != http.StatusBadRequest {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusBadRequest, got.Code)
+	}
+}
+
+func TestServeHTTPWhenDeleteExampleThenSuccess(t *testing.T) {
+	deleteMock = func(ID int64, preconditions api.Preconditions) api.Response {
+		return api.Response{Code: http.StatusNoContent}
+	}
+
+	got := serve(http.MethodDelete, "/examples/3", "")
+
+	if got.Code != http.StatusNoContent {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusNoContent, got.Code)
+	}
+}
+
+func TestServeHTTPWhenGetExampleByIDThenETag(t *testing.T) {
+	getByIDMock = func(ID int64) api.Response {
+		return api.Response{Code: http.StatusOK, ETag: `"2"`, Body: model.Example{ID: ID, Version: 2}}
+	}
+
+	got := serve(http.MethodGet, "/examples/3", "")
+
+	if got.Header().Get("ETag") != `"2"` {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", `"2"`, got.Header().Get("ETag"))
+	}
+}
+
+func TestServeHTTPWhenConditionalGetThenNotModified(t *testing.T) {
+	lastModified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		header   string
+		value    string
+		expected int
+	}{
+		{"If-None-Match", `"2"`, http.StatusNotModified},
+		{"If-None-Match", `"1", W/"2"`, http.StatusNotModified},
+		{"If-None-Match", "*", http.StatusNotModified},
+		{"If-None-Match", `"1"`, http.StatusOK},
+		{"If-Modified-Since", "Fri, 02 Jan 2026 03:04:05 GMT", http.StatusNotModified},
+		{"If-Modified-Since", "Fri, 02 Jan 2026 03:04:04 GMT", http.StatusOK},
+	}
+
+	getByIDMock = func(ID int64) api.Response {
+		return api.Response{
+			Code:         http.StatusOK,
+			ETag:         `"2"`,
+			LastModified: lastModified.Add(500 * time.Millisecond),
+			Body:         model.Example{ID: ID, Version: 2},
+		}
+	}
+
+	handler := &httphandler.RestHTTPHandler{
+		EAPI:         &exampleAPIMock{},
+		TS:           &timeStampMock{},
+		CacheControl: map[string]string{httphandler.GetExample: "max-age=60"},
+	}
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/examples/3", nil)
+		request.Header.Set(test.header, test.value)
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected {
+			t.Errorf("ServeHTTP(%s: %s) failed, expected %v, got %v", test.header, test.value, test.expected, recorder.Code)
+		}
+		if recorder.Code == http.StatusNotModified && recorder.Body.Len() != 0 {
+			t.Errorf("ServeHTTP(%s: %s) failed, expected empty body, got %v", test.header, test.value, recorder.Body.String())
+		}
+		if recorder.Header().Get("Cache-Control") != "max-age=60" {
+			t.Errorf("ServeHTTP(%s: %s) failed, expected %v, got %v", test.header, test.value, "max-age=60", recorder.Header().Get("Cache-Control"))
+		}
+		if recorder.Header().Get("Last-Modified") != "Fri, 02 Jan 2026 03:04:05 GMT" {
+			t.Errorf("ServeHTTP(%s: %s) failed, expected %v, got %v", test.header, test.value, "Fri, 02 Jan 2026 03:04:05 GMT", recorder.Header().Get("Last-Modified"))
+		}
+	}
+}
+
+func TestServeHTTPWhenProblemThenNoCacheControl(t *testing.T) {
+	getByIDMock = func(ID int64) api.Response {
+		return api.Response{
+			Code: http.StatusNotFound,
+			Body: api.NewProblem(api.ProblemTypeNotFound, http.StatusNotFound, "No examples found for ID 3", currentTime),
+		}
+	}
+
+	handler := &httphandler.RestHTTPHandler{
+		EAPI:         &exampleAPIMock{},
+		TS:           &timeStampMock{},
+		CacheControl: map[string]string{httphandler.GetExample: "max-age=60"},
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/examples/3", nil))
+
+	if recorder.Header().Get("Cache-Control") != "" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "", recorder.Header().Get("Cache-Control"))
+	}
+}
+
+func TestServeHTTPWhenDeleteExampleThenPreconditions(t *testing.T) {
+	expected := api.Preconditions{IfMatch: `"2"`, IfNoneMatch: "*"}
+
+	var got api.Preconditions
+	deleteMock = func(ID int64, preconditions api.Preconditions) api.Response {
+		got = preconditions
+		return api.Response{Code: http.StatusNoContent}
+	}
+
+	handler := &httphandler.RestHTTPHandler{EAPI: &exampleAPIMock{}, TS: &timeStampMock{}}
+	request := httptest.NewRequest(http.MethodDelete, "/examples/3", nil)
+	request.Header.Set("If-Match", `"2"`)
+	request.Header.Set("If-None-Match", "*")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	if expected != got {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenUnknownPathThenNotFound(t *testing.T) {
+	for _, path := range []string{"/", "/unknown", "/examples/abc", "/examples/1/other"} {
+		got := serve(http.MethodGet, path, "")
+
+		if got.Code != http.StatusNotFound {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", path, http.StatusNotFound, got.Code)
+		}
+	}
+}
+
+func TestServeHTTPWhenUnsupportedMethodThenNotAllowed(t *testing.T) {
+	got := serve(http.MethodDelete, "/examples", "")
+
+	if got.Code != http.StatusMethodNotAllowed {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusMethodNotAllowed, got.Code)
+	}
+	if got.Header().Get("Allow") != "GET, POST" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "GET, POST", got.Header().Get("Allow"))
+	}
+}
+
+func TestServeHTTPWhenProblemThenProblemJSON(t *testing.T) {
+	expected := `{"type":"/problems/not-found","title":"Not Found","status":404,"detail":"No examples found for ID 3","instance":"/examples/3","time":"2020-01-02T03:04:05Z"}`
+
+	getByIDMock = func(ID int64) api.Response {
+		return api.Response{
+			Code: http.StatusNotFound,
+			Body: api.NewProblem(api.ProblemTypeNotFound, http.StatusNotFound, "No examples found for ID 3", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+		}
+	}
+
+	got := serve(http.MethodGet, "/examples/3", "")
+
+	if got.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "application/problem+json", got.Header().Get("Content-Type"))
+	}
+	if strings.TrimSpace(got.Body.String()) != expected {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got.Body.String())
+	}
+}
+
+func TestOpenAPIWhenRoutesThenEveryOperationDocumented(t *testing.T) {
+	document, err := httphandler.OpenAPI(openapi.Info{Title: "Example API", Version: "1.0.0"}, nil)
+	if err != nil {
+		t.Fatalf("OpenAPI() failed, error %v", err)
+	}
+
+	var routes []httphandler.Route
+	for _, version := range api.DefaultVersions() {
+		for _, route := range httphandler.Routes {
+			routes = append(routes, httphandler.Route{
+				Operation: route.Operation + strings.ToUpper(version.Name[:1]) + version.Name[1:],
+				Method:    route.Method,
+				Path:      "/" + version.Name + route.Path,
+			})
+		}
+	}
+	routes = append(routes, httphandler.APIKeyRoutes...)
+	operations := 0
+	for path, pathItem := range document.Paths {
+		for method, operation := range pathItem {
+			operations++
+			found := false
+			for _, route := range routes {
+				found = found || (route.Path == path && strings.ToLower(route.Method) == method && route.Operation == operation.OperationID)
+			}
+			if !found {
+				t.Errorf("OpenAPI() failed, expected %v, got %s %s", "only the operations of Routes under each version and of APIKeyRoutes", method, path)
+			}
+		}
+	}
+	if operations != len(routes) {
+		t.Errorf("OpenAPI() failed, expected %v, got %v", len(routes), operations)
+	}
+}
+
+func TestOpenAPIWhenRequestFollowsDocumentThenHandled(t *testing.T) {
+	noContent := func() api.Response { return api.Response{Code: http.StatusNoContent} }
+	batchMock = func(batch api.BatchRequest) api.Response { return noContent() }
+	createMock = func(example model.Example) api.Response { return noContent() }
+	deleteMock = func(ID int64, preconditions api.Preconditions) api.Response { return noContent() }
+	deleteLogicallyMock = func(ID int64, preconditions api.Preconditions) api.Response { return noContent() }
+	getMock = func(pageParams api.PageParams) api.Response { return noContent() }
+	getActiveMock = func(pageParams api.PageParams) api.Response { return noContent() }
+	getByIDMock = func(ID int64) api.Response { return noContent() }
+	getByNameMock = func(name string) api.Response { return noContent() }
+	partialUpdateMock = func(ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response {
+		return noContent()
+	}
+	updateMock = func(ID int64, example model.Example, preconditions api.Preconditions) api.Response {
+		return noContent()
+	}
+	issueAPIKeyMock = func(request api.APIKeyRequest) api.Response { return noContent() }
+	listAPIKeysMock = func() api.Response { return noContent() }
+	revokeAPIKeyMock = func(ID int64) api.Response { return noContent() }
+	rotateAPIKeyMock = func(ID int64) api.Response { return noContent() }
+	subscribeMock = func(params api.StreamParams) (api.ExampleSubscription, api.Response) { return nil, noContent() }
+
+	document, _ := httphandler.OpenAPI(openapi.Info{Title: "Example API", Version: "1.0.0"}, nil)
+
+	handler := http.NewServeMux()
+	handler.Handle("/", &httphandler.RestHTTPHandler{EAPI: &exampleAPIMock{}, TS: &timeStampMock{}, ESAPI: &exampleStreamAPIMock{}})
+	handler.Handle(httphandler.APIKeysPath+"/", &httphandler.APIKeyHTTPHandler{AKAPI: &apiKeyAPIMock{}, TS: &timeStampMock{}})
+	handler.Handle(httphandler.APIKeysPath, &httphandler.APIKeyHTTPHandler{AKAPI: &apiKeyAPIMock{}, TS: &timeStampMock{}})
+	for path, pathItem := range document.Paths {
+		for method, operation := range pathItem {
+			target := path
+			for _, parameter := range operation.Parameters {
+				if parameter.In == "path" {
+					target = strings.Replace(target, "{"+parameter.Name+"}", "1", 1)
+				}
+			}
+			if strings.Contains(target, "{") {
+				t.Errorf("OpenAPI() failed, expected %v, got %v", "every path parameter documented", path)
+			}
+			contentTypes := []string{""}
+			if operation.RequestBody != nil {
+				contentTypes = nil
+				for contentType := range operation.RequestBody.Content {
+					contentTypes = append(contentTypes, contentType)
+				}
+			}
+			for _, contentType := range contentTypes {
+				body := "{}"
+				if contentType == patch.JSONPatchType {
+					body = "[]"
+				} else if bodyCodec, ok := codec.Default.Lookup(contentType); ok {
+					var encoded bytes.Buffer
+					bodyCodec.Encode(&encoded, map[string]interface{}{})
+					body = encoded.String()
+				}
+				request := httptest.NewRequest(strings.ToUpper(method), target, strings.NewReader(body))
+				request.Header.Set("Content-Type", contentType)
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, request)
+
+				if recorder.Code != http.StatusNoContent {
+					t.Errorf("ServeHTTP(%s %s %s) failed, expected %v, got %v", method, target, contentType, http.StatusNoContent, recorder.Code)
+				}
+			}
+		}
+	}
+}
+
+func TestServeHTTPWhenGetOpenAPIThenDocument(t *testing.T) {
+	handler := &httphandler.OpenAPIHTTPHandler{Info: openapi.Info{Title: "Example API", Version: "1.0.0"}, TS: &timeStampMock{}}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, httphandler.OpenAPIPath, nil))
+
+	var got openapi.Document
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Errorf("ServeHTTP() failed, error %v", err)
+	}
+	if got.OpenAPI != openapi.Version || got.Info.Title != "Example API" || len(got.Paths) == 0 {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "the OpenAPI document", recorder.Body.String())
+	}
+	if recorder.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "application/json", recorder.Header().Get("Content-Type"))
+	}
+}
+
+func TestServeHTTPWhenGetDocsThenPageReferencesDocument(t *testing.T) {
+	handler := &httphandler.OpenAPIHTTPHandler{Info: openapi.Info{Title: "<Example>", Version: "1.0.0"}, TS: &timeStampMock{}}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, httphandler.DocsPath, nil))
+
+	if got := recorder.Body.String(); !strings.Contains(got, `data-url="/openapi.json"`) || !strings.Contains(got, "<title>&lt;Example&gt;</title>") {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "the page of the document", got)
+	}
+	if got := recorder.Header().Get("Content-Security-Policy"); !strings.Contains(got, "script-src 'self';") || strings.Contains(got, "unsafe-inline") || strings.Contains(got, "https:") {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "a policy allowing only the assets of the service", got)
+	}
+}
+
+func TestServeHTTPWhenGetDocsAssetsThenServedFromBinary(t *testing.T) {
+	expected := []string{"text/css; charset=utf-8", "text/javascript; charset=utf-8"}
+
+	handler := &httphandler.OpenAPIHTTPHandler{TS: &timeStampMock{}}
+	var got []string
+	for _, path := range []string{httphandler.DocsPath + "/docs.css", httphandler.DocsPath + "/docs.js"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code != http.StatusOK || recorder.Body.Len() == 0 {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", path, http.StatusOK, recorder.Code)
+		}
+		got = append(got, recorder.Header().Get("Content-Type"))
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenPostOpenAPIThenNotAllowed(t *testing.T) {
+	handler := &httphandler.OpenAPIHTTPHandler{TS: &timeStampMock{}}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, httphandler.OpenAPIPath, nil))
+
+	if recorder.Code != http.StatusMethodNotAllowed || recorder.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", http.StatusMethodNotAllowed, recorder.Code, recorder.Header().Get("Allow"))
+	}
+}
+
+func TestRouteOf(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{method: http.MethodGet, path: "/examples?limit=1", expected: httphandler.ListExamples},
+		{method: http.MethodPatch, path: "/examples/3", expected: httphandler.PartialUpdateExample},
+		{method: http.MethodGet, path: "/examples/name/a%20b", expected: httphandler.GetExampleByName},
+		{method: http.MethodPut, path: "/examples", expected: ""},
+		{method: http.MethodGet, path: "/unknown", expected: ""},
+		{method: http.MethodPost, path: "/admin/api-keys", expected: httphandler.IssueAPIKey},
+		{method: http.MethodPost, path: "/admin/api-keys/2:rotate", expected: httphandler.RotateAPIKey},
+		{method: http.MethodDelete, path: "/admin/api-keys/2", expected: httphandler.RevokeAPIKey},
+		{method: http.MethodGet, path: "/admin/api-keys/2", expected: ""},
+	}
+	for _, test := range tests {
+		route, ok := httphandler.RouteOf(httptest.NewRequest(test.method, test.path, nil))
+
+		if route.Operation != test.expected || ok != (test.expected != "") {
+			t.Errorf("RouteOf(%s %s) failed, expected %v, got %v", test.method, test.path, test.expected, route.Operation)
+		}
+	}
+}
+
+func TestServeHTTPWhenIssueAPIKeyThenKeyNotStored(t *testing.T) {
+	expected := api.APIKeyRequest{Name: "client", Scopes: []string{"examples:read"}}
+
+	var got api.APIKeyRequest
+	issueAPIKeyMock = func(request api.APIKeyRequest) api.Response {
+		got = request
+		return api.Response{Code: http.StatusCreated, Path: 1, Body: api.IssuedAPIKey{APIKey: api.APIKey{ID: 1}, Key: "key"}}
+	}
+
+	recorder := serveAPIKey(http.MethodPost, "/admin/api-keys", `{"name":"client","scopes":["examples:read"]}`)
+
+	if recorder.Code != http.StatusCreated || recorder.Header().Get("Location") != "/admin/api-keys/1" {
+		t.Errorf("ServeHTTP() failed, expected %v %v, got %v %v", http.StatusCreated, "/admin/api-keys/1", recorder.Code, recorder.Header().Get("Location"))
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+	if recorder.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "no-store", recorder.Header().Get("Cache-Control"))
+	}
+}
+
+func TestServeHTTPWhenListAPIKeysThenConfiguredCodecsWithoutValidators(t *testing.T) {
+	expected := codec.YAML{}.ContentType()
+
+	listAPIKeysMock = func() api.Response {
+		return api.Response{Code: http.StatusOK, ETag: `"1"`, Body: api.Page{Items: []api.APIKey{{ID: 1}}}}
+	}
+	handler := &httphandler.APIKeyHTTPHandler{
+		AKAPI:  &apiKeyAPIMock{},
+		TS:     &timeStampMock{},
+		Codecs: &codec.Registry{Codecs: []codec.Codec{codec.YAML{}}},
+	}
+	request := httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil)
+	request.Header.Set("If-None-Match", `"1"`)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	got := recorder.Header().Get("Content-Type")
+
+	if recorder.Code != http.StatusOK || got != expected {
+		t.Errorf("ServeHTTP() failed, expected %v %v, got %v %v", http.StatusOK, expected, recorder.Code, got)
+	}
+	if recorder.Header().Get("ETag") != "" || recorder.Header().Get("Link") != "" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "neither ETag nor Link", recorder.Header())
+	}
+}
+
+func TestServeHTTPWhenRotateAPIKeyThenIDFromPath(t *testing.T) {
+	expected := int64(7)
+
+	var got int64
+	rotateAPIKeyMock = func(ID int64) api.Response {
+		got = ID
+		return api.Response{Code: http.StatusOK, Body: api.IssuedAPIKey{APIKey: api.APIKey{ID: ID}, Key: "key"}}
+	}
+
+	recorder := serveAPIKey(http.MethodPost, "/admin/api-keys/7:rotate", "")
+
+	if recorder.Code != http.StatusOK || got != expected {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", expected, recorder.Code, got)
+	}
+}
+
+func TestServeHTTPWhenAPIKeyPathIsUnknownThenNotFound(t *testing.T) {
+	for _, path := range []string{"/admin/api-keys/a", "/admin/api-keys/1:renew", "/admin/api-keys/1/x"} {
+		recorder := serveAPIKey(http.MethodDelete, path, "")
+
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", path, http.StatusNotFound, recorder.Code)
+		}
+	}
+}
+
+func TestServeHTTPWhenAPIKeyMethodIsUnsupportedThenNotAllowed(t *testing.T) {
+	recorder := serveAPIKey(http.MethodGet, "/admin/api-keys/1", "")
+
+	if recorder.Code != http.StatusMethodNotAllowed || recorder.Header().Get("Allow") != http.MethodDelete {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", http.StatusMethodNotAllowed, recorder.Code, recorder.Header().Get("Allow"))
+	}
+}
+
+func TestServeHTTPWhenAcceptXMLThenExampleInXML(t *testing.T) {
+	expected := "<example><CreatedAt>0001-01-01T00:00:00Z</CreatedAt><DeactivatedAt>0001-01-01T00:00:00Z</DeactivatedAt>" +
+		"<ID>1</ID><Name>test</Name><UpdatedAt>0001-01-01T00:00:00Z</UpdatedAt><Useful>true</Useful><Version>0</Version></example>"
+
+	getByIDMock = func(ID int64) api.Response {
+		return api.Response{Code: http.StatusOK, Body: model.Example{ID: ID, Name: "test", Useful: true}}
+	}
+
+	got := serveWithHeaders(http.MethodGet, "/examples/1", "", map[string]string{"Accept": "application/json;q=0.5, application/xml"})
+
+	if got.Header().Get("Content-Type") != "application/xml; charset=utf-8" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "application/xml; charset=utf-8", got.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(got.Body.String(), expected) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got.Body.String())
+	}
+	if got.Header().Get("Vary") != "Accept" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "Accept", got.Header().Get("Vary"))
+	}
+}
+
+func TestServeHTTPWhenAcceptCSVThenListingInCSV(t *testing.T) {
+	expected := "ID,Name,Useful,CreatedAt,DeactivatedAt,Version,UpdatedAt\n" +
+		"1,\"a, b\",true,0001-01-01T00:00:00Z,0001-01-01T00:00:00Z,2,0001-01-01T00:00:00Z\n"
+
+	getMock = func(pageParams api.PageParams) api.Response {
+		return api.Response{Code: http.StatusOK, Body: api.Page{Items: []model.Example{{ID: 1, Name: "a, b", Useful: true, Version: 2}}}}
+	}
+
+	got := serveWithHeaders(http.MethodGet, "/examples", "", map[string]string{"Accept": "text/csv"})
+
+	if got.Code != http.StatusOK || got.Body.String() != expected {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", expected, got.Code, got.Body.String())
+	}
+}
+
+func TestServeHTTPWhenAcceptCSVForExampleThenNotAcceptable(t *testing.T) {
+	called := false
+	getByIDMock = func(ID int64) api.Response {
+		called = true
+		return api.Response{Code: http.StatusOK, Body: model.Example{ID: ID}}
+	}
+
+	got := serveWithHeaders(http.MethodGet, "/examples/1", "", map[string]string{"Accept": "text/csv, text/html;q=0.9"})
+
+	if got.Code != http.StatusNotAcceptable || called {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", http.StatusNotAcceptable, got.Code, called)
+	}
+	if got.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "application/problem+json", got.Header().Get("Content-Type"))
+	}
+}
+
+func TestServeHTTPWhenCreateExampleInYAMLThenDecoded(t *testing.T) {
+	expected := model.Example{Name: "test", Useful: true}
+
+	var got model.Example
+	createMock = func(example model.Example) api.Response {
+		got = example
+		return api.Response{Code: http.StatusCreated, Body: example}
+	}
+
+	recorder := serveWithHeaders(http.MethodPost, "/examples", "Name: test\nUseful: true\n", map[string]string{
+		"Content-Type": "application/yaml",
+		"Accept":       "application/x-yaml",
+	})
+
+	if recorder.Code != http.StatusCreated || !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", expected, recorder.Code, got)
+	}
+	if !strings.Contains(recorder.Body.String(), "Name: test\n") {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "Name: test", recorder.Body.String())
+	}
+}
+
+func TestServeHTTPWhenCreateExampleInEveryCodecThenSameExample(t *testing.T) {
+	expected := model.Example{Name: "test", Useful: true, CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	for _, bodyCodec := range codec.Default.Codecs {
+		var got model.Example
+		createMock = func(example model.Example) api.Response {
+			got = example
+			return api.Response{Code: http.StatusCreated, Body: example}
+		}
+		var body bytes.Buffer
+		bodyCodec.Encode(&body, expected)
+
+		recorder := serveWithHeaders(http.MethodPost, "/examples", body.String(), map[string]string{
+			"Content-Type": bodyCodec.ContentType(),
+			"Accept":       bodyCodec.MediaTypes()[0],
+		})
+
+		if recorder.Code != http.StatusCreated || !reflect.DeepEqual(expected, got) {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v %v", bodyCodec.MediaTypes()[0], expected, recorder.Code, got)
+		}
+		if recorder.Header().Get("Content-Type") != bodyCodec.ContentType() || recorder.Body.String() != body.String() {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", bodyCodec.MediaTypes()[0], body.String(), recorder.Body.String())
+		}
+	}
+}
+
+func TestServeHTTPWhenContentTypeIsUnsupportedThenUnsupportedMediaType(t *testing.T) {
+	called := false
+	createMock = func(example model.Example) api.Response {
+		called = true
+		return api.Response{Code: http.StatusNoContent}
+	}
+
+	got := serveWithHeaders(http.MethodPost, "/examples", "ID,Name\n", map[string]string{"Content-Type": "text/csv"})
+
+	if got.Code != http.StatusUnsupportedMediaType || called {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", http.StatusUnsupportedMediaType, got.Code, called)
+	}
+	if !strings.Contains(got.Header().Get("Accept"), "application/msgpack") {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "application/msgpack", got.Header().Get("Accept"))
+	}
+}
+
+func TestServeHTTPWhenIdempotencyKeyRetriedThenReplayed(t *testing.T) {
+	created := 0
+	createMock = func(example model.Example) api.Response {
+		created++
+		return api.Response{Code: http.StatusCreated, Path: 5, ETag: `"1"`}
+	}
+	handler := idempotentHandler()
+
+	first := serveIdempotent(handler, "key-1", `{"Name":"test"}`, "client")
+	second := serveIdempotent(handler, "key-1", `{"Name":"test"}`, "client")
+
+	if created != 1 || second.Code != http.StatusCreated || second.Header().Get("Location") != "/examples/5" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v %v", 1, created, second.Code, second.Header().Get("Location"))
+	}
+	if first.Header().Get(httphandler.IdempotentReplayedHeader) != "" || second.Header().Get(httphandler.IdempotentReplayedHeader) != "true" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "true", second.Header().Get(httphandler.IdempotentReplayedHeader))
+	}
+}
+
+func TestServeHTTPWhenIdempotencyKeyOfAnotherClientThenRun(t *testing.T) {
+	created := 0
+	createMock = func(example model.Example) api.Response {
+		created++
+		return api.Response{Code: http.StatusCreated, Path: int64(created)}
+	}
+	handler := idempotentHandler()
+
+	serveIdempotent(handler, "key-1", `{"Name":"test"}`, "client")
+	got := serveIdempotent(handler, "key-1", `{"Name":"test"}`, "another")
+
+	if created != 2 || got.Header().Get(httphandler.IdempotentReplayedHeader) != "" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", 2, created)
+	}
+}
+
+func TestServeHTTPWhenIdempotencyKeyReusedForAnotherExampleThenUnprocessable(t *testing.T) {
+	createMock = func(example model.Example) api.Response {
+		return api.Response{Code: http.StatusCreated, Path: 5}
+	}
+	handler := idempotentHandler()
+
+	serveIdempotent(handler, "key-1", `{"Name":"test"}`, "client")
+	got := serveIdempotent(handler, "key-1", `{"Name":"other"}`, "client")
+
+	if got.Code != http.StatusUnprocessableEntity {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusUnprocessableEntity, got.Code)
+	}
+}
+
+func TestServeHTTPWhenIdempotencyKeyIsInvalidThenBadRequest(t *testing.T) {
+	handler := idempotentHandler()
+
+	for _, key := range []string{"with space", strings.Repeat("k", httphandler.MaxIdempotencyKeyLength+1)} {
+		got := serveIdempotent(handler, key, `{"Name":"test"}`, "client")
+
+		if got.Code != http.StatusBadRequest {
+			t.Errorf("ServeHTTP(%q) failed, expected %v, got %v", key, http.StatusBadRequest, got.Code)
+		}
+	}
+}
+
+func TestServeHTTPWhenGetExampleOfV2ThenSnakeCase(t *testing.T) {
+	expected := `{"id":1,"name":"test","useful":true,"created_at":"2020-01-02T03:04:05Z","version":2,"updated_at":"2020-01-02T03:04:05Z"}`
+	moment := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	getByIDMock = func(ID int64) api.Response {
+		return api.Response{Code: http.StatusOK, Body: model.Example{ID: ID, Name: "test", Useful: true, CreatedAt: moment, Version: 2, UpdatedAt: moment}}
+	}
+
+	recorder := serve(http.MethodGet, "/v2/examples/1", "")
+
+	if got := strings.TrimSpace(recorder.Body.String()); recorder.Code != http.StatusOK || got != expected {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", expected, recorder.Code, got)
+	}
+}
+
+func TestServeHTTPWhenListExamplesOfV2ThenItemsSnakeCase(t *testing.T) {
+	moment := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	getMock = func(pageParams api.PageParams) api.Response {
+		return api.Response{Code: http.StatusOK, Body: api.Page{Items: []model.Example{{ID: 1, DeactivatedAt: moment}}}}
+	}
+
+	recorder := serve(http.MethodGet, "/v2/examples", "")
+
+	var got struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &got)
+	if len(got.Items) != 1 || got.Items[0]["id"] != 1.0 || got.Items[0]["deactivated_at"] != "2020-01-02T03:04:05Z" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "the items in the representation of v2", recorder.Body.String())
+	}
+}
+
+func TestServeHTTPWhenGetExampleOfV1ThenDeprecated(t *testing.T) {
+	deprecation := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	sunset := deprecation.AddDate(1, 0, 0)
+	v1 := api.V1
+	v1.Deprecation, v1.Sunset = deprecation, sunset
+	getByIDMock = func(ID int64) api.Response {
+		return api.Response{Code: http.StatusOK, Body: model.Example{ID: ID}}
+	}
+	handler := &httphandler.RestHTTPHandler{EAPI: &exampleAPIMock{}, TS: &timeStampMock{}, Versions: []api.Version{v1, api.V2}}
+
+	for _, path := range []string{"/v1/examples/1", "/examples/1"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if got := recorder.Header().Get("Deprecation"); got != "@1577934245" {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", path, "@1577934245", got)
+		}
+		if got := recorder.Header().Get("Sunset"); got != "Sat, 02 Jan 2021 03:04:05 GMT" {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", path, "Sat, 02 Jan 2021 03:04:05 GMT", got)
+		}
+		if !strings.Contains(recorder.Body.String(), `"DeactivatedAt":"0001-01-01T00:00:00Z"`) {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", path, "the representation of v1", recorder.Body.String())
+		}
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v2/examples/1", nil))
+	if got := recorder.Header().Get("Deprecation"); got != "" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "no Deprecation", got)
+	}
+}
+
+func TestServeHTTPWhenCreateExampleOfV2ThenConverted(t *testing.T) {
+	expected := model.Example{Name: "test", Useful: true}
+
+	var got model.Example
+	createMock = func(example model.Example) api.Response {
+		got = example
+		return api.Response{Code: http.StatusCreated, Path: 5}
+	}
+
+	recorder := serve(http.MethodPost, "/v2/examples", `{"name":"test","useful":true}`)
+
+	if recorder.Code != http.StatusCreated || recorder.Header().Get("Location") != "/v2/examples/5" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", "/v2/examples/5", recorder.Code, recorder.Header().Get("Location"))
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenBatchOfV2ThenConverted(t *testing.T) {
+	expected := api.BatchRequest{Items: []api.BatchItem{{Operation: "update", ID: 2, IfMatch: `"1"`, Example: model.Example{Name: "test"}}}}
+
+	var got api.BatchRequest
+	batchMock = func(batch api.BatchRequest) api.Response {
+		got = batch
+		return api.Response{Code: http.StatusOK, Body: api.BatchResponse{}}
+	}
+
+	serve(http.MethodPost, "/v2/examples:batch", `{"items":[{"op":"update","id":2,"if_match":"\"1\"","example":{"name":"test"}}]}`)
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenPatchExampleOfV2ThenPropertiesRenamed(t *testing.T) {
+	expected := document(t, model.Example{Name: "changed", Useful: true})
+
+	var got interface{}
+	var err error
+	partialUpdateMock = func(ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response {
+		got, err = examplePatch.Apply(document(t, model.Example{Name: "test", Useful: true}))
+		return api.Response{Code: http.StatusNoContent}
+	}
+
+	recorder := serveWithHeaders(http.MethodPatch, "/v2/examples/3", `{"name":"changed"}`, map[string]string{"Content-Type": patch.MergePatchType})
+
+	if recorder.Code != http.StatusNoContent || err != nil {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", http.StatusNoContent, recorder.Code, err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestServeHTTPWhenProblemOfV2ThenFieldsRenamed(t *testing.T) {
+	createMock = func(example model.Example) api.Response {
+		problem := api.NewProblem(api.ProblemTypeValidation, http.StatusUnprocessableEntity, "Invalid", currentTime)
+		problem.Errors = []api.FieldError{{Field: "Name", Message: "is required"}, {Field: "items", Message: "other"}}
+		return api.Response{Code: http.StatusUnprocessableEntity, Body: problem}
+	}
+
+	recorder := serve(http.MethodPost, "/v2/examples", `{}`)
+
+	var got api.Problem
+	json.Unmarshal(recorder.Body.Bytes(), &got)
+	if len(got.Errors) != 2 || got.Errors[0].Field != "name" || got.Errors[1].Field != "items" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", "the fields named as in v2", got.Errors)
+	}
+}
+
+func TestServeHTTPWhenUnknownVersionThenNotFound(t *testing.T) {
+	for _, path := range []string{"/v3/examples", "/v2", "/v1/api-keys"} {
+		recorder := serve(http.MethodGet, path, "")
+
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", path, http.StatusNotFound, recorder.Code)
+		}
+	}
+}
+
+func document(t *testing.T, example model.Example) interface{} {
+	value, err := patch.ToDocument(example)
+	if err != nil {
+		t.Fatalf("document() failed, error %v", err)
+	}
+	return value
+}
+
+func TestServeHTTPWhenStreamThenServerSentEvents(t *testing.T) {
+	expected := "id: 2\nevent: created\ndata: {\"id\":2,\"type\":\"created\",\"time\":\"2020-07-01T10:00:00Z\",\"example\":{\"id\":5,\"name\":\"test\",\"useful\":false,\"created_at\":\"0001-01-01T00:00:00Z\",\"version\":0,\"updated_at\":\"0001-01-01T00:00:00Z\"}}\n\n"
+
+	broker := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}}
+	server := httptest.NewServer(streamHandler(broker))
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/v2/examples/stream?type=created")
+	if err != nil {
+		t.Fatalf("Get() failed, expected %v, got %v", nil, err)
+	}
+	defer response.Body.Close()
+	broker.Publish(model.ExampleEvent{Type: model.ExampleDeleted, Example: model.Example{ID: 4}})
+	broker.Publish(model.ExampleEvent{Type: model.ExampleCreated, Example: model.Example{ID: 5, Name: "test"}, Time: streamTime})
+	got := readServerSentEvent(t, bufio.NewReader(response.Body))
+
+	if response.Header.Get("Content-Type") != httphandler.EventStreamType || expected != got {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", expected, response.Header.Get("Content-Type"), got)
+	}
+}
+
+func TestServeHTTPWhenStreamResumedThenReplayedOrReset(t *testing.T) {
+	tests := []struct {
+		lastEventID string
+		expected    string
+	}{
+		{lastEventID: "3", expected: "id: 4\nevent: deleted\n"},
+		{lastEventID: "1", expected: "event: reset\n"},
+	}
+	for _, test := range tests {
+		broker := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}, ReplaySize: 2}
+		for ID := int64(1); ID <= 5; ID++ {
+			broker.Publish(model.ExampleEvent{Type: model.ExampleDeleted, Example: model.Example{ID: ID}})
+		}
+		server := httptest.NewServer(streamHandler(broker))
+
+		request, _ := http.NewRequest(http.MethodGet, server.URL+"/examples/stream", nil)
+		request.Header.Set(httphandler.LastEventIDHeader, test.lastEventID)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("Do() failed, expected %v, got %v", nil, err)
+		}
+		got := readServerSentEvent(t, bufio.NewReader(response.Body))
+		response.Body.Close()
+		server.Close()
+
+		if !strings.HasPrefix(got, test.expected) {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v", test.lastEventID, test.expected, got)
+		}
+	}
+}
+
+func TestServeHTTPWhenStreamParametersInvalidThenBadRequest(t *testing.T) {
+	for _, path := range []string{"/examples/stream?type=renamed", "/examples/stream?id=a", "/examples/stream?lastEventId=-1"} {
+		handler := streamHandler(&stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}})
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if recorder.Code != http.StatusBadRequest || recorder.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v %v", path, http.StatusBadRequest, recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestServeHTTPWhenStreamWithoutAPIThenNotFound(t *testing.T) {
+	got := serve(http.MethodGet, "/examples/stream", "")
+
+	if got.Code != http.StatusNotFound {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusNotFound, got.Code)
+	}
+}
+
+func TestServeHTTPWhenWebSocketThenFilteredEvents(t *testing.T) {
+	expected := []string{`"id":2,"type":"deleted"`, `"id":3,"type":"created"`}
+
+	broker := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}}
+	server := httptest.NewServer(streamHandler(broker))
+	defer server.Close()
+
+	conn, reader := dialWebSocket(t, server.Listener.Addr().String(), "/examples/stream?type=deleted")
+	defer conn.Close()
+	broker.Publish(model.ExampleEvent{Type: model.ExampleCreated, Example: model.Example{ID: 1}})
+	broker.Publish(model.ExampleEvent{Type: model.ExampleDeleted, Example: model.Example{ID: 1}})
+	_, first := readWebSocketFrame(t, reader)
+	writeWebSocketFrame(t, conn, 0x1, []byte(`{"types":["created"]}`))
+	broker.Publish(model.ExampleEvent{Type: model.ExampleCreated, Example: model.Example{ID: 2}})
+	_, second := readWebSocketFrame(t, reader)
+
+	if !strings.Contains(string(first), expected[0]) || !strings.Contains(string(second), expected[1]) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %s %s", expected, first, second)
+	}
+
+	writeWebSocketFrame(t, conn, 0x1, []byte(`{"types":["renamed"]}`))
+	_, problem := readWebSocketFrame(t, reader)
+	writeWebSocketFrame(t, conn, 0x8, []byte{0x03, 0xE8})
+	opcode, _ := readWebSocketFrame(t, reader)
+
+	if !strings.Contains(string(problem), `"status":400`) || opcode != 0x8 {
+		t.Errorf("ServeHTTP() failed, expected %v, got %s %v", "a problem then a close frame", problem, opcode)
+	}
+}
+
+func TestServeHTTPWhenWebSocketMessageRejectedThenClosed(t *testing.T) {
+	tests := []struct {
+		messageType int
+		message     []byte
+		expected    int
+	}{
+		{messageType: websocket.BinaryMessage, message: []byte{0x01}, expected: websocket.CloseUnsupportedData},
+		{messageType: websocket.TextMessage, message: []byte("not a filter"), expected: websocket.CloseUnsupportedData},
+		{messageType: websocket.TextMessage, message: []byte(`{"types":["` + strings.Repeat("a", 5000) + `"]}`), expected: websocket.CloseMessageTooBig},
+	}
+	server := httptest.NewServer(streamHandler(&stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}}))
+	defer server.Close()
+	for _, test := range tests {
+		conn, _, err := websocket.DefaultDialer.Dial("ws://"+server.Listener.Addr().String()+"/examples/stream", nil)
+		if err != nil {
+			t.Fatalf("Dial() failed, expected %v, got %v", nil, err)
+		}
+		conn.WriteMessage(test.messageType, test.message)
+		_, _, err = conn.ReadMessage()
+		conn.Close()
+
+		if !websocket.IsCloseError(err, test.expected) {
+			t.Errorf("ServeHTTP() failed, expected %v, got %v", test.expected, err)
+		}
+	}
+}
+
+func TestServeHTTPWhenWebSocketVersionUnsupportedThenUpgradeRequired(t *testing.T) {
+	handler := streamHandler(&stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}})
+	request := httptest.NewRequest(http.MethodGet, "/examples/stream", nil)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "8")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUpgradeRequired || recorder.Header().Get("Sec-WebSocket-Version") != "13" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", http.StatusUpgradeRequired, recorder.Code, recorder.Header().Get("Sec-WebSocket-Version"))
+	}
+}
+
+func TestServeHTTPWhenWebSocketFromForeignOriginThenForbidden(t *testing.T) {
+	handler := &httphandler.RestHTTPHandler{
+		EAPI:           &exampleAPIMock{},
+		TS:             &timeStampMock{},
+		ESAPI:          &rest.ExampleStreamAPIRest{ESUC: &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}}, TS: &timeStampMock{}},
+		AllowedOrigins: []string{"https://*.example.com"},
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	address := server.Listener.Addr().String()
+
+	tests := []struct {
+		origin   string
+		expected int
+	}{
+		{origin: "https://evil.example.org", expected: http.StatusForbidden},
+		{origin: "https://app.example.com", expected: http.StatusSwitchingProtocols},
+		{origin: "http://" + address, expected: http.StatusSwitchingProtocols},
+	}
+	for _, test := range tests {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			t.Fatalf("Dial() failed, expected %v, got %v", nil, err)
+		}
+		fmt.Fprintf(conn, "GET /examples/stream HTTP/1.1\r\nHost: %s\r\nOrigin: %s\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+			"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n", address, test.origin)
+		response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		conn.Close()
+
+		if err != nil || response.StatusCode != test.expected {
+			t.Errorf("ServeHTTP(%s) failed, expected %v, got %v %v", test.origin, test.expected, response, err)
+		}
+	}
+}
+
+func TestHealthWhenDependencyDownThenServiceUnavailable(t *testing.T) {
+	tests := []struct {
+		path     string
+		code     int
+		expected string
+	}{
+		{path: httphandler.LivenessPath, code: http.StatusOK, expected: `"status":"up"`},
+		{path: httphandler.ReadinessPath, code: http.StatusServiceUnavailable, expected: `{"name":"sql database","status":"down","latencyMs":0,"timeoutMs":2000}`},
+		{path: httphandler.HealthPath, code: http.StatusServiceUnavailable, expected: `"error":"unreachable"`},
+	}
+	registry := &health.Registry{TS: &timeStampMock{}}
+	registry.Register("sql database", health.CheckFunc(func(ctx context.Context) error {
+		return errors.New("unreachable")
+	}))
+	registry.Start()
+	handler := &httphandler.HealthHTTPHandler{HR: registry, ShowErrors: true, TS: &timeStampMock{}}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if recorder.Code != test.code || !strings.Contains(recorder.Body.String(), test.expected) {
+			t.Errorf("ServeHTTP(%s) failed, expected %v %v, got %v %v", test.path, test.code, test.expected, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func TestHealthWhenErrorsNotShownThenOmitted(t *testing.T) {
+	expected := `{"name":"sql database","status":"down","latencyMs":0,"timeoutMs":2000}`
+
+	registry := &health.Registry{TS: &timeStampMock{}}
+	registry.Register("sql database", health.CheckFunc(func(ctx context.Context) error {
+		return errors.New("dial tcp 10.0.0.1:3306: access denied for user admin")
+	}))
+	registry.Start()
+	handler := &httphandler.HealthHTTPHandler{HR: registry, TS: &timeStampMock{}}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, httphandler.HealthPath, nil))
+
+	if recorder.Code != http.StatusServiceUnavailable || !strings.Contains(recorder.Body.String(), expected) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v %v", expected, recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestRouteOfWhenHealthThenAuthenticated(t *testing.T) {
+	expected := httphandler.GetHealth
+
+	route, _ := httphandler.RouteOf(httptest.NewRequest(http.MethodGet, httphandler.HealthPath, nil))
+	got := route.Operation
+
+	if expected != got {
+		t.Errorf("RouteOf() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestHealthWhenStoppingThenNotReady(t *testing.T) {
+	expected := []int{http.StatusOK, http.StatusServiceUnavailable, http.StatusOK}
+
+	registry := &health.Registry{TS: &timeStampMock{}}
+	registry.Start()
+	handler := &httphandler.HealthHTTPHandler{HR: registry, TS: &timeStampMock{}}
+
+	var got []int
+	for _, path := range []string{httphandler.ReadinessPath, httphandler.ReadinessPath, httphandler.LivenessPath} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		got = append(got, recorder.Code)
+		registry.Stop(context.Background())
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestHealthWhenMethodNotAllowedThenProblem(t *testing.T) {
+	handler := &httphandler.HealthHTTPHandler{HR: &health.Registry{TS: &timeStampMock{}}, TS: &timeStampMock{}}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, httphandler.ReadinessPath, nil))
+
+	if recorder.Code != http.StatusMethodNotAllowed || recorder.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("ServeHTTP() failed, expected %v, got %v", http.StatusMethodNotAllowed, recorder.Code)
+	}
+}
+
+func streamHandler(broker *stream.ExampleStreamUseCaseImpl) http.Handler {
+	return &httphandler.RestHTTPHandler{
+		EAPI:  &exampleAPIMock{},
+		TS:    &timeStampMock{},
+		ESAPI: &rest.ExampleStreamAPIRest{ESUC: broker, TS: &timeStampMock{}},
+	}
+}
+
+// readServerSentEvent is responsible for reading the lines of the next event, the comments
+// being skipped
+func readServerSentEvent(t *testing.T, reader *bufio.Reader) string {
+	var event strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString() failed, expected %v, got %v", nil, err)
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		event.WriteString(line)
+		if line == "\n" && event.Len() > 1 {
+			return event.String()
+		}
+	}
+}
+
+func dialWebSocket(t *testing.T, address string, path string) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Dial() failed, expected %v, got %v", nil, err)
+	}
+	fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n", path, address)
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil || response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("ReadResponse() failed, expected %v, got %v %v", http.StatusSwitchingProtocols, response, err)
+	}
+	return conn, reader
+}
+
+func writeWebSocketFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	mask := []byte{1, 2, 3, 4}
+	frame := append([]byte{0x80 | opcode, 0x80 | byte(len(payload))}, mask...)
+	for i, value := range payload {
+		frame = append(frame, value^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("Write() failed, expected %v, got %v", nil, err)
+	}
+}
+
+// readWebSocketFrame is responsible for reading the next frame of the server that is not a ping
+func readWebSocketFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	for {
+		var header [2]byte
+		if _, err := io.ReadFull(reader, header[:]); err != nil {
+			t.Fatalf("ReadFull() failed, expected %v, got %v", nil, err)
+		}
+		length := int(header[1] & 0x7F)
+		if length == 126 {
+			var extended [2]byte
+			io.ReadFull(reader, extended[:])
+			length = int(extended[0])<<8 | int(extended[1])
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			t.Fatalf("ReadFull() failed, expected %v, got %v", nil, err)
+		}
+		if header[0]&0x0F != 0x9 {
+			return header[0] & 0x0F, payload
+		}
+	}
+}
+
+var streamTime = time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+
+func serve(method string, path string, body string) *httptest.ResponseRecorder {
+	handler := &httphandler.RestHTTPHandler{
+		EAPI: &exampleAPIMock{},
+		TS:   &timeStampMock{},
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder
+}
+
+func serveWithHeaders(method string, path string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	handler := &httphandler.RestHTTPHandler{
+		EAPI: &exampleAPIMock{},
+		TS:   &timeStampMock{},
+	}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func idempotentHandler() *httphandler.RestHTTPHandler {
+	return &httphandler.RestHTTPHandler{
+		EAPI: &exampleAPIMock{},
+		TS:   &timeStampMock{},
+		IAPI: &rest.IdempotencyAPIRest{
+			IUC: &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: &timeStampMock{}},
+			TS:  &timeStampMock{},
+		},
+	}
+}
+
+func serveIdempotent(handler http.Handler, key string, body string, subject string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader(body))
+	request.Header.Set(httphandler.IdempotencyKeyHeader, key)
+	request = request.WithContext(auth.WithPrincipal(request.Context(), &auth.Principal{Subject: subject}))
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func servePatch(contentType string, body string) *httptest.ResponseRecorder {
+	handler := &httphandler.RestHTTPHandler{
+		EAPI: &exampleAPIMock{},
+		TS:   &timeStampMock{},
+	}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPatch, "/examples/3", strings.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func serveAPIKey(method string, path string, body string) *httptest.ResponseRecorder {
+	handler := &httphandler.APIKeyHTTPHandler{
+		AKAPI: &apiKeyAPIMock{},
+		TS:    &timeStampMock{},
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder
+}
+
+var currentTime time.Time = time.Now()
+
+var batchMock func(batch api.BatchRequest) api.Response
+
+var createMock func(example model.Example) api.Response
+
+var deleteMock func(ID int64, preconditions api.Preconditions) api.Response
+
+var getMock func(pageParams api.PageParams) api.Response
+
+var getContext context.Context
+
+var getByIDMock func(ID int64) api.Response
+
+var getActiveMock func(pageParams api.PageParams) api.Response
+
+var getByNameMock func(name string) api.Response
+
+var deleteLogicallyMock func(ID int64, preconditions api.Preconditions) api.Response
+
+var partialUpdateMock func(ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response
+
+var updateMock func(ID int64, example model.Example, preconditions api.Preconditions) api.Response
+
+var issueAPIKeyMock func(request api.APIKeyRequest) api.Response
+
+var listAPIKeysMock func() api.Response
+
+var revokeAPIKeyMock func(ID int64) api.Response
+
+var rotateAPIKeyMock func(ID int64) api.Response
+
+var subscribeMock func(params api.StreamParams) (api.ExampleSubscription, api.Response)
+
+type exampleAPIMock struct{}
+
+type exampleStreamAPIMock struct{}
+
+type apiKeyAPIMock struct{}
+
+type timeStampMock struct{}
+
+func (eapi *exampleAPIMock) Batch(ctx context.Context, batch api.BatchRequest) api.Response {
+	return batchMock(batch)
+}
+
+func (eapi *exampleAPIMock) DeleteLogically(ctx context.Context, ID int64, preconditions api.Preconditions) api.Response {
+	return deleteLogicallyMock(ID, preconditions)
+}
+
+func (eapi *exampleAPIMock) GetActive(ctx context.Context, pageParams api.PageParams) api.Response {
+	return getActiveMock(pageParams)
+}
+
+func (eapi *exampleAPIMock) GetByName(ctx context.Context, name string) api.Response {
+	return getByNameMock(name)
+}
+
+func (eapi *exampleAPIMock) Create(ctx context.Context, example model.Example) api.Response {
+	return createMock(example)
+}
+
+func (eapi *exampleAPIMock) Delete(ctx context.Context, ID int64, preconditions api.Preconditions) api.Response {
+	return deleteMock(ID, preconditions)
+}
+
+func (eapi *exampleAPIMock) Get(ctx context.Context, pageParams api.PageParams) api.Response {
+	getContext = ctx
+	return getMock(pageParams)
+}
+
+func (eapi *exampleAPIMock) GetByID(ctx context.Context, ID int64) api.Response {
+	return getByIDMock(ID)
+}
+
+func (eapi *exampleAPIMock) PartialUpdate(ctx context.Context, ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response {
+	return partialUpdateMock(ID, examplePatch, preconditions)
+}
+
+func (eapi *exampleAPIMock) Update(ctx context.Context, ID int64, example model.Example, preconditions api.Preconditions) api.Response {
+	return updateMock(ID, example, preconditions)
+}
+
+func (esapi *exampleStreamAPIMock) Subscribe(ctx context.Context, params api.StreamParams) (api.ExampleSubscription, api.Response) {
+	return subscribeMock(params)
+}
+
+func (akapi *apiKeyAPIMock) Issue(ctx context.Context, request api.APIKeyRequest) api.Response {
+	return issueAPIKeyMock(request)
+}
+
+func (akapi *apiKeyAPIMock) List(ctx context.Context) api.Response {
+	return listAPIKeysMock()
+}
+
+func (akapi *apiKeyAPIMock) Revoke(ctx context.Context, ID int64) api.Response {
+	return revokeAPIKeyMock(ID)
+}
+
+func (akapi *apiKeyAPIMock) Rotate(ctx context.Context, ID int64) api.Response {
+	return rotateAPIKeyMock(ID)
+}
+
+func (tp *timeStampMock) GetCurrentTime() time.Time {
+	return currentTime
+}
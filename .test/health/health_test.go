@@ -0,0 +1,201 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/health"
+)
+
+func TestReportWhenChecksPassThenUp(t *testing.T) {
+	expected := health.Report{
+		Status:    health.StatusUp,
+		Time:      startTime.Add(5 * time.Millisecond),
+		StartedAt: startTime,
+		Checks: []health.CheckReport{
+			{Name: "sql database", Status: health.StatusUp, LatencyMS: 5, TimeoutMS: 1000},
+		},
+	}
+
+	registry := &health.Registry{
+		Timeouts: map[string]time.Duration{"sql database": time.Second},
+		TS:       &timeStampMock{times: []time.Time{startTime, startTime, startTime, startTime.Add(5 * time.Millisecond)}},
+	}
+	registry.Register("sql database", health.CheckFunc(func(ctx context.Context) error {
+		return nil
+	}))
+	registry.Start()
+
+	got := registry.Report(context.Background())
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Report() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestReportWhenCheckFailsThenDown(t *testing.T) {
+	expected := []string{health.StatusDown, health.StatusUp, health.StatusDown}
+
+	registry := &health.Registry{TS: &timeStampMock{}}
+	registry.Register("a", health.CheckFunc(func(ctx context.Context) error {
+		return nil
+	}))
+	registry.Register("b", health.CheckFunc(func(ctx context.Context) error {
+		return errors.New("unreachable")
+	}))
+	registry.Start()
+
+	report := registry.Report(context.Background())
+	got := []string{report.Status, report.Checks[0].Status, report.Checks[1].Status}
+
+	if !reflect.DeepEqual(expected, got) || report.Checks[1].Error != "unreachable" || report.Checks[1].TimeoutMS != 2000 {
+		t.Errorf("Report() failed, expected %v, got %v", expected, report)
+	}
+}
+
+func TestReportWhenCheckIgnoresTimeoutThenTimedOut(t *testing.T) {
+	expected := "timed out after 10ms"
+
+	release := make(chan struct{})
+	defer close(release)
+	registry := &health.Registry{Timeout: 10 * time.Millisecond, TS: &timeStampMock{}}
+	registry.Register("broker", health.CheckFunc(func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+	registry.Start()
+
+	done := make(chan health.Report, 1)
+	go func() {
+		done <- registry.Report(context.Background())
+	}()
+	select {
+	case got := <-done:
+		if got.Status != health.StatusDown || got.Checks[0].Error != expected {
+			t.Errorf("Report() failed, expected %v, got %v", expected, got)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Report() failed, expected %v, got %v", expected, "no report")
+	}
+}
+
+func TestReportWhenNotStartedOrStoppedThenNotReady(t *testing.T) {
+	expected := []string{health.StatusStarting, health.StatusUp, health.StatusStopping}
+
+	registry := &health.Registry{TS: &timeStampMock{}}
+	registry.Register("a", health.CheckFunc(func(ctx context.Context) error {
+		return nil
+	}))
+
+	var got []string
+	got = append(got, registry.Report(context.Background()).Status)
+	registry.Start()
+	got = append(got, registry.Report(context.Background()).Status)
+	registry.Stop(context.Background())
+	got = append(got, registry.Report(context.Background()).Status)
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Report() failed, expected %v, got %v", expected, got)
+	}
+	if alive := registry.Alive(); alive.Status != health.StatusUp || alive.Checks != nil {
+		t.Errorf("Alive() failed, expected %v, got %v", health.StatusUp, alive)
+	}
+}
+
+func TestStopThenDrainDelayWithinDeadline(t *testing.T) {
+	registry := &health.Registry{DrainDelay: 20 * time.Millisecond, TS: &timeStampMock{}}
+	registry.Start()
+
+	start := time.Now()
+	registry.Stop(context.Background())
+	drained := time.Since(start)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	registry.DrainDelay = time.Hour
+	start = time.Now()
+	registry.Stop(ctx)
+	interrupted := time.Since(start)
+
+	if drained < 20*time.Millisecond || interrupted > time.Second {
+		t.Errorf("Stop() failed, expected %v, got %v %v", "the drain delay within the deadline", drained, interrupted)
+	}
+}
+
+func TestReportThenChecksNamed(t *testing.T) {
+	expected := "a,b,c"
+
+	registry := &health.Registry{TS: &timeStampMock{}}
+	for _, name := range strings.Split(expected, ",") {
+		registry.Register(name, health.CheckFunc(func(ctx context.Context) error {
+			return nil
+		}))
+	}
+
+	var names []string
+	for _, check := range registry.Report(context.Background()).Checks {
+		names = append(names, check.Name)
+	}
+	got := strings.Join(names, ",")
+
+	if expected != got {
+		t.Errorf("Report() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestReportWhenWithinCacheTTLThenChecksReused(t *testing.T) {
+	expected := []int{1, 1, 2}
+
+	halfway, expired := startTime.Add(500*time.Millisecond), startTime.Add(time.Second)
+	registry := &health.Registry{
+		CacheTTL: time.Second,
+		TS: &timeStampMock{times: []time.Time{
+			startTime, startTime, startTime, startTime, startTime,
+			halfway, halfway,
+			expired,
+		}},
+	}
+	var checks int
+	registry.Register("sql database", health.CheckFunc(func(ctx context.Context) error {
+		checks++
+		return errors.New("unreachable")
+	}))
+	registry.Start()
+
+	var got []int
+	for range expected {
+		report := registry.Report(context.Background())
+		report.Checks[0].Error = ""
+		got = append(got, checks)
+	}
+	report := registry.Report(context.Background())
+
+	if !reflect.DeepEqual(expected, got) || report.Checks[0].Error != "unreachable" {
+		t.Errorf("Report() failed, expected %v, got %v %v", expected, got, report)
+	}
+}
+
+var startTime = time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+
+type timeStampMock struct {
+	mutex sync.Mutex
+	times []time.Time
+}
+
+func (tp *timeStampMock) GetCurrentTime() time.Time {
+	tp.mutex.Lock()
+	defer tp.mutex.Unlock()
+	if len(tp.times) == 0 {
+		return startTime
+	}
+	current := tp.times[0]
+	if len(tp.times) > 1 {
+		tp.times = tp.times[1:]
+	}
+	return current
+}
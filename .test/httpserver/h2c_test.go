@@ -0,0 +1,32 @@
+//go:build go1.24
+
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/zeroberto/go-ms-template/handler/httpserver"
+)
+
+func TestListenerWhenH2CThenHTTP2WithoutTLS(t *testing.T) {
+	expected := 2
+
+	listener := start(t, &httpserver.Listener{H2C: true})
+	defer listener.Stop(context.Background())
+
+	protocols := &http.Protocols{}
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	response, err := client.Get(fmt.Sprintf("http://%s/", listener.Addr()))
+	if err != nil {
+		t.Fatalf("Get() failed, expected %v, got %v", nil, err)
+	}
+	response.Body.Close()
+
+	if response.ProtoMajor != expected {
+		t.Errorf("Start(H2C) failed, expected %v, got %v", expected, response.ProtoMajor)
+	}
+}
@@ -0,0 +1,351 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/handler/httpserver"
+	"github.com/zeroberto/go-ms-template/handler/middleware"
+)
+
+func TestListenerWhenHTTP2ThenNegotiated(t *testing.T) {
+	tests := []struct {
+		http2    bool
+		expected int
+	}{
+		{http2: true, expected: 2},
+		{http2: false, expected: 1},
+	}
+	for _, test := range tests {
+		pki := newPKI(t)
+		defer os.RemoveAll(pki.dir)
+		certificates := pki.certificates(t, "", &timeStampMock{})
+		config, err := httpserver.TLSPolicy{}.Config(certificates)
+		if err != nil {
+			t.Fatalf("Config() failed, expected %v, got %v", nil, err)
+		}
+		listener := start(t, &httpserver.Listener{TLS: config, HTTP2: test.http2})
+		defer listener.Stop(context.Background())
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.roots}, ForceAttemptHTTP2: true}}
+		response, err := client.Get(fmt.Sprintf("https://%s/", listener.Addr()))
+		if err != nil {
+			t.Fatalf("Get() failed, expected %v, got %v", nil, err)
+		}
+		response.Body.Close()
+
+		if response.ProtoMajor != test.expected {
+			t.Errorf("Start(HTTP2 %v) failed, expected %v, got %v", test.http2, test.expected, response.ProtoMajor)
+		}
+	}
+}
+
+func TestListenerWhenMutualTLSThenClientCertificateIsPrincipal(t *testing.T) {
+	expected := "CN=client"
+
+	pki := newPKI(t)
+	defer os.RemoveAll(pki.dir)
+	certificates := pki.certificates(t, pki.caFile, &timeStampMock{})
+	config, err := httpserver.TLSPolicy{}.Config(certificates)
+	if err != nil {
+		t.Fatalf("Config() failed, expected %v, got %v", nil, err)
+	}
+	var got string
+	handler := middleware.ClientCertificate(nil)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if principal := auth.GetPrincipal(request.Context()); principal != nil {
+			got = principal.Subject
+		}
+	}))
+	listener := start(t, &httpserver.Listener{Handler: handler, TLS: config})
+	defer listener.Stop(context.Background())
+	url := fmt.Sprintf("https://%s/", listener.Addr())
+
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.roots}}}
+	if response, err := anonymous.Get(url); err == nil {
+		response.Body.Close()
+		t.Errorf("Get() failed, expected %v, got %v", "a rejected handshake", response.Status)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.roots, Certificates: []tls.Certificate{pki.client}}}}
+	response, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get() failed, expected %v, got %v", nil, err)
+	}
+	response.Body.Close()
+
+	if expected != got {
+		t.Errorf("ClientCertificate() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestListenerWhenMinVersionThenOlderClientRejected(t *testing.T) {
+	pki := newPKI(t)
+	defer os.RemoveAll(pki.dir)
+	certificates := pki.certificates(t, "", &timeStampMock{})
+	config, err := httpserver.TLSPolicy{MinVersion: "1.3"}.Config(certificates)
+	if err != nil {
+		t.Fatalf("Config() failed, expected %v, got %v", nil, err)
+	}
+	listener := start(t, &httpserver.Listener{TLS: config})
+	defer listener.Stop(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pki.roots, MaxVersion: tls.VersionTLS12}}}
+	if response, err := client.Get(fmt.Sprintf("https://%s/", listener.Addr())); err == nil {
+		response.Body.Close()
+		t.Errorf("Get() failed, expected %v, got %v", "a rejected handshake", response.Status)
+	}
+}
+
+func TestTLSPolicyConfig(t *testing.T) {
+	pki := newPKI(t)
+	defer os.RemoveAll(pki.dir)
+	certificates := pki.certificates(t, pki.caFile, &timeStampMock{})
+
+	config, err := httpserver.TLSPolicy{
+		CipherSuites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"},
+		ClientAuth:   httpserver.VerifyClientCertificateIfGiven,
+	}.Config(certificates)
+
+	if err != nil || config.MinVersion != tls.VersionTLS12 || config.ClientAuth != tls.VerifyClientCertIfGiven ||
+		len(config.CipherSuites) != 1 || config.CipherSuites[0] != tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("Config() failed, expected %v, got %+v %v", "TLS 1.2 with one cipher suite", config, err)
+	}
+}
+
+func TestTLSPolicyConfigWhenInvalidThenError(t *testing.T) {
+	pki := newPKI(t)
+	defer os.RemoveAll(pki.dir)
+	certificates := pki.certificates(t, pki.caFile, &timeStampMock{})
+
+	policies := []httpserver.TLSPolicy{
+		{MinVersion: "1.4"},
+		{CipherSuites: []string{"TLS_UNKNOWN"}},
+		{CipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}},
+		{ClientAuth: "never"},
+	}
+	for _, policy := range policies {
+		if _, err := policy.Config(certificates); err == nil {
+			t.Errorf("Config(%+v) failed, expected %v, got %v", policy, "an error", err)
+		}
+	}
+}
+
+func TestCertificatesWhenFilesChangeThenReloaded(t *testing.T) {
+	pki := newPKI(t)
+	defer os.RemoveAll(pki.dir)
+	ts := &timeStampMock{times: []time.Time{startTime, startTime.Add(time.Second), startTime.Add(2 * time.Second), startTime.Add(time.Minute)}}
+	certificates := pki.certificates(t, "", ts)
+	first, _ := certificates.GetCertificate(nil)
+
+	pki.writeServer(t, 2)
+	// the modification time may not change within the resolution of the file system
+	later := time.Now().Add(time.Hour)
+	os.Chtimes(pki.certFile, later, later)
+	beforeInterval, _ := certificates.GetCertificate(nil)
+	afterInterval, err := certificates.GetCertificate(nil)
+
+	if serial(t, first) != 1 || serial(t, beforeInterval) != 1 || serial(t, afterInterval) != 2 || err != nil {
+		t.Errorf("GetCertificate() failed, expected %v, got %v %v %v %v", "serials 1 1 2", serial(t, first), serial(t, beforeInterval), serial(t, afterInterval), err)
+	}
+}
+
+func TestCertificatesWhenChangedFilesAreInvalidThenKept(t *testing.T) {
+	pki := newPKI(t)
+	defer os.RemoveAll(pki.dir)
+	ts := &timeStampMock{times: []time.Time{startTime, startTime.Add(time.Minute)}}
+	certificates := pki.certificates(t, "", ts)
+
+	if err := ioutil.WriteFile(pki.certFile, []byte("invalid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := certificates.GetCertificate(nil)
+
+	if err != nil || serial(t, got) != 1 {
+		t.Errorf("GetCertificate() failed, expected %v, got %v %v", 1, got, err)
+	}
+}
+
+func TestListenerWhenH2CWithTLSThenError(t *testing.T) {
+	listener := &httpserver.Listener{Address: "127.0.0.1:0", TLS: &tls.Config{}, H2C: true}
+
+	if err := listener.Start(); err == nil {
+		listener.Stop(context.Background())
+		t.Errorf("Start() failed, expected %v, got %v", "an error", err)
+	}
+}
+
+// start is responsible for starting the listener on a random port, answering with 204 when
+// no handler is informed
+func start(t *testing.T, listener *httpserver.Listener) *httpserver.Listener {
+	listener.Address = "127.0.0.1:0"
+	if listener.Handler == nil {
+		listener.Handler = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNoContent)
+		})
+	}
+	listener.OnError = func(err error) {
+		t.Errorf("Serve() failed, expected %v, got %v", nil, err)
+	}
+	if err := listener.Start(); err != nil {
+		t.Fatalf("Start() failed, expected %v, got %v", nil, err)
+	}
+	return listener
+}
+
+// pki holds a CA, with the files of a certificate for 127.0.0.1 and a client certificate
+// for CN=client it signed
+type pki struct {
+	dir      string
+	caFile   string
+	certFile string
+	keyFile  string
+	ca       *x509.Certificate
+	caKey    *ecdsa.PrivateKey
+	roots    *x509.CertPool
+	client   tls.Certificate
+}
+
+func newPKI(t *testing.T) *pki {
+	dir, err := ioutil.TempDir("", "httpserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &pki{
+		dir:      dir,
+		caFile:   filepath.Join(dir, "ca.pem"),
+		certFile: filepath.Join(dir, "cert.pem"),
+		keyFile:  filepath.Join(dir, "key.pem"),
+	}
+	p.caKey = newKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(100),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &p.caKey.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ca, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+	p.roots = x509.NewCertPool()
+	p.roots.AddCert(p.ca)
+	writePEM(t, p.caFile, "CERTIFICATE", der)
+
+	p.writeServer(t, 1)
+	clientKey := newKey(t)
+	clientDER := p.sign(t, &x509.Certificate{
+		SerialNumber: big.NewInt(200),
+		Subject:      pkix.Name{CommonName: "client"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, clientKey)
+	p.client = tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+	return p
+}
+
+// writeServer is responsible for writing the files of a certificate for 127.0.0.1 with the serial
+func (p *pki) writeServer(t *testing.T, serial int64) {
+	key := newKey(t)
+	der := p.sign(t, &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "server"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, key)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, p.certFile, "CERTIFICATE", der)
+	writePEM(t, p.keyFile, "EC PRIVATE KEY", keyDER)
+}
+
+func (p *pki) sign(t *testing.T, template *x509.Certificate, key *ecdsa.PrivateKey) []byte {
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	template.KeyUsage = x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, template, p.ca, &key.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// certificates is responsible for providing the loaded Certificates of the server
+func (p *pki) certificates(t *testing.T, clientCAFile string, ts *timeStampMock) *httpserver.Certificates {
+	certificates := &httpserver.Certificates{
+		CertFile:       p.certFile,
+		KeyFile:        p.keyFile,
+		ClientCAFile:   clientCAFile,
+		ReloadInterval: 30 * time.Second,
+		TS:             ts,
+	}
+	if err := certificates.Load(); err != nil {
+		t.Fatalf("Load() failed, expected %v, got %v", nil, err)
+	}
+	return certificates
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, name string, blockType string, der []byte) {
+	if err := ioutil.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func serial(t *testing.T, certificate *tls.Certificate) int64 {
+	if certificate == nil {
+		return 0
+	}
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.SerialNumber.Int64()
+}
+
+var startTime = time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+
+// timeStampMock provides the times in sequence, repeating the last one
+type timeStampMock struct {
+	times []time.Time
+}
+
+func (tp *timeStampMock) GetCurrentTime() time.Time {
+	if len(tp.times) == 0 {
+		return startTime
+	}
+	current := tp.times[0]
+	if len(tp.times) > 1 {
+		tp.times = tp.times[1:]
+	}
+	return current
+}
@@ -0,0 +1,127 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/lifecycle"
+)
+
+func TestStartAndStop(t *testing.T) {
+	expected := []string{"start a", "start b", "stop b", "stop a"}
+
+	var got []string
+	manager := &lifecycle.Manager{}
+	manager.Register("a", recordingHook("a", &got, nil))
+	manager.Register("b", recordingHook("b", &got, nil))
+
+	if err := manager.Start(); err != nil {
+		t.Errorf("Start() failed, error %v", err)
+	}
+	if err := manager.Stop(context.Background()); err != nil {
+		t.Errorf("Stop() failed, error %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Start() and Stop() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestStartWhenComponentFailsThenStopStarted(t *testing.T) {
+	expected := []string{"start a", "start b", "stop a"}
+
+	var got []string
+	manager := &lifecycle.Manager{}
+	manager.Register("a", recordingHook("a", &got, nil))
+	manager.Register("b", recordingHook("b", &got, errors.New("error")))
+	manager.Register("c", recordingHook("c", &got, nil))
+
+	err := manager.Start()
+
+	if lerr, ok := err.(*lifecycle.Error); !ok || lerr.Component != "b" {
+		t.Errorf("Start() failed, expected error of component %v, got %v", "b", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Start() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestStopWhenComponentFailsThenStopOthers(t *testing.T) {
+	expected := []string{"stop b", "stop a"}
+
+	var got []string
+	manager := &lifecycle.Manager{}
+	manager.Register("a", recordingHook("a", &got, nil))
+	manager.Register("b", lifecycle.Hook{OnStop: func(ctx context.Context) error {
+		got = append(got, "stop b")
+		return errors.New("error")
+	}})
+	manager.Start()
+	got = nil
+
+	err := manager.Stop(context.Background())
+
+	if _, ok := err.(*lifecycle.StopError); !ok {
+		t.Errorf("Stop() failed, expected %T, got %v", &lifecycle.StopError{}, err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Stop() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestWaitWhenSignalReceivedThenStopWithinTimeout(t *testing.T) {
+	var deadline time.Time
+	manager := &lifecycle.Manager{ShutdownTimeout: time.Minute}
+	manager.Register("a", lifecycle.Hook{OnStop: func(ctx context.Context) error {
+		deadline, _ = ctx.Deadline()
+		return nil
+	}})
+	manager.Start()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	}()
+	err := manager.Wait()
+
+	if err != nil {
+		t.Errorf("Wait() failed, error %v", err)
+	}
+	if deadline.IsZero() || time.Until(deadline) > time.Minute {
+		t.Errorf("Wait() failed, expected deadline within %v, got %v", time.Minute, deadline)
+	}
+}
+
+func TestWaitWhenComponentFailsThenReturnError(t *testing.T) {
+	var got []string
+	manager := &lifecycle.Manager{}
+	manager.Register("a", recordingHook("a", &got, nil))
+	manager.Start()
+
+	manager.Fail("a", errors.New("error"))
+	err := manager.Wait()
+
+	if err == nil || err.Error() != "a: error" {
+		t.Errorf("Wait() failed, expected %v, got %v", "a: error", err)
+	}
+	if got[len(got)-1] != "stop a" {
+		t.Errorf("Wait() failed, expected %v, got %v", "stop a", got)
+	}
+}
+
+func recordingHook(name string, records *[]string, startErr error) lifecycle.Hook {
+	return lifecycle.Hook{
+		OnStart: func() error {
+			*records = append(*records, "start "+name)
+			return startErr
+		},
+		OnStop: func(ctx context.Context) error {
+			*records = append(*records, "stop "+name)
+			return nil
+		},
+	}
+}
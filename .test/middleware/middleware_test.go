@@ -0,0 +1,656 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/handler/middleware"
+)
+
+func TestChain(t *testing.T) {
+	expected := []string{"first", "second", "handler"}
+
+	var got []string
+	record := func(name string) middleware.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				got = append(got, name)
+				next.ServeHTTP(writer, request)
+			})
+		}
+	}
+	handler := middleware.Chain(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		got = append(got, "handler")
+	}), record("first"), record("second"))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Chain() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestRequestIDWhenInformedThenPropagated(t *testing.T) {
+	expected := "abc-123"
+
+	var got string
+	handler := middleware.RequestID(middleware.RequestIDHeader)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		got = middleware.GetRequestID(request.Context())
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(middleware.RequestIDHeader, expected)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if expected != got || expected != recorder.Header().Get(middleware.RequestIDHeader) {
+		t.Errorf("RequestID() failed, expected %v, got %v and %v", expected, got, recorder.Header().Get(middleware.RequestIDHeader))
+	}
+}
+
+func TestRequestIDWhenAbsentOrInvalidThenGenerated(t *testing.T) {
+	for _, informed := range []string{"", "with space", strings.Repeat("a", 129)} {
+		var got string
+		handler := middleware.RequestID(middleware.RequestIDHeader)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			got = middleware.GetRequestID(request.Context())
+		}))
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(middleware.RequestIDHeader, informed)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if len(got) != 32 || got == informed || got != recorder.Header().Get(middleware.RequestIDHeader) {
+			t.Errorf("RequestID(%q) failed, expected %v, got %v", informed, "a generated ID", got)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	expected := middleware.AccessLogEntry{
+		Time:       startTime,
+		RequestID:  "abc",
+		Method:     http.MethodPost,
+		URI:        "/examples?a=b",
+		Status:     http.StatusCreated,
+		Bytes:      5,
+		LatencyMs:  1500,
+		RemoteAddr: "192.0.2.1:1234",
+		UserAgent:  "test",
+	}
+
+	var output bytes.Buffer
+	ts := &timeStampMock{times: []time.Time{startTime, startTime.Add(1500 * time.Millisecond)}}
+	handler := middleware.Chain(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusCreated)
+		writer.Write([]byte("hello"))
+	}), middleware.RequestID(middleware.RequestIDHeader), middleware.AccessLog(&output, ts))
+	request := httptest.NewRequest(http.MethodPost, "/examples?a=b", nil)
+	request.Header.Set(middleware.RequestIDHeader, "abc")
+	request.Header.Set("User-Agent", "test")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	var got middleware.AccessLogEntry
+	if err := json.Unmarshal(output.Bytes(), &got); err != nil {
+		t.Errorf("AccessLog() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("AccessLog() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestRecoverWhenPanicThenInternalServerError(t *testing.T) {
+	expected := api.Problem{
+		Type:     api.ProblemTypeBlank,
+		Title:    http.StatusText(http.StatusInternalServerError),
+		Status:   http.StatusInternalServerError,
+		Detail:   "The request could not be completed",
+		Instance: "/examples",
+		Time:     startTime,
+	}
+
+	handler := middleware.Recover(&timeStampMock{times: []time.Time{startTime}})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		panic("failure")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/examples", nil))
+
+	var got api.Problem
+	json.Unmarshal(recorder.Body.Bytes(), &got)
+	if recorder.Code != http.StatusInternalServerError || !reflect.DeepEqual(expected, got) {
+		t.Errorf("Recover() failed, expected %v, got %v %v", expected, recorder.Code, got)
+	}
+	if recorder.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("Recover() failed, expected %v, got %v", "application/problem+json", recorder.Header().Get("Content-Type"))
+	}
+}
+
+func TestRecoverWhenPanicAfterResponseStartedThenResponseKept(t *testing.T) {
+	handler := middleware.Recover(&timeStampMock{})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusAccepted)
+		panic("failure")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/examples", nil))
+
+	if recorder.Code != http.StatusAccepted || recorder.Body.Len() != 0 {
+		t.Errorf("Recover() failed, expected %v, got %v %v", http.StatusAccepted, recorder.Code, recorder.Body.String())
+	}
+}
+
+func TestMaxBodySizeWhenDeclaredLengthExceedsThenRequestEntityTooLarge(t *testing.T) {
+	handler := middleware.MaxBodySize(4, &timeStampMock{})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		t.Errorf("ServeHTTP() should not be called")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader("12345")))
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("MaxBodySize() failed, expected %v, got %v", http.StatusRequestEntityTooLarge, recorder.Code)
+	}
+}
+
+func TestMaxBodySizeWhenStreamedBodyExceedsThenReadFails(t *testing.T) {
+	var got error
+	handler := middleware.MaxBodySize(4, &timeStampMock{})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, got = ioutil.ReadAll(request.Body)
+	}))
+	request := httptest.NewRequest(http.MethodPost, "/examples", strings.NewReader("12345"))
+	request.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	var tooLargeErr *middleware.BodyTooLargeError
+	if !errors.As(got, &tooLargeErr) || tooLargeErr.Limit != 4 {
+		t.Errorf("MaxBodySize() failed, expected %v, got %v", "a BodyTooLargeError", got)
+	}
+}
+
+func TestRequestTimeoutWhenRouteTimeoutThenContextDeadline(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected time.Duration
+	}{
+		{path: "/examples", expected: time.Minute},
+		{path: "/admin/api-keys", expected: time.Hour},
+		{path: "/examples/stream", expected: 0},
+	}
+	requestTimeout := &middleware.RequestTimeout{
+		Default: time.Hour,
+		Routes:  map[string]time.Duration{"listExamples": time.Minute},
+		Route: func(request *http.Request) string {
+			if request.URL.Path == "/examples" {
+				return "listExamples"
+			}
+			return ""
+		},
+		Exempt: func(request *http.Request) bool {
+			return request.URL.Path == "/examples/stream"
+		},
+	}
+
+	for _, test := range tests {
+		var got time.Time
+		handler := requestTimeout.Apply(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			got, _ = request.Context().Deadline()
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if test.expected == 0 && !got.IsZero() || test.expected != 0 && (got.IsZero() || time.Until(got) > test.expected || time.Until(got) < test.expected-time.Minute/2) {
+			t.Errorf("Apply(%s) failed, expected deadline within %v, got %v", test.path, test.expected, got)
+		}
+	}
+}
+
+func TestRateLimiterWhenBucketExhaustedThenTooManyRequests(t *testing.T) {
+	expected := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusOK}
+	expectedHeaders := []string{"2 1 2 ", "2 0 4 ", "2 0 4 2", "2 0 4 "}
+
+	ts := &timeStampMock{times: []time.Time{startTime, startTime, startTime, startTime.Add(2 * time.Second)}}
+	limiter := &middleware.RateLimiter{Default: middleware.RateLimit{Rate: 0.5, Burst: 2}, Key: middleware.ClientIPKey, TS: ts}
+	handler := limiter.Limit(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	var got []int
+	var gotHeaders []string
+	for range expected {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/examples", nil))
+		got = append(got, recorder.Code)
+		gotHeaders = append(gotHeaders, strings.Join([]string{
+			recorder.Header().Get("RateLimit-Limit"),
+			recorder.Header().Get("RateLimit-Remaining"),
+			recorder.Header().Get("RateLimit-Reset"),
+			recorder.Header().Get("Retry-After"),
+		}, " "))
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Limit() failed, expected %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual(expectedHeaders, gotHeaders) {
+		t.Errorf("Limit() failed, expected %q, got %q", expectedHeaders, gotHeaders)
+	}
+}
+
+func TestRateLimiterWhenRouteOrClientDiffersThenSeparateBuckets(t *testing.T) {
+	limiter := &middleware.RateLimiter{
+		Default: middleware.RateLimit{Rate: 1, Burst: 1},
+		Routes:  map[string]middleware.RateLimit{"listExamples": {Rate: 1, Burst: 1}},
+		Route: func(request *http.Request) string {
+			if request.Method == http.MethodGet {
+				return "listExamples"
+			}
+			return "createExample"
+		},
+		Key: middleware.FirstKey(middleware.SubjectKey, middleware.ClientIPKey),
+		TS:  &timeStampMock{},
+	}
+	handler := limiter.Limit(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	serve := func(method string, subject string) int {
+		request := httptest.NewRequest(method, "/examples", nil)
+		if subject != "" {
+			request = request.WithContext(auth.WithPrincipal(request.Context(), &auth.Principal{Subject: subject}))
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	got := []int{
+		serve(http.MethodGet, ""),
+		serve(http.MethodPost, ""),
+		serve(http.MethodGet, "alice"),
+		serve(http.MethodGet, ""),
+		serve(http.MethodGet, "alice"),
+	}
+
+	expected := []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Limit() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestRateLimiterWhenNoRateThenNotLimited(t *testing.T) {
+	limiter := &middleware.RateLimiter{Key: middleware.ClientIPKey, TS: &timeStampMock{}}
+	handler := limiter.Limit(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/examples", nil))
+
+		if recorder.Code != http.StatusOK || recorder.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("Limit() failed, expected %v, got %v", http.StatusOK, recorder.Code)
+		}
+	}
+}
+
+func TestLimitInFlightWhenMaxReachedThenServiceUnavailable(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := middleware.LimitInFlight(1, nil, &timeStampMock{})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		close(entered)
+		<-release
+	}))
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/examples", nil))
+		close(done)
+	}()
+	<-entered
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/examples", nil))
+	close(release)
+	<-done
+
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Header().Get("Retry-After") != "1" {
+		t.Errorf("LimitInFlight() failed, expected %v, got %v", http.StatusServiceUnavailable, recorder.Code)
+	}
+}
+
+func TestLimitInFlightWhenStreamsOpenThenRequestsHandled(t *testing.T) {
+	isStream := func(request *http.Request) bool {
+		return request.URL.Path == "/examples/stream"
+	}
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	handler := middleware.LimitInFlight(2, isStream, &timeStampMock{})(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if isStream(request) {
+			entered <- struct{}{}
+			<-release
+		}
+	}))
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/examples/stream", nil))
+			done <- struct{}{}
+		}()
+		<-entered
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/examples", nil))
+	close(release)
+	<-done
+	<-done
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("LimitInFlight() failed, expected %v, got %v", http.StatusOK, recorder.Code)
+	}
+}
+
+func TestAuthenticateWhenCredentialsAreValidThenPrincipalInContext(t *testing.T) {
+	expected := &auth.Principal{Subject: "alice", Scopes: []string{"examples:read"}}
+
+	var got *auth.Principal
+	handler := authenticator().Authenticate(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		got = auth.GetPrincipal(request.Context())
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/examples", nil)
+	request.Header.Set("Authorization", "bearer valid")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK || !reflect.DeepEqual(expected, got) {
+		t.Errorf("Authenticate() failed, expected %v, got %v %v", expected, recorder.Code, got)
+	}
+}
+
+func TestAuthenticateWhenRejectedThenChallenge(t *testing.T) {
+	tests := []struct {
+		method          string
+		authorization   string
+		expected        int
+		expectedHeaders []string
+	}{
+		{method: http.MethodGet, authorization: "", expected: http.StatusUnauthorized, expectedHeaders: []string{`ApiKey realm="test"`, `Bearer realm="test"`}},
+		{method: http.MethodGet, authorization: "Basic YTpi", expected: http.StatusUnauthorized, expectedHeaders: []string{`ApiKey realm="test"`, `Bearer realm="test"`}},
+		{method: http.MethodGet, authorization: "Bearer invalid", expected: http.StatusUnauthorized, expectedHeaders: []string{`Bearer realm="test", error="invalid_token"`}},
+		{method: http.MethodDelete, authorization: "Bearer valid", expected: http.StatusForbidden, expectedHeaders: []string{`Bearer realm="test", error="insufficient_scope", scope="examples:delete"`}},
+	}
+	for _, test := range tests {
+		handler := authenticator().Authenticate(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			t.Errorf("ServeHTTP() should not be called")
+		}))
+		request := httptest.NewRequest(test.method, "/examples", nil)
+		request.Header.Set("Authorization", test.authorization)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected || !reflect.DeepEqual(test.expectedHeaders, recorder.Header()["Www-Authenticate"]) {
+			t.Errorf("Authenticate(%s) failed, expected %v %v, got %v %v", test.authorization, test.expected, test.expectedHeaders, recorder.Code, recorder.Header()["Www-Authenticate"])
+		}
+		if recorder.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("Authenticate(%s) failed, expected %v, got %v", test.authorization, "application/problem+json", recorder.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestAuthenticateWhenCredentialsCannotBeVerifiedThenServiceUnavailable(t *testing.T) {
+	handler := authenticator().Authenticate(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		t.Errorf("ServeHTTP() should not be called")
+	}))
+	request := httptest.NewRequest(http.MethodGet, "/examples", nil)
+	request.Header.Set("Authorization", "ApiKey unavailable")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("Authenticate() failed, expected %v, got %v %v", http.StatusServiceUnavailable, recorder.Code, recorder.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestAuthenticateWhenNoOperationThenPublic(t *testing.T) {
+	called := false
+	handler := authenticator().Authenticate(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		called = true
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	if !called {
+		t.Errorf("Authenticate() failed, expected %v, got %v", "a public request", called)
+	}
+}
+
+func TestClientCertificateWhenVerifiedThenPrincipalEnforcedByAuthenticate(t *testing.T) {
+	certificate := &x509.Certificate{
+		Subject:      pkix.Name{CommonName: "client"},
+		Issuer:       pkix.Name{CommonName: "ca"},
+		SerialNumber: big.NewInt(7),
+	}
+	tests := []struct {
+		method   string
+		expected int
+	}{
+		{method: http.MethodGet, expected: http.StatusOK},
+		{method: http.MethodDelete, expected: http.StatusForbidden},
+	}
+	for _, test := range tests {
+		var got *auth.Principal
+		handler := middleware.Chain(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			got = auth.GetPrincipal(request.Context())
+		}), middleware.ClientCertificate(map[string][]string{"CN=client": {"examples:read"}}), authenticator().Authenticate)
+		request := httptest.NewRequest(test.method, "/examples", nil)
+		request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.expected || recorder.Header().Get("WWW-Authenticate") != "" {
+			t.Errorf("ClientCertificate(%s) failed, expected %v, got %v %v", test.method, test.expected, recorder.Code, recorder.Header().Get("WWW-Authenticate"))
+		}
+		if test.expected == http.StatusOK && (got == nil || got.Subject != "CN=client" || got.Claims["serial"] != "7") {
+			t.Errorf("ClientCertificate(%s) failed, expected %v, got %v", test.method, "CN=client", got)
+		}
+	}
+}
+
+func TestClientCertificateWhenNotVerifiedThenAnonymous(t *testing.T) {
+	handler := middleware.Chain(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		t.Errorf("ServeHTTP() should not be called")
+	}), middleware.ClientCertificate(nil), authenticator().Authenticate)
+	request := httptest.NewRequest(http.MethodGet, "/examples", nil)
+	request.TLS = &tls.ConnectionState{}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("ClientCertificate() failed, expected %v, got %v", http.StatusUnauthorized, recorder.Code)
+	}
+}
+
+func TestCORSWhenPreflightIsAllowedThenNoContent(t *testing.T) {
+	expected := http.Header{
+		"Vary":                             {"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"},
+		"Access-Control-Allow-Origin":      {"https://admin.example.com"},
+		"Access-Control-Allow-Credentials": {"true"},
+		"Access-Control-Allow-Methods":     {"GET, DELETE"},
+		"Access-Control-Allow-Headers":     {"authorization, If-Match"},
+		"Access-Control-Max-Age":           {"600"},
+	}
+
+	handler := cors().Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		t.Errorf("ServeHTTP() should not be called")
+	}))
+	request := httptest.NewRequest(http.MethodOptions, "/examples/1", nil)
+	request.Header.Set("Origin", "https://admin.example.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+	request.Header.Set("Access-Control-Request-Headers", "authorization, If-Match")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNoContent || !reflect.DeepEqual(expected, recorder.Header()) {
+		t.Errorf("CORS() failed, expected %v, got %v %v", expected, recorder.Code, recorder.Header())
+	}
+}
+
+func TestCORSWhenPreflightIsNotAllowedThenForbidden(t *testing.T) {
+	tests := []struct {
+		origin  string
+		method  string
+		headers string
+	}{
+		{origin: "https://example.org", method: http.MethodGet},
+		{origin: "https://admin.example.com.evil.org", method: http.MethodGet},
+		{origin: "https://admin.example.com", method: http.MethodPut},
+		{origin: "https://admin.example.com", method: http.MethodGet, headers: "X-Custom"},
+	}
+	for _, test := range tests {
+		handler := cors().Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			t.Errorf("ServeHTTP() should not be called")
+		}))
+		request := httptest.NewRequest(http.MethodOptions, "/examples", nil)
+		request.Header.Set("Origin", test.origin)
+		request.Header.Set("Access-Control-Request-Method", test.method)
+		request.Header.Set("Access-Control-Request-Headers", test.headers)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusForbidden || recorder.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("CORS(%v) failed, expected %v, got %v %v", test, http.StatusForbidden, recorder.Code, recorder.Header())
+		}
+	}
+}
+
+func TestCORSWhenOriginIsAllowedThenHeaders(t *testing.T) {
+	tests := []struct {
+		origin          string
+		expectedOrigin  string
+		expectedExposed string
+	}{
+		{origin: "https://admin.example.com", expectedOrigin: "https://admin.example.com", expectedExposed: "ETag, Location"},
+		{origin: "http://localhost:3000", expectedOrigin: "http://localhost:3000", expectedExposed: "ETag, Location"},
+		{origin: "https://example.org"},
+		{},
+	}
+	for _, test := range tests {
+		called := false
+		handler := cors().Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			called = true
+		}))
+		request := httptest.NewRequest(http.MethodGet, "/examples", nil)
+		request.Header.Set("Origin", test.origin)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if !called || recorder.Header().Get("Access-Control-Allow-Origin") != test.expectedOrigin || recorder.Header().Get("Access-Control-Expose-Headers") != test.expectedExposed {
+			t.Errorf("CORS(%s) failed, expected %v, got %v", test.origin, test.expectedOrigin, recorder.Header())
+		}
+		if recorder.Header().Get("Vary") != "Origin" {
+			t.Errorf("CORS(%s) failed, expected %v, got %v", test.origin, "Vary: Origin", recorder.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestCORSWhenEveryOriginWithoutCredentialsThenWildcard(t *testing.T) {
+	handler := (&middleware.CORS{AllowedOrigins: []string{"*"}, TS: &timeStampMock{}}).Handle(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	request := httptest.NewRequest(http.MethodGet, "/examples", nil)
+	request.Header.Set("Origin", "https://example.org")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Header().Get("Access-Control-Allow-Origin") != "*" || recorder.Header().Get("Vary") != "" || recorder.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("CORS() failed, expected %v, got %v", "*", recorder.Header())
+	}
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	expected := http.Header{
+		"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+		"X-Content-Type-Options":    {"nosniff"},
+		"X-Frame-Options":           {"DENY"},
+		"Content-Security-Policy":   {"default-src 'self'"},
+	}
+
+	securityHeaders := &middleware.SecurityHeaders{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+		ContentSecurityPolicy: "default-src 'none'",
+	}
+	handler := securityHeaders.Apply(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Security-Policy", "default-src 'self'")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	if !reflect.DeepEqual(expected, recorder.Header()) {
+		t.Errorf("SecurityHeaders() failed, expected %v, got %v", expected, recorder.Header())
+	}
+}
+
+func cors() *middleware.CORS {
+	return &middleware.CORS{
+		AllowedOrigins:   []string{"https://*.example.com", "http://localhost:*"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodDelete},
+		AllowedHeaders:   []string{"Authorization", "If-Match"},
+		ExposedHeaders:   []string{"ETag", "Location"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+		TS:               &timeStampMock{},
+	}
+}
+
+func authenticator() *middleware.Authenticator {
+	return &middleware.Authenticator{
+		Schemes: map[string]middleware.CredentialVerifier{
+			middleware.BearerScheme: &verifierMock{},
+			middleware.APIKeyScheme: &verifierMock{},
+		},
+		Scopes: map[string][]string{"listExamples": {"examples:read"}, "deleteExample": {"examples:delete"}},
+		Route: func(request *http.Request) string {
+			switch {
+			case request.URL.Path != "/examples":
+				return ""
+			case request.Method == http.MethodDelete:
+				return "deleteExample"
+			}
+			return "listExamples"
+		},
+		Realm: "test",
+		TS:    &timeStampMock{},
+	}
+}
+
+type verifierMock struct{}
+
+func (verifier *verifierMock) Verify(ctx context.Context, credentials string) (*auth.Principal, error) {
+	if credentials == "unavailable" {
+		return nil, errors.New("the database is unavailable")
+	}
+	if credentials != "valid" {
+		return nil, &auth.Error{Cause: errors.New("invalid signature")}
+	}
+	return &auth.Principal{Subject: "alice", Scopes: []string{"examples:read"}}, nil
+}
+
+var startTime = time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+
+// timeStampMock provides the times in sequence, repeating the last one
+type timeStampMock struct {
+	times []time.Time
+}
+
+func (tp *timeStampMock) GetCurrentTime() time.Time {
+	if len(tp.times) == 0 {
+		return startTime
+	}
+	current := tp.times[0]
+	if len(tp.times) > 1 {
+		tp.times = tp.times[1:]
+	}
+	return current
+}
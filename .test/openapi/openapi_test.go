@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/openapi"
+)
+
+type subject struct {
+	Name     string            `json:"name" validate:"required,min=1,max=10,pattern=^[a-z]+$"`
+	Kind     string            `json:"kind,omitempty" validate:"enum=a|b"`
+	Quantity int32             `validate:"min=1,max=5"`
+	Ratio    float64           `json:"ratio"`
+	Count    uint              `json:"count"`
+	At       time.Time         `json:"at"`
+	Data     []byte            `json:"data"`
+	Children []*subject        `json:"children"`
+	Labels   map[string]string `json:"labels"`
+	Any      interface{}       `json:"any"`
+	Example  model.Example     `json:"example"`
+	Ignored  string            `json:"-"`
+	hidden   string
+}
+
+type Example struct{}
+
+func TestSchemaOf(t *testing.T) {
+	expected := `{"$ref":"#/components/schemas/subject"}`
+	expectedSubject := `{"type":"object","properties":{` +
+		`"Quantity":{"type":"integer","format":"int32","minimum":1,"maximum":5},` +
+		`"any":{},` +
+		`"at":{"type":"string","format":"date-time"},` +
+		`"children":{"type":"array","items":{"$ref":"#/components/schemas/subject"}},` +
+		`"count":{"type":"integer","minimum":0},` +
+		`"data":{"type":"string","format":"byte"},` +
+		`"example":{"$ref":"#/components/schemas/Example"},` +
+		`"kind":{"type":"string","enum":["a","b"]},` +
+		`"labels":{"type":"object","additionalProperties":{"type":"string"}},` +
+		`"name":{"type":"string","pattern":"^[a-z]+$","minLength":1,"maxLength":10},` +
+		`"ratio":{"type":"number","format":"double"}},` +
+		`"required":["name"]}`
+
+	components := &openapi.Components{}
+	got := components.SchemaOf(&subject{})
+
+	if data, _ := json.Marshal(got); string(data) != expected {
+		t.Errorf("SchemaOf() failed, expected %v, got %v", expected, string(data))
+	}
+	if data, _ := json.Marshal(components.Schemas["subject"]); string(data) != expectedSubject {
+		t.Errorf("SchemaOf() failed, expected %v, got %v", expectedSubject, string(data))
+	}
+	if _, ok := components.Schemas["Example"].Properties["Name"]; !ok {
+		t.Errorf("SchemaOf() failed, expected %v, got %v", "the properties of model.Example", components.Schemas["Example"])
+	}
+}
+
+func TestSchemaOfWhenNameIsTakenThenQualifiedName(t *testing.T) {
+	expected := []string{"#/components/schemas/Example", "#/components/schemas/OpenapiExample", "#/components/schemas/Example"}
+
+	components := &openapi.Components{}
+	got := []string{
+		components.SchemaOf(model.Example{}).Ref,
+		components.SchemaOf(Example{}).Ref,
+		components.SchemaOf(model.Example{}).Ref,
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("SchemaOf() failed, expected %v, got %v", expected, got)
+	}
+}
+
+type base struct {
+	ID int64 `json:"id" validate:"required"`
+}
+
+type extended struct {
+	base
+	Key string `json:"key"`
+}
+
+func TestSchemaOfWhenStructIsEmbeddedThenPromotedFields(t *testing.T) {
+	expected := `{"type":"object","properties":{"id":{"type":"integer","format":"int64"},"key":{"type":"string"}},"required":["id"]}`
+
+	components := &openapi.Components{}
+	components.SchemaOf(extended{})
+
+	if data, _ := json.Marshal(components.Schemas["extended"]); string(data) != expected {
+		t.Errorf("SchemaOf() failed, expected %v, got %v", expected, string(data))
+	}
+}
@@ -0,0 +1,172 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zeroberto/go-ms-template/patch"
+)
+
+func document(t *testing.T, data string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatalf("document() failed, error %v", err)
+	}
+	value, _ = patch.ToDocument(value)
+	return value
+}
+
+func TestMergePatchApply(t *testing.T) {
+	original := document(t, `{"a":"b","c":{"d":"e","f":"g"},"h":[1,2]}`)
+	expected := document(t, `{"a":"z","c":{"d":"e"},"h":[3],"i":{"j":true}}`)
+
+	mergePatch, err := patch.ParseMergePatch([]byte(`{"a":"z","c":{"f":null},"h":[3],"i":{"j":true,"k":null}}`))
+	if err != nil {
+		t.Errorf("ParseMergePatch() failed, error %v", err)
+	}
+	got, err := mergePatch.Apply(original)
+
+	if err != nil {
+		t.Errorf("Apply() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Apply() failed, expected %v, got %v", expected, got)
+	}
+	if !reflect.DeepEqual(document(t, `{"a":"b","c":{"d":"e","f":"g"},"h":[1,2]}`), original) {
+		t.Errorf("Apply() failed, expected %v, got %v", "the original document unchanged", original)
+	}
+}
+
+func TestParseMergePatchWhenInvalidThenMalformedError(t *testing.T) {
+	_, got := patch.ParseMergePatch([]byte(`{"a":`))
+
+	var malformedErr *patch.MalformedError
+	if !errors.As(got, &malformedErr) {
+		t.Errorf("ParseMergePatch() failed, expected %v, got %v", "a malformed error", got)
+	}
+}
+
+func TestJSONPatchApply(t *testing.T) {
+	original := document(t, `{"a":{"b":[1,2,3]},"c":"d","e~/f":1}`)
+	expected := document(t, `{"a":{"b":[0,1,3,4]},"x":"d","y":1,"copy":{"b":[0,1,3,4]}}`)
+
+	jsonPatch, err := patch.ParseJSONPatch([]byte(`[
+		{"op":"test","path":"/a/b/2","value":3.0},
+		{"op":"add","path":"/a/b/0","value":0},
+		{"op":"remove","path":"/a/b/2"},
+		{"op":"add","path":"/a/b/-","value":4},
+		{"op":"move","from":"/c","path":"/x"},
+		{"op":"replace","path":"/e~0~1f","value":1},
+		{"op":"move","from":"/e~0~1f","path":"/y"},
+		{"op":"copy","from":"/a","path":"/copy"}
+	]`))
+	if err != nil {
+		t.Errorf("ParseJSONPatch() failed, error %v", err)
+	}
+	got, err := jsonPatch.Apply(original)
+
+	if err != nil {
+		t.Errorf("Apply() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Apply() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestJSONPatchApplyWhenTestFailsThenTestFailedError(t *testing.T) {
+	expected := &patch.TestFailedError{Index: 1, Path: "/a"}
+
+	jsonPatch, _ := patch.ParseJSONPatch([]byte(`[
+		{"op":"replace","path":"/a","value":"c"},
+		{"op":"test","path":"/a","value":"b"}
+	]`))
+	_, got := jsonPatch.Apply(document(t, `{"a":"b"}`))
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Apply() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestJSONPatchApplyWhenPathNotExistsThenError(t *testing.T) {
+	tests := map[string]*patch.Error{
+		`[{"op":"remove","path":"/b"}]`:                 {Index: 0, Path: "/b", Message: "member b does not exist"},
+		`[{"op":"add","path":"/b/c","value":1}]`:        {Index: 0, Path: "/b/c", Message: "member b does not exist"},
+		`[{"op":"add","path":"/l/3","value":1}]`:        {Index: 0, Path: "/l/3", Message: "index 3 is out of bounds"},
+		`[{"op":"move","from":"/l","path":"/l/0"}]`:     {Index: 0, Path: "/l/0", Message: "cannot move /l into one of its children"},
+		`[{"op":"replace","path":"/l/01","value":"x"}]`: {Index: 0, Path: "/l/01", Message: "index 01 is out of bounds"},
+	}
+
+	for data, expected := range tests {
+		jsonPatch, err := patch.ParseJSONPatch([]byte(data))
+		if err != nil {
+			t.Errorf("ParseJSONPatch(%s) failed, error %v", data, err)
+		}
+		_, got := jsonPatch.Apply(document(t, `{"a":"b","l":[1]}`))
+
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("Apply(%s) failed, expected %v, got %v", data, expected, got)
+		}
+	}
+}
+
+func TestParseJSONPatchWhenInvalidThenMalformedError(t *testing.T) {
+	tests := []string{
+		`{"op":"add"}`,
+		`[{"op":"swap","path":"/a"}]`,
+		`[{"op":"add","path":"/a"}]`,
+		`[{"op":"remove","path":"a"}]`,
+		`[{"op":"copy","path":"/a","from":"b"}]`,
+	}
+
+	for _, data := range tests {
+		_, got := patch.ParseJSONPatch([]byte(data))
+
+		var malformedErr *patch.MalformedError
+		if !errors.As(got, &malformedErr) {
+			t.Errorf("ParseJSONPatch(%s) failed, expected %v, got %v", data, "a malformed error", got)
+		}
+	}
+}
+
+func TestFromDocumentWhenTypeMismatchThenUnmarshalTypeError(t *testing.T) {
+	var target struct{ Name string }
+
+	got := patch.FromDocument(document(t, `{"Name":1}`), &target)
+
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(got, &typeErr) || typeErr.Field != "Name" {
+		t.Errorf("FromDocument() failed, expected %v, got %v", "a type error on Name", got)
+	}
+}
+
+func TestRenamedApply(t *testing.T) {
+	expected := document(t, `{"Name":"z","Useful":true,"Other":1}`)
+	jsonPatch, err := patch.ParseJSONPatch([]byte(`[{"op":"replace","path":"/name","value":"z"},{"op":"add","path":"/useful","value":true}]`))
+	if err != nil {
+		t.Errorf("ParseJSONPatch() failed, error %v", err)
+	}
+	renamed := patch.Renamed{Patch: jsonPatch, Names: map[string]string{"Name": "name", "Useful": "useful"}}
+
+	got, err := renamed.Apply(document(t, `{"Name":"a","Useful":false,"Other":1}`))
+
+	if err != nil {
+		t.Errorf("Apply() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("Apply() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestRenamedApplyWhenDocumentNameThenMalformedError(t *testing.T) {
+	mergePatch, _ := patch.ParseMergePatch([]byte(`{"Name":"z"}`))
+	renamed := patch.Renamed{Patch: mergePatch, Names: map[string]string{"Name": "name"}}
+
+	_, got := renamed.Apply(document(t, `{"Name":"a"}`))
+
+	var malformedErr *patch.MalformedError
+	if !errors.As(got, &malformedErr) {
+		t.Errorf("Apply() failed, expected %v, got %v", "a malformed error", got)
+	}
+}
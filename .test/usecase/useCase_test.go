@@ -1,17 +1,29 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/zeroberto/go-ms-template/chrono/provider"
 	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/dataservice/idempotencydata/datamemory"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver"
+	"github.com/zeroberto/go-ms-template/filter"
 	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/patch"
 	"github.com/zeroberto/go-ms-template/usecase"
+	"github.com/zeroberto/go-ms-template/usecase/apikey"
 	"github.com/zeroberto/go-ms-template/usecase/example/creation"
 	"github.com/zeroberto/go-ms-template/usecase/example/read"
 	"github.com/zeroberto/go-ms-template/usecase/example/removal"
+	"github.com/zeroberto/go-ms-template/usecase/idempotency"
+	"github.com/zeroberto/go-ms-template/usecase/stream"
 )
 
 func TestCreateExample(t *testing.T) {
@@ -21,7 +33,8 @@ func TestCreateExample(t *testing.T) {
 		ID:        1,
 		Name:      "test",
 		Useful:    true,
-		CreatedAt: fixedTime,
+		CreatedAt: currentTime,
+		UpdatedAt: currentTime,
 	}
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
@@ -33,9 +46,9 @@ func TestCreateExample(t *testing.T) {
 		return example, nil
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	got, err := ecuc.CreateExample(&model.Example{
+	got, err := ecuc.CreateExample(context.Background(), &model.Example{
 		Name:      "test",
 		Useful:    true,
 		CreatedAt: fixedTime,
@@ -61,9 +74,9 @@ func TestCreateExampleWhenEDSCreateReturnsErrorThenFailure(t *testing.T) {
 		return nil, expected
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.CreateExample(&model.Example{})
+	example, got := ecuc.CreateExample(context.Background(), &model.Example{Name: "test"})
 
 	if example != nil {
 		t.Errorf("CreateExample() failed, expected %v, got %v", nil, example)
@@ -82,9 +95,9 @@ func TestCreateExampleWhenNameAlreadyExistsThenFailure(t *testing.T) {
 		return nil, expected
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.CreateExample(&model.Example{})
+	example, got := ecuc.CreateExample(context.Background(), &model.Example{Name: "test"})
 
 	if example != nil {
 		t.Errorf("CreateExample() failed, expected %v, got %v", nil, example)
@@ -95,6 +108,59 @@ func TestCreateExampleWhenNameAlreadyExistsThenFailure(t *testing.T) {
 	}
 }
 
+func TestCreateExampleWhenNameAlreadyExistsThenConflictError(t *testing.T) {
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return &model.Example{ID: 2}, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	_, got := ecuc.CreateExample(context.Background(), &model.Example{ID: 1, Name: "test"})
+
+	var conflictErr *usecase.ConflictError
+	if !errors.As(got, &conflictErr) {
+		t.Errorf("CreateExample() failed, expected %T, got %v", conflictErr, got)
+	}
+}
+
+func TestCreateExampleWhenNameTakenConcurrentlyThenConflictError(t *testing.T) {
+	expected := "Example already exists"
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, nil
+	}
+	edsCreateMock = func(example *model.Example) (*model.Example, error) {
+		return nil, &dataservice.Error{Cause: &dbdriver.ConflictError{Cause: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'test' for key 'example_name_UNIQUE'"}}}
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	_, got := ecuc.CreateExample(context.Background(), &model.Example{Name: "test"})
+
+	var conflictErr *usecase.ConflictError
+	if !errors.As(got, &conflictErr) || got.Error() != expected {
+		t.Errorf("CreateExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestCreateExampleWhenDatabaseUnavailableThenUnavailableError(t *testing.T) {
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, &dataservice.Error{Cause: &dbdriver.UnavailableError{Cause: errors.New("error")}}
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	_, got := ecuc.CreateExample(context.Background(), &model.Example{Name: "test"})
+
+	var unavailableErr *usecase.UnavailableError
+	if !errors.As(got, &unavailableErr) {
+		t.Errorf("CreateExample() failed, expected %T, got %v", unavailableErr, got)
+	}
+}
+
 func TestUpdateExample(t *testing.T) {
 	currentFixedTime := time.Now()
 	createdAtTime := currentFixedTime.Add(-1 * time.Minute)
@@ -123,9 +189,9 @@ func TestUpdateExample(t *testing.T) {
 		return &expected, nil
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	got, err := ecuc.UpdateExample(&model.Example{
+	got, err := ecuc.UpdateExample(context.Background(), &model.Example{
 		Name:      "updated",
 		Useful:    true,
 		CreatedAt: currentFixedTime,
@@ -140,6 +206,46 @@ func TestUpdateExample(t *testing.T) {
 	}
 }
 
+func TestUpdateExampleThenTimestampsFromCurrentAndTimeStamp(t *testing.T) {
+	createdAt := currentTime.Add(-time.Hour)
+	expected := model.Example{ID: 1, Name: "updated", CreatedAt: createdAt, Version: 2, UpdatedAt: currentTime}
+
+	var got model.Example
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: ID, Name: "existing", CreatedAt: createdAt, Version: 2}, nil
+	}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, nil
+	}
+	edsUpdateMock = func(example *model.Example) (updatedExample *model.Example, err error) {
+		got = *example
+		return example, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	ecuc.UpdateExample(context.Background(), &model.Example{ID: 1, Name: "updated", CreatedAt: time.Now()})
+
+	if expected != got {
+		t.Errorf("UpdateExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestUpdateExampleWhenVersionIsNotCurrentThenPreconditionFailedError(t *testing.T) {
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: ID, Version: 2}, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	_, got := ecuc.UpdateExample(context.Background(), &model.Example{ID: 1, Version: 1})
+
+	var preconditionFailedErr *usecase.PreconditionFailedError
+	if !errors.As(got, &preconditionFailedErr) {
+		t.Errorf("UpdateExample() failed, expected %T, got %v", preconditionFailedErr, got)
+	}
+}
+
 func TestUpdateExampleWhenEDSUpdateReturnsErrorThenFailure(t *testing.T) {
 	expected := &usecase.Error{Cause: errors.New("error")}
 
@@ -154,9 +260,9 @@ func TestUpdateExampleWhenEDSUpdateReturnsErrorThenFailure(t *testing.T) {
 		return nil, expected
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.UpdateExample(&model.Example{})
+	example, got := ecuc.UpdateExample(context.Background(), &model.Example{Name: "test"})
 
 	if example != nil {
 		t.Errorf("UpdateExample() failed, expected %v, got %v", nil, example)
@@ -175,9 +281,9 @@ func TestUpdateExampleWhenIDNotExistsThenFailure(t *testing.T) {
 		return nil, nil
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.UpdateExample(&model.Example{ID: 1})
+	example, got := ecuc.UpdateExample(context.Background(), &model.Example{ID: 1, Name: "test"})
 
 	if example != nil {
 		t.Errorf("UpdateExample() failed, expected %v, got %v", nil, example)
@@ -199,9 +305,9 @@ func TestUpdateExampleWhenNameAlreadyExistsThenFailure(t *testing.T) {
 		return &model.Example{ID: 2}, nil
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.UpdateExample(&model.Example{ID: 1})
+	example, got := ecuc.UpdateExample(context.Background(), &model.Example{ID: 1, Name: "test"})
 
 	if example != nil {
 		t.Errorf("UpdateExample() failed, expected %v, got %v", nil, example)
@@ -212,7 +318,7 @@ func TestUpdateExampleWhenNameAlreadyExistsThenFailure(t *testing.T) {
 	}
 }
 
-func TestUpdateExamplePropertiesWhenPropertyIsUsefulThenSuccess(t *testing.T) {
+func TestPatchExampleWhenPropertyIsUsefulThenSuccess(t *testing.T) {
 	currentFixedTime := time.Now()
 	createdAtTime := currentFixedTime.Add(-1 * time.Minute)
 
@@ -233,27 +339,108 @@ func TestUpdateExamplePropertiesWhenPropertyIsUsefulThenSuccess(t *testing.T) {
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
 		return &existing, nil
 	}
-	edsUpdatePropertiesMock = func(ID int64, properties map[string]interface{}) error {
+	edsUpdatePropertiesMock = func(ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error {
 		existing.Useful = properties["Useful"].(bool)
 		return nil
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	got, err := ecuc.UpdateExampleProperties(1, map[string]interface{}{
-		"Useful": true,
-	})
+	examplePatch, _ := patch.ParseMergePatch([]byte(`{"Useful":true}`))
+	got, err := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
 
 	if err != nil {
-		t.Errorf("UpdateExampleProperties() failed, error %v", err)
+		t.Errorf("PatchExample() failed, error %v", err)
 	}
 
 	if expected != *got {
-		t.Errorf("UpdateExampleProperties() failed, expected %v, got %v", expected, got)
+		t.Errorf("PatchExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestPatchExampleWhenJSONPatchThenProperties(t *testing.T) {
+	expected := map[string]interface{}{"Name": "renamed", "Useful": false}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, Name: "test", Useful: true}, nil
+	}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, nil
+	}
+	var got map[string]interface{}
+	edsUpdatePropertiesMock = func(ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error {
+		got = properties
+		return nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	examplePatch, _ := patch.ParseJSONPatch([]byte(`[
+		{"op":"test","path":"/Name","value":"test"},
+		{"op":"replace","path":"/Name","value":"renamed"},
+		{"op":"remove","path":"/Useful"}
+	]`))
+	_, err := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
+
+	if err != nil {
+		t.Errorf("PatchExample() failed, error %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("PatchExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestPatchExampleWhenTestFailsThenConflictError(t *testing.T) {
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, Name: "test"}, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	examplePatch, _ := patch.ParseJSONPatch([]byte(`[{"op":"test","path":"/Name","value":"other"}]`))
+	example, got := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
+
+	if example != nil {
+		t.Errorf("PatchExample() failed, expected %v, got %v", nil, example)
+	}
+
+	var conflictErr *usecase.ConflictError
+	if !errors.As(got, &conflictErr) {
+		t.Errorf("PatchExample() failed, expected %v, got %v", "a conflict error", got)
+	}
+}
+
+func TestPatchExampleWhenPropertyIsInvalidThenValidationError(t *testing.T) {
+	tests := map[string][]usecase.FieldError{
+		`{"Wrong":1}`:       {{Field: "Wrong", Message: "does not exist or cannot be updated"}},
+		`{"ID":2}`:          {{Field: "ID", Message: "does not exist or cannot be updated"}},
+		`{"Name":null}`:     {{Field: "Name", Message: "must not be null"}},
+		`{"Name":1}`:        {{Field: "Name", Message: "must be of type string"}},
+		`{"Useful":"true"}`: {{Field: "Useful", Message: "must be of type bool"}},
+	}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, Name: "test"}, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	for document, expected := range tests {
+		examplePatch, _ := patch.ParseMergePatch([]byte(document))
+		_, got := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
+
+		var validationErr *usecase.ValidationError
+		if !errors.As(got, &validationErr) || !reflect.DeepEqual(expected, validationErr.Fields) {
+			t.Errorf("PatchExample(%s) failed, expected %v, got %v", document, expected, got)
+		}
 	}
 }
 
-func TestUpdateExamplePropertiesWhenPropertyNotExistsThenFailure(t *testing.T) {
+func TestPatchExampleWhenPropertyNotExistsThenFailure(t *testing.T) {
 	expected := &usecase.Error{Cause: errors.New("Property Wrong does not exist or cannot be updated")}
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
@@ -261,22 +448,21 @@ func TestUpdateExamplePropertiesWhenPropertyNotExistsThenFailure(t *testing.T) {
 		return &model.Example{}, nil
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.UpdateExampleProperties(1, map[string]interface{}{
-		"Wrong": 1,
-	})
+	examplePatch, _ := patch.ParseMergePatch([]byte(`{"Wrong":1}`))
+	example, got := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
 
 	if example != nil {
-		t.Errorf("UpdateExample() failed, expected %v, got %v", nil, example)
+		t.Errorf("PatchExample() failed, expected %v, got %v", nil, example)
 	}
 
 	if got == nil || expected.Error() != got.Error() {
-		t.Errorf("UpdateExample() failed, expected %v, got %v", expected, got)
+		t.Errorf("PatchExample() failed, expected %v, got %v", expected, got)
 	}
 }
 
-func TestUpdateExamplePropertiesWhenPropertyIsNameAndNameAlreadyExistsThenFailure(t *testing.T) {
+func TestPatchExampleWhenPropertyIsNameAndNameAlreadyExistsThenFailure(t *testing.T) {
 	expected := &usecase.Error{Cause: errors.New("Example already exists")}
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
@@ -287,62 +473,241 @@ func TestUpdateExamplePropertiesWhenPropertyIsNameAndNameAlreadyExistsThenFailur
 		return &model.Example{ID: 2}, nil
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.UpdateExampleProperties(1, map[string]interface{}{
-		"Name": "shouldNotUpdated",
-	})
+	examplePatch, _ := patch.ParseMergePatch([]byte(`{"Name":"shouldNotUpdated"}`))
+	example, got := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
 
 	if example != nil {
-		t.Errorf("UpdateExample() failed, expected %v, got %v", nil, example)
+		t.Errorf("PatchExample() failed, expected %v, got %v", nil, example)
 	}
 
 	if got == nil || expected.Error() != got.Error() {
-		t.Errorf("UpdateExample() failed, expected %v, got %v", expected, got)
+		t.Errorf("PatchExample() failed, expected %v, got %v", expected, got)
 	}
 }
 
-func TestUpdateExamplePropertiesWhenEDSUpdatePropertiesReturnsErrorThenFailure(t *testing.T) {
+func TestPatchExampleWhenEDSUpdatePropertiesReturnsErrorThenFailure(t *testing.T) {
 	expected := &usecase.Error{Cause: errors.New("error")}
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
-		return &model.Example{}, nil
+		return &model.Example{Name: "test"}, nil
 	}
 	edsFindByNameMock = func(name string) (*model.Example, error) {
 		return nil, nil
 	}
-	edsUpdatePropertiesMock = func(ID int64, properties map[string]interface{}) error {
+	edsUpdatePropertiesMock = func(ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error {
 		return expected
 	}
 
-	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds}
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	example, got := ecuc.UpdateExampleProperties(1, map[string]interface{}{})
+	examplePatch, _ := patch.ParseMergePatch([]byte(`{"Useful":true}`))
+	example, got := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
 
 	if example != nil {
-		t.Errorf("UpdateExampleProperties() failed, expected %v, got %v", nil, example)
+		t.Errorf("PatchExample() failed, expected %v, got %v", nil, example)
 	}
 
 	if got == nil || expected.Error() != got.Error() {
-		t.Errorf("UpdateExampleProperties() failed, expected %v, got %v", expected, got)
+		t.Errorf("PatchExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestSaveExamplesWhenBestEffortThenAppliesValidItems(t *testing.T) {
+	expectedIDs := []int64{10, 2, 0, 3}
+	expectedVersions := []int64{1, 0, 0, 0}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	committed := false
+	edsBatchMock = func(fn func() error) error {
+		err := fn()
+		committed = err == nil
+		return err
+	}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, nil
+	}
+	edsCreateMock = func(example *model.Example) (*model.Example, error) {
+		example.ID = 10
+		example.Version = 1
+		return example, nil
+	}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		if ID == 3 {
+			return &model.Example{ID: 3, Version: 4}, nil
+		}
+		return nil, nil
+	}
+	var deleted []int64
+	edsDeleteMock = func(ID int64, version int64) error {
+		deleted = append(deleted, ID, version)
+		return nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{
+		EDS:   eds,
+		TS:    &timeStampMock{},
+		ERMUC: &removal.ExampleRemovalUseCaseImpl{EDS: eds},
+	}
+
+	got, err := ecuc.SaveExamples(context.Background(), []model.BatchItem{
+		{Operation: model.BatchCreate, Example: model.Example{Name: "a"}},
+		{Operation: model.BatchUpdate, Example: model.Example{ID: 2, Name: "b"}},
+		{Operation: model.BatchCreate, Example: model.Example{Name: "a"}},
+		{Operation: model.BatchDelete, Example: model.Example{ID: 3}},
+	}, model.BatchBestEffort)
+
+	if err != nil {
+		t.Errorf("SaveExamples() failed, error %v", err)
+	}
+	for i, result := range got {
+		if expectedIDs[i] != result.ID || expectedVersions[i] != result.Version {
+			t.Errorf("SaveExamples() failed, expected %v, got %v", expectedIDs[i], result)
+		}
+	}
+	var notExistsErr *usecase.NotExistsError
+	var conflictErr *usecase.ConflictError
+	if got[0].Err != nil || !errors.As(got[1].Err, &notExistsErr) || !errors.As(got[2].Err, &conflictErr) || got[3].Err != nil {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", "success, not found, conflict and success", got)
+	}
+	if !reflect.DeepEqual([]int64{3, 4}, deleted) {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", []int64{3, 4}, deleted)
+	}
+	if !committed {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", "a committed batch", committed)
+	}
+}
+
+func TestSaveExamplesWhenAllOrNothingAndItemFailsThenAborted(t *testing.T) {
+	expected := []model.BatchResult{
+		{Operation: model.BatchCreate, Err: &usecase.BatchAbortedError{Index: 1}},
+		{Operation: model.BatchUpdate, ID: 2, Err: &usecase.NotExistsError{ID: 2}},
+		{Operation: model.BatchCreate, Err: &usecase.BatchAbortedError{Index: 1}},
+	}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	committed := false
+	edsBatchMock = func(fn func() error) error {
+		err := fn()
+		committed = err == nil
+		return err
+	}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, nil
+	}
+	edsCreateMock = func(example *model.Example) (*model.Example, error) {
+		example.ID = 10
+		example.Version = 1
+		return example, nil
+	}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return nil, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	got, err := ecuc.SaveExamples(context.Background(), []model.BatchItem{
+		{Operation: model.BatchCreate, Example: model.Example{Name: "a"}},
+		{Operation: model.BatchUpdate, Example: model.Example{ID: 2, Name: "b"}},
+		{Operation: model.BatchCreate, Example: model.Example{Name: "c"}},
+	}, model.BatchAllOrNothing)
+
+	if err != nil {
+		t.Errorf("SaveExamples() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", expected, got)
+	}
+	if committed {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", "a rolled back batch", committed)
+	}
+}
+
+func TestSaveExamplesWhenItemIsInvalidThenNothingExecuted(t *testing.T) {
+	expected := []model.BatchResult{
+		{Operation: model.BatchCreate, Err: &usecase.BatchAbortedError{Index: 1}},
+		{Operation: model.BatchDelete, ID: 3, Err: &usecase.ValidationError{
+			Message: `Unsupported batch operation "delete"`,
+			Fields:  []usecase.FieldError{{Field: "operation", Message: "is not a supported operation"}},
+		}},
+		{Operation: model.BatchUpdate, Err: &usecase.ValidationError{
+			Message: "The ID is required to update an Example",
+			Fields:  []usecase.FieldError{{Field: "ID", Message: "must be informed"}},
+		}},
+	}
+
+	edsBatchMock = func(fn func() error) error {
+		t.Errorf("Batch() should not be called")
+		return nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	got, err := ecuc.SaveExamples(context.Background(), []model.BatchItem{
+		{Operation: model.BatchCreate, Example: model.Example{Name: "a"}},
+		{Operation: model.BatchDelete, Example: model.Example{ID: 3}},
+		{Operation: model.BatchUpdate, Example: model.Example{Name: "b"}},
+	}, model.BatchAllOrNothing)
+
+	if err != nil {
+		t.Errorf("SaveExamples() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestSaveExamplesWhenModeIsUnknownThenValidationError(t *testing.T) {
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	_, got := ecuc.SaveExamples(context.Background(), []model.BatchItem{}, "sometimes")
+
+	var validationErr *usecase.ValidationError
+	if !errors.As(got, &validationErr) {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", "a validation error", got)
+	}
+}
+
+func TestSaveExamplesWhenBatchFailsThenFailure(t *testing.T) {
+	expected := &usecase.UnavailableError{Cause: &dbdriver.UnavailableError{Cause: errors.New("error")}}
+
+	edsBatchMock = func(fn func() error) error {
+		return expected.Cause
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	_, got := ecuc.SaveExamples(context.Background(), []model.BatchItem{
+		{Operation: model.BatchCreate, Example: model.Example{Name: "a"}},
+	}, model.BatchBestEffort)
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", expected, got)
 	}
 }
 
 func TestListExamples(t *testing.T) {
-	expected := []model.Example{
-		model.Example{ID: 1},
-		model.Example{ID: 2},
+	expected := &model.ExamplePage{
+		Examples: []model.Example{
+			model.Example{ID: 1},
+			model.Example{ID: 2},
+		},
+		HasNext: true,
 	}
+	expectedPageRequest := model.PageRequest{Limit: 2}
 
+	var gotPageRequest model.PageRequest
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
-	edsFindAllMock = func() ([]model.Example, error) {
+	edsFindPageMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
+		gotPageRequest = pageRequest
 		return expected, nil
 	}
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	got, err := eruc.ListExamples()
+	got, err := eruc.ListExamples(context.Background(), model.PageRequest{Limit: 2})
 
 	if err != nil {
 		t.Errorf("ListExamples() failed, error %v", err)
@@ -351,22 +716,26 @@ func TestListExamples(t *testing.T) {
 	if !reflect.DeepEqual(expected, got) {
 		t.Errorf("ListExamples() failed, expected %v, got %v", expected, got)
 	}
+
+	if !reflect.DeepEqual(expectedPageRequest, gotPageRequest) {
+		t.Errorf("ListExamples() failed, expected %v, got %v", expectedPageRequest, gotPageRequest)
+	}
 }
 
-func TestListExamplesWhenEDSFindAllReturnsErrorThenFailure(t *testing.T) {
+func TestListExamplesWhenEDSFindPageReturnsErrorThenFailure(t *testing.T) {
 	expected := &usecase.Error{Cause: errors.New("error")}
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
-	edsFindAllMock = func() ([]model.Example, error) {
+	edsFindPageMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
 		return nil, expected
 	}
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	examples, got := eruc.ListExamples()
+	page, got := eruc.ListExamples(context.Background(), model.PageRequest{Limit: 2})
 
-	if examples != nil {
-		t.Errorf("ListExamples() failed, expected %v, got %v", nil, examples)
+	if page != nil {
+		t.Errorf("ListExamples() failed, expected %v, got %v", nil, page)
 	}
 
 	if got == nil || expected.Error() != got.Error() {
@@ -374,20 +743,55 @@ func TestListExamplesWhenEDSFindAllReturnsErrorThenFailure(t *testing.T) {
 	}
 }
 
+func TestListExamplesWhenPageRequestIsInvalidThenValidationError(t *testing.T) {
+	expected := []usecase.FieldError{
+		{Field: "limit", Message: "must be greater than zero"},
+		{Field: "offset", Message: "must not be negative"},
+	}
+
+	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: &exampleDataServiceMock{}}
+
+	_, got := eruc.ListExamples(context.Background(), model.PageRequest{Offset: -1})
+
+	var validationErr *usecase.ValidationError
+	if !errors.As(got, &validationErr) || !reflect.DeepEqual(expected, validationErr.Fields) {
+		t.Errorf("ListExamples() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestListExamplesWhenSortedWithIDCursorThenValidationError(t *testing.T) {
+	expected := []usecase.FieldError{{Field: "cursor", Message: "must not point to an ID when sorting"}}
+
+	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: &exampleDataServiceMock{}}
+
+	_, got := eruc.ListExamples(context.Background(), model.PageRequest{
+		Limit:   2,
+		AfterID: 3,
+		Sort:    []filter.Order{{Field: "name"}},
+	})
+
+	var validationErr *usecase.ValidationError
+	if !errors.As(got, &validationErr) || !reflect.DeepEqual(expected, validationErr.Fields) {
+		t.Errorf("ListExamples() failed, expected %v, got %v", expected, got)
+	}
+}
+
 func TestListActiveExamples(t *testing.T) {
-	expected := []model.Example{
-		model.Example{ID: 1},
-		model.Example{ID: 2},
+	expected := &model.ExamplePage{
+		Examples: []model.Example{
+			model.Example{ID: 1},
+			model.Example{ID: 2},
+		},
 	}
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
-	edsFindActivesMock = func() ([]model.Example, error) {
+	edsFindActivesPageMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
 		return expected, nil
 	}
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	got, err := eruc.ListActiveExamples()
+	got, err := eruc.ListActiveExamples(context.Background(), model.PageRequest{Limit: 2})
 
 	if err != nil {
 		t.Errorf("ListActiveExamples() failed, error %v", err)
@@ -398,20 +802,20 @@ func TestListActiveExamples(t *testing.T) {
 	}
 }
 
-func TestListActiveExamplesWhenEDSFindActivesReturnsErrorThenFailure(t *testing.T) {
+func TestListActiveExamplesWhenEDSFindActivesPageReturnsErrorThenFailure(t *testing.T) {
 	expected := &usecase.Error{Cause: errors.New("error")}
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
-	edsFindActivesMock = func() ([]model.Example, error) {
+	edsFindActivesPageMock = func(pageRequest model.PageRequest) (*model.ExamplePage, error) {
 		return nil, expected
 	}
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	examples, got := eruc.ListActiveExamples()
+	page, got := eruc.ListActiveExamples(context.Background(), model.PageRequest{Limit: 2})
 
-	if examples != nil {
-		t.Errorf("ListActiveExamples() failed, expected %v, got %v", nil, examples)
+	if page != nil {
+		t.Errorf("ListActiveExamples() failed, expected %v, got %v", nil, page)
 	}
 
 	if got == nil || expected.Error() != got.Error() {
@@ -429,7 +833,7 @@ func TestGetExample(t *testing.T) {
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	got, err := eruc.GetExample(1)
+	got, err := eruc.GetExample(context.Background(), 1)
 
 	if err != nil {
 		t.Errorf("GetExample() failed, error %v", err)
@@ -450,7 +854,7 @@ func TestGetExampleWhenEDSFindByIDReturnsErrorThenFailure(t *testing.T) {
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	example, got := eruc.GetExample(1)
+	example, got := eruc.GetExample(context.Background(), 1)
 
 	if example != nil {
 		t.Errorf("GetExample() failed, expected %v, got %v", nil, example)
@@ -461,6 +865,27 @@ func TestGetExampleWhenEDSFindByIDReturnsErrorThenFailure(t *testing.T) {
 	}
 }
 
+func TestGetExampleWhenIDNotExistsThenFailure(t *testing.T) {
+	expected := &usecase.NotExistsError{ID: 1}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return nil, nil
+	}
+
+	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
+
+	example, got := eruc.GetExample(context.Background(), 1)
+
+	if example != nil {
+		t.Errorf("GetExample() failed, expected %v, got %v", nil, example)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("GetExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
 func TestGetExampleByName(t *testing.T) {
 	expected := &model.Example{ID: 1, Name: "test"}
 
@@ -471,7 +896,7 @@ func TestGetExampleByName(t *testing.T) {
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	got, err := eruc.GetExampleByName("test")
+	got, err := eruc.GetExampleByName(context.Background(), "test")
 
 	if err != nil {
 		t.Errorf("GetExampleByName() failed, error %v", err)
@@ -482,6 +907,27 @@ func TestGetExampleByName(t *testing.T) {
 	}
 }
 
+func TestGetExampleByNameWhenNameNotExistsThenFailure(t *testing.T) {
+	expected := &usecase.NotExistsError{Name: "test"}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, nil
+	}
+
+	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
+
+	example, got := eruc.GetExampleByName(context.Background(), "test")
+
+	if example != nil {
+		t.Errorf("GetExampleByName() failed, expected %v, got %v", nil, example)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("GetExampleByName() failed, expected %v, got %v", expected, got)
+	}
+}
+
 func TestGetExampleByNameWhenEDSFindByNameReturnsErrorThenFailure(t *testing.T) {
 	expected := &usecase.Error{Cause: errors.New("error")}
 
@@ -492,7 +938,7 @@ func TestGetExampleByNameWhenEDSFindByNameReturnsErrorThenFailure(t *testing.T)
 
 	var eruc usecase.ExampleReadUseCase = &read.ExampleReadUseCaseImpl{EDS: eds}
 
-	example, got := eruc.GetExampleByName("test")
+	example, got := eruc.GetExampleByName(context.Background(), "test")
 
 	if example != nil {
 		t.Errorf("GetExampleByName() failed, expected %v, got %v", nil, example)
@@ -508,68 +954,148 @@ func TestDeleteExample(t *testing.T) {
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
 		return &model.Example{}, nil
 	}
-	edsDeleteMock = func(ID int64) error {
+	edsDeleteMock = func(ID int64, version int64) error {
 		return nil
 	}
 
 	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
 
-	got := eruc.DeleteExample(1)
+	got := eruc.DeleteExample(context.Background(), 1, 0)
 
 	if got != nil {
 		t.Errorf("DeleteExample() failed, expected %v, got %v", nil, got)
 	}
 }
 
-func TestDeleteExampleWhenIDNotExistsThenFailure(t *testing.T) {
-	expected := &usecase.NotExistsError{ID: 1}
+func TestDeleteExampleWhenVersionIsZeroThenCurrentVersion(t *testing.T) {
+	expected := int64(4)
 
-	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	var got int64
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
-		return nil, expected
+		return &model.Example{ID: ID, Version: 4}, nil
+	}
+	edsDeleteMock = func(ID int64, version int64) error {
+		got = version
+		return nil
 	}
 
-	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: &exampleDataServiceMock{}}
 
-	got := eruc.DeleteExample(1)
+	eruc.DeleteExample(context.Background(), 1, 0)
 
-	if got == nil {
-		t.Errorf("DeleteExample() failed, expected %v, got %v", expected, nil)
+	if expected != got {
+		t.Errorf("DeleteExample() failed, expected %v, got %v", expected, got)
 	}
 }
 
-func TestDeleteExampleWhenEDSDeleteReturnsErrorThenFailure(t *testing.T) {
-	expected := &usecase.Error{Cause: errors.New("error")}
-
-	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+func TestDeleteExampleWhenVersionIsNotCurrentThenPreconditionFailedError(t *testing.T) {
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
-		return &model.Example{}, nil
-	}
-	edsDeleteMock = func(ID int64) error {
-		return expected
+		return &model.Example{ID: ID, Version: 4}, nil
 	}
 
-	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: &exampleDataServiceMock{}}
 
-	got := eruc.DeleteExample(1)
+	got := eruc.DeleteExample(context.Background(), 1, 3)
 
-	if got == nil {
-		t.Errorf("DeleteExample() failed, expected %v, got %v", expected, nil)
+	var preconditionFailedErr *usecase.PreconditionFailedError
+	if !errors.As(got, &preconditionFailedErr) {
+		t.Errorf("DeleteExample() failed, expected %T, got %v", preconditionFailedErr, got)
 	}
 }
 
-func TestDeleteExampleLogically(t *testing.T) {
-	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+func TestDeleteExampleWhenEDSDeleteReportsStaleVersionThenPreconditionFailedError(t *testing.T) {
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: ID, Version: 4}, nil
+	}
+	edsDeleteMock = func(ID int64, version int64) error {
+		return &dataservice.StaleVersionError{ID: ID, Version: version}
+	}
+
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: &exampleDataServiceMock{}}
+
+	got := eruc.DeleteExample(context.Background(), 1, 4)
+
+	var preconditionFailedErr *usecase.PreconditionFailedError
+	if !errors.As(got, &preconditionFailedErr) {
+		t.Errorf("DeleteExample() failed, expected %T, got %v", preconditionFailedErr, got)
+	}
+}
+
+func TestDeleteExampleWhenIDNotExistsThenFailure(t *testing.T) {
+	expected := &usecase.NotExistsError{ID: 1}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return nil, expected
+	}
+
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
+
+	got := eruc.DeleteExample(context.Background(), 1, 0)
+
+	if got == nil {
+		t.Errorf("DeleteExample() failed, expected %v, got %v", expected, nil)
+	}
+}
+
+func TestDeleteExampleWhenEDSDeleteReturnsErrorThenFailure(t *testing.T) {
+	expected := &usecase.Error{Cause: errors.New("error")}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
 		return &model.Example{}, nil
 	}
-	edsLogicalDeletionMock = func(ID int64, deactivationDatetime time.Time) error {
-		return nil
+	edsDeleteMock = func(ID int64, version int64) error {
+		return expected
 	}
 
 	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
 
-	got := eruc.DeleteExampleLogically(1, time.Now())
+	got := eruc.DeleteExample(context.Background(), 1, 0)
+
+	if got == nil {
+		t.Errorf("DeleteExample() failed, expected %v, got %v", expected, nil)
+	}
+}
+
+func TestDeleteExampleLogically(t *testing.T) {
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, Version: 3}, nil
+	}
+	var gotVersion int64
+	var gotTime time.Time
+	edsLogicalDeletionMock = func(ID int64, version int64, deactivationDatetime time.Time) error {
+		gotVersion = version
+		gotTime = deactivationDatetime
+		return nil
+	}
+
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	got := eruc.DeleteExampleLogically(context.Background(), 1, 0)
+
+	if got != nil {
+		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", nil, got)
+	}
+	if gotVersion != 3 || !gotTime.Equal(currentTime) {
+		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", []interface{}{3, currentTime}, []interface{}{gotVersion, gotTime})
+	}
+}
+
+func TestDeleteExampleLogicallyWhenAlreadyDeactivatedThenUnchanged(t *testing.T) {
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, DeactivatedAt: currentTime.Add(-time.Hour)}, nil
+	}
+	edsLogicalDeletionMock = func(ID int64, version int64, deactivationDatetime time.Time) error {
+		t.Errorf("LogicalDeletion() should not be called")
+		return nil
+	}
+
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	got := eruc.DeleteExampleLogically(context.Background(), 1, 0)
 
 	if got != nil {
 		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", nil, got)
@@ -581,15 +1107,31 @@ func TestDeleteExampleLogicallyWhenIDNotExistsThenFailure(t *testing.T) {
 
 	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
-		return nil, expected
+		return nil, nil
 	}
 
-	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	got := eruc.DeleteExampleLogically(1, time.Now())
+	got := eruc.DeleteExampleLogically(context.Background(), 1, 0)
 
-	if got == nil {
-		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", expected, nil)
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestDeleteExampleLogicallyWhenVersionIsStaleThenPreconditionFailed(t *testing.T) {
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, Version: 3}, nil
+	}
+
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
+
+	got := eruc.DeleteExampleLogically(context.Background(), 1, 2)
+
+	var preconditionFailedErr *usecase.PreconditionFailedError
+	if !errors.As(got, &preconditionFailedErr) {
+		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", "a precondition failed error", got)
 	}
 }
 
@@ -600,71 +1142,784 @@ func TestDeleteExampleLogicallyWhenEDSDeleteReturnsErrorThenFailure(t *testing.T
 	edsFindByIDMock = func(ID int64) (*model.Example, error) {
 		return &model.Example{}, nil
 	}
-	edsLogicalDeletionMock = func(ID int64, deactivationDatetime time.Time) error {
+	edsLogicalDeletionMock = func(ID int64, version int64, deactivationDatetime time.Time) error {
 		return expected
 	}
 
-	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds, TS: &timeStampMock{}}
 
-	got := eruc.DeleteExampleLogically(1, time.Now())
+	got := eruc.DeleteExampleLogically(context.Background(), 1, 0)
 
 	if got == nil {
 		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", expected, nil)
 	}
 }
 
+func TestDeleteExamples(t *testing.T) {
+	expected := []model.BatchResult{
+		{Operation: model.BatchDelete, ID: 1},
+		{Operation: model.BatchDelete, ID: 2, Err: &usecase.PreconditionFailedError{Cause: &dataservice.StaleVersionError{ID: 2, Version: 1}}},
+	}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsBatchMock = func(fn func() error) error {
+		return fn()
+	}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: ID, Version: 2}, nil
+	}
+	edsDeleteMock = func(ID int64, version int64) error {
+		return nil
+	}
+
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds}
+
+	got, err := eruc.DeleteExamples(context.Background(), []model.BatchItem{
+		{Operation: model.BatchDelete, Example: model.Example{ID: 1}},
+		{Operation: model.BatchDelete, Example: model.Example{ID: 2, Version: 1}},
+	}, model.BatchBestEffort)
+
+	if err != nil {
+		t.Errorf("DeleteExamples() failed, error %v", err)
+	}
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("DeleteExamples() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestIssueAPIKeyThenKeyAuthenticates(t *testing.T) {
+	var persisted model.APIKey
+	akdsCreateMock = func(apiKey *model.APIKey) (*model.APIKey, error) {
+		apiKey.ID = 1
+		persisted = *apiKey
+		return apiKey, nil
+	}
+	akdsFindByPrefixMock = func(prefix string) (*model.APIKey, error) {
+		if prefix != persisted.Prefix {
+			return nil, nil
+		}
+		return &persisted, nil
+	}
+	var akuc usecase.APIKeyUseCase = &apikey.APIKeyUseCaseImpl{AKDS: &apiKeyDataServiceMock{}, TS: &timeStampMock{}}
+
+	issued, key, err := akuc.IssueAPIKey(context.Background(), &model.APIKey{Name: " client ", Scopes: []string{"examples:read"}})
+	if err != nil {
+		t.Fatalf("IssueAPIKey() failed, error %v", err)
+	}
+	got, err := akuc.AuthenticateAPIKey(context.Background(), key)
+
+	if err != nil {
+		t.Errorf("AuthenticateAPIKey() failed, error %v", err)
+	}
+	if got == nil || got.ID != 1 || got.Name != "client" || !got.CreatedAt.Equal(currentTime) {
+		t.Errorf("IssueAPIKey() failed, expected %v, got %v", "the key of client", got)
+	}
+	if !strings.HasPrefix(key, apikey.KeyPrefix+"_"+issued.Prefix+"_") || len(issued.Hash) == 0 {
+		t.Errorf("IssueAPIKey() failed, expected %v, got %v", "a key identified by the prefix", key)
+	}
+}
+
+func TestIssueAPIKeyWhenInvalidThenValidationError(t *testing.T) {
+	var akuc usecase.APIKeyUseCase = &apikey.APIKeyUseCaseImpl{AKDS: &apiKeyDataServiceMock{}, TS: &timeStampMock{}}
+
+	for _, apiKey := range []model.APIKey{
+		{Name: ""},
+		{Name: "client", Scopes: []string{"examples read"}},
+		{Name: "client", Scopes: []string{strings.Repeat("a", 500), strings.Repeat("b", 500)}},
+		{Name: "client", ExpiresAt: currentTime},
+	} {
+		_, _, err := akuc.IssueAPIKey(context.Background(), &apiKey)
+
+		var validationErr *usecase.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("IssueAPIKey(%v) failed, expected %v, got %v", apiKey, "ValidationError", err)
+		}
+	}
+}
+
+func TestAuthenticateAPIKeyWhenInvalidThenInvalidCredentialsError(t *testing.T) {
+	var persisted model.APIKey
+	akdsCreateMock = func(apiKey *model.APIKey) (*model.APIKey, error) {
+		persisted = *apiKey
+		return apiKey, nil
+	}
+	akdsFindByPrefixMock = func(prefix string) (*model.APIKey, error) {
+		current := persisted
+		return &current, nil
+	}
+	var akuc usecase.APIKeyUseCase = &apikey.APIKeyUseCaseImpl{AKDS: &apiKeyDataServiceMock{}, TS: &timeStampMock{}}
+	_, key, _ := akuc.IssueAPIKey(context.Background(), &model.APIKey{Name: "client", ExpiresAt: currentTime.Add(time.Hour)})
+
+	tests := []struct {
+		name   string
+		key    string
+		change func(apiKey *model.APIKey)
+	}{
+		{name: "malformed", key: "token"},
+		{name: "wrong secret", key: key + "x"},
+		{name: "revoked", key: key, change: func(apiKey *model.APIKey) { apiKey.RevokedAt = currentTime }},
+		{name: "expired", key: key, change: func(apiKey *model.APIKey) { apiKey.ExpiresAt = currentTime }},
+	}
+	original := persisted
+	for _, test := range tests {
+		persisted = original
+		if test.change != nil {
+			test.change(&persisted)
+		}
+
+		got, err := akuc.AuthenticateAPIKey(context.Background(), test.key)
+
+		var invalidErr *usecase.InvalidCredentialsError
+		if got != nil || !errors.As(err, &invalidErr) {
+			t.Errorf("AuthenticateAPIKey(%s) failed, expected %v, got %v", test.name, "InvalidCredentialsError", err)
+		}
+	}
+}
+
+func TestRotateAPIKeyThenPreviousKeyInvalid(t *testing.T) {
+	var persisted model.APIKey
+	akdsCreateMock = func(apiKey *model.APIKey) (*model.APIKey, error) {
+		apiKey.ID = 1
+		persisted = *apiKey
+		return apiKey, nil
+	}
+	akdsFindByIDMock = func(ID int64) (*model.APIKey, error) {
+		current := persisted
+		return &current, nil
+	}
+	akdsFindByPrefixMock = func(prefix string) (*model.APIKey, error) {
+		if prefix != persisted.Prefix {
+			return nil, nil
+		}
+		current := persisted
+		return &current, nil
+	}
+	akdsUpdateSecretMock = func(apiKey *model.APIKey) error {
+		persisted = *apiKey
+		return nil
+	}
+	var akuc usecase.APIKeyUseCase = &apikey.APIKeyUseCaseImpl{AKDS: &apiKeyDataServiceMock{}, TS: &timeStampMock{}}
+	_, previous, _ := akuc.IssueAPIKey(context.Background(), &model.APIKey{Name: "client"})
+
+	_, key, err := akuc.RotateAPIKey(context.Background(), 1)
+
+	if err != nil {
+		t.Errorf("RotateAPIKey() failed, error %v", err)
+	}
+	if _, err := akuc.AuthenticateAPIKey(context.Background(), key); err != nil {
+		t.Errorf("RotateAPIKey() failed, expected %v, got %v", "the new key to authenticate", err)
+	}
+	if _, err := akuc.AuthenticateAPIKey(context.Background(), previous); err == nil {
+		t.Errorf("RotateAPIKey() failed, expected %v, got %v", "the previous key to be rejected", err)
+	}
+}
+
+func TestRotateAPIKeyWhenRevokedThenConflictError(t *testing.T) {
+	akdsFindByIDMock = func(ID int64) (*model.APIKey, error) {
+		return &model.APIKey{ID: ID, RevokedAt: currentTime}, nil
+	}
+	var akuc usecase.APIKeyUseCase = &apikey.APIKeyUseCaseImpl{AKDS: &apiKeyDataServiceMock{}, TS: &timeStampMock{}}
+
+	_, _, err := akuc.RotateAPIKey(context.Background(), 1)
+
+	var conflictErr *usecase.ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Errorf("RotateAPIKey() failed, expected %v, got %v", "ConflictError", err)
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	expected := currentTime
+
+	var got time.Time
+	akdsFindByIDMock = func(ID int64) (*model.APIKey, error) {
+		return &model.APIKey{ID: ID}, nil
+	}
+	akdsRevokeMock = func(ID int64, revocationDatetime time.Time) error {
+		got = revocationDatetime
+		return nil
+	}
+	var akuc usecase.APIKeyUseCase = &apikey.APIKeyUseCaseImpl{AKDS: &apiKeyDataServiceMock{}, TS: &timeStampMock{}}
+
+	if err := akuc.RevokeAPIKey(context.Background(), 1); err != nil {
+		t.Errorf("RevokeAPIKey() failed, error %v", err)
+	}
+	if !got.Equal(expected) {
+		t.Errorf("RevokeAPIKey() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestRevokeAPIKeyWhenNotExistsThenNotExistsError(t *testing.T) {
+	expected := "No API keys found for ID 1"
+
+	akdsFindByIDMock = func(ID int64) (*model.APIKey, error) {
+		return nil, nil
+	}
+	var akuc usecase.APIKeyUseCase = &apikey.APIKeyUseCaseImpl{AKDS: &apiKeyDataServiceMock{}, TS: &timeStampMock{}}
+
+	err := akuc.RevokeAPIKey(context.Background(), 1)
+
+	var notExistsErr *usecase.NotExistsError
+	if !errors.As(err, &notExistsErr) || err.Error() != expected {
+		t.Errorf("RevokeAPIKey() failed, expected %v, got %v", expected, err)
+	}
+}
+
+func TestCreateExampleThenCreatedEventPublished(t *testing.T) {
+	expected := []model.ExampleEvent{{
+		Type:    model.ExampleCreated,
+		Example: model.Example{ID: 1, Name: "test", CreatedAt: currentTime, UpdatedAt: currentTime},
+		Time:    currentTime,
+	}}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		return nil, nil
+	}
+	edsCreateMock = func(example *model.Example) (*model.Example, error) {
+		example.ID = 1
+		return example, nil
+	}
+	var got []model.ExampleEvent
+	eepPublishMock = func(event model.ExampleEvent) {
+		got = append(got, event)
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}, EEP: &exampleEventPublisherMock{}}
+
+	if _, err := ecuc.CreateExample(context.Background(), &model.Example{Name: "test"}); err != nil {
+		t.Errorf("CreateExample() failed, error %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("CreateExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestSaveExamplesThenEventsPublishedOnlyWhenCommitted(t *testing.T) {
+	tests := []struct {
+		mode     model.BatchMode
+		expected int
+	}{
+		{mode: model.BatchBestEffort, expected: 2},
+		{mode: model.BatchAllOrNothing, expected: 0},
+	}
+	for _, test := range tests {
+		var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+		published := -1
+		var got []model.ExampleEvent
+		edsBatchMock = func(fn func() error) error {
+			err := fn()
+			published = len(got)
+			return err
+		}
+		edsFindByNameMock = func(name string) (*model.Example, error) {
+			return nil, nil
+		}
+		edsCreateMock = func(example *model.Example) (*model.Example, error) {
+			example.ID = 10
+			return example, nil
+		}
+		edsFindByIDMock = func(ID int64) (*model.Example, error) {
+			return nil, nil
+		}
+		eepPublishMock = func(event model.ExampleEvent) {
+			got = append(got, event)
+		}
+
+		var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: &timeStampMock{}, EEP: &exampleEventPublisherMock{}}
+
+		_, err := ecuc.SaveExamples(context.Background(), []model.BatchItem{
+			{Operation: model.BatchCreate, Example: model.Example{Name: "a"}},
+			{Operation: model.BatchUpdate, Example: model.Example{ID: 2, Name: "b"}},
+			{Operation: model.BatchCreate, Example: model.Example{Name: "c"}},
+		}, test.mode)
+
+		if err != nil {
+			t.Errorf("SaveExamples(%s) failed, error %v", test.mode, err)
+		}
+		if len(got) != test.expected || published != 0 {
+			t.Errorf("SaveExamples(%s) failed, expected %v, got %v published %v within the batch", test.mode, test.expected, len(got), published)
+		}
+	}
+}
+
+func TestDeleteExampleLogicallyThenDeactivatedEventPublished(t *testing.T) {
+	expected := []model.ExampleEvent{{
+		Type:    model.ExampleDeactivated,
+		Example: model.Example{ID: 1, DeactivatedAt: currentTime, UpdatedAt: currentTime, Version: 4},
+		Time:    currentTime,
+	}}
+
+	var eds dataservice.ExampleDataService = &exampleDataServiceMock{}
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, Version: 3}, nil
+	}
+	edsLogicalDeletionMock = func(ID int64, version int64, deactivationDatetime time.Time) error {
+		return nil
+	}
+	var got []model.ExampleEvent
+	eepPublishMock = func(event model.ExampleEvent) {
+		got = append(got, event)
+	}
+
+	var eruc usecase.ExampleRemovalUseCase = &removal.ExampleRemovalUseCaseImpl{EDS: eds, TS: &timeStampMock{}, EEP: &exampleEventPublisherMock{}}
+
+	if err := eruc.DeleteExampleLogically(context.Background(), 1, 0); err != nil {
+		t.Errorf("DeleteExampleLogically() failed, error %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Errorf("DeleteExampleLogically() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestSubscribeThenPublishedEventsMatchingFilter(t *testing.T) {
+	expected := []uint64{2, 4}
+
+	esuc := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}}
+	esuc.Publish(model.ExampleEvent{Type: model.ExampleCreated, Example: model.Example{ID: 1}})
+	subscription, _ := esuc.Subscribe(1, model.ExampleEventFilter{IDs: []int64{2}})
+	defer subscription.Close()
+	esuc.Publish(model.ExampleEvent{Type: model.ExampleCreated, Example: model.Example{ID: 2}})
+	esuc.Publish(model.ExampleEvent{Type: model.ExampleCreated, Example: model.Example{ID: 3}})
+	esuc.Publish(model.ExampleEvent{Type: model.ExampleDeleted, Example: model.Example{ID: 2}})
+
+	var got []uint64
+	var event model.ExampleEvent
+	for len(got) < len(expected) {
+		event = <-subscription.Events()
+		got = append(got, event.ID)
+	}
+
+	if !reflect.DeepEqual(expected, got) || !subscription.Resumed() || !event.Time.Equal(currentTime) {
+		t.Errorf("Subscribe() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestSubscribeWhenLastEventIDNotKeptThenNotResumed(t *testing.T) {
+	tests := []struct {
+		lastEventID uint64
+		expected    bool
+	}{
+		{lastEventID: 0, expected: true},
+		{lastEventID: 3, expected: true},
+		{lastEventID: 5, expected: true},
+		{lastEventID: 1, expected: false},
+		{lastEventID: 6, expected: false},
+	}
+	esuc := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}, ReplaySize: 2}
+	for ID := int64(1); ID <= 5; ID++ {
+		esuc.Publish(model.ExampleEvent{Type: model.ExampleUpdated, Example: model.Example{ID: ID}})
+	}
+	for _, test := range tests {
+		subscription, _ := esuc.Subscribe(test.lastEventID, model.ExampleEventFilter{})
+		got := subscription.Resumed()
+		subscription.Close()
+
+		if test.expected != got {
+			t.Errorf("Subscribe(%d) failed, expected %v, got %v", test.lastEventID, test.expected, got)
+		}
+	}
+}
+
+func TestPublishWhenSubscriberFallsBehindThenDropped(t *testing.T) {
+	esuc := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}, SubscriberBuffer: 1}
+	subscription, _ := esuc.Subscribe(0, model.ExampleEventFilter{})
+	for ID := int64(1); ID <= 3; ID++ {
+		esuc.Publish(model.ExampleEvent{Type: model.ExampleUpdated, Example: model.Example{ID: ID}})
+	}
+
+	count := 0
+	for range subscription.Events() {
+		count++
+	}
+
+	if count != 1 || !subscription.Dropped() {
+		t.Errorf("Publish() failed, expected %v, got %v %v", "a dropped subscription", count, subscription.Dropped())
+	}
+}
+
+func TestStopThenSubscriptionsClosed(t *testing.T) {
+	esuc := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}}
+	esuc.Start()
+	before, _ := esuc.Subscribe(0, model.ExampleEventFilter{})
+	esuc.Stop(context.Background())
+	after, _ := esuc.Subscribe(0, model.ExampleEventFilter{})
+
+	_, beforeOpen := <-before.Events()
+	_, afterOpen := <-after.Events()
+
+	if beforeOpen || afterOpen || before.Dropped() {
+		t.Errorf("Stop() failed, expected %v, got %v %v", "closed subscriptions", beforeOpen, afterOpen)
+	}
+	if err := esuc.Check(context.Background()); err == nil {
+		t.Errorf("Check() failed, expected %v, got %v", "an error once stopped", err)
+	}
+}
+
+func TestSubscribeWhenMaxSubscribersReachedThenUnavailable(t *testing.T) {
+	esuc := &stream.ExampleStreamUseCaseImpl{TS: &timeStampMock{}, MaxSubscribers: 1}
+	first, _ := esuc.Subscribe(0, model.ExampleEventFilter{})
+
+	refused, err := esuc.Subscribe(0, model.ExampleEventFilter{})
+	first.Close()
+	replacement, replacementErr := esuc.Subscribe(0, model.ExampleEventFilter{})
+
+	var unavailableErr *usecase.UnavailableError
+	if refused != nil || !errors.As(err, &unavailableErr) || replacement == nil || replacementErr != nil {
+		t.Errorf("Subscribe() failed, expected %v, got %v %v", "the subscriber beyond the maximum refused", err, replacementErr)
+	}
+}
+
+var edsBatchMock func(fn func() error) error
+
 var edsCreateMock func(example *model.Example) (persistedExample *model.Example, err error)
 
-var edsDeleteMock func(ID int64) error
+var edsDeleteMock func(ID int64, version int64) error
 
 var edsFindActivesMock func() ([]model.Example, error)
 
+var edsFindActivesPageMock func(pageRequest model.PageRequest) (*model.ExamplePage, error)
+
 var edsFindAllMock func() ([]model.Example, error)
 
 var edsFindByIDMock func(ID int64) (*model.Example, error)
 
 var edsFindByNameMock func(name string) (*model.Example, error)
 
-var edsLogicalDeletionMock func(ID int64, deactivationDatetime time.Time) error
+var edsFindPageMock func(pageRequest model.PageRequest) (*model.ExamplePage, error)
+
+var edsLogicalDeletionMock func(ID int64, version int64, deactivationDatetime time.Time) error
 
 var edsUpdateMock func(example *model.Example) (updatedExample *model.Example, err error)
 
-var edsUpdatePropertiesMock func(ID int64, properties map[string]interface{}) error
+var edsUpdatePropertiesMock func(ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error
 
 type exampleDataServiceMock struct{}
 
-func (eds *exampleDataServiceMock) Create(example *model.Example) (persistedExample *model.Example, err error) {
+func (eds *exampleDataServiceMock) Batch(ctx context.Context, fn func(ctx context.Context) error) error {
+	return edsBatchMock(func() error {
+		return fn(ctx)
+	})
+}
+
+func (eds *exampleDataServiceMock) Create(ctx context.Context, example *model.Example) (persistedExample *model.Example, err error) {
 	return edsCreateMock(example)
 }
 
-func (eds *exampleDataServiceMock) Delete(ID int64) error {
-	return edsDeleteMock(ID)
+func (eds *exampleDataServiceMock) Delete(ctx context.Context, ID int64, version int64) error {
+	return edsDeleteMock(ID, version)
 }
 
-func (eds *exampleDataServiceMock) FindActives() ([]model.Example, error) {
+func (eds *exampleDataServiceMock) FindActives(ctx context.Context) ([]model.Example, error) {
 	return edsFindActivesMock()
 }
 
-func (eds *exampleDataServiceMock) FindAll() ([]model.Example, error) {
+func (eds *exampleDataServiceMock) FindActivesPage(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	return edsFindActivesPageMock(pageRequest)
+}
+
+func (eds *exampleDataServiceMock) FindAll(ctx context.Context) ([]model.Example, error) {
 	return edsFindAllMock()
 }
 
-func (eds *exampleDataServiceMock) FindByID(ID int64) (*model.Example, error) {
+func (eds *exampleDataServiceMock) FindByID(ctx context.Context, ID int64) (*model.Example, error) {
 	return edsFindByIDMock(ID)
 }
 
-func (eds *exampleDataServiceMock) FindByName(name string) (*model.Example, error) {
+func (eds *exampleDataServiceMock) FindByName(ctx context.Context, name string) (*model.Example, error) {
 	return edsFindByNameMock(name)
 }
 
-func (eds *exampleDataServiceMock) LogicalDeletion(ID int64, deactivationDatetime time.Time) error {
-	return edsLogicalDeletionMock(ID, deactivationDatetime)
+func (eds *exampleDataServiceMock) FindPage(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	return edsFindPageMock(pageRequest)
 }
 
-func (eds *exampleDataServiceMock) Update(example *model.Example) (updatedExample *model.Example, err error) {
+func (eds *exampleDataServiceMock) LogicalDeletion(ctx context.Context, ID int64, version int64, deactivationDatetime time.Time) error {
+	return edsLogicalDeletionMock(ID, version, deactivationDatetime)
+}
+
+func (eds *exampleDataServiceMock) Update(ctx context.Context, example *model.Example) (updatedExample *model.Example, err error) {
 	return edsUpdateMock(example)
 }
 
-func (eds *exampleDataServiceMock) UpdateProperties(ID int64, properties map[string]interface{}) error {
-	return edsUpdatePropertiesMock(ID, properties)
+func (eds *exampleDataServiceMock) UpdateProperties(ctx context.Context, ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error {
+	return edsUpdatePropertiesMock(ID, version, updateDatetime, properties)
+}
+
+var eepPublishMock func(event model.ExampleEvent)
+
+type exampleEventPublisherMock struct{}
+
+func (eep *exampleEventPublisherMock) Publish(event model.ExampleEvent) {
+	eepPublishMock(event)
+}
+
+var akdsCreateMock func(apiKey *model.APIKey) (*model.APIKey, error)
+
+var akdsFindAllMock func() ([]model.APIKey, error)
+
+var akdsFindByIDMock func(ID int64) (*model.APIKey, error)
+
+var akdsFindByPrefixMock func(prefix string) (*model.APIKey, error)
+
+var akdsRevokeMock func(ID int64, revocationDatetime time.Time) error
+
+var akdsUpdateSecretMock func(apiKey *model.APIKey) error
+
+type apiKeyDataServiceMock struct{}
+
+func (akds *apiKeyDataServiceMock) Create(ctx context.Context, apiKey *model.APIKey) (*model.APIKey, error) {
+	return akdsCreateMock(apiKey)
+}
+
+func (akds *apiKeyDataServiceMock) FindAll(ctx context.Context) ([]model.APIKey, error) {
+	return akdsFindAllMock()
+}
+
+func (akds *apiKeyDataServiceMock) FindByID(ctx context.Context, ID int64) (*model.APIKey, error) {
+	return akdsFindByIDMock(ID)
+}
+
+func (akds *apiKeyDataServiceMock) FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	return akdsFindByPrefixMock(prefix)
+}
+
+func (akds *apiKeyDataServiceMock) Revoke(ctx context.Context, ID int64, revocationDatetime time.Time) error {
+	return akdsRevokeMock(ID, revocationDatetime)
+}
+
+func (akds *apiKeyDataServiceMock) UpdateSecret(ctx context.Context, apiKey *model.APIKey) error {
+	return akdsUpdateSecretMock(apiKey)
+}
+
+var currentTime time.Time = time.Now()
+
+type timeStampMock struct {
+	now time.Time
+}
+
+func (tp *timeStampMock) GetCurrentTime() time.Time {
+	if tp.now.IsZero() {
+		return currentTime
+	}
+	return tp.now
+}
+
+func TestCreateExampleWhenNameIsEmptyThenValidationError(t *testing.T) {
+	expected := []usecase.FieldError{{Field: "Name", Message: "is required"}}
+
+	edsCreateMock = func(example *model.Example) (persistedExample *model.Example, err error) {
+		t.Errorf("Create() should not be called")
+		return example, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	_, got := ecuc.CreateExample(context.Background(), &model.Example{Name: "   "})
+
+	var validationErr *usecase.ValidationError
+	if !errors.As(got, &validationErr) || !reflect.DeepEqual(expected, validationErr.Fields) {
+		t.Errorf("CreateExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestCreateExampleWhenNameIsTooLongThenValidationError(t *testing.T) {
+	expected := []usecase.FieldError{{Field: "Name", Message: "must have at most 100 characters"}}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	_, got := ecuc.CreateExample(context.Background(), &model.Example{Name: strings.Repeat("é", 101)})
+
+	var validationErr *usecase.ValidationError
+	if !errors.As(got, &validationErr) || !reflect.DeepEqual(expected, validationErr.Fields) {
+		t.Errorf("CreateExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestCreateExampleWhenNameIsNotNormalisedThenNormalisedNamePersisted(t *testing.T) {
+	expected := "Café"
+
+	var searched, got string
+	edsFindByNameMock = func(name string) (*model.Example, error) {
+		searched = name
+		return nil, nil
+	}
+	edsCreateMock = func(example *model.Example) (persistedExample *model.Example, err error) {
+		got = example.Name
+		return example, nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	if _, err := ecuc.CreateExample(context.Background(), &model.Example{Name: " Cafe\u0301\t"}); err != nil {
+		t.Errorf("CreateExample() failed, error %v", err)
+	}
+
+	if expected != got || expected != searched {
+		t.Errorf("CreateExample() failed, expected %q, got %q searched by %q", expected, got, searched)
+	}
+}
+
+func TestPatchExampleWhenNameBecomesInvalidThenValidationError(t *testing.T) {
+	expected := []usecase.FieldError{{Field: "Name", Message: `must match ^[^\p{Cc}]*$`}}
+
+	edsFindByIDMock = func(ID int64) (*model.Example, error) {
+		return &model.Example{ID: 1, Name: "test"}, nil
+	}
+	edsUpdatePropertiesMock = func(ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error {
+		t.Errorf("UpdateProperties() should not be called")
+		return nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	examplePatch, _ := patch.ParseMergePatch([]byte(`{"Name":"line\nbreak"}`))
+	_, got := ecuc.PatchExample(context.Background(), 1, 0, examplePatch)
+
+	var validationErr *usecase.ValidationError
+	if !errors.As(got, &validationErr) || !reflect.DeepEqual(expected, validationErr.Fields) {
+		t.Errorf("PatchExample() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestSaveExamplesWhenNamesDifferOnlyInCaseThenConflictError(t *testing.T) {
+	edsBatchMock = func(fn func() error) error {
+		t.Errorf("Batch() should not be called")
+		return nil
+	}
+
+	var ecuc usecase.ExampleCreationUseCase = &creation.ExampleCreationUseCaseImpl{EDS: &exampleDataServiceMock{}, TS: &timeStampMock{}}
+
+	got, err := ecuc.SaveExamples(context.Background(), []model.BatchItem{
+		{Operation: model.BatchCreate, Example: model.Example{Name: "Straße"}},
+		{Operation: model.BatchCreate, Example: model.Example{Name: " STRASSE "}},
+	}, model.BatchAllOrNothing)
+
+	if err != nil {
+		t.Errorf("SaveExamples() failed, error %v", err)
+	}
+	var conflictErr *usecase.ConflictError
+	if len(got) != 2 || !errors.As(got[1].Err, &conflictErr) {
+		t.Errorf("SaveExamples() failed, expected %v, got %v", "a conflict on item 1", got)
+	}
+}
+
+func TestBeginRequestWhenKeyIsNewThenReserved(t *testing.T) {
+	iuc := &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: &timeStampMock{}}
+
+	got, reservation, err := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	if got != nil || reservation == "" || err != nil {
+		t.Errorf("BeginRequest() failed, expected %v, got %v %v %v", "a reservation", got, reservation, err)
+	}
+}
+
+func TestBeginRequestWhenInProgressThenConflictError(t *testing.T) {
+	iuc := &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: &timeStampMock{}}
+	iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	_, _, err := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	var conflictErr *usecase.ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Errorf("BeginRequest() failed, expected %v, got %v", "ConflictError", err)
+	}
+}
+
+func TestBeginRequestWhenCompletedThenResponse(t *testing.T) {
+	expected := model.IdempotentRequest{Fingerprint: "fingerprint", Completed: true, Code: 201, Path: 3, ETag: `"1"`}
+
+	iuc := &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: &timeStampMock{}}
+	iuc.BeginRequest(context.Background(), "key", "fingerprint")
+	iuc.CompleteRequest(context.Background(), &model.IdempotentRequest{Key: "key", Fingerprint: "fingerprint", Code: 201, Path: 3, ETag: `"1"`})
+
+	got, _, err := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	if err != nil || got == nil {
+		t.Fatalf("BeginRequest() failed, expected %v, got %v %v", expected, got, err)
+	}
+	if got.Key == "key" || got.ExpiresAt != currentTime.Add(idempotency.DefaultTTL) {
+		t.Errorf("BeginRequest() failed, expected %v, got %v %v", "a hashed key expiring after DefaultTTL", got.Key, got.ExpiresAt)
+	}
+	got.Key, got.CreatedAt, got.ExpiresAt = "", time.Time{}, time.Time{}
+	if !reflect.DeepEqual(expected, *got) {
+		t.Errorf("BeginRequest() failed, expected %v, got %v", expected, *got)
+	}
+}
+
+func TestBeginRequestWhenAnotherFingerprintThenValidationError(t *testing.T) {
+	iuc := &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: &timeStampMock{}}
+	iuc.BeginRequest(context.Background(), "key", "fingerprint")
+	iuc.CompleteRequest(context.Background(), &model.IdempotentRequest{Key: "key", Fingerprint: "fingerprint", Code: 201})
+
+	_, _, err := iuc.BeginRequest(context.Background(), "key", "another")
+
+	var validationErr *usecase.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("BeginRequest() failed, expected %v, got %v", "ValidationError", err)
+	}
+}
+
+func TestBeginRequestWhenInProgressThenWaitsForResponse(t *testing.T) {
+	iuc := &idempotency.IdempotencyUseCaseImpl{
+		IDS:          &datamemory.IdempotencyDataServiceMemory{},
+		TS:           &provider.TimeStampImpl{},
+		Wait:         5 * time.Second,
+		PollInterval: time.Millisecond,
+	}
+	iuc.BeginRequest(context.Background(), "key", "fingerprint")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		iuc.CompleteRequest(context.Background(), &model.IdempotentRequest{Key: "key", Fingerprint: "fingerprint", Code: 201})
+	}()
+
+	got, _, err := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	if err != nil || got == nil || got.Code != 201 {
+		t.Errorf("BeginRequest() failed, expected %v, got %v %v", 201, got, err)
+	}
+}
+
+func TestBeginRequestWhenExpiredThenReservedAgain(t *testing.T) {
+	ts := &timeStampMock{}
+	iuc := &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: ts, TTL: time.Hour}
+	iuc.BeginRequest(context.Background(), "key", "fingerprint")
+	iuc.CompleteRequest(context.Background(), &model.IdempotentRequest{Key: "key", Fingerprint: "fingerprint", Code: 201})
+
+	ts.now = currentTime.Add(time.Hour)
+	got, _, err := iuc.BeginRequest(context.Background(), "key", "another")
+
+	if got != nil || err != nil {
+		t.Errorf("BeginRequest() failed, expected %v, got %v %v", nil, got, err)
+	}
+}
+
+func TestAbandonRequestWhenInProgressThenKeyReleased(t *testing.T) {
+	iuc := &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: &timeStampMock{}}
+	_, reservation, _ := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	err := iuc.AbandonRequest(context.Background(), "key", reservation)
+	got, _, beginErr := iuc.BeginRequest(context.Background(), "key", "another")
+
+	if err != nil || got != nil || beginErr != nil {
+		t.Errorf("AbandonRequest() failed, expected %v, got %v %v %v", nil, err, got, beginErr)
+	}
+}
+
+func TestAbandonRequestWhenLeaseExpiredAndReservedAgainThenKeyHeld(t *testing.T) {
+	ts := &timeStampMock{}
+	iuc := &idempotency.IdempotencyUseCaseImpl{IDS: &datamemory.IdempotencyDataServiceMemory{}, TS: ts}
+	_, expired, _ := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+	ts.now = currentTime.Add(idempotency.DefaultLease)
+	_, retry, _ := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	err := iuc.AbandonRequest(context.Background(), "key", expired)
+	_, _, beginErr := iuc.BeginRequest(context.Background(), "key", "fingerprint")
+
+	var conflictErr *usecase.ConflictError
+	if err != nil || expired == retry || !errors.As(beginErr, &conflictErr) {
+		t.Errorf("AbandonRequest() failed, expected %v, got %v %v", "the key held by the retry", err, beginErr)
+	}
 }
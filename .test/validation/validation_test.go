@@ -0,0 +1,128 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zeroberto/go-ms-template/validation"
+)
+
+type subject struct {
+	Name     string `validate:"required,min=2,max=5" normalize:"trim,nfc"`
+	Code     string `validate:"pattern=^[A-Z]+$"`
+	Kind     string `validate:"enum=a|b"`
+	Quantity int    `validate:"min=1,max=10"`
+	Even     int    `validate:"rule=even"`
+	Free     string
+}
+
+func validator() *validation.Validator {
+	return &validation.Validator{Rules: map[string]validation.Rule{
+		"even": func(value interface{}) string {
+			if value.(int)%2 != 0 {
+				return "must be even"
+			}
+			return ""
+		},
+	}}
+}
+
+func TestValidate(t *testing.T) {
+	got := validator().Validate(&subject{Name: "abc", Code: "AB", Kind: "a", Quantity: 1, Even: 2})
+
+	if got != nil {
+		t.Errorf("Validate() failed, expected %v, got %v", nil, got)
+	}
+}
+
+func TestValidateWhenFieldsAreInvalidThenError(t *testing.T) {
+	expected := &validation.Error{Fields: []validation.FieldError{
+		{Field: "Name", Message: "is required"},
+		{Field: "Code", Message: "must match ^[A-Z]+$"},
+		{Field: "Kind", Message: "must be one of a, b"},
+		{Field: "Quantity", Message: "must be at most 10"},
+		{Field: "Even", Message: "must be even"},
+	}}
+
+	got := validator().Validate(subject{Code: "ab", Kind: "c", Quantity: 11, Even: 1})
+
+	var validationErr *validation.Error
+	if !errors.As(got, &validationErr) || !reflect.DeepEqual(expected, validationErr) {
+		t.Errorf("Validate() failed, expected %v, got %v", expected, got)
+	}
+	if expected := "Property Name is required"; got == nil || got.Error() != expected {
+		t.Errorf("Validate() failed, expected %v, got %v", expected, got)
+	}
+}
+
+func TestValidateWhenStringIsOutOfBoundsThenError(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "a", expected: "must have at least 2 characters"},
+		{name: "abcdef", expected: "must have at most 5 characters"},
+		{name: "ééééé", expected: ""},
+	}
+	for _, test := range tests {
+		got := validator().Validate(subject{Name: test.name, Code: "A", Kind: "a", Quantity: 1})
+
+		var message string
+		var validationErr *validation.Error
+		if errors.As(got, &validationErr) {
+			message = validationErr.Fields[0].Message
+		}
+		if test.expected != message {
+			t.Errorf("Validate() failed, expected %v, got %v", test.expected, got)
+		}
+	}
+}
+
+func TestValidateWhenTagIsInvalidThenError(t *testing.T) {
+	value := struct {
+		Name string `validate:"rule=unknown"`
+	}{}
+
+	got := validator().Validate(value)
+
+	var validationErr *validation.Error
+	if got == nil || errors.As(got, &validationErr) {
+		t.Errorf("Validate() failed, expected %v, got %v", "an invalid tag error", got)
+	}
+}
+
+func TestValidateWhenNotStructThenError(t *testing.T) {
+	got := validator().Validate("text")
+
+	if got == nil {
+		t.Errorf("Validate() failed, expected %v, got %v", "an error", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	expected := subject{Name: "Café", Free: " free "}
+
+	got := subject{Name: "\t Cafe\u0301 ", Free: " free "}
+	validation.Normalize(&got)
+
+	if expected != got {
+		t.Errorf("Normalize() failed, expected %q, got %q", expected.Name, got.Name)
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		first  string
+		second string
+	}{
+		{first: "Example", second: "eXAMPLE"},
+		{first: "Straße", second: "STRASSE"},
+		{first: "Café", second: "CAFÉ"},
+	}
+	for _, test := range tests {
+		if validation.Fold(test.first) != validation.Fold(test.second) {
+			t.Errorf("Fold() failed, expected %q, got %q", validation.Fold(test.first), validation.Fold(test.second))
+		}
+	}
+}
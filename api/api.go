@@ -1,40 +1,100 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/patch"
 )
 
 // ExampleAPI contains the api methods available for the Example model
 type ExampleAPI interface {
+	// Batch applies a batch of changes to Examples
+	Batch(ctx context.Context, batch BatchRequest) Response
 	// Create creates a new Example
-	Create(example model.Example) Response
+	Create(ctx context.Context, example model.Example) Response
 	// Delete deletes an existing Example
-	Delete(ID int64) Response
-	// Get provides all Examples
-	Get() Response
+	Delete(ctx context.Context, ID int64, preconditions Preconditions) Response
+	// DeleteLogically deactivates an existing Example
+	DeleteLogically(ctx context.Context, ID int64, preconditions Preconditions) Response
+	// Get provides a page of all Examples
+	Get(ctx context.Context, pageParams PageParams) Response
+	// GetActive provides a page of the active Examples
+	GetActive(ctx context.Context, pageParams PageParams) Response
 	// GetByID provides an Example via an ID
-	GetByID(ID int64) Response
-	// PartialUpdate updates the properties of an existing Example
-	PartialUpdate(ID int64, properties map[string]interface{}) Response
+	GetByID(ctx context.Context, ID int64) Response
+	// GetByName provides an Example via its name
+	GetByName(ctx context.Context, name string) Response
+	// PartialUpdate applies a patch to an existing Example
+	PartialUpdate(ctx context.Context, ID int64, examplePatch patch.Patch, preconditions Preconditions) Response
 	// Update updates or creates, if it does not exist, a complete Example
-	Update(ID int64, example model.Example) Response
+	Update(ctx context.Context, ID int64, example model.Example, preconditions Preconditions) Response
 }
 
 // Response represents the request response
 type Response struct {
 	Code int
 	Path int64
-	Body interface{}
+	// ETag identifies the version of the resource provided or changed
+	ETag string
+	// LastModified is the moment of the last change of the resource provided
+	LastModified time.Time
+	Body         interface{}
+	// Replayed reports that the response was kept for a previous request with the same
+	// idempotency key
+	Replayed bool
 }
 
-// ResponseBody represents the standard response body
-type ResponseBody struct {
-	Time    time.Time
-	Message string
-	Code    int
+// Problem represents the standard error response body, according to RFC 7807
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Time     time.Time    `json:"time"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError represents the reason why a specific field of the request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+const (
+	// ProblemTypeBlank identifies problems that carry no semantics beyond the status code
+	ProblemTypeBlank string = "about:blank"
+	// ProblemTypeInvalidParameter identifies problems about request parameters that cannot be interpreted
+	ProblemTypeInvalidParameter string = "/problems/invalid-parameter"
+	// ProblemTypeNotFound identifies problems about resources that do not exist
+	ProblemTypeNotFound string = "/problems/not-found"
+	// ProblemTypeConflict identifies problems about conflicts with the current state of a resource
+	ProblemTypeConflict string = "/problems/conflict"
+	// ProblemTypeValidation identifies problems about invalid request data
+	ProblemTypeValidation string = "/problems/validation"
+	// ProblemTypePreconditionFailed identifies problems about unmet request preconditions
+	ProblemTypePreconditionFailed string = "/problems/precondition-failed"
+	// ProblemTypePreconditionRequired identifies problems about requests missing a required precondition
+	ProblemTypePreconditionRequired string = "/problems/precondition-required"
+	// ProblemTypeBatchAborted identifies problems about items of a batch not applied as another item failed
+	ProblemTypeBatchAborted string = "/problems/batch-aborted"
+	// ProblemTypeUnavailable identifies problems about dependencies that cannot be reached
+	ProblemTypeUnavailable string = "/problems/unavailable"
+)
+
+// NewProblem is responsible for creating a Problem with the standard title of the status code
+func NewProblem(problemType string, status int, detail string, time time.Time) Problem {
+	return Problem{
+		Type:   problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Time:   time,
+	}
 }
 
 // Error is responsible for encapsulating errors generated by API methods
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// APIKeyAPI contains the api methods available for managing the API keys
+type APIKeyAPI interface {
+	// Issue issues a new API key
+	Issue(ctx context.Context, request APIKeyRequest) Response
+	// List provides all API keys, revoked ones included
+	List(ctx context.Context) Response
+	// Revoke revokes an existing API key
+	Revoke(ctx context.Context, ID int64) Response
+	// Rotate replaces the key of an existing API key
+	Rotate(ctx context.Context, ID int64) Response
+}
+
+// APIKeyRequest represents the body of the requests that issue API keys
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes,omitempty"`
+	// ExpiresAt is absent when the key does not expire
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// APIKey represents an API key, identified by its prefix, whose secret is never provided
+type APIKey struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// IssuedAPIKey represents an API key just issued or rotated, along with the key itself,
+// which is provided only once
+type IssuedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
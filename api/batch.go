@@ -0,0 +1,34 @@
+package api
+
+import "github.com/zeroberto/go-ms-template/model"
+
+// BatchRequest represents a batch of changes to Examples, decoded from the body of any version
+// of the API, where Mode is "allOrNothing" (the default) or "bestEffort"
+type BatchRequest struct {
+	Mode  string      `json:"mode"`
+	Items []BatchItem `json:"items"`
+}
+
+// BatchItem represents a change within a batch, where Operation is "create", "update"
+// or "delete". IfMatch holds the ETag the Example must match to be updated or deleted
+type BatchItem struct {
+	Operation string        `json:"op"`
+	ID        int64         `json:"id,omitempty"`
+	IfMatch   string        `json:"ifMatch,omitempty"`
+	Example   model.Example `json:"example"`
+}
+
+// BatchResponse represents the body of the response to a batch, with the result of each
+// item at the same position
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult represents the outcome of an item of a batch, with the status code the
+// equivalent single request would have had
+type BatchResult struct {
+	Status  int      `json:"status"`
+	ID      int64    `json:"id,omitempty"`
+	ETag    string   `json:"etag,omitempty"`
+	Problem *Problem `json:"problem,omitempty"`
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Preconditions represents the conditional headers of a request that changes a resource
+type Preconditions struct {
+	// IfMatch holds the entity tags the resource must currently have, or "*" for any
+	IfMatch string
+	// IfNoneMatch holds "*" when the resource must not exist yet
+	IfNoneMatch string
+}
+
+// PreconditionRequiredError must be reported when the request does not inform the
+// entity tag required to change the resource
+type PreconditionRequiredError struct{}
+
+func (err *PreconditionRequiredError) Error() string {
+	return "The If-Match header is required to change the resource"
+}
+
+// ETag is responsible for providing the strong entity tag of a version of a resource
+func ETag(version int64) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// WeakETag is responsible for providing a weak entity tag that identifies a representation
+// derived from several resources, such as a listing
+func WeakETag(hash uint64) string {
+	return fmt.Sprintf(`W/"%x"`, hash)
+}
+
+// ParseETag is responsible for providing the version of a resource from a strong entity
+// tag generated by ETag. Weak entity tags are rejected, as If-Match requires a strong comparison
+func ParseETag(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, fmt.Errorf("%s is not a strong entity tag", value)
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("%s is not an entity tag of this resource", value)
+	}
+	return version, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"time"
+
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// ExampleV1 represents the Example in the version 1 of the API, whose properties are named
+// as the fields of the Example model were when the version was released
+type ExampleV1 struct {
+	ID            int64     `json:"ID"`
+	Name          string    `json:"Name" validate:"required,max=100,pattern=^[^\\p{Cc}]*$"`
+	Useful        bool      `json:"Useful"`
+	CreatedAt     time.Time `json:"CreatedAt"`
+	DeactivatedAt time.Time `json:"DeactivatedAt"`
+	Version       int64     `json:"Version"`
+	UpdatedAt     time.Time `json:"UpdatedAt"`
+}
+
+// BatchRequestV1 represents the body of a batch of changes to Examples in the version 1 of the API
+type BatchRequestV1 struct {
+	Mode  string        `json:"mode"`
+	Items []BatchItemV1 `json:"items"`
+}
+
+// BatchItemV1 represents a change within a batch in the version 1 of the API
+type BatchItemV1 struct {
+	Operation string    `json:"op"`
+	ID        int64     `json:"id,omitempty"`
+	IfMatch   string    `json:"ifMatch,omitempty"`
+	Example   ExampleV1 `json:"example"`
+}
+
+// RepresentationV1 is responsible for converting the Example model to and from the bodies of
+// the version 1 of the API
+type RepresentationV1 struct{}
+
+// NewExample provides an empty ExampleV1
+func (RepresentationV1) NewExample() ExampleBody {
+	return &ExampleV1{}
+}
+
+// NewBatch provides an empty BatchRequestV1
+func (RepresentationV1) NewBatch() BatchBody {
+	return &BatchRequestV1{}
+}
+
+// FromExample provides the ExampleV1 corresponding to the model
+func (RepresentationV1) FromExample(example model.Example) interface{} {
+	return ExampleV1{
+		ID:            example.ID,
+		Name:          example.Name,
+		Useful:        example.Useful,
+		CreatedAt:     example.CreatedAt,
+		DeactivatedAt: example.DeactivatedAt,
+		Version:       example.Version,
+		UpdatedAt:     example.UpdatedAt,
+	}
+}
+
+// ToExample provides the Example model corresponding to the ExampleV1
+func (example *ExampleV1) ToExample() model.Example {
+	return model.Example{
+		ID:            example.ID,
+		Name:          example.Name,
+		Useful:        example.Useful,
+		CreatedAt:     example.CreatedAt,
+		DeactivatedAt: example.DeactivatedAt,
+		Version:       example.Version,
+		UpdatedAt:     example.UpdatedAt,
+	}
+}
+
+// ToBatch provides the BatchRequest corresponding to the BatchRequestV1
+func (batch *BatchRequestV1) ToBatch() BatchRequest {
+	request := BatchRequest{Mode: batch.Mode, Items: make([]BatchItem, len(batch.Items))}
+	for i, item := range batch.Items {
+		request.Items[i] = BatchItem{
+			Operation: item.Operation,
+			ID:        item.ID,
+			IfMatch:   item.IfMatch,
+			Example:   item.Example.ToExample(),
+		}
+	}
+	return request
+}
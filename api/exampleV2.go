@@ -0,0 +1,94 @@
+package api
+
+import (
+	"time"
+
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// ExampleV2 represents the Example in the version 2 of the API, where DeactivatedAt is
+// omitted while the Example is active
+type ExampleV2 struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name" validate:"required,max=100,pattern=^[^\\p{Cc}]*$"`
+	Useful        bool       `json:"useful"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	Version       int64      `json:"version"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// BatchRequestV2 represents the body of a batch of changes to Examples in the version 2 of the API
+type BatchRequestV2 struct {
+	Mode  string        `json:"mode"`
+	Items []BatchItemV2 `json:"items"`
+}
+
+// BatchItemV2 represents a change within a batch in the version 2 of the API
+type BatchItemV2 struct {
+	Operation string    `json:"op"`
+	ID        int64     `json:"id,omitempty"`
+	IfMatch   string    `json:"if_match,omitempty"`
+	Example   ExampleV2 `json:"example"`
+}
+
+// RepresentationV2 is responsible for converting the Example model to and from the bodies of
+// the version 2 of the API
+type RepresentationV2 struct{}
+
+// NewExample provides an empty ExampleV2
+func (RepresentationV2) NewExample() ExampleBody {
+	return &ExampleV2{}
+}
+
+// NewBatch provides an empty BatchRequestV2
+func (RepresentationV2) NewBatch() BatchBody {
+	return &BatchRequestV2{}
+}
+
+// FromExample provides the ExampleV2 corresponding to the model
+func (RepresentationV2) FromExample(example model.Example) interface{} {
+	exampleV2 := ExampleV2{
+		ID:        example.ID,
+		Name:      example.Name,
+		Useful:    example.Useful,
+		CreatedAt: example.CreatedAt,
+		Version:   example.Version,
+		UpdatedAt: example.UpdatedAt,
+	}
+	if !example.DeactivatedAt.IsZero() {
+		deactivatedAt := example.DeactivatedAt
+		exampleV2.DeactivatedAt = &deactivatedAt
+	}
+	return exampleV2
+}
+
+// ToExample provides the Example model corresponding to the ExampleV2
+func (example *ExampleV2) ToExample() model.Example {
+	exampleModel := model.Example{
+		ID:        example.ID,
+		Name:      example.Name,
+		Useful:    example.Useful,
+		CreatedAt: example.CreatedAt,
+		Version:   example.Version,
+		UpdatedAt: example.UpdatedAt,
+	}
+	if example.DeactivatedAt != nil {
+		exampleModel.DeactivatedAt = *example.DeactivatedAt
+	}
+	return exampleModel
+}
+
+// ToBatch provides the BatchRequest corresponding to the BatchRequestV2
+func (batch *BatchRequestV2) ToBatch() BatchRequest {
+	request := BatchRequest{Mode: batch.Mode, Items: make([]BatchItem, len(batch.Items))}
+	for i, item := range batch.Items {
+		request.Items[i] = BatchItem{
+			Operation: item.Operation,
+			ID:        item.ID,
+			IfMatch:   item.IfMatch,
+			Example:   item.Example.ToExample(),
+		}
+	}
+	return request
+}
@@ -0,0 +1,11 @@
+package api
+
+import "context"
+
+// IdempotencyAPI contains the api methods available for running at most once the requests
+// identified by an idempotency key
+type IdempotencyAPI interface {
+	// Run provides the response to the request with the key and the fingerprint run before,
+	// marked as Replayed, or else runs it with fn, keeping its response for the retries
+	Run(ctx context.Context, key string, fingerprint string, fn func(ctx context.Context) Response) Response
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// PageParams represents the pagination parameters of a listing request
+type PageParams struct {
+	Limit  int
+	Offset int64
+	Cursor string
+	Total  bool
+	// Filter is an expression of the filter package restricting the listing
+	Filter string
+	// Sort is a comma-separated list of fields, prefixed by "-" when descending
+	Sort string
+}
+
+// Page represents the standard body of listing responses
+type Page struct {
+	Items    interface{} `json:"items"`
+	Next     string      `json:"next,omitempty"`
+	Previous string      `json:"prev,omitempty"`
+	Total    *int64      `json:"total,omitempty"`
+}
+
+// Cursor represents the position of a page in a listing, by ID when the listing is
+// sorted by ID and by Offset otherwise
+type Cursor struct {
+	AfterID  int64 `json:"a,omitempty"`
+	BeforeID int64 `json:"b,omitempty"`
+	Offset   int64 `json:"o,omitempty"`
+}
+
+// ParamError must be reported when a parameter of the request cannot be interpreted
+type ParamError struct {
+	Param string
+	Cause error
+}
+
+func (err *ParamError) Error() string {
+	return fmt.Sprintf("Invalid %s: %v", err.Param, err.Cause)
+}
+
+// Unwrap provides the cause of the error
+func (err *ParamError) Unwrap() error {
+	return err.Cause
+}
+
+// EncodeCursor is responsible for converting a Cursor into an opaque string
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor is responsible for converting an opaque string generated by EncodeCursor into a Cursor
+func DecodeCursor(value string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
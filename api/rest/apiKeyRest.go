@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+// APIKeyAPIRest is responsible for implementing the APIKeyAPI using HTTP REST abstraction
+type APIKeyAPIRest struct {
+	AKUC usecase.APIKeyUseCase
+	TS   chrono.TimeStamp
+}
+
+// Issue issues a new API key by REST abstraction
+func (akapi *APIKeyAPIRest) Issue(ctx context.Context, request api.APIKeyRequest) api.Response {
+	apiKey := &model.APIKey{Name: request.Name, Scopes: request.Scopes}
+	if request.ExpiresAt != nil {
+		apiKey.ExpiresAt = *request.ExpiresAt
+	}
+	apiKey, key, err := akapi.AKUC.IssueAPIKey(ctx, apiKey)
+	if err != nil {
+		return report(err, akapi.TS.GetCurrentTime())
+	}
+	return api.Response{
+		Code: http.StatusCreated,
+		Path: apiKey.ID,
+		Body: api.IssuedAPIKey{APIKey: toAPIKey(apiKey), Key: key},
+	}
+}
+
+// List provides all API keys by REST abstraction
+func (akapi *APIKeyAPIRest) List(ctx context.Context) api.Response {
+	apiKeys, err := akapi.AKUC.ListAPIKeys(ctx)
+	if err != nil {
+		return report(err, akapi.TS.GetCurrentTime())
+	}
+	items := make([]api.APIKey, 0, len(apiKeys))
+	for i := range apiKeys {
+		items = append(items, toAPIKey(&apiKeys[i]))
+	}
+	return api.Response{
+		Code: http.StatusOK,
+		Body: api.Page{Items: items},
+	}
+}
+
+// Revoke revokes an existing API key by REST abstraction
+func (akapi *APIKeyAPIRest) Revoke(ctx context.Context, ID int64) api.Response {
+	if err := akapi.AKUC.RevokeAPIKey(ctx, ID); err != nil {
+		return report(err, akapi.TS.GetCurrentTime())
+	}
+	return api.Response{Code: http.StatusNoContent}
+}
+
+// Rotate replaces the key of an existing API key by REST abstraction
+func (akapi *APIKeyAPIRest) Rotate(ctx context.Context, ID int64) api.Response {
+	apiKey, key, err := akapi.AKUC.RotateAPIKey(ctx, ID)
+	if err != nil {
+		return report(err, akapi.TS.GetCurrentTime())
+	}
+	return api.Response{
+		Code: http.StatusOK,
+		Body: api.IssuedAPIKey{APIKey: toAPIKey(apiKey), Key: key},
+	}
+}
+
+func toAPIKey(apiKey *model.APIKey) api.APIKey {
+	scopes := apiKey.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return api.APIKey{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    scopes,
+		CreatedAt: apiKey.CreatedAt,
+		ExpiresAt: toOptionalTime(apiKey.ExpiresAt),
+		RevokedAt: toOptionalTime(apiKey.RevokedAt),
+	}
+}
+
+func toOptionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
@@ -1,117 +1,474 @@
 package rest
 
 import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
 	"net/http"
 	"time"
 
-	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/pkg/errors"
 
 	"github.com/zeroberto/go-ms-template/api"
 	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/filter"
 	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/patch"
 	"github.com/zeroberto/go-ms-template/usecase"
 )
 
+const (
+	// StatusClientClosedRequest is reported when the client goes away before the response is ready
+	StatusClientClosedRequest = 499
+	// DefaultPageSize is the number of Examples per page when neither the request nor DefaultPageSize informs it
+	DefaultPageSize = 20
+	// DefaultMaxPageSize is the maximum number of Examples per page when MaxPageSize is not informed
+	DefaultMaxPageSize = 100
+	// DefaultMaxBatchSize is the maximum number of items of a batch when MaxBatchSize is not informed
+	DefaultMaxBatchSize = 100
+)
+
 // ExampleAPIRest is responsible for implementing the ExampleAPIInterface using HTTP REST abstraction
 type ExampleAPIRest struct {
 	ECUC  usecase.ExampleCreationUseCase
 	ERUC  usecase.ExampleReadUseCase
 	ERMUC usecase.ExampleRemovalUseCase
 	TS    chrono.TimeStamp
+	// DefaultPageSize is the number of Examples per page when the request does not inform it
+	DefaultPageSize int
+	// MaxPageSize limits the number of Examples per page
+	MaxPageSize int
+	// RequireIfMatch rejects the changes that do not inform the ETag of the Example
+	RequireIfMatch bool
+	// MaxBatchSize limits the number of items of a batch
+	MaxBatchSize int
+}
+
+// Get provides a page of all Examples by REST abstraction
+func (eapi *ExampleAPIRest) Get(ctx context.Context, pageParams api.PageParams) api.Response {
+	return eapi.getPage(ctx, pageParams, eapi.ERUC.ListExamples)
 }
 
-// Get provides all Examples by REST abstraction
-func (eapi *ExampleAPIRest) Get() api.Response {
-	examples, err := eapi.ERUC.ListExamples()
+// GetActive provides a page of the active Examples by REST abstraction
+func (eapi *ExampleAPIRest) GetActive(ctx context.Context, pageParams api.PageParams) api.Response {
+	return eapi.getPage(ctx, pageParams, eapi.ERUC.ListActiveExamples)
+}
+
+func (eapi *ExampleAPIRest) getPage(
+	ctx context.Context,
+	pageParams api.PageParams,
+	list func(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error),
+) api.Response {
+	pageRequest, err := eapi.getPageRequest(pageParams)
+	if err != nil {
+		return report(err, eapi.TS.GetCurrentTime())
+	}
+	page, err := list(ctx, pageRequest)
 	if err != nil {
 		return report(err, eapi.TS.GetCurrentTime())
 	}
+	body := toPage(page, pageRequest)
+	eTag, lastModified := getPageValidators(page, body)
 	return api.Response{
-		Code: http.StatusOK,
-		Body: examples,
+		Code:         http.StatusOK,
+		ETag:         eTag,
+		LastModified: lastModified,
+		Body:         body,
 	}
 }
 
 // GetByID provides an Example via an ID by REST abstraction
-func (eapi *ExampleAPIRest) GetByID(ID int64) api.Response {
-	example, err := eapi.ERUC.GetExample(ID)
+func (eapi *ExampleAPIRest) GetByID(ctx context.Context, ID int64) api.Response {
+	example, err := eapi.ERUC.GetExample(ctx, ID)
 	if err != nil {
 		return report(err, eapi.TS.GetCurrentTime())
 	}
+	return toExampleResponse(example)
+}
+
+// GetByName provides an Example via its name by REST abstraction
+func (eapi *ExampleAPIRest) GetByName(ctx context.Context, name string) api.Response {
+	example, err := eapi.ERUC.GetExampleByName(ctx, name)
+	if err != nil {
+		return report(err, eapi.TS.GetCurrentTime())
+	}
+	return toExampleResponse(example)
+}
+
+func toExampleResponse(example *model.Example) api.Response {
 	return api.Response{
-		Code: http.StatusOK,
-		Body: *example,
+		Code:         http.StatusOK,
+		ETag:         api.ETag(example.Version),
+		LastModified: example.UpdatedAt,
+		Body:         *example,
 	}
 }
 
 // Create creates a new Example by REST abstraction
-func (eapi *ExampleAPIRest) Create(example model.Example) api.Response {
-	_, err := eapi.ECUC.CreateExample(&example)
+func (eapi *ExampleAPIRest) Create(ctx context.Context, example model.Example) api.Response {
+	_, err := eapi.ECUC.CreateExample(ctx, &example)
 	if err != nil {
 		return report(err, eapi.TS.GetCurrentTime())
 	}
 	return api.Response{
 		Code: http.StatusCreated,
 		Path: example.ID,
+		ETag: api.ETag(example.Version),
 	}
 }
 
-// Update updates or creates, if it does not exist, a complete Example by REST abstraction
-func (eapi *ExampleAPIRest) Update(ID int64, example model.Example) api.Response {
-	_, updateErr := eapi.ECUC.UpdateExample(&example)
+// Update updates or creates, if it does not exist, a complete Example by REST abstraction.
+// With If-None-Match: * the Example is only created
+func (eapi *ExampleAPIRest) Update(ctx context.Context, ID int64, example model.Example, preconditions api.Preconditions) api.Response {
+	if preconditions.IfNoneMatch == "*" {
+		return eapi.createIfNotExists(ctx, ID, example)
+	}
+	version, err := eapi.getVersion(preconditions)
+	if err != nil {
+		return report(err, eapi.TS.GetCurrentTime())
+	}
+	example.Version = version
+	updatedExample, updateErr := eapi.ECUC.UpdateExample(ctx, &example)
 	if updateErr != nil {
-		_, ok := updateErr.(*usecase.NotExistsError)
-		if ok {
-			return eapi.Create(example)
+		var notExistsErr *usecase.NotExistsError
+		if errors.As(updateErr, &notExistsErr) && preconditions.IfMatch == "" {
+			return eapi.Create(ctx, example)
 		}
-		return report(updateErr, eapi.TS.GetCurrentTime())
+		return eapi.reportChange(updateErr, preconditions)
 	}
 	return api.Response{
 		Code: http.StatusNoContent,
+		ETag: api.ETag(updatedExample.Version),
 	}
 }
 
-// PartialUpdate updates the properties of an existing Example by REST abstraction
-func (eapi *ExampleAPIRest) PartialUpdate(ID int64, properties map[string]interface{}) api.Response {
-	_, err := eapi.ECUC.UpdateExampleProperties(ID, properties)
+// PartialUpdate applies a patch to an existing Example by REST abstraction
+func (eapi *ExampleAPIRest) PartialUpdate(ctx context.Context, ID int64, examplePatch patch.Patch, preconditions api.Preconditions) api.Response {
+	version, err := eapi.getVersion(preconditions)
 	if err != nil {
 		return report(err, eapi.TS.GetCurrentTime())
 	}
+	example, err := eapi.ECUC.PatchExample(ctx, ID, version, examplePatch)
+	if err != nil {
+		return eapi.reportChange(err, preconditions)
+	}
 	return api.Response{
 		Code: http.StatusNoContent,
+		ETag: api.ETag(example.Version),
 	}
 }
 
 // Delete deletes an existing Example by REST abstraction
-func (eapi *ExampleAPIRest) Delete(ID int64) api.Response {
-	err := eapi.ERMUC.DeleteExample(ID)
+func (eapi *ExampleAPIRest) Delete(ctx context.Context, ID int64, preconditions api.Preconditions) api.Response {
+	version, err := eapi.getVersion(preconditions)
 	if err != nil {
 		return report(err, eapi.TS.GetCurrentTime())
 	}
+	if err := eapi.ERMUC.DeleteExample(ctx, ID, version); err != nil {
+		return eapi.reportChange(err, preconditions)
+	}
 	return api.Response{Code: http.StatusNoContent}
 }
 
-func report(err error, time time.Time) api.Response {
-	code := getCode(err)
+// DeleteLogically deactivates an existing Example by REST abstraction, at the current time
+func (eapi *ExampleAPIRest) DeleteLogically(ctx context.Context, ID int64, preconditions api.Preconditions) api.Response {
+	version, err := eapi.getVersion(preconditions)
+	if err != nil {
+		return report(err, eapi.TS.GetCurrentTime())
+	}
+	if err := eapi.ERMUC.DeleteExampleLogically(ctx, ID, version); err != nil {
+		return eapi.reportChange(err, preconditions)
+	}
+	return api.Response{Code: http.StatusNoContent}
+}
+
+// Batch applies a batch of changes to Examples by REST abstraction, within a single transaction.
+// Batches made only of deletions are handled by the removal use case
+func (eapi *ExampleAPIRest) Batch(ctx context.Context, batch api.BatchRequest) api.Response {
+	mode := model.BatchMode(batch.Mode)
+	if mode == "" {
+		mode = model.BatchAllOrNothing
+	}
+	if err := eapi.validateBatch(batch, mode); err != nil {
+		return report(err, eapi.TS.GetCurrentTime())
+	}
+
+	results := make([]model.BatchResult, len(batch.Items))
+	items := make([]model.BatchItem, 0, len(batch.Items))
+	positions := make([]int, 0, len(batch.Items))
+	failed := -1
+	for i, item := range batch.Items {
+		batchItem := model.BatchItem{Operation: model.BatchOperation(item.Operation), Example: item.Example}
+		batchItem.Example.ID = item.ID
+		results[i] = model.BatchResult{Operation: batchItem.Operation, ID: item.ID}
+		if batchItem.Operation != model.BatchCreate {
+			version, err := eapi.getVersion(api.Preconditions{IfMatch: item.IfMatch})
+			if err != nil {
+				results[i].Err = err
+				if failed < 0 {
+					failed = i
+				}
+				continue
+			}
+			batchItem.Example.Version = version
+		}
+		items = append(items, batchItem)
+		positions = append(positions, i)
+	}
+
+	if failed >= 0 && mode == model.BatchAllOrNothing {
+		usecase.AbortBatch(results, failed)
+	} else if len(items) > 0 {
+		saved, err := eapi.saveExamples(ctx, items, mode)
+		if err != nil {
+			return report(err, eapi.TS.GetCurrentTime())
+		}
+		for j, result := range saved {
+			var abortedErr *usecase.BatchAbortedError
+			if errors.As(result.Err, &abortedErr) {
+				result.Err = &usecase.BatchAbortedError{Index: positions[abortedErr.Index]}
+			}
+			results[positions[j]] = result
+		}
+	}
+
+	response := api.BatchResponse{Results: make([]api.BatchResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = toBatchResult(result, batch.Items[i].IfMatch, eapi.TS.GetCurrentTime())
+	}
 	return api.Response{
-		Code: code,
-		Body: api.ResponseBody{
-			Time:    time,
-			Code:    code,
-			Message: err.Error(),
-		},
+		Code: http.StatusOK,
+		Body: response,
 	}
 }
 
-func getCode(err error) int {
-	if _, ok := err.(*usecase.NotExistsError); ok {
-		return http.StatusNotFound
+func (eapi *ExampleAPIRest) validateBatch(batch api.BatchRequest, mode model.BatchMode) error {
+	if err := usecase.ValidateBatchMode(mode); err != nil {
+		return err
 	}
-	if _, ok := err.(*dataservice.Error); ok {
-		return http.StatusInternalServerError
+	maxBatchSize := eapi.MaxBatchSize
+	if maxBatchSize == 0 {
+		maxBatchSize = DefaultMaxBatchSize
 	}
-	if e, ok := err.(*usecase.Error); ok {
-		return getCode(e.Cause)
+	switch {
+	case len(batch.Items) == 0:
+		return &usecase.ValidationError{
+			Message: "The batch has no items",
+			Fields:  []usecase.FieldError{{Field: "items", Message: "must not be empty"}},
+		}
+	case len(batch.Items) > maxBatchSize:
+		return &usecase.ValidationError{
+			Message: fmt.Sprintf("The batch has more than %d items", maxBatchSize),
+			Fields:  []usecase.FieldError{{Field: "items", Message: fmt.Sprintf("must not have more than %d items", maxBatchSize)}},
+		}
+	}
+	return nil
+}
+
+func (eapi *ExampleAPIRest) saveExamples(ctx context.Context, items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+	for _, item := range items {
+		if item.Operation != model.BatchDelete {
+			return eapi.ECUC.SaveExamples(ctx, items, mode)
+		}
+	}
+	return eapi.ERMUC.DeleteExamples(ctx, items, mode)
+}
+
+// toBatchResult provides the outcome of an item with the status of the equivalent single
+// request, where a missing Example fails If-Match
+func toBatchResult(result model.BatchResult, ifMatch string, time time.Time) api.BatchResult {
+	if result.Err != nil {
+		var notExistsErr *usecase.NotExistsError
+		if ifMatch != "" && errors.As(result.Err, &notExistsErr) {
+			result.Err = &usecase.PreconditionFailedError{Cause: result.Err}
+		}
+		problem := getProblem(result.Err, time)
+		return api.BatchResult{Status: problem.Status, ID: result.ID, Problem: &problem}
+	}
+	switch result.Operation {
+	case model.BatchCreate:
+		return api.BatchResult{Status: http.StatusCreated, ID: result.ID, ETag: api.ETag(result.Version)}
+	case model.BatchUpdate:
+		return api.BatchResult{Status: http.StatusNoContent, ID: result.ID, ETag: api.ETag(result.Version)}
+	}
+	return api.BatchResult{Status: http.StatusNoContent, ID: result.ID}
+}
+
+func (eapi *ExampleAPIRest) createIfNotExists(ctx context.Context, ID int64, example model.Example) api.Response {
+	_, err := eapi.ERUC.GetExample(ctx, ID)
+	if err == nil {
+		return report(&usecase.PreconditionFailedError{Cause: errors.Errorf("Example %d already exists", ID)}, eapi.TS.GetCurrentTime())
+	}
+	var notExistsErr *usecase.NotExistsError
+	if !errors.As(err, &notExistsErr) {
+		return report(err, eapi.TS.GetCurrentTime())
+	}
+	return eapi.Create(ctx, example)
+}
+
+// getVersion provides the version of the Example required by If-Match, zero meaning any version
+func (eapi *ExampleAPIRest) getVersion(preconditions api.Preconditions) (int64, error) {
+	switch preconditions.IfMatch {
+	case "":
+		if eapi.RequireIfMatch {
+			return 0, &api.PreconditionRequiredError{}
+		}
+		return 0, nil
+	case "*":
+		return 0, nil
+	}
+	version, err := api.ParseETag(preconditions.IfMatch)
+	if err != nil {
+		return 0, &usecase.PreconditionFailedError{Cause: err}
+	}
+	return version, nil
+}
+
+// reportChange reports the error of a change, where a missing Example fails If-Match
+func (eapi *ExampleAPIRest) reportChange(err error, preconditions api.Preconditions) api.Response {
+	var notExistsErr *usecase.NotExistsError
+	if preconditions.IfMatch != "" && errors.As(err, &notExistsErr) {
+		err = &usecase.PreconditionFailedError{Cause: err}
+	}
+	return report(err, eapi.TS.GetCurrentTime())
+}
+
+func (eapi *ExampleAPIRest) getPageRequest(pageParams api.PageParams) (model.PageRequest, error) {
+	pageRequest := model.PageRequest{
+		Limit:      pageParams.Limit,
+		Offset:     pageParams.Offset,
+		CountTotal: pageParams.Total,
+	}
+	expression, err := filter.Parse(pageParams.Filter, model.ExampleFields)
+	if err != nil {
+		return pageRequest, &api.ParamError{Param: "filter", Cause: err}
+	}
+	pageRequest.Filter = expression
+	if pageRequest.Sort, err = filter.ParseSort(pageParams.Sort, model.ExampleFields); err != nil {
+		return pageRequest, &api.ParamError{Param: "sort", Cause: err}
+	}
+	if pageRequest.Limit == 0 {
+		pageRequest.Limit = eapi.DefaultPageSize
+		if pageRequest.Limit == 0 {
+			pageRequest.Limit = DefaultPageSize
+		}
+	}
+	maxPageSize := eapi.MaxPageSize
+	if maxPageSize == 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+	if pageRequest.Limit > maxPageSize {
+		pageRequest.Limit = maxPageSize
+	}
+	if pageParams.Cursor != "" {
+		cursor, err := api.DecodeCursor(pageParams.Cursor)
+		if err != nil {
+			return pageRequest, &api.ParamError{Param: "cursor", Cause: errors.New("is not a valid cursor")}
+		}
+		pageRequest.AfterID = cursor.AfterID
+		pageRequest.BeforeID = cursor.BeforeID
+		if cursor.AfterID == 0 && cursor.BeforeID == 0 {
+			pageRequest.Offset = cursor.Offset
+		}
+	}
+	return pageRequest, nil
+}
+
+func toPage(examplePage *model.ExamplePage, pageRequest model.PageRequest) api.Page {
+	page := api.Page{
+		Items: examplePage.Examples,
+		Total: examplePage.Total,
+	}
+	if len(pageRequest.Sort) > 0 {
+		if examplePage.HasNext {
+			page.Next = api.EncodeCursor(api.Cursor{Offset: pageRequest.Offset + int64(len(examplePage.Examples))})
+		}
+		if examplePage.HasPrevious {
+			previous := pageRequest.Offset - int64(pageRequest.Limit)
+			if previous < 0 {
+				previous = 0
+			}
+			page.Previous = api.EncodeCursor(api.Cursor{Offset: previous})
+		}
+	} else if count := len(examplePage.Examples); count > 0 {
+		if examplePage.HasNext {
+			page.Next = api.EncodeCursor(api.Cursor{AfterID: examplePage.Examples[count-1].ID})
+		}
+		if examplePage.HasPrevious {
+			page.Previous = api.EncodeCursor(api.Cursor{BeforeID: examplePage.Examples[0].ID})
+		}
+	}
+	return page
+}
+
+// getPageValidators provides a weak ETag that changes with the version of any Example of the
+// page, and the moment of the last change among them
+func getPageValidators(examplePage *model.ExamplePage, page api.Page) (string, time.Time) {
+	hash := fnv.New64a()
+	var lastModified time.Time
+	for _, example := range examplePage.Examples {
+		fmt.Fprintf(hash, "%d:%d;", example.ID, example.Version)
+		if example.UpdatedAt.After(lastModified) {
+			lastModified = example.UpdatedAt
+		}
+	}
+	fmt.Fprintf(hash, "%s;%s", page.Next, page.Previous)
+	if page.Total != nil {
+		fmt.Fprintf(hash, ";%d", *page.Total)
+	}
+	return api.WeakETag(hash.Sum64()), lastModified
+}
+
+func report(err error, time time.Time) api.Response {
+	problem := getProblem(err, time)
+	return api.Response{
+		Code: problem.Status,
+		Body: problem,
+	}
+}
+
+func getProblem(err error, time time.Time) api.Problem {
+	var paramErr *api.ParamError
+	var notExistsErr *usecase.NotExistsError
+	var conflictErr *usecase.ConflictError
+	var validationErr *usecase.ValidationError
+	var preconditionFailedErr *usecase.PreconditionFailedError
+	var preconditionRequiredErr *api.PreconditionRequiredError
+	var batchAbortedErr *usecase.BatchAbortedError
+	var unavailableErr *usecase.UnavailableError
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		problem := api.NewProblem(api.ProblemTypeBlank, StatusClientClosedRequest, err.Error(), time)
+		problem.Title = "Client Closed Request"
+		return problem
+	case errors.Is(err, context.DeadlineExceeded):
+		return api.NewProblem(api.ProblemTypeBlank, http.StatusGatewayTimeout, err.Error(), time)
+	case errors.As(err, &paramErr):
+		problem := api.NewProblem(api.ProblemTypeInvalidParameter, http.StatusBadRequest, err.Error(), time)
+		problem.Errors = []api.FieldError{{Field: paramErr.Param, Message: paramErr.Cause.Error()}}
+		return problem
+	case errors.As(err, &preconditionFailedErr):
+		return api.NewProblem(api.ProblemTypePreconditionFailed, http.StatusPreconditionFailed, err.Error(), time)
+	case errors.As(err, &notExistsErr):
+		return api.NewProblem(api.ProblemTypeNotFound, http.StatusNotFound, err.Error(), time)
+	case errors.As(err, &conflictErr):
+		return api.NewProblem(api.ProblemTypeConflict, http.StatusConflict, err.Error(), time)
+	case errors.As(err, &validationErr):
+		problem := api.NewProblem(api.ProblemTypeValidation, http.StatusUnprocessableEntity, err.Error(), time)
+		for _, field := range validationErr.Fields {
+			problem.Errors = append(problem.Errors, api.FieldError{Field: field.Field, Message: field.Message})
+		}
+		return problem
+	case errors.As(err, &preconditionRequiredErr):
+		return api.NewProblem(api.ProblemTypePreconditionRequired, http.StatusPreconditionRequired, err.Error(), time)
+	case errors.As(err, &batchAbortedErr):
+		return api.NewProblem(api.ProblemTypeBatchAborted, http.StatusFailedDependency, err.Error(), time)
+	case errors.As(err, &unavailableErr):
+		log.Printf("Dependency unavailable: %v", err)
+		return api.NewProblem(api.ProblemTypeUnavailable, http.StatusServiceUnavailable, "A dependency of the service is unavailable", time)
 	}
-	return http.StatusBadRequest
+	log.Printf("Unexpected error: %v", err)
+	return api.NewProblem(api.ProblemTypeBlank, http.StatusInternalServerError, "An unexpected error occurred", time)
 }
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+// abandonTimeout limits the release of the key of a request that failed, which is done
+// even when the request was cancelled
+const abandonTimeout time.Duration = 5 * time.Second
+
+// replayableBodies lists the types of the bodies that are kept for the retries, by name
+var replayableBodies = map[string]reflect.Type{
+	typeName(api.Problem{}):   reflect.TypeOf(api.Problem{}),
+	typeName(model.Example{}): reflect.TypeOf(model.Example{}),
+}
+
+// IdempotencyAPIRest is responsible for implementing the IdempotencyAPI using HTTP REST
+// abstraction. The responses of the server errors are not kept, so that the requests can
+// be retried
+type IdempotencyAPIRest struct {
+	IUC usecase.IdempotencyUseCase
+	TS  chrono.TimeStamp
+}
+
+// Run runs the request at most once by REST abstraction
+func (iapi *IdempotencyAPIRest) Run(ctx context.Context, key string, fingerprint string, fn func(ctx context.Context) api.Response) api.Response {
+	previous, reservation, err := iapi.IUC.BeginRequest(ctx, key, fingerprint)
+	if err != nil {
+		return report(err, iapi.TS.GetCurrentTime())
+	}
+	if previous != nil {
+		response, err := toReplayedResponse(previous)
+		if err != nil {
+			return report(err, iapi.TS.GetCurrentTime())
+		}
+		return response
+	}
+
+	kept := false
+	defer func() {
+		if !kept {
+			iapi.abandon(key, reservation)
+		}
+	}()
+	response := fn(ctx)
+	if response.Code >= http.StatusInternalServerError || response.Code == StatusClientClosedRequest {
+		return response
+	}
+	request := &model.IdempotentRequest{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Code:        response.Code,
+		Path:        response.Path,
+		ETag:        response.ETag,
+	}
+	if response.Body != nil {
+		if _, ok := replayableBodies[typeName(response.Body)]; !ok {
+			log.Printf("Couldn't keep the response to an idempotent request, as its body is a %T", response.Body)
+			return response
+		}
+		if request.Body, err = json.Marshal(response.Body); err != nil {
+			log.Printf("Couldn't keep the response to an idempotent request: %v", err)
+			return response
+		}
+		request.BodyType = typeName(response.Body)
+	}
+	// the request is done, so that its key is not released even if the response is not kept,
+	// holding the key until the lease expires
+	kept = true
+	if err := iapi.IUC.CompleteRequest(ctx, request); err != nil {
+		log.Printf("Couldn't keep the response to an idempotent request: %v", err)
+	}
+	return response
+}
+
+func (iapi *IdempotencyAPIRest) abandon(key string, reservation string) {
+	ctx, cancel := context.WithTimeout(context.Background(), abandonTimeout)
+	defer cancel()
+	if err := iapi.IUC.AbandonRequest(ctx, key, reservation); err != nil {
+		log.Printf("Couldn't release the key of an idempotent request: %v", err)
+	}
+}
+
+// toReplayedResponse is responsible for providing again the response kept for a request
+func toReplayedResponse(request *model.IdempotentRequest) (api.Response, error) {
+	response := api.Response{
+		Code:     request.Code,
+		Path:     request.Path,
+		ETag:     request.ETag,
+		Replayed: true,
+	}
+	if request.BodyType == "" {
+		return response, nil
+	}
+	bodyType, ok := replayableBodies[request.BodyType]
+	if !ok {
+		return response, fmt.Errorf("Couldn't replay a body of type %s", request.BodyType)
+	}
+	body := reflect.New(bodyType)
+	if err := json.Unmarshal(request.Body, body.Interface()); err != nil {
+		return response, err
+	}
+	response.Body = body.Elem().Interface()
+	return response, nil
+}
+
+func typeName(value interface{}) string {
+	return fmt.Sprintf("%T", value)
+}
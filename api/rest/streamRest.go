@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+// ExampleStreamAPIRest is responsible for implementing the ExampleStreamAPI using HTTP REST
+// abstraction
+type ExampleStreamAPIRest struct {
+	ESUC usecase.ExampleStreamUseCase
+	TS   chrono.TimeStamp
+}
+
+// Subscribe provides the changes to the Examples by REST abstraction
+func (esapi *ExampleStreamAPIRest) Subscribe(ctx context.Context, params api.StreamParams) (api.ExampleSubscription, api.Response) {
+	var lastEventID uint64
+	if params.LastEventID != "" {
+		var err error
+		if lastEventID, err = strconv.ParseUint(params.LastEventID, 10, 64); err != nil {
+			return nil, report(&api.ParamError{Param: "Last-Event-ID", Cause: errors.New("must be a non-negative integer")}, esapi.TS.GetCurrentTime())
+		}
+	}
+	filter := model.ExampleEventFilter{IDs: params.IDs}
+	for _, name := range params.Types {
+		eventType, ok := toEventType(name)
+		if !ok {
+			return nil, report(&api.ParamError{Param: "type", Cause: fmt.Errorf("unknown type %q", name)}, esapi.TS.GetCurrentTime())
+		}
+		filter.Types = append(filter.Types, eventType)
+	}
+	subscription, err := esapi.ESUC.Subscribe(lastEventID, filter)
+	if err != nil {
+		return nil, report(err, esapi.TS.GetCurrentTime())
+	}
+	return subscription, api.Response{Code: http.StatusOK}
+}
+
+func toEventType(name string) (model.ExampleEventType, bool) {
+	for _, eventType := range model.ExampleEventTypes {
+		if string(eventType) == name {
+			return eventType, true
+		}
+	}
+	return "", false
+}
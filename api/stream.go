@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// ExampleStreamAPI contains the api methods to follow the changes to Examples
+type ExampleStreamAPI interface {
+	// Subscribe provides the changes to the Examples that pass the parameters, the Response
+	// reporting why there is no subscription when it is nil
+	Subscribe(ctx context.Context, params StreamParams) (ExampleSubscription, Response)
+}
+
+// ExampleSubscription delivers the changes to Examples to a subscriber
+type ExampleSubscription interface {
+	// Events provides the changes in the order they happened, being closed when the
+	// subscription is closed or dropped
+	Events() <-chan model.ExampleEvent
+	// Resumed reports whether every change after the LastEventID of the subscription is
+	// delivered, which fails when they were no longer kept
+	Resumed() bool
+	// Dropped reports whether the subscription was dropped for not keeping up with the changes
+	Dropped() bool
+	// Close ends the subscription
+	Close()
+}
+
+// StreamParams represents the parameters of a subscription to the changes to Examples
+type StreamParams struct {
+	// LastEventID is the ID of the last change received, after which the subscription resumes
+	LastEventID string
+	// Types lists the types of the changes delivered, every type when empty
+	Types []string
+	// IDs lists the identifiers of the Examples whose changes are delivered, every Example
+	// when empty
+	IDs []int64
+}
+
+// ExampleEventBody represents a change to an Example in the stream, where the Example is in
+// the representation of the version of the API
+type ExampleEventBody struct {
+	ID      uint64      `json:"id"`
+	Type    string      `json:"type"`
+	Time    time.Time   `json:"time"`
+	Example interface{} `json:"example"`
+}
+
+// StreamFilter represents the message by which the WebSocket clients replace the filter of
+// their subscription
+type StreamFilter struct {
+	Types []string `json:"types"`
+	IDs   []int64  `json:"ids"`
+}
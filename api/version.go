@@ -0,0 +1,80 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// Version represents a version of the API, selected by the first segment of the paths, with
+// the representation of the Example model it exchanges
+type Version struct {
+	// Name is the segment of the paths that selects the version, such as v2
+	Name string
+	// Representation converts the Example model to and from the bodies of the version
+	Representation ExampleRepresentation
+	// Deprecation is the moment from which the version is deprecated, which may be in the
+	// future, zero when it is not
+	Deprecation time.Time
+	// Sunset is the moment from which the version may no longer be provided, zero when it is
+	// not scheduled
+	Sunset time.Time
+}
+
+// ExampleRepresentation is responsible for converting the Example model to and from the
+// bodies exchanged by a version of the API
+type ExampleRepresentation interface {
+	// NewExample provides an empty Example of the version, to decode a request body into
+	NewExample() ExampleBody
+	// NewBatch provides an empty batch of the version, to decode a request body into
+	NewBatch() BatchBody
+	// FromExample provides the Example of the version corresponding to the model
+	FromExample(example model.Example) interface{}
+}
+
+// ExampleBody represents an Example decoded from a request of a version of the API
+type ExampleBody interface {
+	// ToExample provides the Example model corresponding to the body
+	ToExample() model.Example
+}
+
+// BatchBody represents a batch decoded from a request of a version of the API
+type BatchBody interface {
+	// ToBatch provides the BatchRequest corresponding to the body
+	ToBatch() BatchRequest
+}
+
+var (
+	// V1 is the version that keeps the representation of the Example provided before the
+	// versioning of the API
+	V1 = Version{Name: "v1", Representation: RepresentationV1{}}
+	// V2 is the version whose representation of the Example has snake_case properties
+	V2 = Version{Name: "v2", Representation: RepresentationV2{}}
+)
+
+// DefaultVersions is responsible for providing the versions of the API, without deprecations,
+// from the oldest, which serves the paths without a version
+func DefaultVersions() []Version {
+	return []Version{V1, V2}
+}
+
+// PropertyNames is responsible for providing the names of the properties of the Example in
+// the representation, by the names of the fields of the Example model they correspond to
+func PropertyNames(representation ExampleRepresentation) map[string]string {
+	names := map[string]string{}
+	exampleType := reflect.TypeOf(representation.FromExample(model.Example{}))
+	for i := 0; i < exampleType.NumField(); i++ {
+		field := exampleType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[field.Name] = name
+	}
+	return names
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+// DefaultAPIKeyCacheSize is the maximum number of keys cached when MaxEntries is not informed
+const DefaultAPIKeyCacheSize int = 1000
+
+// APIKeyVerifier is responsible for authenticating principals by API key, caching the keys
+// authenticated for TTL, so that a revocation takes effect within TTL. The cache is keyed by
+// the hash of the keys, which are never kept
+type APIKeyVerifier struct {
+	AKUC usecase.APIKeyUseCase
+	TTL  time.Duration
+	// MaxEntries limits the number of keys cached, DefaultAPIKeyCacheSize when zero
+	MaxEntries int
+	TS         chrono.TimeStamp
+
+	mutex sync.Mutex
+	cache map[[sha256.Size]byte]cachedPrincipal
+}
+
+type cachedPrincipal struct {
+	principal *Principal
+	expiresAt time.Time
+}
+
+// Verify is responsible for providing the principal of the API key, whose subject is
+// apikey:<ID>
+func (verifier *APIKeyVerifier) Verify(ctx context.Context, key string) (*Principal, error) {
+	hash := sha256.Sum256([]byte(key))
+	now := verifier.TS.GetCurrentTime()
+	if principal, ok := verifier.cached(hash, now); ok {
+		return principal, nil
+	}
+
+	apiKey, err := verifier.AKUC.AuthenticateAPIKey(ctx, key)
+	var invalidErr *usecase.InvalidCredentialsError
+	if errors.As(err, &invalidErr) {
+		return nil, &Error{Cause: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	principal := &Principal{
+		Subject: fmt.Sprintf("apikey:%d", apiKey.ID),
+		Scopes:  apiKey.Scopes,
+		Claims:  map[string]interface{}{"name": apiKey.Name, "prefix": apiKey.Prefix},
+	}
+	expiresAt := now.Add(verifier.TTL)
+	if !apiKey.ExpiresAt.IsZero() && apiKey.ExpiresAt.Before(expiresAt) {
+		expiresAt = apiKey.ExpiresAt
+	}
+	verifier.store(hash, cachedPrincipal{principal: principal, expiresAt: expiresAt}, now)
+	return principal, nil
+}
+
+func (verifier *APIKeyVerifier) cached(hash [sha256.Size]byte, now time.Time) (*Principal, bool) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	entry, ok := verifier.cache[hash]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.principal, true
+}
+
+// store is responsible for caching the principal, removing the expired entries when the
+// cache is full and, when none expired, an arbitrary one
+func (verifier *APIKeyVerifier) store(hash [sha256.Size]byte, entry cachedPrincipal, now time.Time) {
+	if verifier.TTL <= 0 {
+		return
+	}
+	maxEntries := verifier.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultAPIKeyCacheSize
+	}
+
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	if verifier.cache == nil {
+		verifier.cache = map[[sha256.Size]byte]cachedPrincipal{}
+	}
+	if len(verifier.cache) >= maxEntries {
+		for key, current := range verifier.cache {
+			if !now.Before(current.expiresAt) {
+				delete(verifier.cache, key)
+			}
+		}
+	}
+	for key := range verifier.cache {
+		if len(verifier.cache) < maxEntries {
+			break
+		}
+		delete(verifier.cache, key)
+	}
+	verifier.cache[hash] = entry
+}
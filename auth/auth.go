@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Principal represents the authenticated client of a request
+type Principal struct {
+	Subject string
+	// Scopes lists the operations the principal was granted
+	Scopes []string
+	// Claims holds every claim of the token that authenticated the principal
+	Claims map[string]interface{}
+}
+
+// HasScopes is responsible for checking that the principal was granted all the scopes
+func (principal *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		granted := false
+		for _, current := range principal.Scopes {
+			granted = granted || current == scope
+		}
+		if !granted {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+// WithPrincipal is responsible for providing a copy of the context holding the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// GetPrincipal is responsible for providing the principal of the request the context
+// belongs to, nil when the request is anonymous
+func GetPrincipal(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// Error must be reported when a token cannot authenticate a principal
+type Error struct {
+	Cause error
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("Invalid token: %v", err.Cause)
+}
+
+// Unwrap provides the cause of the error
+func (err *Error) Unwrap() error {
+	return err.Cause
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"crypto/x509"
+)
+
+// CertificatePrincipal is responsible for providing the principal of a verified client
+// certificate, whose subject is the distinguished name of the certificate, such as
+// CN=client,O=Example. The scopes are granted by subject
+func CertificatePrincipal(certificate *x509.Certificate, scopes map[string][]string) *Principal {
+	subject := certificate.Subject.String()
+	return &Principal{
+		Subject: subject,
+		Scopes:  scopes[subject],
+		Claims: map[string]interface{}{
+			"cn":     certificate.Subject.CommonName,
+			"issuer": certificate.Issuer.String(),
+			"serial": certificate.SerialNumber.String(),
+		},
+	}
+}
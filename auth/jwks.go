@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+const (
+	// DefaultRefreshInterval is the interval between the fetches of a RemoteJWKS when
+	// RefreshInterval is not informed
+	DefaultRefreshInterval time.Duration = 15 * time.Minute
+	// minRefetchInterval is the minimum interval between the fetches of a RemoteJWKS caused by
+	// unknown key IDs
+	minRefetchInterval time.Duration = 30 * time.Second
+	// minRetryInterval is the backoff after the first failed fetch of a RemoteJWKS, doubled by
+	// each failure up to its refresh interval
+	minRetryInterval time.Duration = time.Second
+)
+
+// KeySet provides the keys that verify the signatures of the tokens: []byte for HS256,
+// *rsa.PublicKey for RS256 and *ecdsa.PublicKey for ES256
+type KeySet interface {
+	// Key provides the key with the ID for the algorithm
+	Key(ctx context.Context, keyID string, algorithm string) (interface{}, error)
+}
+
+// HMACSecret is the secret shared with the issuer of the HS256 tokens
+type HMACSecret []byte
+
+// Key provides the secret for HS256
+func (secret HMACSecret) Key(ctx context.Context, keyID string, algorithm string) (interface{}, error) {
+	if algorithm != HS256 {
+		return nil, errors.Errorf("no key for %s", algorithm)
+	}
+	return []byte(secret), nil
+}
+
+// KeySets tries every KeySet, in order, until one provides the key
+type KeySets []KeySet
+
+// Key provides the first key found
+func (keySets KeySets) Key(ctx context.Context, keyID string, algorithm string) (interface{}, error) {
+	err := errors.Errorf("no key for %s", algorithm)
+	for _, keySet := range keySets {
+		var key interface{}
+		if key, err = keySet.Key(ctx, keyID, algorithm); err == nil {
+			return key, nil
+		}
+	}
+	return nil, err
+}
+
+// JWK represents a JSON Web Key (RFC 7517) of type RSA, EC or oct
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	K         string `json:"k,omitempty"`
+}
+
+// JWKS represents a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ReadJWKS is responsible for reading a JWKS from a local file
+func ReadJWKS(fileName string) (*JWKS, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var jwks JWKS
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, errors.Wrapf(err, "malformed JWKS %s", fileName)
+	}
+	return &jwks, nil
+}
+
+// Key provides the key with the ID, or the only key usable for the algorithm when the token
+// does not inform its ID
+func (jwks *JWKS) Key(ctx context.Context, keyID string, algorithm string) (interface{}, error) {
+	var found interface{}
+	for _, jwk := range jwks.Keys {
+		if (keyID != "" && jwk.KeyID != keyID) || (jwk.Algorithm != "" && jwk.Algorithm != algorithm) || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil || !usableFor(key, algorithm) {
+			continue
+		}
+		if found != nil {
+			return nil, errors.Errorf("the key of the token cannot be told apart, kid is required")
+		}
+		found = key
+	}
+	if found == nil {
+		return nil, errors.Errorf("no key %q for %s", keyID, algorithm)
+	}
+	return found, nil
+}
+
+func usableFor(key interface{}, algorithm string) bool {
+	switch key.(type) {
+	case []byte:
+		return algorithm == HS256
+	case *rsa.PublicKey:
+		return algorithm == RS256
+	case *ecdsa.PublicKey:
+		return algorithm == ES256
+	}
+	return false
+}
+
+func (jwk *JWK) publicKey() (interface{}, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := decodeInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if jwk.Curve != "P-256" {
+			return nil, errors.Errorf("unsupported curve %q", jwk.Curve)
+		}
+		x, err := decodeInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("the point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case "oct":
+		return base64.RawURLEncoding.DecodeString(jwk.K)
+	}
+	return nil, errors.Errorf("unsupported key type %q", jwk.KeyType)
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// RemoteJWKS is responsible for providing the keys of a JWKS published by the issuer, fetched
+// again when older than RefreshInterval or when a token informs an unknown key ID. The fetches
+// happen one at a time, without holding the verifications, which keep using the last JWKS
+// fetched while the issuer cannot be reached, retried with an exponential backoff
+type RemoteJWKS struct {
+	URL    string
+	Client *http.Client
+	// RefreshInterval is how long the JWKS is used before it is fetched again,
+	// DefaultRefreshInterval when zero and never less than minRefetchInterval
+	RefreshInterval time.Duration
+	TS              chrono.TimeStamp
+
+	mutex     sync.RWMutex
+	jwks      *JWKS
+	attempted time.Time
+	failures  int
+	lastErr   error
+	fetching  chan struct{}
+}
+
+// Key provides the key of the last JWKS fetched
+func (remote *RemoteJWKS) Key(ctx context.Context, keyID string, algorithm string) (interface{}, error) {
+	now := remote.TS.GetCurrentTime()
+	remote.mutex.RLock()
+	jwks, due, lastErr := remote.jwks, remote.due(now, remote.refreshInterval()), remote.lastErr
+	remote.mutex.RUnlock()
+
+	if jwks == nil && !due {
+		return nil, lastErr
+	}
+	if due {
+		var err error
+		if jwks, err = remote.refresh(ctx, now); jwks == nil {
+			return nil, err
+		}
+	}
+	key, err := jwks.Key(ctx, keyID, algorithm)
+	if err == nil {
+		return key, nil
+	}
+	remote.mutex.RLock()
+	due = remote.due(now, minRefetchInterval)
+	remote.mutex.RUnlock()
+	if !due {
+		return nil, err
+	}
+	refreshed, fetchErr := remote.refresh(ctx, now)
+	if refreshed == jwks {
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return nil, err
+	}
+	return refreshed.Key(ctx, keyID, algorithm)
+}
+
+// refreshInterval is responsible for providing the interval between the fetches of the JWKS
+func (remote *RemoteJWKS) refreshInterval() time.Duration {
+	if remote.RefreshInterval <= 0 {
+		return DefaultRefreshInterval
+	}
+	if remote.RefreshInterval < minRefetchInterval {
+		return minRefetchInterval
+	}
+	return remote.RefreshInterval
+}
+
+// due is responsible for checking whether the JWKS may be fetched again, interval after the last
+// attempt when it succeeded, and after the backoff of the failures otherwise
+func (remote *RemoteJWKS) due(now time.Time, interval time.Duration) bool {
+	if remote.failures > 0 {
+		backoff := minRetryInterval << uint(remote.failures-1)
+		if backoff <= 0 || backoff > remote.refreshInterval() {
+			backoff = remote.refreshInterval()
+		}
+		interval = backoff
+	}
+	return !now.Before(remote.attempted.Add(interval))
+}
+
+// refresh is responsible for fetching the JWKS, unless another request is already fetching it,
+// in which case the JWKS in use is provided, or waited for when there is none yet
+func (remote *RemoteJWKS) refresh(ctx context.Context, now time.Time) (*JWKS, error) {
+	remote.mutex.Lock()
+	if fetching := remote.fetching; fetching != nil {
+		jwks := remote.jwks
+		remote.mutex.Unlock()
+		if jwks != nil {
+			return jwks, nil
+		}
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		remote.mutex.RLock()
+		defer remote.mutex.RUnlock()
+		return remote.jwks, remote.lastErr
+	}
+	fetching := make(chan struct{})
+	remote.fetching = fetching
+	remote.mutex.Unlock()
+
+	jwks, err := remote.fetch(ctx)
+
+	remote.mutex.Lock()
+	defer remote.mutex.Unlock()
+	remote.attempted = now
+	remote.lastErr = err
+	if err != nil {
+		remote.failures++
+	} else {
+		remote.jwks, remote.failures = jwks, 0
+	}
+	remote.fetching = nil
+	close(fetching)
+	return remote.jwks, err
+}
+
+func (remote *RemoteJWKS) fetch(ctx context.Context) (*JWKS, error) {
+	client := remote.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	request, err := http.NewRequest(http.MethodGet, remote.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't fetch the JWKS")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("couldn't fetch the JWKS, status %d", response.StatusCode)
+	}
+	var jwks JWKS
+	if err := json.NewDecoder(response.Body).Decode(&jwks); err != nil {
+		return nil, errors.Wrap(err, "malformed JWKS")
+	}
+	return &jwks, nil
+}
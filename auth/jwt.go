@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+const (
+	// HS256 identifies the signatures with HMAC using SHA-256
+	HS256 string = "HS256"
+	// RS256 identifies the signatures with RSASSA-PKCS1-v1_5 using SHA-256
+	RS256 string = "RS256"
+	// ES256 identifies the signatures with ECDSA using P-256 and SHA-256
+	ES256 string = "ES256"
+)
+
+// Verifier is responsible for authenticating principals by JWT (RFC 7519), signed with
+// HS256, RS256 or ES256
+type Verifier struct {
+	Keys KeySet
+	// Issuer is the iss the tokens must have, empty accepting any issuer
+	Issuer string
+	// Audience must be one of the aud of the tokens, empty accepting any audience
+	Audience string
+	// Leeway tolerates the clock skew when checking exp and nbf
+	Leeway time.Duration
+	TS     chrono.TimeStamp
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verify is responsible for checking the signature and the claims of the token, providing the
+// principal it authenticates
+func (verifier *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, &Error{Cause: errors.New("a JWT must have 3 parts")}
+	}
+	var tokenHeader header
+	if err := decodePart(parts[0], &tokenHeader); err != nil {
+		return nil, &Error{Cause: errors.Wrap(err, "malformed header")}
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, &Error{Cause: errors.Wrap(err, "malformed signature")}
+	}
+	key, err := verifier.Keys.Key(ctx, tokenHeader.KeyID, tokenHeader.Algorithm)
+	if err != nil {
+		return nil, &Error{Cause: err}
+	}
+	if err := verifySignature(tokenHeader.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, &Error{Cause: err}
+	}
+
+	var claims map[string]interface{}
+	if err := decodePart(parts[1], &claims); err != nil {
+		return nil, &Error{Cause: errors.Wrap(err, "malformed claims")}
+	}
+	if err := verifier.checkClaims(claims); err != nil {
+		return nil, &Error{Cause: err}
+	}
+	subject, _ := claims["sub"].(string)
+	return &Principal{Subject: subject, Scopes: getScopes(claims), Claims: claims}, nil
+}
+
+func (verifier *Verifier) checkClaims(claims map[string]interface{}) error {
+	now := verifier.TS.GetCurrentTime()
+	expiration, ok := claims["exp"].(float64)
+	if !ok {
+		return errors.New("exp is required")
+	}
+	if !now.Before(time.Unix(int64(expiration), 0).Add(verifier.Leeway)) {
+		return errors.New("the token expired")
+	}
+	if notBefore, ok := claims["nbf"].(float64); ok && now.Add(verifier.Leeway).Before(time.Unix(int64(notBefore), 0)) {
+		return errors.New("the token is not valid yet")
+	}
+	if issuer, _ := claims["iss"].(string); verifier.Issuer != "" && issuer != verifier.Issuer {
+		return errors.Errorf("unexpected issuer %q", issuer)
+	}
+	if verifier.Audience != "" && !hasAudience(claims["aud"], verifier.Audience) {
+		return errors.Errorf("the token is not intended for %s", verifier.Audience)
+	}
+	return nil
+}
+
+func hasAudience(claim interface{}, audience string) bool {
+	switch value := claim.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, item := range value {
+			if item == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getScopes is responsible for providing the scopes of the space-separated scope claim
+// (RFC 8693) or, in its absence, of the scp array
+func getScopes(claims map[string]interface{}) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	var scopes []string
+	if items, ok := claims["scp"].([]interface{}); ok {
+		for _, item := range items {
+			if scope, ok := item.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes
+}
+
+// verifySignature is responsible for checking the signature with the key, whose type must
+// match the algorithm so that a public key cannot be used as an HMAC secret
+func verifySignature(algorithm string, key interface{}, signingInput string, signature []byte) error {
+	hash := sha256.Sum256([]byte(signingInput))
+	switch algorithm {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return errors.Errorf("%s requires an HMAC secret", algorithm)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid signature")
+		}
+	case RS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.Errorf("%s requires an RSA key", algorithm)
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], signature); err != nil {
+			return errors.New("invalid signature")
+		}
+	case ES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve.Params().BitSize != 256 {
+			return errors.Errorf("%s requires a P-256 key", algorithm)
+		}
+		if len(signature) != 64 {
+			return errors.New("invalid signature")
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(publicKey, hash[:], r, s) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return errors.Errorf("unsupported algorithm %q", algorithm)
+	}
+	return nil
+}
+
+func decodePart(part string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
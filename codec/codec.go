@@ -0,0 +1,265 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Codec is responsible for encoding the bodies of the responses and decoding the bodies of
+// the requests in a media type
+type Codec interface {
+	// MediaTypes lists the media types the codec handles, the first being the one it produces
+	MediaTypes() []string
+	// ContentType is the Content-Type of the bodies the codec encodes
+	ContentType() string
+	// Encode is responsible for writing the representation of the value
+	Encode(writer io.Writer, value interface{}) error
+	// Decode is responsible for reading the representation of the value
+	Decode(reader io.Reader, value interface{}) error
+}
+
+// Registry is responsible for selecting the codecs by the Accept and Content-Type headers,
+// the first codec being the default
+type Registry struct {
+	Codecs []Codec
+	// Listings holds the codecs offered only for listings, which only encode
+	Listings []Codec
+}
+
+// Default is the registry of the codecs of JSON, XML, YAML, MessagePack and, for the
+// listings, CSV
+var Default = &Registry{
+	Codecs:   []Codec{JSON{}, XML{}, YAML{}, MessagePack{}},
+	Listings: []Codec{CSV{}},
+}
+
+// Negotiate is responsible for selecting the codec of the media type the client prefers,
+// according to the quality values of the Accept header (RFC 7231), the order of the codecs
+// breaking ties. An empty Accept selects the default codec
+func (registry *Registry) Negotiate(accept string, listing bool) (Codec, bool) {
+	candidates := registry.Codecs
+	if listing {
+		candidates = append(append([]Codec{}, registry.Codecs...), registry.Listings...)
+	}
+	if strings.TrimSpace(accept) == "" {
+		return candidates[0], true
+	}
+	ranges := parseAccept(accept)
+	var best Codec
+	bestQuality := 0.0
+	for _, codec := range candidates {
+		for _, mediaType := range codec.MediaTypes() {
+			if quality := getQuality(ranges, mediaType); quality > bestQuality {
+				best, bestQuality = codec, quality
+			}
+		}
+	}
+	return best, best != nil
+}
+
+// Lookup is responsible for providing the codec that decodes the media type, the default
+// codec decoding the bodies without media type
+func (registry *Registry) Lookup(mediaType string) (Codec, bool) {
+	if mediaType == "" {
+		return registry.Codecs[0], true
+	}
+	for _, codec := range registry.Codecs {
+		for _, current := range codec.MediaTypes() {
+			if strings.EqualFold(current, mediaType) {
+				return codec, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// MediaTypes lists the media types produced by the codecs, those of Listings included when
+// listing
+func (registry *Registry) MediaTypes(listing bool) []string {
+	var mediaTypes []string
+	for _, codec := range registry.Codecs {
+		mediaTypes = append(mediaTypes, codec.MediaTypes()[0])
+	}
+	if listing {
+		for _, codec := range registry.Listings {
+			mediaTypes = append(mediaTypes, codec.MediaTypes()[0])
+		}
+	}
+	return mediaTypes
+}
+
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, item := range strings.Split(accept, ",") {
+		params := strings.Split(item, ";")
+		current := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		for _, param := range params[1:] {
+			name, value := param, ""
+			if i := strings.Index(param, "="); i >= 0 {
+				name, value = param[:i], param[i+1:]
+			}
+			if strings.TrimSpace(strings.ToLower(name)) != "q" {
+				continue
+			}
+			if quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				current.quality = quality
+			}
+		}
+		if current.mediaType != "" {
+			ranges = append(ranges, current)
+		}
+	}
+	return ranges
+}
+
+// getQuality is responsible for providing the quality of the most specific range that
+// matches the media type, zero when none does
+func getQuality(ranges []mediaRange, mediaType string) float64 {
+	mediaType = strings.ToLower(mediaType)
+	mainType := mediaType[:strings.Index(mediaType+"/", "/")]
+	quality, specificity := 0.0, -1
+	for _, current := range ranges {
+		matched := -1
+		switch current.mediaType {
+		case mediaType:
+			matched = 2
+		case mainType + "/*":
+			matched = 1
+		case "*/*":
+			matched = 0
+		}
+		if matched > specificity {
+			quality, specificity = current.quality, matched
+		}
+	}
+	return quality
+}
+
+// toTree is responsible for providing the JSON representation of the value as maps, slices
+// and scalars, so that every codec names the fields by their json tags as JSON does
+func toTree(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return normalize(tree), nil
+}
+
+// fromTree is responsible for filling the value with the tree decoded by a codec, as JSON
+// would fill it
+func fromTree(tree interface{}, value interface{}) error {
+	data, err := json.Marshal(normalize(tree))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// normalize is responsible for replacing the numbers of JSON by int64 or float64 and the
+// keys of the maps by strings
+func normalize(tree interface{}) interface{} {
+	switch value := tree.(type) {
+	case json.Number:
+		if integer, err := value.Int64(); err == nil {
+			return integer
+		}
+		if float, err := value.Float64(); err == nil {
+			return float
+		}
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = normalize(item)
+		}
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = normalize(item)
+		}
+		return converted
+	case []interface{}:
+		for i, item := range value {
+			value[i] = normalize(item)
+		}
+	}
+	return tree
+}
+
+func sortedKeys(value map[string]interface{}) []string {
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type field struct {
+	name string
+	t    reflect.Type
+}
+
+// getFields is responsible for providing the fields of the struct in the order they are
+// declared, as named by JSON
+func getFields(t reflect.Type) []field {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		current := t.Field(i)
+		name := strings.Split(current.Tag.Get("json"), ",")[0]
+		switch {
+		case current.Anonymous && name == "":
+			fields = append(fields, getFields(current.Type)...)
+		case current.PkgPath != "" || name == "-":
+		case name != "":
+			fields = append(fields, field{name: name, t: current.Type})
+		default:
+			fields = append(fields, field{name: current.Name, t: current.Type})
+		}
+	}
+	return fields
+}
+
+// JSON is responsible for the bodies in JSON (RFC 8259)
+type JSON struct{}
+
+// MediaTypes lists application/json
+func (JSON) MediaTypes() []string {
+	return []string{"application/json"}
+}
+
+// ContentType is application/json
+func (JSON) ContentType() string {
+	return "application/json"
+}
+
+// Encode is responsible for writing the value in JSON
+func (JSON) Encode(writer io.Writer, value interface{}) error {
+	return json.NewEncoder(writer).Encode(value)
+}
+
+// Decode is responsible for reading the value in JSON
+func (JSON) Decode(reader io.Reader, value interface{}) error {
+	return json.NewDecoder(reader).Decode(value)
+}
@@ -0,0 +1,144 @@
+package codec
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// maxDepth limits the nesting of the MessagePack documents decoded
+const maxDepth int = 64
+
+// MessagePack is responsible for the bodies in MessagePack, encoded and decoded by
+// github.com/vmihailenco/msgpack, the fields being named by their json tags as in JSON. The
+// timestamps (extension -1) are decoded as RFC 3339 times
+type MessagePack struct{}
+
+// MediaTypes lists application/msgpack and its unregistered variants
+func (MessagePack) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+// ContentType is application/msgpack
+func (MessagePack) ContentType() string {
+	return "application/msgpack"
+}
+
+// Encode is responsible for writing the value in MessagePack, the keys of the maps sorted and
+// the integers in their shortest format
+func (MessagePack) Encode(writer io.Writer, value interface{}) error {
+	tree, err := toTree(value)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	encoder := msgpack.NewEncoder(&buffer)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	if err := encoder.Encode(tree); err != nil {
+		return err
+	}
+	_, err = writer.Write(buffer.Bytes())
+	return err
+}
+
+// Decode is responsible for reading the value in MessagePack
+func (MessagePack) Decode(reader io.Reader, value interface{}) error {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	source := bytes.NewReader(data)
+	tree, err := decodeMessagePack(msgpack.NewDecoder(source), 0)
+	if err != nil {
+		return err
+	}
+	if source.Len() != 0 {
+		return errors.New("unexpected data after the MessagePack document")
+	}
+	return fromTree(tree, value)
+}
+
+// decodeMessagePack is responsible for decoding the arrays and the maps within maxDepth, the
+// library decoding the scalars, so that neither the nesting nor the lengths announced by
+// the documents exhaust the stack or the memory
+func decodeMessagePack(decoder *msgpack.Decoder, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("the MessagePack document is too deep")
+	}
+	code, err := decoder.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msgpcode.IsFixedArray(code), code == msgpcode.Array16, code == msgpcode.Array32:
+		return decodeArray(decoder, depth)
+	case msgpcode.IsFixedMap(code), code == msgpcode.Map16, code == msgpcode.Map32:
+		return decodeMap(decoder, depth)
+	case code == msgpcode.Bin8, code == msgpcode.Bin16, code == msgpcode.Bin32:
+		return decoder.DecodeBytes()
+	}
+	value, err := decoder.DecodeInterfaceLoose()
+	if err != nil {
+		return nil, err
+	}
+	switch current := value.(type) {
+	case uint64:
+		if current > math.MaxInt64 {
+			return float64(current), nil
+		}
+		return int64(current), nil
+	case time.Time:
+		return current.UTC(), nil
+	}
+	return value, nil
+}
+
+func decodeArray(decoder *msgpack.Decoder, depth int) (interface{}, error) {
+	length, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+	// the items are appended as they are decoded, the length being untrusted
+	items := []interface{}{}
+	for i := 0; i < length; i++ {
+		item, err := decodeMessagePack(decoder, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func decodeMap(decoder *msgpack.Decoder, depth int) (interface{}, error) {
+	length, err := decoder.DecodeMapLen()
+	if err != nil {
+		return nil, err
+	}
+	fields := map[interface{}]interface{}{}
+	for i := 0; i < length; i++ {
+		key, err := decodeMessagePack(decoder, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		switch current := key.(type) {
+		case []byte:
+			key = string(current)
+		case []interface{}, map[interface{}]interface{}:
+			return nil, errors.New("the keys of the MessagePack maps must be scalars")
+		}
+		value, err := decodeMessagePack(decoder, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = value
+	}
+	return fields, nil
+}
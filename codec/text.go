@@ -0,0 +1,389 @@
+package codec
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// XML is responsible for the bodies in XML, mapped to the JSON representation of the values:
+// an element per property, named after it, and an item element per array item, the null
+// properties being left out. The properties whose names are not XML names are entry elements
+// with the name in their key attribute
+type XML struct{}
+
+// MediaTypes lists application/xml and text/xml
+func (XML) MediaTypes() []string {
+	return []string{"application/xml", "text/xml"}
+}
+
+// ContentType is application/xml
+func (XML) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+// Encode is responsible for writing the value in XML, in an element named after its type
+func (XML) Encode(writer io.Writer, value interface{}) error {
+	tree, err := toTree(value)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	if err := writeElement(encoder, rootName(value), tree); err != nil {
+		return err
+	}
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, "\n")
+	return err
+}
+
+// Decode is responsible for reading the value in XML, whatever the name of the root element,
+// the text of the elements being converted to the types of the properties they fill
+func (XML) Decode(reader io.Reader, value interface{}) error {
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if _, ok := token.(xml.StartElement); ok {
+			break
+		}
+	}
+	tree, err := readElement(decoder, 0)
+	if err != nil {
+		return err
+	}
+	return fromTree(coerce(tree, reflect.TypeOf(value)), value)
+}
+
+// versionSuffix matches the names of the types that end with a version, such as ExampleV2
+var versionSuffix = regexp.MustCompile(`^(.*[a-z])V[0-9]+$`)
+
+// rootName is responsible for naming the root element after the type of the value, such as
+// example for model.Example, dropping the version suffix of types such as api.ExampleV2
+func rootName(value interface{}) string {
+	t := reflect.TypeOf(value)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Name() == "" {
+		return "response"
+	}
+	name := []rune(versionSuffix.ReplaceAllString(t.Name(), "$1"))
+	name[0] = unicode.ToLower(name[0])
+	return string(name)
+}
+
+func writeElement(encoder *xml.Encoder, name string, tree interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !isXMLName(name) {
+		start = xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}}}
+	}
+	switch value := tree.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(value) {
+			if err := writeElement(encoder, key, value[key]); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case []interface{}:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, item := range value {
+			if err := writeElement(encoder, "item", item); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+	return encoder.EncodeElement(formatScalar(tree), start)
+}
+
+// readElement is responsible for reading the content of the element just started as a map of
+// its child elements, an array of its item elements or else its text
+func readElement(decoder *xml.Decoder, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, errors.New("the XML document is too deep")
+	}
+	var text strings.Builder
+	var items []interface{}
+	children := map[string]interface{}{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch current := token.(type) {
+		case xml.StartElement:
+			child, err := readElement(decoder, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			name := current.Name.Local
+			for _, attr := range current.Attr {
+				if name == "entry" && attr.Name.Local == "key" {
+					name = attr.Value
+				}
+			}
+			if name == "item" {
+				items = append(items, child)
+			} else {
+				children[name] = child
+			}
+		case xml.CharData:
+			text.Write(current)
+		case xml.EndElement:
+			switch {
+			case len(children) > 0:
+				if items != nil {
+					children["item"] = items
+				}
+				return children, nil
+			case items != nil:
+				return items, nil
+			}
+			return text.String(), nil
+		}
+	}
+}
+
+// coerce is responsible for converting the texts of the tree decoded from XML to the types
+// of the properties of the value of the type, as named by JSON
+func coerce(tree interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return tree
+	}
+	text, isText := tree.(string)
+	switch t.Kind() {
+	case reflect.Bool:
+		if value, err := strconv.ParseBool(strings.TrimSpace(text)); isText && err == nil {
+			return value
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if isText {
+			return json.Number(strings.TrimSpace(text))
+		}
+	case reflect.Slice, reflect.Array:
+		if isText && strings.TrimSpace(text) == "" {
+			return []interface{}{}
+		}
+		if items, ok := tree.([]interface{}); ok {
+			for i, item := range items {
+				items[i] = coerce(item, t.Elem())
+			}
+		}
+	case reflect.Map, reflect.Struct:
+		if isText && strings.TrimSpace(text) == "" {
+			return map[string]interface{}{}
+		}
+		properties, ok := tree.(map[string]interface{})
+		if !ok {
+			return tree
+		}
+		if t.Kind() == reflect.Map {
+			for name, property := range properties {
+				properties[name] = coerce(property, t.Elem())
+			}
+			return tree
+		}
+		for _, field := range getFields(t) {
+			for name, property := range properties {
+				if strings.EqualFold(name, field.name) {
+					properties[name] = coerce(property, field.t)
+				}
+			}
+		}
+	}
+	return tree
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func isXMLName(name string) bool {
+	for i, r := range name {
+		if !unicode.IsLetter(r) && r != '_' && (i == 0 || (!unicode.IsDigit(r) && r != '-' && r != '.')) {
+			return false
+		}
+	}
+	return name != "" && !strings.HasPrefix(strings.ToLower(name), "xml")
+}
+
+func formatScalar(value interface{}) string {
+	switch scalar := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return scalar
+	case float64:
+		return strconv.FormatFloat(scalar, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// YAML is responsible for the bodies in YAML, the fields being named by their json tags as
+// in JSON
+type YAML struct{}
+
+// MediaTypes lists application/yaml and its unregistered variants
+func (YAML) MediaTypes() []string {
+	return []string{"application/yaml", "application/x-yaml", "text/yaml"}
+}
+
+// ContentType is application/yaml
+func (YAML) ContentType() string {
+	return "application/yaml; charset=utf-8"
+}
+
+// Encode is responsible for writing the value in YAML
+func (YAML) Encode(writer io.Writer, value interface{}) error {
+	tree, err := toTree(value)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// Decode is responsible for reading the value in YAML
+func (YAML) Decode(reader io.Reader, value interface{}) error {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	return fromTree(tree, value)
+}
+
+// CSV is responsible for the listings in CSV (RFC 4180), with a header row naming the fields
+// of the items and a row per item, where the nested values are written in JSON
+type CSV struct{}
+
+// MediaTypes lists text/csv
+func (CSV) MediaTypes() []string {
+	return []string{"text/csv"}
+}
+
+// ContentType is text/csv with a header row
+func (CSV) ContentType() string {
+	return "text/csv; charset=utf-8; header=present"
+}
+
+// Encode is responsible for writing the items of the listing, which is a slice or a struct
+// with a slice in the items field, such as api.Page
+func (CSV) Encode(writer io.Writer, value interface{}) error {
+	items, itemType, err := getItems(value)
+	if err != nil {
+		return err
+	}
+	tree, err := toTree(items)
+	if err != nil {
+		return err
+	}
+	rows, _ := tree.([]interface{})
+	var columns []string
+	for _, field := range getFields(itemType) {
+		columns = append(columns, field.name)
+	}
+	if columns == nil {
+		columns = getColumns(rows)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fields, _ := row.(map[string]interface{})
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			switch field := fields[column].(type) {
+			case map[string]interface{}, []interface{}:
+				data, _ := json.Marshal(field)
+				record[i] = string(data)
+			default:
+				record[i] = formatScalar(field)
+			}
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Decode is not supported, as CSV only represents listings
+func (CSV) Decode(reader io.Reader, value interface{}) error {
+	return errors.New("CSV bodies are not supported")
+}
+
+// getItems is responsible for providing the items of a listing along with their type
+func getItems(value interface{}) (interface{}, reflect.Type, error) {
+	listing := reflect.ValueOf(value)
+	for listing.Kind() == reflect.Ptr || listing.Kind() == reflect.Interface {
+		listing = listing.Elem()
+	}
+	if listing.Kind() == reflect.Struct {
+		field, ok := listing.Type().FieldByName("Items")
+		if !ok {
+			return nil, nil, errors.Errorf("CSV only represents listings, not %s", listing.Type())
+		}
+		listing = listing.FieldByIndex(field.Index)
+		for listing.Kind() == reflect.Ptr || listing.Kind() == reflect.Interface {
+			listing = listing.Elem()
+		}
+	}
+	if listing.Kind() != reflect.Slice && listing.Kind() != reflect.Array {
+		return nil, nil, errors.New("CSV only represents listings")
+	}
+	return listing.Interface(), listing.Type().Elem(), nil
+}
+
+// getColumns is responsible for naming the columns after the properties of the items when
+// they are not structs
+func getColumns(rows []interface{}) []string {
+	properties := map[string]interface{}{}
+	for _, row := range rows {
+		fields, _ := row.(map[string]interface{})
+		for key := range fields {
+			properties[key] = true
+		}
+	}
+	return sortedKeys(properties)
+}
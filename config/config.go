@@ -2,20 +2,223 @@ package config
 
 import (
 	"io/ioutil"
+	"time"
 
 	"gopkg.in/yaml.v2"
 )
 
-// AppConfig reflects the properties of the mysql database
+// AppConfig reflects the properties of the application
 type AppConfig struct {
-	SQLDBConfig SQLDBConfig `yaml:"sqlDbConfig"`
+	ServerConfig      ServerConfig      `yaml:"serverConfig"`
+	MiddlewareConfig  MiddlewareConfig  `yaml:"middlewareConfig"`
+	SecurityConfig    SecurityConfig    `yaml:"securityConfig"`
+	RateLimitConfig   RateLimitConfig   `yaml:"rateLimitConfig"`
+	AuthConfig        AuthConfig        `yaml:"authConfig"`
+	PaginationConfig  PaginationConfig  `yaml:"paginationConfig"`
+	IdempotencyConfig IdempotencyConfig `yaml:"idempotencyConfig"`
+	StreamConfig      StreamConfig      `yaml:"streamConfig"`
+	HealthConfig      HealthConfig      `yaml:"healthConfig"`
+	SQLDBConfig       SQLDBConfig       `yaml:"sqlDbConfig"`
+}
+
+// ServerConfig reflects the properties of the http server
+type ServerConfig struct {
+	Address         string                   `yaml:"address"`
+	ShutdownTimeout time.Duration            `yaml:"shutdownTimeout"`
+	RequestTimeout  time.Duration            `yaml:"requestTimeout"`
+	RouteTimeouts   map[string]time.Duration `yaml:"routeTimeouts"`
+	// RequireIfMatch rejects the changes to resources that do not inform their ETag
+	RequireIfMatch bool `yaml:"requireIfMatch"`
+	// CacheControl holds the Cache-Control of the successful responses, by operation
+	CacheControl map[string]string `yaml:"cacheControl"`
+	// MaxBatchSize limits the number of items of a batch of changes
+	MaxBatchSize int `yaml:"maxBatchSize"`
+	// Versions schedules the deprecation of the versions of the API, by name
+	Versions map[string]VersionConfig `yaml:"versions"`
+	// Listeners lists the addresses served, Address being served over plaintext HTTP/1.1
+	// when empty
+	Listeners []ListenerConfig `yaml:"listeners"`
+}
+
+// ListenerConfig reflects the properties of an address served
+type ListenerConfig struct {
+	Address string `yaml:"address"`
+	// TLS enables HTTPS when CertFile is informed
+	TLS TLSConfig `yaml:"tls"`
+	// HTTP2 serves HTTP/2 over TLS along with HTTP/1.1
+	HTTP2 bool `yaml:"http2"`
+	// H2C serves HTTP/2 without TLS along with HTTP/1.1, for the internal traffic
+	H2C bool `yaml:"h2c"`
+}
+
+// TLSConfig reflects the certificates and the policy of HTTPS
+type TLSConfig struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// ClientCAFile holds the CAs of the client certificates, enabling mutual TLS
+	ClientCAFile string `yaml:"clientCaFile"`
+	// ClientAuth is require, the default, or verifyIfGiven, which accepts clients without
+	// a certificate
+	ClientAuth string `yaml:"clientAuth"`
+	// MinVersion is the oldest version of TLS accepted, among 1.0, 1.1, 1.2 and 1.3
+	MinVersion string `yaml:"minVersion"`
+	// CipherSuites lists the cipher suites accepted up to TLS 1.2, the defaults of Go when empty
+	CipherSuites []string `yaml:"cipherSuites"`
+	// ReloadInterval is the minimum interval between the checks of the certificate files,
+	// which are loaded again when they change
+	ReloadInterval time.Duration `yaml:"reloadInterval"`
+}
+
+// VersionConfig reflects the deprecation of a version of the API, zero moments meaning that
+// it is not scheduled
+type VersionConfig struct {
+	Deprecation time.Time `yaml:"deprecation"`
+	Sunset      time.Time `yaml:"sunset"`
+}
+
+// MiddlewareConfig reflects the properties of the middlewares around the http handlers
+type MiddlewareConfig struct {
+	// RequestIDHeader names the header that identifies the requests, empty disabling the IDs
+	RequestIDHeader string `yaml:"requestIdHeader"`
+	// AccessLog enables the log of every request, with its status and latency
+	AccessLog bool `yaml:"accessLog"`
+	// RecoverPanics reports the panics of the handlers as 500 responses
+	RecoverPanics bool `yaml:"recoverPanics"`
+	// MaxBodySize limits the size of the request bodies in bytes, zero meaning no limit
+	MaxBodySize int64 `yaml:"maxBodySize"`
+}
+
+// SecurityConfig reflects the properties of the CORS handling and of the security headers
+type SecurityConfig struct {
+	CORS    CORSConfig            `yaml:"cors"`
+	Headers SecurityHeadersConfig `yaml:"headers"`
+}
+
+// CORSConfig reflects the properties of the calls from other origins by browsers
+type CORSConfig struct {
+	// AllowedOrigins lists the origins allowed, where * matches any sequence of characters,
+	// empty disabling CORS
+	AllowedOrigins   []string      `yaml:"allowedOrigins"`
+	AllowedMethods   []string      `yaml:"allowedMethods"`
+	AllowedHeaders   []string      `yaml:"allowedHeaders"`
+	ExposedHeaders   []string      `yaml:"exposedHeaders"`
+	AllowCredentials bool          `yaml:"allowCredentials"`
+	MaxAge           time.Duration `yaml:"maxAge"`
+}
+
+// SecurityHeadersConfig reflects the security headers set on every response, the empty
+// ones not being set
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge enables Strict-Transport-Security, zero disabling it
+	HSTSMaxAge            time.Duration `yaml:"hstsMaxAge"`
+	HSTSIncludeSubdomains bool          `yaml:"hstsIncludeSubdomains"`
+	ContentTypeNosniff    bool          `yaml:"contentTypeNosniff"`
+	FrameOptions          string        `yaml:"frameOptions"`
+	ContentSecurityPolicy string        `yaml:"contentSecurityPolicy"`
+	ReferrerPolicy        string        `yaml:"referrerPolicy"`
+}
+
+// RateLimitConfig reflects the properties of the limits on the requests of the clients
+type RateLimitConfig struct {
+	// Keys lists how the clients are identified, by precedence, among subject and ip
+	Keys    []string  `yaml:"keys"`
+	Default RateLimit `yaml:"default"`
+	// Routes overrides Default for specific operations
+	Routes map[string]RateLimit `yaml:"routes"`
+	// MaxInFlight limits the requests handled at the same time, zero meaning no limit
+	MaxInFlight int `yaml:"maxInFlight"`
+}
+
+// RateLimit reflects a token bucket, refilled with Rate requests per second up to Burst
+// requests, zero Rate meaning no limit
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// AuthConfig reflects the properties of the authentication of the requests by JWT and API key
+type AuthConfig struct {
+	// Enabled requires the requests of every operation to be authenticated
+	Enabled bool   `yaml:"enabled"`
+	Realm   string `yaml:"realm"`
+	// Issuer is the iss the tokens must have, empty accepting any issuer
+	Issuer string `yaml:"issuer"`
+	// Audience must be one of the aud of the tokens, empty accepting any audience
+	Audience string        `yaml:"audience"`
+	Leeway   time.Duration `yaml:"leeway"`
+	// HMACSecretFile holds the secret of the HS256 tokens
+	HMACSecretFile string `yaml:"hmacSecretFile"`
+	// JWKSFile holds the keys of the RS256 and ES256 tokens
+	JWKSFile string `yaml:"jwksFile"`
+	// JWKSURL publishes the keys of the RS256 and ES256 tokens
+	JWKSURL string `yaml:"jwksUrl"`
+	// JWKSRefreshInterval is how long the JWKS of JWKSURL is used before it is fetched again,
+	// 15 minutes when zero
+	JWKSRefreshInterval time.Duration `yaml:"jwksRefreshInterval"`
+	// APIKeys enables the ApiKey scheme, whose keys are managed by the operations under
+	// /admin/api-keys, which must require scopes
+	APIKeys bool `yaml:"apiKeys"`
+	// APIKeyCacheTTL is how long the API keys authenticated are cached, delaying their revocation
+	APIKeyCacheTTL time.Duration `yaml:"apiKeyCacheTtl"`
+	// Scopes lists the scopes required by each operation
+	Scopes map[string][]string `yaml:"scopes"`
+	// ClientCertificateScopes lists the scopes granted to the client certificates verified by
+	// mutual TLS, by subject, such as CN=client,O=Example
+	ClientCertificateScopes map[string][]string `yaml:"clientCertificateScopes"`
+}
+
+// PaginationConfig reflects the properties of the listings
+type PaginationConfig struct {
+	DefaultPageSize int `yaml:"defaultPageSize"`
+	MaxPageSize     int `yaml:"maxPageSize"`
+}
+
+// IdempotencyConfig reflects the properties of the creations retried with an Idempotency-Key
+type IdempotencyConfig struct {
+	// Store keeps the responses to the requests, among memory and sql, empty ignoring the
+	// Idempotency-Key
+	Store string `yaml:"store"`
+	// TTL is how long the responses are provided again to the requests with the same key
+	TTL time.Duration `yaml:"ttl"`
+	// Lease is how long a request in progress holds its key, in case it is interrupted
+	Lease time.Duration `yaml:"lease"`
+	// Wait is how long the requests wait for a request in progress with the same key
+	Wait time.Duration `yaml:"wait"`
+}
+
+// StreamConfig reflects the properties of the stream of the changes to Examples
+type StreamConfig struct {
+	// ReplaySize is how many changes are kept to resume the streams by Last-Event-ID
+	ReplaySize int `yaml:"replaySize"`
+	// SubscriberBuffer is how many changes a subscriber may fall behind before it is dropped
+	SubscriberBuffer int `yaml:"subscriberBuffer"`
+	// MaxSubscribers limits the streams open at once, which are not counted as requests in
+	// flight, zero meaning no limit
+	MaxSubscribers int `yaml:"maxSubscribers"`
+	// KeepAlive is the interval of the messages that keep the idle streams open
+	KeepAlive time.Duration `yaml:"keepAlive"`
+}
+
+// HealthConfig reflects the properties of the checks of the dependencies
+type HealthConfig struct {
+	// Timeout limits each check
+	Timeout time.Duration `yaml:"timeout"`
+	// Timeouts overrides Timeout by dependency, such as sql database
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+	// DrainDelay is how long the readiness fails before the shutdown of the listeners
+	DrainDelay time.Duration `yaml:"drainDelay"`
+	// CacheTTL is how long the results of the checks are reused by the reports
+	CacheTTL time.Duration `yaml:"cacheTtl"`
 }
 
 // SQLDBConfig reflects the properties of the sql database
 type SQLDBConfig struct {
-	Type string `ỳaml:"type"`
-	Host string `yaml:"host"`
-	Port uint   `yaml:"port"`
+	Type         string `yaml:"type"`
+	Host         string `yaml:"host"`
+	Port         uint   `yaml:"port"`
+	Database     string `yaml:"database"`
+	User         string `yaml:"user"`
+	PasswordFile string `yaml:"passwordFile"`
 }
 
 // ReadConfig is responsible for read the config file
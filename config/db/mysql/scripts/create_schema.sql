@@ -6,5 +6,35 @@ CREATE TABLE `example_db`.`example` (
   `useful` TINYINT(1) NULL DEFAULT NULL,
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `deactivated_at` TIMESTAMP NULL,
+  `version` BIGINT UNSIGNED NOT NULL DEFAULT 1,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   UNIQUE INDEX `example_name_UNIQUE` (`name` ASC) VISIBLE);
+
+CREATE TABLE `example_db`.`api_key` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(100) NOT NULL,
+  `prefix` CHAR(12) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+  `salt` BINARY(16) NOT NULL,
+  `hash` BINARY(32) NOT NULL,
+  `scopes` VARCHAR(1000) NOT NULL DEFAULT '',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `expires_at` TIMESTAMP NULL,
+  `revoked_at` TIMESTAMP NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `api_key_prefix_UNIQUE` (`prefix` ASC) VISIBLE);
+
+CREATE TABLE `example_db`.`idempotent_request` (
+  `idempotency_key` CHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+  `fingerprint` CHAR(64) CHARACTER SET ascii COLLATE ascii_bin NOT NULL,
+  `reservation` CHAR(32) CHARACTER SET ascii COLLATE ascii_bin NOT NULL DEFAULT '',
+  `completed` TINYINT(1) NOT NULL DEFAULT 0,
+  `code` SMALLINT UNSIGNED NOT NULL DEFAULT 0,
+  `path` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  `etag` VARCHAR(100) NOT NULL DEFAULT '',
+  `body_type` VARCHAR(100) NOT NULL DEFAULT '',
+  `body` MEDIUMBLOB NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `expires_at` TIMESTAMP NOT NULL,
+  PRIMARY KEY (`idempotency_key`),
+  INDEX `idempotent_request_expires_at` (`expires_at` ASC) VISIBLE);
@@ -0,0 +1,178 @@
+package datamysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver"
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+const (
+	// PersistAPIKey represents a sql command to insert an APIKey into the base
+	PersistAPIKey string = `INSERT INTO api_key (name, prefix, salt, hash, scopes, created_at, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	// QueryAPIKey represents a search query for API keys in the base
+	QueryAPIKey string = `SELECT id, name, prefix, salt, hash, scopes, created_at, expires_at, revoked_at FROM api_key`
+	// QueryAPIKeyByID represents a search query for APIKey by ID in the base
+	QueryAPIKeyByID string = QueryAPIKey + ` WHERE id = ?`
+	// QueryAPIKeyByPrefix represents a search query for APIKey by prefix in the base
+	QueryAPIKeyByPrefix string = QueryAPIKey + ` WHERE prefix = ?`
+	// RevokeAPIKey represents a sql command to update the revoked_at column of an APIKey that is not revoked in the base
+	RevokeAPIKey string = `UPDATE api_key SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	// UpdateAPIKeySecret represents a sql command to replace the secret of an APIKey that is not revoked in the base
+	UpdateAPIKeySecret string = `UPDATE api_key SET prefix = ?, salt = ?, hash = ?, created_at = ? WHERE id = ? AND revoked_at IS NULL`
+)
+
+// APIKeyDataServiceMySQL is responsible for providing the methods of accessing
+// the data of the APIKey model in a MySQL Database
+type APIKeyDataServiceMySQL struct {
+	SQLD dbdriver.SQLDriver
+}
+
+// Create is responsible for persisting an APIKey in the repository
+// in a MySQL Database
+func (ds *APIKeyDataServiceMySQL) Create(ctx context.Context, apiKey *model.APIKey) (persistedAPIKey *model.APIKey, err error) {
+	rows, err := ds.SQLD.PrepareAndExecute(
+		ctx,
+		PersistAPIKey,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.Salt,
+		apiKey.Hash,
+		strings.Join(apiKey.Scopes, " "),
+		apiKey.CreatedAt,
+		toNullTime(apiKey.ExpiresAt),
+	)
+	if err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	lastInsertID, err := rows.LastInsertId()
+	if err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	apiKey.ID = lastInsertID
+
+	return apiKey, nil
+}
+
+// FindAll is responsible for returning all API keys from the repository
+// in a MySQL Database
+func (ds *APIKeyDataServiceMySQL) FindAll(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := ds.SQLD.Query(ctx, QueryAPIKey+` ORDER BY id`)
+	if err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	defer rows.Close()
+
+	apiKeys := []model.APIKey{}
+
+	for rows.Next() {
+		apiKey, err := rowsToAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, *apiKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	return apiKeys, nil
+}
+
+// FindByID is responsible for returning an APIKey from the repository
+// in a MySQL Database
+func (ds *APIKeyDataServiceMySQL) FindByID(ctx context.Context, ID int64) (*model.APIKey, error) {
+	return ds.queryAPIKey(ctx, QueryAPIKeyByID, ID)
+}
+
+// FindByPrefix is responsible for returning an APIKey from the repository according to the prefix
+// in a MySQL Database
+func (ds *APIKeyDataServiceMySQL) FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error) {
+	return ds.queryAPIKey(ctx, QueryAPIKeyByPrefix, prefix)
+}
+
+// Revoke is responsible for revoking an APIKey in the repository
+// in a MySQL Database
+func (ds *APIKeyDataServiceMySQL) Revoke(ctx context.Context, ID int64, revocationDatetime time.Time) error {
+	if _, err := ds.SQLD.PrepareAndExecute(ctx, RevokeAPIKey, revocationDatetime, ID); err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+	return nil
+}
+
+// UpdateSecret is responsible for replacing the secret of an APIKey in the repository
+// in a MySQL Database
+func (ds *APIKeyDataServiceMySQL) UpdateSecret(ctx context.Context, apiKey *model.APIKey) error {
+	rows, err := ds.SQLD.PrepareAndExecute(
+		ctx,
+		UpdateAPIKeySecret,
+		apiKey.Prefix,
+		apiKey.Salt,
+		apiKey.Hash,
+		apiKey.CreatedAt,
+		apiKey.ID,
+	)
+	if err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+
+	affectedRows, err := rows.RowsAffected()
+	if err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+	if affectedRows == 0 {
+		return &dataservice.RevokedError{ID: apiKey.ID}
+	}
+	return nil
+}
+
+func (ds *APIKeyDataServiceMySQL) queryAPIKey(ctx context.Context, query string, args ...interface{}) (*model.APIKey, error) {
+	rows, err := ds.SQLD.Query(ctx, query, args...)
+	if err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return rowsToAPIKey(rows)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+	return nil, nil
+}
+
+func rowsToAPIKey(rows *sql.Rows) (*model.APIKey, error) {
+	var apiKey model.APIKey
+	var scopes string
+	var expiresAt, revokedAt sql.NullTime
+	if err := rows.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.Salt,
+		&apiKey.Hash,
+		&scopes,
+		&apiKey.CreatedAt,
+		&expiresAt,
+		&revokedAt,
+	); err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+	apiKey.Scopes = strings.Fields(scopes)
+	apiKey.ExpiresAt = expiresAt.Time
+	apiKey.RevokedAt = revokedAt.Time
+	return &apiKey, nil
+}
+
+func toNullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
@@ -1,6 +1,8 @@
 package dataservice
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	"github.com/zeroberto/go-ms-template/model"
@@ -9,24 +11,74 @@ import (
 // ExampleDataService is responsible for providing the methods of accessing
 // the data of the Example model
 type ExampleDataService interface {
+	// Batch is responsible for executing the operations of fn within a single transaction of the
+	// repository, which is committed when fn succeeds and undone otherwise. When ctx is already
+	// within a batch, fn joins it
+	Batch(ctx context.Context, fn func(ctx context.Context) error) error
 	// Create is responsible for persisting an Example in the repository
-	Create(example *model.Example) (persistedExample *model.Example, err error)
-	// Delete is responsible for physically removing Example from the repository
-	Delete(ID int64) error
+	Create(ctx context.Context, example *model.Example) (persistedExample *model.Example, err error)
+	// Delete is responsible for physically removing Example from the repository, provided
+	// it is still at the given version
+	Delete(ctx context.Context, ID int64, version int64) error
 	// FindActives is responsible for returning all examples that are active from the repository
-	FindActives() ([]model.Example, error)
+	FindActives(ctx context.Context) ([]model.Example, error)
+	// FindActivesPage is responsible for returning a page of the active examples from the repository
+	FindActivesPage(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error)
 	// FindAll is responsible for returning all examples from the repository
-	FindAll() ([]model.Example, error)
+	FindAll(ctx context.Context) ([]model.Example, error)
 	// FindByID is responsible for returning an Example from the repository
-	FindByID(ID int64) (*model.Example, error)
-	// FindByName is responsible for returning an Example from the repository according to the name
-	FindByName(name string) (*model.Example, error)
-	// LogicalDeletion is responsible for removing Example logically from the repository
-	LogicalDeletion(ID int64, deactivationDatetime time.Time) error
-	// Update is responsible for updating an existing Example in the repository
-	Update(example *model.Example) (updatedExample *model.Example, err error)
-	// UpdateProperty is responsible for updating a particular Example property in the repository
-	UpdateProperties(ID int64, properties map[string]interface{}) error
+	FindByID(ctx context.Context, ID int64) (*model.Example, error)
+	// FindByName is responsible for returning an Example from the repository according to the name,
+	// compared regardless of case
+	FindByName(ctx context.Context, name string) (*model.Example, error)
+	// FindPage is responsible for returning a page of examples from the repository
+	FindPage(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error)
+	// LogicalDeletion is responsible for removing Example logically from the repository, provided
+	// it is still at the given version
+	LogicalDeletion(ctx context.Context, ID int64, version int64, deactivationDatetime time.Time) error
+	// Update is responsible for updating an existing Example in the repository, provided
+	// it is still at the version of the given Example
+	Update(ctx context.Context, example *model.Example) (updatedExample *model.Example, err error)
+	// UpdateProperty is responsible for updating a particular Example property in the repository,
+	// provided it is still at the given version
+	UpdateProperties(ctx context.Context, ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error
+}
+
+// APIKeyDataService is responsible for providing the methods of accessing
+// the data of the APIKey model
+type APIKeyDataService interface {
+	// Create is responsible for persisting an APIKey in the repository
+	Create(ctx context.Context, apiKey *model.APIKey) (persistedAPIKey *model.APIKey, err error)
+	// FindAll is responsible for returning all API keys from the repository
+	FindAll(ctx context.Context) ([]model.APIKey, error)
+	// FindByID is responsible for returning an APIKey from the repository
+	FindByID(ctx context.Context, ID int64) (*model.APIKey, error)
+	// FindByPrefix is responsible for returning an APIKey from the repository according to the prefix
+	FindByPrefix(ctx context.Context, prefix string) (*model.APIKey, error)
+	// Revoke is responsible for revoking an APIKey in the repository, unless it is already revoked
+	Revoke(ctx context.Context, ID int64, revocationDatetime time.Time) error
+	// UpdateSecret is responsible for replacing the prefix, the salt and the hash of an APIKey
+	// that is not revoked in the repository, reporting RevokedError when it was revoked
+	UpdateSecret(ctx context.Context, apiKey *model.APIKey) error
+}
+
+// IdempotencyDataService is responsible for providing the methods of accessing
+// the data of the IdempotentRequest model
+type IdempotencyDataService interface {
+	// Reserve is responsible for persisting an IdempotentRequest in the repository, unless its
+	// key is held by a request that expires after the CreatedAt of the given one, which is
+	// returned instead
+	Reserve(ctx context.Context, request *model.IdempotentRequest) (existingRequest *model.IdempotentRequest, err error)
+	// Complete is responsible for storing the response of an IdempotentRequest in the repository,
+	// along with its new expiry
+	Complete(ctx context.Context, request *model.IdempotentRequest) error
+	// Release is responsible for removing the IdempotentRequest of the reservation from the
+	// repository while it is not completed, so that its key can be used again. A request whose
+	// key was reserved again, after its lease expired, does not release the new reservation
+	Release(ctx context.Context, key string, reservation string) error
+	// DeleteExpired is responsible for removing the requests expired at the given moment from
+	// the repository, returning how many were removed
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
 }
 
 // Error is responsible for encapsulating errors generated by operations in the data access layer
@@ -37,3 +89,33 @@ type Error struct {
 func (err *Error) Error() string {
 	return err.Cause.Error()
 }
+
+// Unwrap provides the cause of the error
+func (err *Error) Unwrap() error {
+	return err.Cause
+}
+
+// StaleVersionError must be reported when the Example is no longer at the version
+// expected by the operation, as it was changed concurrently
+type StaleVersionError struct {
+	ID      int64
+	Version int64
+}
+
+func (err *StaleVersionError) Error() string {
+	return fmt.Sprintf("Example %d is no longer at version %d", err.ID, err.Version)
+}
+
+// Stale reports that the error is caused by a concurrent change
+func (err *StaleVersionError) Stale() bool {
+	return true
+}
+
+// RevokedError must be reported when the APIKey cannot be changed, as it was revoked
+type RevokedError struct {
+	ID int64
+}
+
+func (err *RevokedError) Error() string {
+	return fmt.Sprintf("API key %d is revoked", err.ID)
+}
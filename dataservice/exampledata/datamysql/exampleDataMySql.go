@@ -1,52 +1,100 @@
 package datamysql
 
 import (
+	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/zeroberto/go-ms-template/dataservice"
-	"github.com/zeroberto/go-ms-template/driver/dbdriver/sqldbdriver"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver"
+	"github.com/zeroberto/go-ms-template/filter"
 	"github.com/zeroberto/go-ms-template/model"
 )
 
 const (
-	// DeleteExample represents a sql command to physically remove an Example from the base
-	DeleteExample string = `DELETE FROM example WHERE id = ?`
+	// DeleteExample represents a sql command to physically remove an Example at a version from the base
+	DeleteExample string = `DELETE FROM example WHERE id = ? AND version = ?`
 	// PersistExample represents a sql command to insert an Example into the base
-	PersistExample string = `INSERT INTO example (name, useful, created_at) VALUES (?, ?, ?)`
+	PersistExample string = `INSERT INTO example (name, useful, created_at, version, updated_at) VALUES (?, ?, ?, 1, ?)`
 	// QueryExample represents a search query for Examples in the base
 	QueryExample string = `SELECT * FROM example`
 	// QueryActiveExamples represents a search query for active Examples in the base
 	QueryActiveExamples string = `SELECT * FROM example WHERE deactivated_at IS NULL`
+	// QueryExamplePage represents a search query for a page of Examples in the base, according
+	// to a condition and the sort order
+	QueryExamplePage string = `SELECT * FROM example WHERE %s ORDER BY %s LIMIT ? OFFSET ?`
+	// CountExamples represents a query for the number of Examples in the base according to a condition
+	CountExamples string = `SELECT COUNT(*) FROM example WHERE %s`
+	// AllExamplesCondition represents the condition satisfied by all Examples in the base
+	AllExamplesCondition string = `1 = 1`
+	// ActiveExamplesCondition represents the condition satisfied by active Examples in the base
+	ActiveExamplesCondition string = `deactivated_at IS NULL`
 	// QueryExampleByID represents a search query for Example by ID in the base
 	QueryExampleByID string = `SELECT * FROM example WHERE id = ?`
 	// QueryExampleByName represents a search query for Example by name in the base
-	QueryExampleByName string = `SELECT * FROM example WHERE id = ?`
-	// UpdateExample represents a sql command to update an Example in the base
-	UpdateExample string = `UPDATE example SET name = ?, useful = ? WHERE id = ?`
-	// UpdateExampleProperties represents a sql command to update an Example in the base
-	UpdateExampleProperties string = `UPDATE example SET %s WHERE id = ?`
-	// DeactivateExample represents a sql command to update the deactivate column of the Example in the base
-	DeactivateExample string = `UPDATE example SET deactivated_at = ? WHERE id = ?`
+	QueryExampleByName string = `SELECT * FROM example WHERE name = ?`
+	// UpdateExample represents a sql command to update an Example at a version in the base
+	UpdateExample string = `UPDATE example SET name = ?, useful = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
+	// UpdateExampleProperties represents a sql command to update an Example at a version in the base
+	UpdateExampleProperties string = `UPDATE example SET %s, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
+	// DeactivateExample represents a sql command to update the deactivate column of the Example at a version in the base
+	DeactivateExample string = `UPDATE example SET deactivated_at = ?, version = version + 1, updated_at = ? WHERE id = ? AND version = ?`
 )
 
+// exampleColumns maps the fields of model.ExampleFields to the columns of the example table
+var exampleColumns = filter.Columns{
+	"id":            "id",
+	"name":          "name",
+	"useful":        "COALESCE(useful, FALSE)",
+	"createdAt":     "created_at",
+	"deactivatedAt": "deactivated_at",
+	"updatedAt":     "updated_at",
+	"active":        "(deactivated_at IS NULL)",
+}
+
 // ExampleDataServiceMySQL is responsible for providing the methods of accessing
 // the data of the Example model in a MySQL Database
 type ExampleDataServiceMySQL struct {
-	sqlDriver sqldbdriver.SQLDBDriver
+	SQLD dbdriver.SQLDriver
+}
+
+type batchKey struct {
+	ds *ExampleDataServiceMySQL
+}
+
+// Batch is responsible for executing the operations of fn within a single transaction
+// in a MySQL Database
+func (ds *ExampleDataServiceMySQL) Batch(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(batchKey{ds: ds}) != nil {
+		return fn(ctx)
+	}
+	ctx, err := ds.SQLD.BeginTransaction(ctx)
+	if err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+	defer ds.SQLD.EndTransaction(ctx)
+
+	if err := fn(context.WithValue(ctx, batchKey{ds: ds}, true)); err != nil {
+		return err
+	}
+	if err := ds.SQLD.Commit(ctx); err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+	return nil
 }
 
 // Create is responsible for persisting an Example in the repository
 // in a MySQL Database
-func (ds *ExampleDataServiceMySQL) Create(example *model.Example) (persistedExample *model.Example, err error) {
-	rows, err := ds.sqlDriver.PrepareAndExecute(
-		DeleteExample,
+func (ds *ExampleDataServiceMySQL) Create(ctx context.Context, example *model.Example) (persistedExample *model.Example, err error) {
+	rows, err := ds.SQLD.PrepareAndExecute(
+		ctx,
+		PersistExample,
 		example.Name,
 		example.Useful,
 		example.CreatedAt,
+		example.UpdatedAt,
 	)
 	if err != nil {
 		return nil, &dataservice.Error{Cause: err}
@@ -58,176 +106,253 @@ func (ds *ExampleDataServiceMySQL) Create(example *model.Example) (persistedExam
 	}
 
 	example.ID = lastInsertID
+	example.Version = 1
 
 	return example, nil
 }
 
 // Delete is responsible for physically removing Example from the repository
 // in a MySQL Database
-func (ds *ExampleDataServiceMySQL) Delete(ID int64) error {
-	_, err := ds.sqlDriver.Execute(DeleteExample, ID)
-
+func (ds *ExampleDataServiceMySQL) Delete(ctx context.Context, ID int64, version int64) error {
+	rows, err := ds.SQLD.Execute(ctx, DeleteExample, ID, version)
 	if err != nil {
 		return &dataservice.Error{Cause: err}
 	}
 
-	return nil
+	return checkVersion(rows, ID, version)
 }
 
 // FindActives is responsible for returning all examples that are active from the repository
 // in a MySQL Database
-func (ds *ExampleDataServiceMySQL) FindActives() ([]model.Example, error) {
-	rows, err := ds.sqlDriver.Query(QueryActiveExamples)
-
-	defer rows.Close()
-
-	if err != nil {
-		return nil, &dataservice.Error{Cause: err}
-	}
-
-	examples := []model.Example{}
-
-	for rows.Next() {
-		example, err := rowsToExample(rows)
-		if err != nil {
-			return nil, &dataservice.Error{Cause: err}
-		}
-		examples = append(examples, *example)
-	}
+func (ds *ExampleDataServiceMySQL) FindActives(ctx context.Context) ([]model.Example, error) {
+	return ds.queryExamples(ctx, QueryActiveExamples)
+}
 
-	return examples, nil
+// FindActivesPage is responsible for returning a page of the active examples from the repository
+// in a MySQL Database
+func (ds *ExampleDataServiceMySQL) FindActivesPage(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	return ds.findPage(ctx, ActiveExamplesCondition, pageRequest)
 }
 
 // FindAll is responsible for returning all examples from the repository
 // in a MySQL Database
-func (ds *ExampleDataServiceMySQL) FindAll() ([]model.Example, error) {
-	rows, err := ds.sqlDriver.Query(QueryExample)
-
-	defer rows.Close()
-
-	if err != nil {
-		return nil, &dataservice.Error{Cause: err}
-	}
-
-	examples := []model.Example{}
-
-	for rows.Next() {
-		example, err := rowsToExample(rows)
-		if err != nil {
-			return nil, &dataservice.Error{Cause: err}
-		}
-		examples = append(examples, *example)
-	}
-
-	return examples, nil
+func (ds *ExampleDataServiceMySQL) FindAll(ctx context.Context) ([]model.Example, error) {
+	return ds.queryExamples(ctx, QueryExample)
 }
 
 // FindByID is responsible for returning an Example from the repository
 // in a MySQL Database
-func (ds *ExampleDataServiceMySQL) FindByID(ID int64) (*model.Example, error) {
-	rows, err := ds.sqlDriver.Query(QueryExampleByID)
-
-	defer rows.Close()
-
+func (ds *ExampleDataServiceMySQL) FindByID(ctx context.Context, ID int64) (*model.Example, error) {
+	rows, err := ds.SQLD.Query(ctx, QueryExampleByID, ID)
 	if err != nil {
 		return nil, &dataservice.Error{Cause: err}
 	}
 
+	defer rows.Close()
+
 	return toExample(rows)
 }
 
 // FindByName is responsible for returning an Example from the repository according to the name
-// in a MySQL Database
-func (ds *ExampleDataServiceMySQL) FindByName(name string) (*model.Example, error) {
-	rows, err := ds.sqlDriver.Query(QueryExampleByName, name)
-
-	defer rows.Close()
-
+// in a MySQL Database, where the utf8mb4_general_ci collation compares names regardless of case
+func (ds *ExampleDataServiceMySQL) FindByName(ctx context.Context, name string) (*model.Example, error) {
+	rows, err := ds.SQLD.Query(ctx, QueryExampleByName, name)
 	if err != nil {
 		return nil, &dataservice.Error{Cause: err}
 	}
 
+	defer rows.Close()
+
 	return toExample(rows)
 }
 
+// FindPage is responsible for returning a page of examples from the repository
+// in a MySQL Database
+func (ds *ExampleDataServiceMySQL) FindPage(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	return ds.findPage(ctx, AllExamplesCondition, pageRequest)
+}
+
 // LogicalDeletion is responsible for removing Example logically from the repository
 // in a MySQL Database
-func (ds *ExampleDataServiceMySQL) LogicalDeletion(ID int64, deactivationDatetime time.Time) error {
-	_, err := ds.sqlDriver.PrepareAndExecute(DeactivateExample, deactivationDatetime, ID)
+func (ds *ExampleDataServiceMySQL) LogicalDeletion(ctx context.Context, ID int64, version int64, deactivationDatetime time.Time) error {
+	rows, err := ds.SQLD.PrepareAndExecute(ctx, DeactivateExample, deactivationDatetime, deactivationDatetime, ID, version)
 	if err != nil {
 		return &dataservice.Error{Cause: err}
 	}
-	return nil
+
+	return checkVersion(rows, ID, version)
 }
 
 // Update is responsible for updating an existing Example
 // in the repository in a MySQL Database
-func (ds *ExampleDataServiceMySQL) Update(example *model.Example) (updatedExample *model.Example, err error) {
-	rows, err := ds.sqlDriver.PrepareAndExecute(
+func (ds *ExampleDataServiceMySQL) Update(ctx context.Context, example *model.Example) (updatedExample *model.Example, err error) {
+	rows, err := ds.SQLD.PrepareAndExecute(
+		ctx,
 		UpdateExample,
 		example.Name,
 		example.Useful,
+		example.UpdatedAt,
 		example.ID,
+		example.Version,
 	)
 	if err != nil {
 		return nil, &dataservice.Error{Cause: err}
 	}
 
-	affectedRows, err := rows.RowsAffected()
-	if err != nil {
-		return nil, &dataservice.Error{Cause: err}
-	}
-	if affectedRows == 0 {
-		return nil, &dataservice.Error{Cause: errors.New("Couldn't update example. SQL command did not return any affected lines")}
+	if err := checkVersion(rows, example.ID, example.Version); err != nil {
+		return nil, err
 	}
+	example.Version++
 
 	return example, nil
 }
 
 // UpdateProperties is responsible for updating a particular Example property in the repository
 // in a MySQL Database
-func (ds *ExampleDataServiceMySQL) UpdateProperties(ID int64, properties map[string]interface{}) error {
-	queryParams := ""
-	queryParamValues := make([]interface{}, len(properties))
+func (ds *ExampleDataServiceMySQL) UpdateProperties(ctx context.Context, ID int64, version int64, updateDatetime time.Time, properties map[string]interface{}) error {
+	queryParams := make([]string, 0, len(properties))
+	queryParamValues := make([]interface{}, 0, len(properties)+3)
 
 	for k, v := range properties {
-		queryParams += fmt.Sprintf("%s = ?,", k)
+		queryParams = append(queryParams, fmt.Sprintf("%s = ?", k))
 		queryParamValues = append(queryParamValues, v)
 	}
-	strings.TrimSuffix(queryParams, ",")
+	queryParamValues = append(queryParamValues, updateDatetime, ID, version)
 
-	rows, err := ds.sqlDriver.PrepareAndExecute(
-		fmt.Sprintf(UpdateExampleProperties, queryParams),
-		queryParamValues,
+	rows, err := ds.SQLD.PrepareAndExecute(
+		ctx,
+		fmt.Sprintf(UpdateExampleProperties, strings.Join(queryParams, ", ")),
+		queryParamValues...,
 	)
 	if err != nil {
 		return &dataservice.Error{Cause: err}
 	}
 
-	affectedRows, err := rows.RowsAffected()
+	return checkVersion(rows, ID, version)
+}
+
+func (ds *ExampleDataServiceMySQL) findPage(ctx context.Context, condition string, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	filterCondition, args, err := filter.ToSQL(pageRequest.Filter, exampleColumns)
 	if err != nil {
-		return &dataservice.Error{Cause: err}
+		return nil, &dataservice.Error{Cause: err}
 	}
-	if affectedRows == 0 {
-		return &dataservice.Error{Cause: errors.New("Couldn't update example properties. SQL command did not return any affected lines")}
+	if filterCondition != "" {
+		condition = fmt.Sprintf("%s AND %s", condition, filterCondition)
 	}
-	return nil
+	countArgs := args
+
+	order, err := filter.OrderBy(pageRequest.Sort, exampleColumns)
+	if err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+	conditions := []string{condition}
+	offset := pageRequest.Offset
+	if pageRequest.AfterID > 0 {
+		conditions = append(conditions, "id > ?")
+		args = append(args, pageRequest.AfterID)
+		order = append(order, "id ASC")
+		offset = 0
+	} else if pageRequest.BeforeID > 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, pageRequest.BeforeID)
+		order = append(order, "id DESC")
+		offset = 0
+	} else {
+		order = append(order, "id ASC")
+	}
+	args = append(args, pageRequest.Limit+1, offset)
+
+	query := fmt.Sprintf(QueryExamplePage, strings.Join(conditions, " AND "), strings.Join(order, ", "))
+	examples, err := ds.queryExamples(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &model.ExamplePage{}
+	more := len(examples) > pageRequest.Limit
+	if more {
+		examples = examples[:pageRequest.Limit]
+	}
+	if pageRequest.BeforeID > 0 {
+		for i, j := 0, len(examples)-1; i < j; i, j = i+1, j-1 {
+			examples[i], examples[j] = examples[j], examples[i]
+		}
+		page.HasPrevious = more
+		page.HasNext = true
+	} else {
+		page.HasNext = more
+		page.HasPrevious = pageRequest.AfterID > 0 || offset > 0
+	}
+	page.Examples = examples
+
+	if pageRequest.CountTotal {
+		var total int64
+		if err := ds.SQLD.QueryRow(ctx, fmt.Sprintf(CountExamples, condition), countArgs...).Scan(&total); err != nil {
+			return nil, &dataservice.Error{Cause: err}
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+func (ds *ExampleDataServiceMySQL) queryExamples(ctx context.Context, query string, args ...interface{}) ([]model.Example, error) {
+	rows, err := ds.SQLD.Query(ctx, query, args...)
+	if err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	defer rows.Close()
+
+	examples := []model.Example{}
+
+	for rows.Next() {
+		example, err := rowsToExample(rows)
+		if err != nil {
+			return nil, &dataservice.Error{Cause: err}
+		}
+		examples = append(examples, *example)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	return examples, nil
 }
 
 func rowsToExample(rows *sql.Rows) (*model.Example, error) {
 	var example model.Example
+	var useful sql.NullBool
+	var deactivatedAt sql.NullTime
 	if err := rows.Scan(
 		&example.ID,
 		&example.Name,
-		&example.Useful,
+		&useful,
 		&example.CreatedAt,
-		&example.DeactivatedAt,
+		&deactivatedAt,
+		&example.Version,
+		&example.UpdatedAt,
 	); err != nil {
 		return nil, &dataservice.Error{Cause: err}
 	}
+	example.Useful = useful.Bool
+	example.DeactivatedAt = deactivatedAt.Time
 	return &example, nil
 }
 
+// checkVersion reports a StaleVersionError when a command restricted to a version of the
+// Example did not affect it
+func checkVersion(result sql.Result, ID int64, version int64) error {
+	affectedRows, err := result.RowsAffected()
+	if err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+	if affectedRows == 0 {
+		return &dataservice.StaleVersionError{ID: ID, Version: version}
+	}
+	return nil
+}
+
 func toExample(rows *sql.Rows) (*model.Example, error) {
 	if rows.Next() {
 		example, err := rowsToExample(rows)
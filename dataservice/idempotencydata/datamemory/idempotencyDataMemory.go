@@ -0,0 +1,86 @@
+package datamemory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// IdempotencyDataServiceMemory is responsible for providing the methods of accessing
+// the data of the IdempotentRequest model in memory, which is neither shared by the
+// instances of the service nor kept across restarts
+type IdempotencyDataServiceMemory struct {
+	mutex    sync.Mutex
+	requests map[string]model.IdempotentRequest
+}
+
+// Reserve is responsible for persisting an IdempotentRequest in memory
+func (ds *IdempotencyDataServiceMemory) Reserve(ctx context.Context, request *model.IdempotentRequest) (existingRequest *model.IdempotentRequest, err error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if ds.requests == nil {
+		ds.requests = map[string]model.IdempotentRequest{}
+	}
+	if existing, ok := ds.requests[request.Key]; ok && existing.ExpiresAt.After(request.CreatedAt) {
+		return copyRequest(existing), nil
+	}
+	ds.requests[request.Key] = *copyRequest(*request)
+	return nil, nil
+}
+
+// Complete is responsible for storing the response of an IdempotentRequest in memory
+func (ds *IdempotencyDataServiceMemory) Complete(ctx context.Context, request *model.IdempotentRequest) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	existing, ok := ds.requests[request.Key]
+	if !ok || existing.Completed || existing.Fingerprint != request.Fingerprint {
+		return &dataservice.Error{Cause: fmt.Errorf("The idempotency key %s is no longer reserved", request.Key)}
+	}
+	completed := copyRequest(*request)
+	completed.Completed = true
+	completed.CreatedAt = existing.CreatedAt
+	ds.requests[request.Key] = *completed
+	return nil
+}
+
+// Release is responsible for removing the IdempotentRequest in progress of the reservation
+// from memory
+func (ds *IdempotencyDataServiceMemory) Release(ctx context.Context, key string, reservation string) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	if existing, ok := ds.requests[key]; ok && !existing.Completed && existing.Reservation == reservation {
+		delete(ds.requests, key)
+	}
+	return nil
+}
+
+// DeleteExpired is responsible for removing the expired requests from memory
+func (ds *IdempotencyDataServiceMemory) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+
+	var deleted int64
+	for key, existing := range ds.requests {
+		if !existing.ExpiresAt.After(now) {
+			delete(ds.requests, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// copyRequest is responsible for copying the request along with its body, so that the
+// requests kept are not changed by the callers
+func copyRequest(request model.IdempotentRequest) *model.IdempotentRequest {
+	if request.Body != nil {
+		request.Body = append([]byte{}, request.Body...)
+	}
+	return &request
+}
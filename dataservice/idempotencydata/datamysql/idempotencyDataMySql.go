@@ -0,0 +1,154 @@
+package datamysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver"
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// reserveAttempts limits the attempts to reserve a key that is released meanwhile
+const reserveAttempts int = 3
+
+const (
+	// PersistIdempotentRequest represents a sql command to insert an IdempotentRequest into the base, unless its key is held
+	PersistIdempotentRequest string = `INSERT IGNORE INTO idempotent_request (idempotency_key, fingerprint, reservation, created_at, expires_at) VALUES (?, ?, ?, ?, ?)`
+	// QueryIdempotentRequestByKey represents a search query for IdempotentRequest by key in the base
+	QueryIdempotentRequestByKey string = `SELECT idempotency_key, fingerprint, reservation, completed, code, path, etag, body_type, body, created_at, expires_at ` +
+		`FROM idempotent_request WHERE idempotency_key = ?`
+	// CompleteIdempotentRequest represents a sql command to store the response of an IdempotentRequest in progress in the base
+	CompleteIdempotentRequest string = `UPDATE idempotent_request SET completed = 1, code = ?, path = ?, etag = ?, body_type = ?, body = ?, expires_at = ? ` +
+		`WHERE idempotency_key = ? AND fingerprint = ? AND completed = 0`
+	// ReleaseIdempotentRequest represents a sql command to delete the IdempotentRequest in progress of a reservation from the base
+	ReleaseIdempotentRequest string = `DELETE FROM idempotent_request WHERE idempotency_key = ? AND reservation = ? AND completed = 0`
+	// DeleteExpiredIdempotentRequest represents a sql command to delete an expired IdempotentRequest by key from the base
+	DeleteExpiredIdempotentRequest string = `DELETE FROM idempotent_request WHERE idempotency_key = ? AND expires_at <= ?`
+	// DeleteExpiredIdempotentRequests represents a sql command to delete all the expired IdempotentRequests from the base
+	DeleteExpiredIdempotentRequests string = `DELETE FROM idempotent_request WHERE expires_at <= ?`
+)
+
+// IdempotencyDataServiceMySQL is responsible for providing the methods of accessing
+// the data of the IdempotentRequest model in a MySQL Database
+type IdempotencyDataServiceMySQL struct {
+	SQLD dbdriver.SQLDriver
+}
+
+// Reserve is responsible for persisting an IdempotentRequest in the repository
+// in a MySQL Database, where the primary key makes the concurrent reservations of a key fail
+func (ds *IdempotencyDataServiceMySQL) Reserve(ctx context.Context, request *model.IdempotentRequest) (existingRequest *model.IdempotentRequest, err error) {
+	for attempt := 0; attempt < reserveAttempts; attempt++ {
+		if _, err := ds.SQLD.PrepareAndExecute(ctx, DeleteExpiredIdempotentRequest, request.Key, request.CreatedAt); err != nil {
+			return nil, &dataservice.Error{Cause: err}
+		}
+		rows, err := ds.SQLD.PrepareAndExecute(ctx, PersistIdempotentRequest, request.Key, request.Fingerprint, request.Reservation, request.CreatedAt, request.ExpiresAt)
+		if err != nil {
+			return nil, &dataservice.Error{Cause: err}
+		}
+		affectedRows, err := rows.RowsAffected()
+		if err != nil {
+			return nil, &dataservice.Error{Cause: err}
+		}
+		if affectedRows == 1 {
+			return nil, nil
+		}
+		existingRequest, err := ds.queryRequest(ctx, request.Key)
+		if err != nil || existingRequest != nil {
+			return existingRequest, err
+		}
+	}
+	return nil, &dataservice.Error{Cause: fmt.Errorf("Couldn't reserve the idempotency key %s", request.Key)}
+}
+
+// Complete is responsible for storing the response of an IdempotentRequest in the repository
+// in a MySQL Database
+func (ds *IdempotencyDataServiceMySQL) Complete(ctx context.Context, request *model.IdempotentRequest) error {
+	rows, err := ds.SQLD.PrepareAndExecute(
+		ctx,
+		CompleteIdempotentRequest,
+		request.Code,
+		request.Path,
+		request.ETag,
+		request.BodyType,
+		request.Body,
+		request.ExpiresAt,
+		request.Key,
+		request.Fingerprint,
+	)
+	if err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+
+	affectedRows, err := rows.RowsAffected()
+	if err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+	if affectedRows == 0 {
+		return &dataservice.Error{Cause: fmt.Errorf("The idempotency key %s is no longer reserved", request.Key)}
+	}
+	return nil
+}
+
+// Release is responsible for removing the IdempotentRequest in progress of the reservation
+// from the repository in a MySQL Database
+func (ds *IdempotencyDataServiceMySQL) Release(ctx context.Context, key string, reservation string) error {
+	if _, err := ds.SQLD.PrepareAndExecute(ctx, ReleaseIdempotentRequest, key, reservation); err != nil {
+		return &dataservice.Error{Cause: err}
+	}
+	return nil
+}
+
+// DeleteExpired is responsible for removing the expired requests from the repository
+// in a MySQL Database
+func (ds *IdempotencyDataServiceMySQL) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	rows, err := ds.SQLD.PrepareAndExecute(ctx, DeleteExpiredIdempotentRequests, now)
+	if err != nil {
+		return 0, &dataservice.Error{Cause: err}
+	}
+
+	affectedRows, err := rows.RowsAffected()
+	if err != nil {
+		return 0, &dataservice.Error{Cause: err}
+	}
+	return affectedRows, nil
+}
+
+func (ds *IdempotencyDataServiceMySQL) queryRequest(ctx context.Context, key string) (*model.IdempotentRequest, error) {
+	rows, err := ds.SQLD.Query(ctx, QueryIdempotentRequestByKey, key)
+	if err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		return rowsToIdempotentRequest(rows)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+	return nil, nil
+}
+
+func rowsToIdempotentRequest(rows *sql.Rows) (*model.IdempotentRequest, error) {
+	var request model.IdempotentRequest
+	if err := rows.Scan(
+		&request.Key,
+		&request.Fingerprint,
+		&request.Reservation,
+		&request.Completed,
+		&request.Code,
+		&request.Path,
+		&request.ETag,
+		&request.BodyType,
+		&request.Body,
+		&request.CreatedAt,
+		&request.ExpiresAt,
+	); err != nil {
+		return nil, &dataservice.Error{Cause: err}
+	}
+	return &request, nil
+}
@@ -1,19 +1,20 @@
 package dbdriver
 
 import (
+	"context"
 	"database/sql"
 )
 
 // SQLDriver is responsible for performing operations on a SQL database
 type SQLDriver interface {
 	// Execute an SQL statement with transaction
-	Execute(query string, args ...interface{}) (sql.Result, error)
+	Execute(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	// Prepare a sql statement with transaction for future execution
-	PrepareAndExecute(query string, args ...interface{}) (sql.Result, error)
+	PrepareAndExecute(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	// Query is responsible for executing an sql command and returning multiple lines
-	Query(query string, args ...interface{}) (*sql.Rows, error)
+	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	// QueryRow is responsible for executing an sql command and returning a single line
-	QueryRow(query string, args ...interface{}) *sql.Row
+	QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row
 	// Transactional is used to indicate that the current interface is enabled to use transactions
 	Transactional
 }
@@ -21,39 +22,40 @@ type SQLDriver interface {
 // NoSQLDriver is responsible for performing operations on a NoSQL database
 type NoSQLDriver interface {
 	// AddDoc is responsible for creating a new document in the database
-	AddDoc(doc interface{}, args ...interface{}) (result *interface{}, err error)
+	AddDoc(ctx context.Context, doc interface{}, args ...interface{}) (result *interface{}, err error)
 	// ReplaceDoc is responsible for replacing an existing document in the database
-	ReplaceDoc(UID string, doc interface{}, args ...interface{}) (result *interface{}, err error)
+	ReplaceDoc(ctx context.Context, UID string, doc interface{}, args ...interface{}) (result *interface{}, err error)
 	// Command is responsible for executing an command in the database
-	Command(command string, args ...interface{}) (result *interface{}, err error)
+	Command(ctx context.Context, command string, args ...interface{}) (result *interface{}, err error)
 	// QueryDocs is responsible for obtaining several documents from the database
-	QueryDocs(query string, args ...interface{}) ([]interface{}, error)
+	QueryDocs(ctx context.Context, query string, args ...interface{}) ([]interface{}, error)
 	// QueryDoc is responsible for obtaining a single document from the database
-	QueryDoc(query string, args ...interface{}) (result *interface{}, err error)
+	QueryDoc(ctx context.Context, query string, args ...interface{}) (result *interface{}, err error)
 }
 
 // GraphDriver is responsible for performing operations on graph databases
 type GraphDriver interface {
 	// AddElement is responsible for creating a new element on the graph database
-	AddElement(element interface{}, elementType interface{}, args ...interface{}) (result *interface{}, err error)
+	AddElement(ctx context.Context, element interface{}, elementType interface{}, args ...interface{}) (result *interface{}, err error)
 	// Command is responsible for executing an command in the graph database
-	Command(command string, args ...interface{}) (result *interface{}, err error)
+	Command(ctx context.Context, command string, args ...interface{}) (result *interface{}, err error)
 	// QueryElements is responsible for obtaining several elements from the graph database
-	QueryElements(query string, args ...interface{}) ([]interface{}, error)
+	QueryElements(ctx context.Context, query string, args ...interface{}) ([]interface{}, error)
 	// QueryElement is responsible for obtaining a single element from the graph database
-	QueryElement(query string, args ...interface{}) (result *interface{}, err error)
+	QueryElement(ctx context.Context, query string, args ...interface{}) (result *interface{}, err error)
 }
 
-// Transactional is responsible for enabling transactions
+// Transactional is responsible for enabling transactions, which are bound to the context
+// returned by BeginTransaction so that concurrent requests do not share them
 type Transactional interface {
 	// BeginTransaction is responsible for initiating a transaction in the database
-	BeginTransaction() error
+	BeginTransaction(ctx context.Context) (context.Context, error)
 	// Commit is responsible for persisting the modified information in the database
-	Commit() error
+	Commit(ctx context.Context) error
 	// EndTransaction is responsible for ending the transaction in the database
-	EndTransaction() error
+	EndTransaction(ctx context.Context) error
 	// Rollback is responsible for undoing all modifications made to the database within the current transaction
-	Rollback() error
+	Rollback(ctx context.Context) error
 }
 
 // Error is responsible for encapsulating errors generated by operations in the database
@@ -64,3 +66,47 @@ type Error struct {
 func (err *Error) Error() string {
 	return err.Cause.Error()
 }
+
+// Unwrap provides the cause of the error
+func (err *Error) Unwrap() error {
+	return err.Cause
+}
+
+// UnavailableError is responsible for encapsulating errors generated when the database cannot be reached
+type UnavailableError struct {
+	Cause error
+}
+
+func (err *UnavailableError) Error() string {
+	return err.Cause.Error()
+}
+
+// Unwrap provides the cause of the error
+func (err *UnavailableError) Unwrap() error {
+	return err.Cause
+}
+
+// Unavailable reports that the database cannot be reached
+func (err *UnavailableError) Unavailable() bool {
+	return true
+}
+
+// ConflictError is responsible for encapsulating errors generated when a change violates a
+// unique constraint of the database
+type ConflictError struct {
+	Cause error
+}
+
+func (err *ConflictError) Error() string {
+	return err.Cause.Error()
+}
+
+// Unwrap provides the cause of the error
+func (err *ConflictError) Unwrap() error {
+	return err.Cause
+}
+
+// Conflict reports that the change conflicts with the data in the database
+func (err *ConflictError) Conflict() bool {
+	return true
+}
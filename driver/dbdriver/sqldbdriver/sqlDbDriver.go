@@ -1,35 +1,54 @@
 package sqldbdriver
 
 import (
+	"context"
 	"database/sql"
+	sqldriver "database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/zeroberto/go-ms-template/driver/dbdriver"
 )
 
 // SQLDBDriver is responsible for performing operations on a SQL database
 type SQLDBDriver struct {
-	DB *sql.Tx
+	DB           *sql.DB
+	mu           sync.Mutex
+	transactions map[*sql.Tx]struct{}
+}
+
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // Execute an SQL statement with transaction on the SQL database
-func (driver *SQLDBDriver) Execute(query string, args ...interface{}) (sql.Result, error) {
-	return driver.DB.Exec(query, args...)
+func (driver *SQLDBDriver) Execute(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := driver.executor(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return result, nil
 }
 
 // PrepareAndExecute a sql statement with transaction for future execution
 // for the SQL database
-func (driver *SQLDBDriver) PrepareAndExecute(query string, args ...interface{}) (sql.Result, error) {
-	stmt, err := driver.DB.Prepare(query)
-
-	defer stmt.Close()
-
+func (driver *SQLDBDriver) PrepareAndExecute(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := driver.executor(ctx).PrepareContext(ctx, query)
 	if err != nil {
-		return nil, &dbdriver.Error{Cause: err}
+		return nil, wrap(err)
 	}
 
-	result, err := stmt.Exec(args...)
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, args...)
 	if err != nil {
-		return nil, &dbdriver.Error{Cause: err}
+		return nil, wrap(err)
 	}
 
 	return result, err
@@ -37,12 +56,66 @@ func (driver *SQLDBDriver) PrepareAndExecute(query string, args ...interface{})
 
 // Query is responsible for executing an sql command and returning multiple lines
 // for the SQL database
-func (driver *SQLDBDriver) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return driver.DB.Query(query, args...)
+func (driver *SQLDBDriver) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := driver.executor(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	return rows, nil
 }
 
 // QueryRow is responsible for executing an sql command and returning a single line
 // for the SQL database
-func (driver *SQLDBDriver) QueryRow(query string, args ...interface{}) *sql.Row {
-	return driver.DB.QueryRow(query, args...)
+func (driver *SQLDBDriver) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return driver.executor(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// Check is responsible for reporting that the SQL database cannot be reached
+func (driver *SQLDBDriver) Check(ctx context.Context) error {
+	if driver.DB == nil {
+		return &dbdriver.UnavailableError{Cause: errors.New("the database is not open")}
+	}
+	if err := driver.DB.PingContext(ctx); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
+
+// Close is responsible for undoing the transactions in progress, if any, and
+// closing the connection pool of the database
+func (driver *SQLDBDriver) Close() error {
+	driver.mu.Lock()
+	open := driver.transactions
+	driver.transactions = nil
+	driver.mu.Unlock()
+
+	for tx := range open {
+		tx.Rollback()
+	}
+	if err := driver.DB.Close(); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
+
+func (driver *SQLDBDriver) executor(ctx context.Context) executor {
+	if tx := driver.transaction(ctx); tx != nil {
+		return tx
+	}
+	return driver.DB
+}
+
+// mysqlDuplicateEntry is the number of the MySQL error raised by the violations of the unique keys
+const mysqlDuplicateEntry uint16 = 1062
+
+func wrap(err error) error {
+	var netErr net.Error
+	if errors.Is(err, sqldriver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.As(err, &netErr) {
+		return &dbdriver.UnavailableError{Cause: err}
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return &dbdriver.ConflictError{Cause: err}
+	}
+	return &dbdriver.Error{Cause: err}
 }
@@ -1,21 +1,92 @@
 package sqldbdriver
 
-// BeginTransaction is responsible for initiating a transaction in the database
-func (driver *SQLDBDriver) BeginTransaction() error {
-	return nil
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/zeroberto/go-ms-template/driver/dbdriver"
+)
+
+type transactionKey struct {
+	driver *SQLDBDriver
+}
+
+type transaction struct {
+	tx *sql.Tx
+}
+
+// BeginTransaction is responsible for initiating a transaction in the database,
+// providing a context to which the transaction is bound
+func (driver *SQLDBDriver) BeginTransaction(ctx context.Context) (context.Context, error) {
+	if driver.transaction(ctx) != nil {
+		return nil, &dbdriver.Error{Cause: errors.New("A transaction is already in progress")}
+	}
+	tx, err := driver.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, wrap(err)
+	}
+	driver.mu.Lock()
+	if driver.transactions == nil {
+		driver.transactions = map[*sql.Tx]struct{}{}
+	}
+	driver.transactions[tx] = struct{}{}
+	driver.mu.Unlock()
+	return context.WithValue(ctx, transactionKey{driver: driver}, &transaction{tx: tx}), nil
 }
 
 // Commit is responsible for persisting the modified information in the database
-func (driver *SQLDBDriver) Commit() error {
-	return driver.DB.Commit()
+func (driver *SQLDBDriver) Commit(ctx context.Context) error {
+	tx, err := driver.takeTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return &dbdriver.Error{Cause: err}
+	}
+	return nil
 }
 
-// EndTransaction is responsible for ending the transaction in the database
-func (driver *SQLDBDriver) EndTransaction() error {
-	return driver.DB.Rollback()
+// EndTransaction is responsible for ending the transaction in the database,
+// undoing it if it was neither committed nor rolled back
+func (driver *SQLDBDriver) EndTransaction(ctx context.Context) error {
+	if driver.transaction(ctx) == nil {
+		return nil
+	}
+	return driver.Rollback(ctx)
 }
 
 // Rollback is responsible for undoing all modifications made to the database within the current transaction
-func (driver *SQLDBDriver) Rollback() error {
+func (driver *SQLDBDriver) Rollback(ctx context.Context) error {
+	tx, err := driver.takeTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Rollback(); err != nil {
+		return &dbdriver.Error{Cause: err}
+	}
 	return nil
 }
+
+func (driver *SQLDBDriver) transaction(ctx context.Context) *sql.Tx {
+	t, ok := ctx.Value(transactionKey{driver: driver}).(*transaction)
+	if !ok {
+		return nil
+	}
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	return t.tx
+}
+
+func (driver *SQLDBDriver) takeTransaction(ctx context.Context) (*sql.Tx, error) {
+	t, ok := ctx.Value(transactionKey{driver: driver}).(*transaction)
+	driver.mu.Lock()
+	defer driver.mu.Unlock()
+	if !ok || t.tx == nil {
+		return nil, &dbdriver.Error{Cause: errors.New("There is no transaction in progress")}
+	}
+	tx := t.tx
+	t.tx = nil
+	delete(driver.transactions, tx)
+	return tx, nil
+}
@@ -0,0 +1,378 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Type represents the type of the values of a field
+type Type int
+
+const (
+	// String identifies fields with text values
+	String Type = iota
+	// Int identifies fields with integer values
+	Int
+	// Bool identifies fields with true or false values
+	Bool
+	// Time identifies fields with date and time values, informed as 2006-01-02 or RFC 3339
+	Time
+)
+
+// Fields represents the whitelist of the fields that can be used in expressions, by name
+type Fields map[string]Type
+
+// Operator represents the comparison applied by a Comparison
+type Operator string
+
+const (
+	// Equal is satisfied when the value of the field is equal to the value
+	Equal Operator = "=="
+	// NotEqual is satisfied when the value of the field is different from the value
+	NotEqual Operator = "!="
+	// GreaterThan is satisfied when the value of the field is greater than the value
+	GreaterThan Operator = "=gt="
+	// GreaterOrEqual is satisfied when the value of the field is greater than or equal to the value
+	GreaterOrEqual Operator = "=ge="
+	// LessThan is satisfied when the value of the field is less than the value
+	LessThan Operator = "=lt="
+	// LessOrEqual is satisfied when the value of the field is less than or equal to the value
+	LessOrEqual Operator = "=le="
+	// Prefix is satisfied when the text of the field starts with the value
+	Prefix Operator = "=prefix:"
+	// Contains is satisfied when the text of the field contains the value
+	Contains Operator = "=contains:"
+)
+
+var operators = map[Operator][]Type{
+	Equal:          {String, Int, Bool, Time},
+	NotEqual:       {String, Int, Bool, Time},
+	GreaterThan:    {String, Int, Time},
+	GreaterOrEqual: {String, Int, Time},
+	LessThan:       {String, Int, Time},
+	LessOrEqual:    {String, Int, Time},
+	Prefix:         {String},
+	Contains:       {String},
+}
+
+// Expression represents a node of the abstract syntax tree of a filter
+type Expression interface {
+	expression()
+}
+
+// And is satisfied when all of its operands are satisfied
+type And struct {
+	Operands []Expression
+}
+
+// Or is satisfied when any of its operands is satisfied
+type Or struct {
+	Operands []Expression
+}
+
+// Comparison is satisfied when the value of the field compares to Value according to the Operator
+type Comparison struct {
+	Field    string
+	Operator Operator
+	// Value has the Go type of the Type of the field: string, int64, bool or time.Time
+	Value interface{}
+}
+
+func (And) expression()        {}
+func (Or) expression()         {}
+func (Comparison) expression() {}
+
+// Order represents a sort criterion of a listing
+type Order struct {
+	Field      string
+	Descending bool
+}
+
+// Error must be reported when an expression cannot be parsed, pointing at the offending token
+type Error struct {
+	Message string
+	// Token is empty when the expression ends unexpectedly
+	Token string
+	// Position is the byte offset of the Token in the expression
+	Position int
+}
+
+func (err *Error) Error() string {
+	if err.Token == "" {
+		return fmt.Sprintf("%s at position %d", err.Message, err.Position)
+	}
+	return fmt.Sprintf("%s %q at position %d", err.Message, err.Token, err.Position)
+}
+
+// Parse is responsible for converting an expression such as
+// name=prefix:foo;useful==true,createdAt=ge=2026-01-01 into its syntax tree, where ";"
+// means and, "," means or, and parentheses group. Only the given fields may be used.
+// An empty expression results in a nil Expression
+func Parse(expression string, fields Fields) (Expression, error) {
+	p := &parser{input: expression, fields: fields}
+	p.skipSpaces()
+	if p.end() {
+		return nil, nil
+	}
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.end() {
+		return nil, p.errorf("unexpected token", p.position, p.input[p.position:p.position+1])
+	}
+	return result, nil
+}
+
+// ParseSort is responsible for converting a sort such as name,-createdAt into the
+// list of orders, where "-" means descending and "+" or nothing means ascending.
+// Only the given fields may be used. An empty sort results in no orders
+func ParseSort(sort string, fields Fields) ([]Order, error) {
+	var orders []Order
+	if strings.TrimSpace(sort) == "" {
+		return orders, nil
+	}
+	used := map[string]bool{}
+	position := 0
+	for _, item := range strings.Split(sort, ",") {
+		start := position + len(item) - len(strings.TrimLeftFunc(item, unicode.IsSpace))
+		position += len(item) + 1
+		name := strings.TrimSpace(item)
+		order := Order{}
+		if strings.HasPrefix(name, "-") || strings.HasPrefix(name, "+") {
+			order.Descending = name[0] == '-'
+			name = name[1:]
+		}
+		if name == "" {
+			return nil, &Error{Message: "missing sort field", Position: start}
+		}
+		if _, ok := fields[name]; !ok {
+			return nil, &Error{Message: "unknown field", Token: name, Position: start}
+		}
+		if used[name] {
+			return nil, &Error{Message: "duplicate sort field", Token: name, Position: start}
+		}
+		used[name] = true
+		order.Field = name
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+type parser struct {
+	input    string
+	position int
+	fields   Fields
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	return p.parseList(',', p.parseAnd, func(operands []Expression) Expression { return Or{Operands: operands} })
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	return p.parseList(';', p.parseTerm, func(operands []Expression) Expression { return And{Operands: operands} })
+}
+
+func (p *parser) parseList(separator byte, parseOperand func() (Expression, error), group func([]Expression) Expression) (Expression, error) {
+	operand, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []Expression{operand}
+	for p.peek() == separator {
+		p.position++
+		p.skipSpaces()
+		if operand, err = parseOperand(); err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return group(operands), nil
+}
+
+func (p *parser) parseTerm() (Expression, error) {
+	if p.peek() != '(' {
+		return p.parseComparison()
+	}
+	start := p.position
+	p.position++
+	p.skipSpaces()
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != ')' {
+		if p.end() {
+			return nil, p.errorf("unclosed parenthesis", start, "(")
+		}
+		return nil, p.errorf("unexpected token", p.position, p.input[p.position:p.position+1])
+	}
+	p.position++
+	p.skipSpaces()
+	return result, nil
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	fieldPosition := p.position
+	field := p.scan(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' })
+	if field == "" {
+		return nil, p.unexpected("expected field")
+	}
+	fieldType, ok := p.fields[field]
+	if !ok {
+		return nil, p.errorf("unknown field", fieldPosition, field)
+	}
+	p.skipSpaces()
+
+	operatorPosition := p.position
+	operator, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+	if !supports(operator, fieldType) {
+		return nil, p.errorf(fmt.Sprintf("operator not supported by field %s", field), operatorPosition, string(operator))
+	}
+	p.skipSpaces()
+
+	valuePosition := p.position
+	text, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	value, err := convert(text, fieldType)
+	if err != nil {
+		return nil, p.errorf(fmt.Sprintf("invalid value for field %s", field), valuePosition, p.input[valuePosition:p.position])
+	}
+	p.skipSpaces()
+	return Comparison{Field: field, Operator: operator, Value: value}, nil
+}
+
+func (p *parser) parseOperator() (Operator, error) {
+	start := p.position
+	switch {
+	case strings.HasPrefix(p.input[p.position:], string(Equal)):
+		p.position += len(Equal)
+		return Equal, nil
+	case strings.HasPrefix(p.input[p.position:], string(NotEqual)):
+		p.position += len(NotEqual)
+		return NotEqual, nil
+	case p.peek() != '=':
+		return "", p.unexpected("expected operator")
+	}
+	p.position++
+	p.scan(unicode.IsLetter)
+	if p.peek() == '=' || p.peek() == ':' {
+		p.position++
+	}
+	operator := Operator(p.input[start:p.position])
+	if _, ok := operators[operator]; !ok {
+		return "", p.errorf("unknown operator", start, string(operator))
+	}
+	return operator, nil
+}
+
+func (p *parser) parseValue() (string, error) {
+	quote := p.peek()
+	if quote != '"' && quote != '\'' {
+		value := p.scan(func(r rune) bool { return !unicode.IsSpace(r) && !strings.ContainsRune(";,()\"'", r) })
+		if value == "" {
+			return "", p.unexpected("expected value")
+		}
+		return value, nil
+	}
+	start := p.position
+	p.position++
+	var value strings.Builder
+	for !p.end() {
+		c := p.input[p.position]
+		p.position++
+		switch {
+		case c == quote:
+			return value.String(), nil
+		case c == '\\' && !p.end():
+			value.WriteByte(p.input[p.position])
+			p.position++
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unclosed quote", start, string(quote))
+}
+
+func (p *parser) scan(accept func(rune) bool) string {
+	start := p.position
+	for i, r := range p.input[start:] {
+		if !accept(r) {
+			p.position = start + i
+			return p.input[start:p.position]
+		}
+	}
+	p.position = len(p.input)
+	return p.input[start:]
+}
+
+func (p *parser) skipSpaces() {
+	p.scan(unicode.IsSpace)
+}
+
+func (p *parser) peek() byte {
+	if p.end() {
+		return 0
+	}
+	return p.input[p.position]
+}
+
+func (p *parser) end() bool {
+	return p.position >= len(p.input)
+}
+
+func (p *parser) unexpected(message string) error {
+	if p.end() {
+		return p.errorf("unexpected end of expression, "+message, p.position, "")
+	}
+	token := p.scan(func(r rune) bool { return !unicode.IsSpace(r) && !strings.ContainsRune(";,()", r) })
+	if token == "" {
+		token = p.input[p.position : p.position+1]
+	}
+	return p.errorf(message, p.position-len(token), token)
+}
+
+func (p *parser) errorf(message string, position int, token string) error {
+	return &Error{Message: message, Token: token, Position: position}
+}
+
+func supports(operator Operator, fieldType Type) bool {
+	for _, supported := range operators[operator] {
+		if supported == fieldType {
+			return true
+		}
+	}
+	return false
+}
+
+func convert(text string, fieldType Type) (interface{}, error) {
+	switch fieldType {
+	case Int:
+		return strconv.ParseInt(text, 10, 64)
+	case Bool:
+		switch text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", text)
+	case Time:
+		if value, err := time.Parse("2006-01-02", text); err == nil {
+			return value, nil
+		}
+		return time.Parse(time.RFC3339, text)
+	}
+	return text, nil
+}
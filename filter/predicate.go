@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"strings"
+	"time"
+)
+
+// Values provides the values of the fields of a record, by name, with the Go type of their
+// Type, nil standing for the NULL of SQL
+type Values func(field string) interface{}
+
+// Match is responsible for evaluating the expression against a record, for the backends
+// that cannot translate it into a query. A nil expression is satisfied by any record, and,
+// as in SQL, no comparison is satisfied by a nil value
+func Match(expression Expression, values Values) bool {
+	switch node := expression.(type) {
+	case nil:
+		return true
+	case And:
+		for _, operand := range node.Operands {
+			if !Match(operand, values) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, operand := range node.Operands {
+			if Match(operand, values) {
+				return true
+			}
+		}
+		return false
+	case Comparison:
+		value := values(node.Field)
+		switch node.Operator {
+		case Prefix:
+			text, ok := value.(string)
+			return ok && strings.HasPrefix(text, node.Value.(string))
+		case Contains:
+			text, ok := value.(string)
+			return ok && strings.Contains(text, node.Value.(string))
+		}
+		result, ok := compare(value, node.Value)
+		if !ok {
+			return false
+		}
+		switch node.Operator {
+		case Equal:
+			return result == 0
+		case NotEqual:
+			return result != 0
+		case GreaterThan:
+			return result > 0
+		case GreaterOrEqual:
+			return result >= 0
+		case LessThan:
+			return result < 0
+		case LessOrEqual:
+			return result <= 0
+		}
+	}
+	return false
+}
+
+// Compare is responsible for comparing two records according to the orders, resulting in
+// a negative number when a comes first, a positive number when b comes first and zero otherwise.
+// The nil values come first in ascending orders, as the NULL of MySQL
+func Compare(orders []Order, a Values, b Values) int {
+	for _, order := range orders {
+		x, y := a(order.Field), b(order.Field)
+		result, _ := compare(x, y)
+		switch {
+		case x == nil && y != nil:
+			result = -1
+		case x != nil && y == nil:
+			result = 1
+		}
+		if order.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+func compare(a interface{}, b interface{}) (int, bool) {
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case int64:
+		if y, ok := b.(int64); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, true
+			case y:
+				return -1, true
+			}
+			return 1, true
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			switch {
+			case x.Before(y):
+				return -1, true
+			case x.After(y):
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
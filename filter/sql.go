@@ -0,0 +1,87 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Columns maps the fields of the expressions to the SQL expressions that provide their values
+type Columns map[string]string
+
+// ToSQL is responsible for translating the expression into an SQL condition whose values
+// are given as parameters. A nil expression results in an empty condition
+func ToSQL(expression Expression, columns Columns) (string, []interface{}, error) {
+	if expression == nil {
+		return "", nil, nil
+	}
+	var args []interface{}
+	condition, err := toSQL(expression, columns, &args)
+	return condition, args, err
+}
+
+// OrderBy is responsible for translating the orders into the items of an SQL ORDER BY clause
+func OrderBy(orders []Order, columns Columns) ([]string, error) {
+	items := make([]string, 0, len(orders))
+	for _, order := range orders {
+		column, ok := columns[order.Field]
+		if !ok {
+			return nil, fmt.Errorf("No column mapped for field %s", order.Field)
+		}
+		direction := "ASC"
+		if order.Descending {
+			direction = "DESC"
+		}
+		items = append(items, fmt.Sprintf("%s %s", column, direction))
+	}
+	return items, nil
+}
+
+func toSQL(expression Expression, columns Columns, args *[]interface{}) (string, error) {
+	switch node := expression.(type) {
+	case And:
+		return join(node.Operands, " AND ", columns, args)
+	case Or:
+		return join(node.Operands, " OR ", columns, args)
+	case Comparison:
+		column, ok := columns[node.Field]
+		if !ok {
+			return "", fmt.Errorf("No column mapped for field %s", node.Field)
+		}
+		switch node.Operator {
+		case Prefix:
+			*args = append(*args, escapeLike(node.Value.(string))+"%")
+			return fmt.Sprintf("%s LIKE ? ESCAPE '!'", column), nil
+		case Contains:
+			*args = append(*args, "%"+escapeLike(node.Value.(string))+"%")
+			return fmt.Sprintf("%s LIKE ? ESCAPE '!'", column), nil
+		}
+		*args = append(*args, node.Value)
+		return fmt.Sprintf("%s %s ?", column, sqlOperators[node.Operator]), nil
+	}
+	return "", fmt.Errorf("Unsupported expression %T", expression)
+}
+
+var sqlOperators = map[Operator]string{
+	Equal:          "=",
+	NotEqual:       "<>",
+	GreaterThan:    ">",
+	GreaterOrEqual: ">=",
+	LessThan:       "<",
+	LessOrEqual:    "<=",
+}
+
+func join(operands []Expression, separator string, columns Columns, args *[]interface{}) (string, error) {
+	conditions := make([]string, 0, len(operands))
+	for _, operand := range operands {
+		condition, err := toSQL(operand, columns, args)
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+	}
+	return "(" + strings.Join(conditions, separator) + ")", nil
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
@@ -3,6 +3,10 @@ module github.com/zeroberto/go-ms-template
 go 1.14
 
 require (
+	github.com/go-sql-driver/mysql v1.5.0
+	github.com/gorilla/websocket v1.4.2
 	github.com/pkg/errors v0.9.1
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	golang.org/x/text v0.3.3
 	gopkg.in/yaml.v2 v2.3.0
 )
@@ -0,0 +1,136 @@
+package httphandler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/codec"
+)
+
+const (
+	// APIKeysPath represents the base path of the API keys, which are managed by administrators
+	APIKeysPath string = "/admin/api-keys"
+	// APIKeyPath represents the path of a single API key, identified by its ID
+	APIKeyPath string = APIKeysPath + "/{id}"
+	// APIKeyRotationPath represents the path of the rotation of an API key
+	APIKeyRotationPath string = APIKeyPath + ":rotate"
+)
+
+const (
+	// ListAPIKeys identifies the operation that provides all API keys
+	ListAPIKeys string = "listApiKeys"
+	// IssueAPIKey identifies the operation that issues an API key
+	IssueAPIKey string = "issueApiKey"
+	// RotateAPIKey identifies the operation that replaces the key of an API key
+	RotateAPIKey string = "rotateApiKey"
+	// RevokeAPIKey identifies the operation that revokes an API key
+	RevokeAPIKey string = "revokeApiKey"
+)
+
+// APIKeyRoutes lists all operations provided by the APIKeyHTTPHandler
+var APIKeyRoutes = []Route{
+	{Operation: ListAPIKeys, Method: http.MethodGet, Path: APIKeysPath},
+	{Operation: IssueAPIKey, Method: http.MethodPost, Path: APIKeysPath},
+	{Operation: RotateAPIKey, Method: http.MethodPost, Path: APIKeyRotationPath},
+	{Operation: RevokeAPIKey, Method: http.MethodDelete, Path: APIKeyPath},
+}
+
+// APIKeyHTTPHandler is responsible for providing the routines that manage the API keys, whose
+// responses are never stored by caches as they may hold keys
+type APIKeyHTTPHandler struct {
+	AKAPI api.APIKeyAPI
+	TS    chrono.TimeStamp
+	// Codecs selects the media types of the bodies by the Accept and Content-Type headers,
+	// codec.Default when nil
+	Codecs *codec.Registry
+}
+
+// ServeHTTP is responsible for dispatching the request to the API key API
+func (apiKeyHandler *APIKeyHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if err := apiKeyHandler.handle(writer, request); err != nil {
+		log.Printf("Couldn't handle %s %s: %v", request.Method, request.URL.Path, err)
+	}
+}
+
+func (apiKeyHandler *APIKeyHTTPHandler) handle(writer http.ResponseWriter, request *http.Request) error {
+	path, ID, ok := matchAPIKey(request.URL.EscapedPath())
+	if !ok {
+		return writeProblem(writer, request, http.StatusNotFound, "Resource not found", apiKeyHandler.TS)
+	}
+	writer.Header().Set("Cache-Control", "no-store")
+	var allowed []string
+	for _, route := range APIKeyRoutes {
+		if route.Path != path {
+			continue
+		}
+		if route.Method == request.Method {
+			return apiKeyHandler.dispatch(request.Context(), route.Operation, writer, request, ID)
+		}
+		allowed = append(allowed, route.Method)
+	}
+	writer.Header().Set("Allow", strings.Join(allowed, ", "))
+	return writeProblem(writer, request, http.StatusMethodNotAllowed, "Method not allowed", apiKeyHandler.TS)
+}
+
+func (apiKeyHandler *APIKeyHTTPHandler) dispatch(ctx context.Context, operation string, writer http.ResponseWriter, request *http.Request, ID int64) error {
+	switch operation {
+	case ListAPIKeys:
+		return apiKeyHandler.write(writer, request, apiKeyHandler.AKAPI.List(ctx))
+	case IssueAPIKey:
+		var apiKeyRequest api.APIKeyRequest
+		if err := decodeBody(request, &apiKeyRequest, apiKeyHandler.codecs()); err != nil {
+			return writeBodyError(writer, request, err, apiKeyHandler.codecs(), apiKeyHandler.TS)
+		}
+		return apiKeyHandler.write(writer, request, apiKeyHandler.AKAPI.Issue(ctx, apiKeyRequest))
+	case RotateAPIKey:
+		return apiKeyHandler.write(writer, request, apiKeyHandler.AKAPI.Rotate(ctx, ID))
+	case RevokeAPIKey:
+		return apiKeyHandler.write(writer, request, apiKeyHandler.AKAPI.Revoke(ctx, ID))
+	}
+	return fmt.Errorf("Operation %s is not supported", operation)
+}
+
+// write is responsible for writing the response, which holds neither validators nor links
+// as the API keys are not cached, the API keys issued being located under APIKeysPath
+func (apiKeyHandler *APIKeyHTTPHandler) write(writer http.ResponseWriter, request *http.Request, response api.Response) error {
+	if response.Path != 0 {
+		writer.Header().Set("Location", fmt.Sprintf("%s/%d", APIKeysPath, response.Path))
+	}
+	if response.Body == nil {
+		writer.WriteHeader(response.Code)
+		return nil
+	}
+	return writeBody(writer, request, response.Code, response.Body, apiKeyHandler.codecs(), apiKeyHandler.TS)
+}
+
+func (apiKeyHandler *APIKeyHTTPHandler) codecs() *codec.Registry {
+	return getCodecs(apiKeyHandler.Codecs)
+}
+
+// matchAPIKey is responsible for identifying the route path of the escaped path of a request,
+// along with the ID of the API key it holds
+func matchAPIKey(path string) (string, int64, bool) {
+	path = strings.TrimSuffix(path, "/")
+	if path == APIKeysPath {
+		return path, 0, true
+	}
+	value := strings.TrimPrefix(path, APIKeysPath+"/")
+	if value == path {
+		return "", 0, false
+	}
+	routePath := APIKeyPath
+	if strings.HasSuffix(value, ":rotate") {
+		value, routePath = strings.TrimSuffix(value, ":rotate"), APIKeyRotationPath
+	}
+	ID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return routePath, ID, true
+}
@@ -0,0 +1,149 @@
+// docs.js renders the OpenAPI document whose URL is the data-url of the #docs element: the
+// operations grouped by path, with their parameters, request bodies and responses, followed
+// by the schemas of the components. The text is always set with textContent, never parsed
+"use strict";
+
+(function () {
+  var root = document.getElementById("docs");
+
+  function element(tag, className, text) {
+    var node = document.createElement(tag);
+    if (className) {
+      node.className = className;
+    }
+    if (text !== undefined && text !== null) {
+      node.textContent = String(text);
+    }
+    return node;
+  }
+
+  function schemaName(schema) {
+    if (!schema) {
+      return "";
+    }
+    if (schema.$ref) {
+      return schema.$ref.replace("#/components/schemas/", "");
+    }
+    if (schema.type === "array") {
+      return schemaName(schema.items) + "[]";
+    }
+    var type = Array.isArray(schema.type) ? schema.type.join(" | ") : schema.type || "object";
+    return schema.format ? type + " (" + schema.format + ")" : type;
+  }
+
+  function contentList(content) {
+    var list = element("ul", "content");
+    Object.keys(content || {}).forEach(function (mediaType) {
+      list.appendChild(element("li", null, mediaType + ": " + schemaName(content[mediaType].schema)));
+    });
+    return list;
+  }
+
+  function parameters(operation) {
+    var table = element("table", "parameters");
+    var header = element("tr");
+    ["Name", "In", "Type", "Required", "Description"].forEach(function (title) {
+      header.appendChild(element("th", null, title));
+    });
+    table.appendChild(header);
+    (operation.parameters || []).forEach(function (parameter) {
+      var row = element("tr");
+      row.appendChild(element("td", "name", parameter.name));
+      row.appendChild(element("td", null, parameter.in));
+      row.appendChild(element("td", null, schemaName(parameter.schema)));
+      row.appendChild(element("td", null, parameter.required ? "yes" : "no"));
+      row.appendChild(element("td", null, parameter.description || ""));
+      table.appendChild(row);
+    });
+    return table;
+  }
+
+  function operation(path, method, details) {
+    var section = element("details", "operation " + method);
+    var summary = element("summary");
+    summary.appendChild(element("span", "method", method.toUpperCase()));
+    summary.appendChild(element("span", "path", path));
+    summary.appendChild(element("span", "summary", details.summary || details.operationId || ""));
+    section.appendChild(summary);
+    if (details.description) {
+      section.appendChild(element("p", null, details.description));
+    }
+    if (details.deprecated) {
+      section.appendChild(element("p", "deprecated", "Deprecated"));
+    }
+    if (details.parameters && details.parameters.length > 0) {
+      section.appendChild(element("h4", null, "Parameters"));
+      section.appendChild(parameters(details));
+    }
+    if (details.requestBody) {
+      section.appendChild(element("h4", null, "Request body"));
+      section.appendChild(contentList(details.requestBody.content));
+    }
+    section.appendChild(element("h4", null, "Responses"));
+    var responses = element("ul", "responses");
+    Object.keys(details.responses || {}).forEach(function (code) {
+      var response = details.responses[code];
+      var item = element("li", null, code + " " + (response.description || ""));
+      if (response.content) {
+        item.appendChild(contentList(response.content));
+      }
+      responses.appendChild(item);
+    });
+    section.appendChild(responses);
+    return section;
+  }
+
+  function schemas(components) {
+    var section = element("section", "schemas");
+    section.appendChild(element("h2", null, "Schemas"));
+    var all = (components && components.schemas) || {};
+    Object.keys(all).sort().forEach(function (name) {
+      var schema = all[name];
+      var details = element("details", "schema");
+      details.appendChild(element("summary", null, name));
+      var properties = schema.properties || {};
+      var required = schema.required || [];
+      var table = element("table", "properties");
+      Object.keys(properties).forEach(function (property) {
+        var row = element("tr");
+        row.appendChild(element("td", "name", property + (required.indexOf(property) >= 0 ? " *" : "")));
+        row.appendChild(element("td", null, schemaName(properties[property])));
+        row.appendChild(element("td", null, properties[property].description || ""));
+        table.appendChild(row);
+      });
+      details.appendChild(table);
+      section.appendChild(details);
+    });
+    return section;
+  }
+
+  function render(document_) {
+    root.textContent = "";
+    var info = document_.info || {};
+    root.appendChild(element("h1", null, (info.title || "API") + " " + (info.version || "")));
+    if (info.description) {
+      root.appendChild(element("p", null, info.description));
+    }
+    Object.keys(document_.paths || {}).forEach(function (path) {
+      var item = document_.paths[path];
+      ["get", "put", "post", "delete", "patch", "head", "options"].forEach(function (method) {
+        if (item[method]) {
+          root.appendChild(operation(path, method, item[method]));
+        }
+      });
+    });
+    root.appendChild(schemas(document_.components));
+  }
+
+  fetch(root.getAttribute("data-url"), {headers: {Accept: "application/json"}})
+    .then(function (response) {
+      if (!response.ok) {
+        throw new Error("status " + response.status);
+      }
+      return response.json();
+    })
+    .then(render)
+    .catch(function (error) {
+      root.textContent = "Couldn't load the OpenAPI document: " + error.message;
+    });
+})();
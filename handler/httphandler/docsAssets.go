@@ -0,0 +1,9 @@
+// Code generated by docsgen.go from the files of docs; DO NOT EDIT.
+
+package httphandler
+
+// docsAssets holds the files of the page that renders the OpenAPI document, by their path
+var docsAssets = map[string]docsAsset{
+	DocsPath + "/docs.css": {contentType: "text/css; charset=utf-8", content: "body { font-family: sans-serif; margin: 2em auto; max-width: 72em; color: #222; }\nh1 { font-size: 1.6em; }\n.operation { border: 1px solid #ccc; border-radius: 4px; margin: 0.5em 0; padding: 0.4em 0.8em; }\n.operation summary { cursor: pointer; }\n.method { display: inline-block; min-width: 5em; font-weight: bold; }\n.path { font-family: monospace; margin-right: 1em; }\n.summary { color: #555; }\n.get .method { color: #1b6ac9; }\n.post .method { color: #1d8a3a; }\n.put .method, .patch .method { color: #b86e00; }\n.delete .method { color: #c0392b; }\n.deprecated { color: #c0392b; font-weight: bold; }\ntable { border-collapse: collapse; margin: 0.4em 0; }\nth, td { border-bottom: 1px solid #eee; padding: 0.2em 0.8em; text-align: left; vertical-align: top; }\n.name { font-family: monospace; }\n.schema { margin: 0.3em 0; }\n"},
+	DocsPath + "/docs.js":  {contentType: "text/javascript; charset=utf-8", content: "// docs.js renders the OpenAPI document whose URL is the data-url of the #docs element: the\n// operations grouped by path, with their parameters, request bodies and responses, followed\n// by the schemas of the components. The text is always set with textContent, never parsed\n\"use strict\";\n\n(function () {\n  var root = document.getElementById(\"docs\");\n\n  function element(tag, className, text) {\n    var node = document.createElement(tag);\n    if (className) {\n      node.className = className;\n    }\n    if (text !== undefined && text !== null) {\n      node.textContent = String(text);\n    }\n    return node;\n  }\n\n  function schemaName(schema) {\n    if (!schema) {\n      return \"\";\n    }\n    if (schema.$ref) {\n      return schema.$ref.replace(\"#/components/schemas/\", \"\");\n    }\n    if (schema.type === \"array\") {\n      return schemaName(schema.items) + \"[]\";\n    }\n    var type = Array.isArray(schema.type) ? schema.type.join(\" | \") : schema.type || \"object\";\n    return schema.format ? type + \" (\" + schema.format + \")\" : type;\n  }\n\n  function contentList(content) {\n    var list = element(\"ul\", \"content\");\n    Object.keys(content || {}).forEach(function (mediaType) {\n      list.appendChild(element(\"li\", null, mediaType + \": \" + schemaName(content[mediaType].schema)));\n    });\n    return list;\n  }\n\n  function parameters(operation) {\n    var table = element(\"table\", \"parameters\");\n    var header = element(\"tr\");\n    [\"Name\", \"In\", \"Type\", \"Required\", \"Description\"].forEach(function (title) {\n      header.appendChild(element(\"th\", null, title));\n    });\n    table.appendChild(header);\n    (operation.parameters || []).forEach(function (parameter) {\n      var row = element(\"tr\");\n      row.appendChild(element(\"td\", \"name\", parameter.name));\n      row.appendChild(element(\"td\", null, parameter.in));\n      row.appendChild(element(\"td\", null, schemaName(parameter.schema)));\n      row.appendChild(element(\"td\", null, parameter.required ? \"yes\" : \"no\"));\n      row.appendChild(element(\"td\", null, parameter.description || \"\"));\n      table.appendChild(row);\n    });\n    return table;\n  }\n\n  function operation(path, method, details) {\n    var section = element(\"details\", \"operation \" + method);\n    var summary = element(\"summary\");\n    summary.appendChild(element(\"span\", \"method\", method.toUpperCase()));\n    summary.appendChild(element(\"span\", \"path\", path));\n    summary.appendChild(element(\"span\", \"summary\", details.summary || details.operationId || \"\"));\n    section.appendChild(summary);\n    if (details.description) {\n      section.appendChild(element(\"p\", null, details.description));\n    }\n    if (details.deprecated) {\n      section.appendChild(element(\"p\", \"deprecated\", \"Deprecated\"));\n    }\n    if (details.parameters && details.parameters.length > 0) {\n      section.appendChild(element(\"h4\", null, \"Parameters\"));\n      section.appendChild(parameters(details));\n    }\n    if (details.requestBody) {\n      section.appendChild(element(\"h4\", null, \"Request body\"));\n      section.appendChild(contentList(details.requestBody.content));\n    }\n    section.appendChild(element(\"h4\", null, \"Responses\"));\n    var responses = element(\"ul\", \"responses\");\n    Object.keys(details.responses || {}).forEach(function (code) {\n      var response = details.responses[code];\n      var item = element(\"li\", null, code + \" \" + (response.description || \"\"));\n      if (response.content) {\n        item.appendChild(contentList(response.content));\n      }\n      responses.appendChild(item);\n    });\n    section.appendChild(responses);\n    return section;\n  }\n\n  function schemas(components) {\n    var section = element(\"section\", \"schemas\");\n    section.appendChild(element(\"h2\", null, \"Schemas\"));\n    var all = (components && components.schemas) || {};\n    Object.keys(all).sort().forEach(function (name) {\n      var schema = all[name];\n      var details = element(\"details\", \"schema\");\n      details.appendChild(element(\"summary\", null, name));\n      var properties = schema.properties || {};\n      var required = schema.required || [];\n      var table = element(\"table\", \"properties\");\n      Object.keys(properties).forEach(function (property) {\n        var row = element(\"tr\");\n        row.appendChild(element(\"td\", \"name\", property + (required.indexOf(property) >= 0 ? \" *\" : \"\")));\n        row.appendChild(element(\"td\", null, schemaName(properties[property])));\n        row.appendChild(element(\"td\", null, properties[property].description || \"\"));\n        table.appendChild(row);\n      });\n      details.appendChild(table);\n      section.appendChild(details);\n    });\n    return section;\n  }\n\n  function render(document_) {\n    root.textContent = \"\";\n    var info = document_.info || {};\n    root.appendChild(element(\"h1\", null, (info.title || \"API\") + \" \" + (info.version || \"\")));\n    if (info.description) {\n      root.appendChild(element(\"p\", null, info.description));\n    }\n    Object.keys(document_.paths || {}).forEach(function (path) {\n      var item = document_.paths[path];\n      [\"get\", \"put\", \"post\", \"delete\", \"patch\", \"head\", \"options\"].forEach(function (method) {\n        if (item[method]) {\n          root.appendChild(operation(path, method, item[method]));\n        }\n      });\n    });\n    root.appendChild(schemas(document_.components));\n  }\n\n  fetch(root.getAttribute(\"data-url\"), {headers: {Accept: \"application/json\"}})\n    .then(function (response) {\n      if (!response.ok) {\n        throw new Error(\"status \" + response.status);\n      }\n      return response.json();\n    })\n    .then(render)\n    .catch(function (error) {\n      root.textContent = \"Couldn't load the OpenAPI document: \" + error.message;\n    });\n})();\n"},
+}
@@ -0,0 +1,59 @@
+//go:build ignore
+// +build ignore
+
+// docsgen writes docsAssets.go, which holds the files of docs in the binary, so that the page
+// of the OpenAPI document is served without loading anything from other origins
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// contentTypes holds the media types of the files by their extension, fixed rather than read
+// from the system, so that the output is the same on every machine
+var contentTypes = map[string]string{
+	".css": "text/css; charset=utf-8",
+	".js":  "text/javascript; charset=utf-8",
+}
+
+func main() {
+	files, err := filepath.Glob(filepath.Join("docs", "*"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(files)
+
+	var source bytes.Buffer
+	source.WriteString("// Code generated by docsgen.go from the files of docs; DO NOT EDIT.\n\n")
+	source.WriteString("package httphandler\n\n")
+	source.WriteString("// docsAssets holds the files of the page that renders the OpenAPI document, by their path\n")
+	source.WriteString("var docsAssets = map[string]docsAsset{\n")
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		contentType, ok := contentTypes[filepath.Ext(file)]
+		if !ok {
+			log.Fatalf("unknown media type of %s", file)
+		}
+		fmt.Fprintf(&source, "DocsPath + %s: {contentType: %s, content: %s},\n",
+			strconv.Quote("/"+filepath.Base(file)), strconv.Quote(contentType), strconv.Quote(string(content)))
+	}
+	source.WriteString("}\n")
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("docsAssets.go", formatted, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
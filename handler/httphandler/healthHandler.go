@@ -0,0 +1,82 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/health"
+)
+
+const (
+	// LivenessPath represents the path that reports whether the service runs
+	LivenessPath string = "/healthz"
+	// ReadinessPath represents the path that reports whether the service accepts requests
+	ReadinessPath string = "/readyz"
+	// HealthPath represents the path of the detailed report of the health of the service
+	HealthPath string = "/health"
+)
+
+// GetHealth represents the operation of the detailed report, served behind the authentication
+const GetHealth string = "getHealth"
+
+// HealthRoutes lists the operations of the HealthHTTPHandler that are served with the API,
+// the liveness and the readiness being left to the orchestrators
+var HealthRoutes = []Route{
+	{Operation: GetHealth, Method: http.MethodGet, Path: HealthPath},
+	{Operation: GetHealth, Method: http.MethodHead, Path: HealthPath},
+}
+
+// HealthHTTPHandler is responsible for reporting the health of the service to the
+// orchestrators, the failing reports being answered with 503
+type HealthHTTPHandler struct {
+	HR health.Reporter
+	// ShowErrors reports the errors of the dependencies in the detailed report, which must
+	// then be served to authenticated clients only
+	ShowErrors bool
+	TS         chrono.TimeStamp
+}
+
+// ServeHTTP is responsible for providing the liveness, the readiness and the detailed report,
+// which omit the errors of the dependencies unless ShowErrors is set
+func (healthHandler *HealthHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		writer.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		writeProblem(writer, request, http.StatusMethodNotAllowed, "Method not allowed", healthHandler.TS)
+		return
+	}
+	var report health.Report
+	switch strings.TrimSuffix(request.URL.Path, "/") {
+	case LivenessPath:
+		report = healthHandler.HR.Alive()
+	case ReadinessPath:
+		report = withoutErrors(healthHandler.HR.Report(request.Context()))
+	case HealthPath:
+		report = healthHandler.HR.Report(request.Context())
+		if !healthHandler.ShowErrors {
+			report = withoutErrors(report)
+		}
+	default:
+		writeProblem(writer, request, http.StatusNotFound, "Resource not found", healthHandler.TS)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Cache-Control", "no-store")
+	if report.Status != health.StatusUp {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(writer).Encode(report)
+}
+
+// withoutErrors is responsible for omitting the errors of the dependencies, which may reveal
+// their addresses and credentials
+func withoutErrors(report health.Report) health.Report {
+	checks := make([]health.CheckReport, len(report.Checks))
+	for i, check := range report.Checks {
+		check.Error = ""
+		checks[i] = check
+	}
+	report.Checks = checks
+	return report
+}
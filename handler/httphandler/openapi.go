@@ -0,0 +1,504 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/codec"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/openapi"
+	"github.com/zeroberto/go-ms-template/patch"
+)
+
+const (
+	// OpenAPIPath represents the path of the OpenAPI document of the Example API
+	OpenAPIPath string = "/openapi.json"
+	// DocsPath represents the path of the page that renders the OpenAPI document
+	DocsPath string = "/docs"
+)
+
+// operationDoc describes an operation of Routes for the OpenAPI document, where the
+// request and response bodies are given by values of their types
+type operationDoc struct {
+	summary    string
+	parameters []string
+	request    map[string]interface{}
+	responses  map[int]responseDoc
+}
+
+type responseDoc struct {
+	description string
+	headers     []string
+	body        interface{}
+}
+
+// exampleDoc stands for the Example in the representation of the version of the operation
+type exampleDoc struct{}
+
+// batchDoc stands for the batch of changes in the representation of the version of the operation
+type batchDoc struct{}
+
+// pageOfExamples stands for the api.Page of the listings, whose items are Examples
+type pageOfExamples struct{}
+
+// eventStream stands for the Server-Sent Events of the changes to Examples, whose data is an
+// api.ExampleEventBody
+type eventStream struct{}
+
+// pageOfAPIKeys stands for the api.Page of the listing of the API keys
+type pageOfAPIKeys struct{}
+
+// jsonPatch stands for a JSON Patch document
+type jsonPatch []JSONPatchOperation
+
+// JSONPatchOperation describes an operation of a JSON Patch document in the OpenAPI document
+type JSONPatchOperation struct {
+	Op    string      `json:"op" validate:"required,enum=add|remove|replace|move|copy|test"`
+	Path  string      `json:"path" validate:"required"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// mergePatch stands for a JSON Merge Patch document
+type mergePatch map[string]interface{}
+
+var parameterDocs = map[string]openapi.Parameter{
+	"id":            {Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+	"name":          {Name: "name", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+	"limit":         {Name: "limit", In: "query", Description: "Maximum number of items of the page", Schema: &openapi.Schema{Type: "integer"}},
+	"offset":        {Name: "offset", In: "query", Description: "Number of items skipped", Schema: &openapi.Schema{Type: "integer", Format: "int64"}},
+	"cursor":        {Name: "cursor", In: "query", Description: "Position of the page, as provided by the Link header", Schema: &openapi.Schema{Type: "string"}},
+	"total":         {Name: "total", In: "query", Description: "Whether the total of items is provided", Schema: &openapi.Schema{Type: "boolean"}},
+	"filter":        {Name: "filter", In: "query", Description: "Expression restricting the items, such as useful==true", Schema: &openapi.Schema{Type: "string"}},
+	"sort":          {Name: "sort", In: "query", Description: "Comma-separated fields, prefixed by - when descending", Schema: &openapi.Schema{Type: "string"}},
+	"mode":          {Name: "mode", In: "query", Description: "Whether the Example is removed or deactivated", Schema: &openapi.Schema{Type: "string", Enum: []string{"physical", "logical"}}},
+	"If-Match":      {Name: "If-Match", In: "header", Description: "ETag the Example must currently have", Schema: &openapi.Schema{Type: "string"}},
+	"If-None-Match": {Name: "If-None-Match", In: "header", Description: "ETags already held by the client", Schema: &openapi.Schema{Type: "string"}},
+	"type":          {Name: "type", In: "query", Description: "Comma-separated types of the changes streamed", Schema: &openapi.Schema{Type: "string"}},
+	"ids":           {Name: "id", In: "query", Description: "Comma-separated IDs of the Examples whose changes are streamed", Schema: &openapi.Schema{Type: "string"}},
+	"Last-Event-ID": {Name: "Last-Event-ID", In: "header", Description: "ID of the last change received, after which the stream resumes", Schema: &openapi.Schema{Type: "string"}},
+	"lastEventId":   {Name: "lastEventId", In: "query", Description: "Last-Event-ID for the clients that cannot inform headers", Schema: &openapi.Schema{Type: "string"}},
+	"Idempotency-Key": {Name: "Idempotency-Key", In: "header", Description: "Unique key of the request, whose retries get the same response",
+		Schema: &openapi.Schema{Type: "string", MaxLength: &maxIdempotencyKeyLength}},
+}
+
+var headerDocs = map[string]openapi.Header{
+	"ETag":          {Description: "Version of the representation", Schema: &openapi.Schema{Type: "string"}},
+	"Last-Modified": {Description: "Moment of the last change", Schema: &openapi.Schema{Type: "string"}},
+	"Link":          {Description: "Links to the first, previous and next pages (RFC 8288)", Schema: &openapi.Schema{Type: "string"}},
+	"Location":      {Description: "Path of the resource created", Schema: &openapi.Schema{Type: "string"}},
+	"Accept-Patch":  {Description: "Patch media types accepted", Schema: &openapi.Schema{Type: "string"}},
+	"Idempotent-Replayed": {Description: "Whether the response is the one of a previous request with the Idempotency-Key",
+		Schema: &openapi.Schema{Type: "boolean"}},
+	"Deprecation": {Description: "Moment from which the version of the API is deprecated (RFC 9745)", Schema: &openapi.Schema{Type: "string"}},
+	"Sunset":      {Description: "Moment from which the version of the API may no longer be provided (RFC 8594)", Schema: &openapi.Schema{Type: "string"}},
+}
+
+var maxIdempotencyKeyLength = MaxIdempotencyKeyLength
+
+var listParameters = []string{"limit", "offset", "cursor", "total", "filter", "sort", "If-None-Match"}
+
+var operationDocs = map[string]operationDoc{
+	ListExamples: {
+		summary:    "Provides a page of all Examples",
+		parameters: listParameters,
+		responses: map[int]responseDoc{
+			http.StatusOK:          {description: "Page of Examples", headers: []string{"ETag", "Last-Modified", "Link"}, body: pageOfExamples{}},
+			http.StatusNotModified: {description: "The page did not change"},
+			http.StatusBadRequest:  {description: "Invalid parameters", body: api.Problem{}},
+		},
+	},
+	ListActiveExamples: {
+		summary:    "Provides a page of the active Examples",
+		parameters: listParameters,
+		responses: map[int]responseDoc{
+			http.StatusOK:          {description: "Page of active Examples", headers: []string{"ETag", "Last-Modified", "Link"}, body: pageOfExamples{}},
+			http.StatusNotModified: {description: "The page did not change"},
+			http.StatusBadRequest:  {description: "Invalid parameters", body: api.Problem{}},
+		},
+	},
+	CreateExample: {
+		summary:    "Creates an Example",
+		parameters: []string{"Idempotency-Key"},
+		request:    map[string]interface{}{"application/json": exampleDoc{}},
+		responses: map[int]responseDoc{
+			http.StatusCreated:             {description: "Example created", headers: []string{"ETag", "Location", "Idempotent-Replayed"}},
+			http.StatusBadRequest:          {description: "Malformed body or Idempotency-Key", body: api.Problem{}},
+			http.StatusConflict:            {description: "An Example with the same name exists, or a request with the Idempotency-Key is in progress", body: api.Problem{}},
+			http.StatusUnprocessableEntity: {description: "Invalid Example, or Idempotency-Key used for another request", body: api.Problem{}},
+		},
+	},
+	GetExample: {
+		summary:    "Provides an Example via its ID",
+		parameters: []string{"id", "If-None-Match"},
+		responses: map[int]responseDoc{
+			http.StatusOK:          {description: "Example", headers: []string{"ETag", "Last-Modified"}, body: exampleDoc{}},
+			http.StatusNotModified: {description: "The Example did not change"},
+			http.StatusNotFound:    {description: "The Example does not exist", body: api.Problem{}},
+		},
+	},
+	GetExampleByName: {
+		summary:    "Provides an Example via its name",
+		parameters: []string{"name", "If-None-Match"},
+		responses: map[int]responseDoc{
+			http.StatusOK:          {description: "Example", headers: []string{"ETag", "Last-Modified"}, body: exampleDoc{}},
+			http.StatusNotModified: {description: "The Example did not change"},
+			http.StatusNotFound:    {description: "No Example has the name", body: api.Problem{}},
+		},
+	},
+	UpdateExample: {
+		summary:    "Updates a complete Example, or creates it when it does not exist",
+		parameters: []string{"id", "If-Match", "If-None-Match"},
+		request:    map[string]interface{}{"application/json": exampleDoc{}},
+		responses: map[int]responseDoc{
+			http.StatusCreated:              {description: "Example created", headers: []string{"ETag", "Location"}},
+			http.StatusNoContent:            {description: "Example updated", headers: []string{"ETag"}},
+			http.StatusBadRequest:           {description: "Malformed body", body: api.Problem{}},
+			http.StatusConflict:             {description: "An Example with the same name exists", body: api.Problem{}},
+			http.StatusPreconditionFailed:   {description: "The Example does not match If-Match", body: api.Problem{}},
+			http.StatusUnprocessableEntity:  {description: "Invalid Example", body: api.Problem{}},
+			http.StatusPreconditionRequired: {description: "If-Match is required", body: api.Problem{}},
+		},
+	},
+	PartialUpdateExample: {
+		summary:    "Applies a patch to an Example",
+		parameters: []string{"id", "If-Match"},
+		request: map[string]interface{}{
+			patch.MergePatchType: mergePatch{},
+			patch.JSONPatchType:  jsonPatch{},
+			"application/json":   mergePatch{},
+		},
+		responses: map[int]responseDoc{
+			http.StatusNoContent:            {description: "Example updated", headers: []string{"ETag"}},
+			http.StatusBadRequest:           {description: "Malformed patch", body: api.Problem{}},
+			http.StatusNotFound:             {description: "The Example does not exist", body: api.Problem{}},
+			http.StatusConflict:             {description: "A test operation failed or the name is taken", body: api.Problem{}},
+			http.StatusPreconditionFailed:   {description: "The Example does not match If-Match", body: api.Problem{}},
+			http.StatusUnsupportedMediaType: {description: "Unsupported patch media type", headers: []string{"Accept-Patch"}, body: api.Problem{}},
+			http.StatusUnprocessableEntity:  {description: "Invalid patched Example", body: api.Problem{}},
+			http.StatusPreconditionRequired: {description: "If-Match is required", body: api.Problem{}},
+		},
+	},
+	DeleteExample: {
+		summary:    "Deletes an Example, or deactivates it with mode=logical",
+		parameters: []string{"id", "mode", "If-Match"},
+		responses: map[int]responseDoc{
+			http.StatusNoContent:            {description: "Example deleted"},
+			http.StatusBadRequest:           {description: "Invalid mode", body: api.Problem{}},
+			http.StatusNotFound:             {description: "The Example does not exist", body: api.Problem{}},
+			http.StatusPreconditionFailed:   {description: "The Example does not match If-Match", body: api.Problem{}},
+			http.StatusPreconditionRequired: {description: "If-Match is required", body: api.Problem{}},
+		},
+	},
+	BatchExamples: {
+		summary: "Applies a batch of changes to Examples",
+		request: map[string]interface{}{"application/json": batchDoc{}},
+		responses: map[int]responseDoc{
+			http.StatusOK:                  {description: "Result of each item", body: api.BatchResponse{}},
+			http.StatusBadRequest:          {description: "Malformed body", body: api.Problem{}},
+			http.StatusUnprocessableEntity: {description: "Invalid batch", body: api.Problem{}},
+		},
+	},
+	StreamExamples: {
+		summary:    "Streams the changes to Examples over Server-Sent Events, or over WebSocket when the request opens one",
+		parameters: []string{"type", "ids", "Last-Event-ID", "lastEventId"},
+		responses: map[int]responseDoc{
+			http.StatusSwitchingProtocols: {description: "WebSocket whose messages are the changes, the text messages of the client replacing the filter"},
+			http.StatusOK:                 {description: "Changes, as events named by their type", body: eventStream{}},
+			http.StatusBadRequest:         {description: "Invalid parameters", body: api.Problem{}},
+		},
+	},
+	ListAPIKeys: {
+		summary: "Provides all API keys, revoked ones included",
+		responses: map[int]responseDoc{
+			http.StatusOK: {description: "API keys", headers: []string{"Link"}, body: pageOfAPIKeys{}},
+		},
+	},
+	IssueAPIKey: {
+		summary: "Issues an API key, provided only in this response",
+		request: map[string]interface{}{"application/json": api.APIKeyRequest{}},
+		responses: map[int]responseDoc{
+			http.StatusCreated:             {description: "API key issued", headers: []string{"Location"}, body: api.IssuedAPIKey{}},
+			http.StatusBadRequest:          {description: "Malformed body", body: api.Problem{}},
+			http.StatusUnprocessableEntity: {description: "Invalid API key", body: api.Problem{}},
+		},
+	},
+	RotateAPIKey: {
+		summary:    "Replaces the key of an API key, the previous key no longer authenticating",
+		parameters: []string{"id"},
+		responses: map[int]responseDoc{
+			http.StatusOK:       {description: "New key, provided only in this response", body: api.IssuedAPIKey{}},
+			http.StatusNotFound: {description: "The API key does not exist", body: api.Problem{}},
+			http.StatusConflict: {description: "The API key is revoked", body: api.Problem{}},
+		},
+	},
+	RevokeAPIKey: {
+		summary:    "Revokes an API key",
+		parameters: []string{"id"},
+		responses: map[int]responseDoc{
+			http.StatusNoContent: {description: "API key revoked"},
+			http.StatusNotFound:  {description: "The API key does not exist", body: api.Problem{}},
+		},
+	},
+}
+
+// OpenAPI is responsible for generating the OpenAPI document of the operations of Routes, under
+// each of the versions, and of APIKeyRoutes, failing when an operation is not documented or a
+// documented operation has no route. The versions are api.DefaultVersions() when nil
+func OpenAPI(info openapi.Info, versions []api.Version) (*openapi.Document, error) {
+	if versions == nil {
+		versions = api.DefaultVersions()
+	}
+	document := &openapi.Document{OpenAPI: openapi.Version, Info: info, Paths: map[string]openapi.PathItem{}}
+	for _, version := range versions {
+		for _, route := range Routes {
+			versionedRoute := Route{Operation: route.Operation + strings.Title(version.Name), Method: route.Method, Path: "/" + version.Name + route.Path}
+			if err := addOperation(document, versionedRoute, route.Operation, &version); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, route := range APIKeyRoutes {
+		if err := addOperation(document, route, route.Operation, nil); err != nil {
+			return nil, err
+		}
+	}
+	routes := allRoutes()
+	if len(operationDocs) != len(routes) {
+		var missing []string
+		for operation := range operationDocs {
+			if !hasRoute(routes, operation) {
+				missing = append(missing, operation)
+			}
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("Operations %s are documented but have no route", strings.Join(missing, ", "))
+	}
+	return document, nil
+}
+
+// addOperation is responsible for adding the route to the document, as documented for the
+// operation, with the representation of the version when the route has one
+func addOperation(document *openapi.Document, route Route, operation string, version *api.Version) error {
+	components := &document.Components
+	doc, ok := operationDocs[operation]
+	if !ok {
+		return fmt.Errorf("Operation %s is not documented", operation)
+	}
+	var representation api.ExampleRepresentation
+	var headers []string
+	if version != nil {
+		representation = version.Representation
+		if !version.Deprecation.IsZero() {
+			headers = append(headers, "Deprecation")
+		}
+		if !version.Sunset.IsZero() {
+			headers = append(headers, "Sunset")
+		}
+	}
+	apiOperation := &openapi.Operation{
+		OperationID: route.Operation,
+		Summary:     doc.summary,
+		Responses:   map[string]openapi.Response{},
+		Deprecated:  version != nil && !version.Deprecation.IsZero(),
+	}
+	for _, name := range doc.parameters {
+		parameter, ok := parameterDocs[name]
+		if !ok {
+			return fmt.Errorf("Parameter %s of %s is not documented", name, operation)
+		}
+		apiOperation.Parameters = append(apiOperation.Parameters, parameter)
+	}
+	if len(doc.request) > 0 {
+		apiOperation.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{}}
+		for mediaType, body := range doc.request {
+			apiOperation.RequestBody.Content[mediaType] = openapi.MediaType{Schema: getSchema(components, body, representation)}
+			if _, ok := body.(mergePatch); ok || mediaType != "application/json" {
+				continue
+			}
+			for _, alternative := range codec.Default.MediaTypes(false) {
+				apiOperation.RequestBody.Content[alternative] = openapi.MediaType{Schema: getSchema(components, body, representation)}
+			}
+		}
+	}
+	for code, response := range doc.responses {
+		response.headers = append(append([]string{}, response.headers...), headers...)
+		apiOperation.Responses[strconv.Itoa(code)] = toResponse(components, response, representation)
+	}
+	apiOperation.Responses["default"] = toResponse(components, responseDoc{description: "Unexpected error", headers: headers, body: api.Problem{}}, representation)
+
+	if document.Paths[route.Path] == nil {
+		document.Paths[route.Path] = openapi.PathItem{}
+	}
+	document.Paths[route.Path][strings.ToLower(route.Method)] = apiOperation
+	return nil
+}
+
+func allRoutes() []Route {
+	routes := make([]Route, 0, len(Routes)+len(APIKeyRoutes))
+	return append(append(routes, Routes...), APIKeyRoutes...)
+}
+
+func hasRoute(routes []Route, operation string) bool {
+	for _, route := range routes {
+		if route.Operation == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func toResponse(components *openapi.Components, doc responseDoc, representation api.ExampleRepresentation) openapi.Response {
+	response := openapi.Response{Description: doc.description}
+	for _, name := range doc.headers {
+		if response.Headers == nil {
+			response.Headers = map[string]openapi.Header{}
+		}
+		response.Headers[name] = headerDocs[name]
+	}
+	if _, ok := doc.body.(api.Problem); ok {
+		response.Content = map[string]openapi.MediaType{"application/problem+json": {Schema: getSchema(components, doc.body, representation)}}
+	} else if _, ok := doc.body.(eventStream); ok {
+		response.Content = map[string]openapi.MediaType{EventStreamType: {Schema: getSchema(components, doc.body, representation)}}
+	} else if doc.body != nil {
+		response.Content = map[string]openapi.MediaType{}
+		for _, bodyCodec := range codec.Default.Codecs {
+			response.Content[bodyCodec.MediaTypes()[0]] = openapi.MediaType{Schema: getSchema(components, doc.body, representation)}
+		}
+		if isPageDoc(doc.body) {
+			for _, listingCodec := range codec.Default.Listings {
+				response.Content[listingCodec.MediaTypes()[0]] = openapi.MediaType{Schema: &openapi.Schema{
+					Type:        "string",
+					Description: "The items of the page, a row each after a header row naming their properties",
+				}}
+			}
+		}
+	}
+	return response
+}
+
+func isPageDoc(body interface{}) bool {
+	switch body.(type) {
+	case pageOfExamples, pageOfAPIKeys:
+		return true
+	}
+	return false
+}
+
+// getSchema is responsible for providing the schema of the body, whose Examples follow the
+// representation
+func getSchema(components *openapi.Components, body interface{}, representation api.ExampleRepresentation) *openapi.Schema {
+	switch body.(type) {
+	case exampleDoc:
+		return components.SchemaOf(representation.FromExample(model.Example{}))
+	case batchDoc:
+		return components.SchemaOf(representation.NewBatch())
+	case pageOfExamples:
+		return &openapi.Schema{AllOf: []*openapi.Schema{
+			components.SchemaOf(api.Page{}),
+			{Properties: map[string]*openapi.Schema{"items": {Type: "array", Items: components.SchemaOf(representation.FromExample(model.Example{}))}}},
+		}}
+	case pageOfAPIKeys:
+		return &openapi.Schema{AllOf: []*openapi.Schema{
+			components.SchemaOf(api.Page{}),
+			{Properties: map[string]*openapi.Schema{"items": {Type: "array", Items: components.SchemaOf(api.APIKey{})}}},
+		}}
+	case eventStream:
+		return &openapi.Schema{AllOf: []*openapi.Schema{
+			components.SchemaOf(api.ExampleEventBody{}),
+			{Properties: map[string]*openapi.Schema{"example": components.SchemaOf(representation.FromExample(model.Example{}))}},
+		}}
+	case mergePatch:
+		return &openapi.Schema{Type: "object", Description: "Properties of the Example to change, null removing them"}
+	}
+	return components.SchemaOf(body)
+}
+
+// OpenAPIHTTPHandler is responsible for serving the OpenAPI document of the Example API and
+// the page that renders it
+type OpenAPIHTTPHandler struct {
+	Info openapi.Info
+	TS   chrono.TimeStamp
+	// Versions lists the versions of the API documented, api.DefaultVersions() when nil
+	Versions []api.Version
+
+	once     sync.Once
+	document []byte
+	err      error
+}
+
+// ServeHTTP is responsible for providing the OpenAPI document, generated on the first request
+func (openAPIHandler *OpenAPIHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		writer.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		writeProblem(writer, request, http.StatusMethodNotAllowed, "Method not allowed", openAPIHandler.TS)
+		return
+	}
+	switch strings.TrimSuffix(request.URL.Path, "/") {
+	case OpenAPIPath:
+		openAPIHandler.once.Do(func() {
+			var document *openapi.Document
+			if document, openAPIHandler.err = OpenAPI(openAPIHandler.Info, openAPIHandler.Versions); openAPIHandler.err == nil {
+				openAPIHandler.document, openAPIHandler.err = json.Marshal(document)
+			}
+		})
+		if openAPIHandler.err != nil {
+			writeProblem(writer, request, http.StatusInternalServerError, openAPIHandler.err.Error(), openAPIHandler.TS)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Write(openAPIHandler.document)
+	case DocsPath:
+		writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+		writer.Header().Set("Content-Security-Policy", docsContentSecurityPolicy)
+		fmt.Fprintf(writer, docsPage, html.EscapeString(openAPIHandler.Info.Title), OpenAPIPath)
+	default:
+		asset, ok := docsAssets[request.URL.Path]
+		if !ok {
+			writeProblem(writer, request, http.StatusNotFound, "Resource not found", openAPIHandler.TS)
+			return
+		}
+		writer.Header().Set("Content-Type", asset.contentType)
+		writer.Header().Set("Cache-Control", "no-cache")
+		io.WriteString(writer, asset.content)
+	}
+}
+
+// docsContentSecurityPolicy allows the page of the document to load its own script and style,
+// served from the binary, overriding the policy of the API responses
+const docsContentSecurityPolicy string = "default-src 'none'; script-src 'self'; style-src 'self'; " +
+	"img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'"
+
+// docsPage renders the OpenAPI document with the assets of docs, formatted with the title of
+// the API and the path of the document
+const docsPage string = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>%s</title>
+  <link rel="stylesheet" href="` + DocsPath + `/docs.css">
+</head>
+<body>
+  <main id="docs" data-url="%s">Loading the OpenAPI document...</main>
+  <script src="` + DocsPath + `/docs.js"></script>
+</body>
+</html>
+`
+
+//go:generate go run docsgen.go
+
+// docsAsset is a file of the page of the document, held in the binary by docsAssets.go, which
+// go generate writes from the files of docs
+type docsAsset struct {
+	contentType string
+	content     string
+}
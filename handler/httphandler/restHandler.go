@@ -1,17 +1,699 @@
 package httphandler
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/codec"
+	"github.com/zeroberto/go-ms-template/handler/middleware"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/patch"
+)
+
+const (
+	// ExamplesPath represents the base path of the Example resource
+	ExamplesPath string = "/examples"
+	// ExamplePath represents the path of a single Example, identified by its ID
+	ExamplePath string = ExamplesPath + "/{id}"
+	// ExamplesBatchPath represents the path of the batches of changes to Examples
+	ExamplesBatchPath string = ExamplesPath + ":batch"
+	// ActiveExamplesPath represents the path of the active Examples
+	ActiveExamplesPath string = ExamplesPath + "/active"
+	// ExampleByNamePath represents the path of a single Example, identified by its name
+	ExampleByNamePath string = ExamplesPath + "/name/{name}"
+	// ExamplesStreamPath represents the path of the stream of the changes to Examples
+	ExamplesStreamPath string = ExamplesPath + "/stream"
+)
+
+const (
+	// ListExamples identifies the operation that provides all Examples
+	ListExamples string = "listExamples"
+	// ListActiveExamples identifies the operation that provides the active Examples
+	ListActiveExamples string = "listActiveExamples"
+	// CreateExample identifies the operation that creates an Example
+	CreateExample string = "createExample"
+	// GetExample identifies the operation that provides an Example via an ID
+	GetExample string = "getExample"
+	// GetExampleByName identifies the operation that provides an Example via its name
+	GetExampleByName string = "getExampleByName"
+	// UpdateExample identifies the operation that updates or creates a complete Example
+	UpdateExample string = "updateExample"
+	// PartialUpdateExample identifies the operation that updates the properties of an Example
+	PartialUpdateExample string = "partialUpdateExample"
+	// DeleteExample identifies the operation that deletes an Example, or deactivates it with
+	// the query parameter mode=logical
+	DeleteExample string = "deleteExample"
+	// BatchExamples identifies the operation that applies a batch of changes to Examples
+	BatchExamples string = "batchExamples"
+	// StreamExamples identifies the operation that streams the changes to Examples, over
+	// Server-Sent Events or WebSocket
+	StreamExamples string = "streamExamples"
 )
 
+const (
+	// IdempotencyKeyHeader names the header that identifies the retries of a creation
+	IdempotencyKeyHeader string = "Idempotency-Key"
+	// IdempotentReplayedHeader names the header that marks the responses of previous requests
+	IdempotentReplayedHeader string = "Idempotent-Replayed"
+	// MaxIdempotencyKeyLength limits the length of the idempotency keys
+	MaxIdempotencyKeyLength int = 255
+)
+
+// AcceptPatch lists the patch media types accepted by PartialUpdateExample, where
+// application/json is interpreted as a merge patch
+const AcceptPatch string = patch.MergePatchType + ", " + patch.JSONPatchType + ", application/json"
+
+var patchParsers = map[string]func(data []byte) (patch.Patch, error){
+	patch.MergePatchType: parseMergePatch,
+	"application/json":   parseMergePatch,
+	patch.JSONPatchType:  parseJSONPatch,
+}
+
+// Route represents an operation of the Example API reachable over HTTP
+type Route struct {
+	Operation string
+	Method    string
+	Path      string
+}
+
+// Routes lists all operations provided by the RestHTTPHandler
+var Routes = []Route{
+	{Operation: ListExamples, Method: http.MethodGet, Path: ExamplesPath},
+	{Operation: ListActiveExamples, Method: http.MethodGet, Path: ActiveExamplesPath},
+	{Operation: CreateExample, Method: http.MethodPost, Path: ExamplesPath},
+	{Operation: GetExample, Method: http.MethodGet, Path: ExamplePath},
+	{Operation: GetExampleByName, Method: http.MethodGet, Path: ExampleByNamePath},
+	{Operation: UpdateExample, Method: http.MethodPut, Path: ExamplePath},
+	{Operation: PartialUpdateExample, Method: http.MethodPatch, Path: ExamplePath},
+	{Operation: DeleteExample, Method: http.MethodDelete, Path: ExamplePath},
+	{Operation: BatchExamples, Method: http.MethodPost, Path: ExamplesBatchPath},
+	{Operation: StreamExamples, Method: http.MethodGet, Path: ExamplesStreamPath},
+}
+
 // RestHTTPHandler is responsible for providing routines with HTTP1.1 methods
 type RestHTTPHandler struct {
+	EAPI api.ExampleAPI
+	TS   chrono.TimeStamp
+	// CacheControl holds the Cache-Control of the successful responses of specific operations
+	CacheControl map[string]string
+	// Codecs selects the media types of the bodies by the Accept and Content-Type headers,
+	// codec.Default when nil
+	Codecs *codec.Registry
+	// IAPI runs at most once the creations with an Idempotency-Key, the header being ignored
+	// when nil
+	IAPI api.IdempotencyAPI
+	// Versions lists the versions of the API, selected by the first segment of the paths, the
+	// paths without a version being served by the first one. api.DefaultVersions() when nil
+	Versions []api.Version
+	// ESAPI streams the changes to Examples, StreamExamples not being found when nil
+	ESAPI api.ExampleStreamAPI
+	// KeepAlive is the interval of the messages that keep the streams open through proxies,
+	// DefaultKeepAlive when zero
+	KeepAlive time.Duration
+	// AllowedOrigins lists the origins, besides the origin of the service, whose pages may open
+	// the WebSockets, as the CORS allowed origins, which the browsers don't enforce on them
+	AllowedOrigins []string
+}
+
+// unsupportedMediaTypeError reports a request body in a media type no codec decodes
+type unsupportedMediaTypeError struct {
+	mediaType string
+}
+
+func (e *unsupportedMediaTypeError) Error() string {
+	return fmt.Sprintf("Unsupported media type %q", e.mediaType)
+}
+
+// ServeHTTP is responsible for dispatching the request to the Example API
+func (restHandler *RestHTTPHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if err := restHandler.handle(writer, request); err != nil {
+		log.Printf("Couldn't handle %s %s: %v", request.Method, request.URL.Path, err)
+	}
 }
 
 func (restHandler *RestHTTPHandler) handle(writer http.ResponseWriter, request *http.Request) error {
-	// switch request.Method {
-	// case http.MethodGet:
+	version, ok := restHandler.versionOf(request)
+	if !ok {
+		return restHandler.writeError(writer, request, http.StatusNotFound, "Resource not found")
+	}
+	setDeprecation(writer, version)
+	_, versionedPath := splitVersion(request.URL.EscapedPath())
+	path, ID, name, ok := match(versionedPath)
+	if !ok {
+		return restHandler.writeError(writer, request, http.StatusNotFound, "Resource not found")
+	}
+	var allowed []string
+	for _, route := range Routes {
+		if route.Path != path {
+			continue
+		}
+		if route.Method == request.Method && route.Operation == StreamExamples {
+			return restHandler.stream(writer, request, version.Representation)
+		}
+		if route.Method == request.Method {
+			writer.Header().Add("Vary", "Accept")
+			if _, ok := restHandler.codecs().Negotiate(request.Header.Get("Accept"), isListing(route.Operation)); !ok {
+				mediaTypes := strings.Join(restHandler.codecs().MediaTypes(isListing(route.Operation)), ", ")
+				return restHandler.writeError(writer, request, http.StatusNotAcceptable, fmt.Sprintf("None of the media types accepted is available, which are %s", mediaTypes))
+			}
+			if cacheControl, ok := restHandler.CacheControl[route.Operation]; ok {
+				writer.Header().Set("Cache-Control", cacheControl)
+			}
+			return restHandler.dispatch(request.Context(), route.Operation, version.Representation, writer, request, ID, name)
+		}
+		allowed = append(allowed, route.Method)
+	}
+	writer.Header().Set("Allow", strings.Join(allowed, ", "))
+	return restHandler.writeError(writer, request, http.StatusMethodNotAllowed, "Method not allowed")
+}
+
+func (restHandler *RestHTTPHandler) dispatch(
+	ctx context.Context,
+	operation string,
+	representation api.ExampleRepresentation,
+	writer http.ResponseWriter,
+	request *http.Request,
+	ID int64,
+	name string,
+) error {
+	switch operation {
+	case ListExamples, ListActiveExamples:
+		pageParams, err := getPageParams(request)
+		if err != nil {
+			return restHandler.writeError(writer, request, http.StatusBadRequest, err.Error())
+		}
+		if operation == ListActiveExamples {
+			return restHandler.write(writer, request, restHandler.EAPI.GetActive(ctx, pageParams))
+		}
+		return restHandler.write(writer, request, restHandler.EAPI.Get(ctx, pageParams))
+	case CreateExample:
+		body := representation.NewExample()
+		if err := decodeBody(request, body, restHandler.codecs()); err != nil {
+			return writeBodyError(writer, request, err, restHandler.codecs(), restHandler.TS)
+		}
+		example := body.ToExample()
+		key := request.Header.Get(IdempotencyKeyHeader)
+		if key == "" || restHandler.IAPI == nil {
+			return restHandler.write(writer, request, restHandler.EAPI.Create(ctx, example))
+		}
+		if !isIdempotencyKey(key) {
+			return restHandler.writeError(writer, request, http.StatusBadRequest,
+				fmt.Sprintf("Invalid %s, which must have up to %d visible ASCII characters", IdempotencyKeyHeader, MaxIdempotencyKeyLength))
+		}
+		return restHandler.write(writer, request, restHandler.IAPI.Run(ctx, getIdempotencyKey(request, key), getFingerprint(request, example),
+			func(ctx context.Context) api.Response {
+				return restHandler.EAPI.Create(ctx, example)
+			}))
+	case GetExample:
+		return restHandler.write(writer, request, restHandler.EAPI.GetByID(ctx, ID))
+	case GetExampleByName:
+		return restHandler.write(writer, request, restHandler.EAPI.GetByName(ctx, name))
+	case UpdateExample:
+		body := representation.NewExample()
+		if err := decodeBody(request, body, restHandler.codecs()); err != nil {
+			return writeBodyError(writer, request, err, restHandler.codecs(), restHandler.TS)
+		}
+		example := body.ToExample()
+		example.ID = ID
+		return restHandler.write(writer, request, restHandler.EAPI.Update(ctx, ID, example, getPreconditions(request)))
+	case PartialUpdateExample:
+		mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		parsePatch, ok := patchParsers[mediaType]
+		if !ok {
+			writer.Header().Set("Accept-Patch", AcceptPatch)
+			return restHandler.writeError(writer, request, http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported patch media type %q", mediaType))
+		}
+		data, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			return writeBodyError(writer, request, fmt.Errorf("Couldn't read request body: %w", err), restHandler.codecs(), restHandler.TS)
+		}
+		examplePatch, err := parsePatch(data)
+		if err != nil {
+			return restHandler.writeError(writer, request, http.StatusBadRequest, err.Error())
+		}
+		examplePatch = renamePatch(examplePatch, api.PropertyNames(representation))
+		return restHandler.write(writer, request, restHandler.EAPI.PartialUpdate(ctx, ID, examplePatch, getPreconditions(request)))
+	case DeleteExample:
+		switch mode := request.URL.Query().Get("mode"); mode {
+		case "", "physical":
+			return restHandler.write(writer, request, restHandler.EAPI.Delete(ctx, ID, getPreconditions(request)))
+		case "logical":
+			return restHandler.write(writer, request, restHandler.EAPI.DeleteLogically(ctx, ID, getPreconditions(request)))
+		default:
+			return restHandler.writeError(writer, request, http.StatusBadRequest, fmt.Sprintf("Invalid mode %q", mode))
+		}
+	case BatchExamples:
+		body := representation.NewBatch()
+		if err := decodeBody(request, body, restHandler.codecs()); err != nil {
+			return writeBodyError(writer, request, err, restHandler.codecs(), restHandler.TS)
+		}
+		return restHandler.write(writer, request, restHandler.EAPI.Batch(ctx, body.ToBatch()))
+	}
+	return fmt.Errorf("Operation %s is not supported", operation)
+}
+
+// write is responsible for writing the response, whose Examples are converted into the
+// representation of the version of the API selected by the path of the request
+func (restHandler *RestHTTPHandler) write(writer http.ResponseWriter, request *http.Request, response api.Response) error {
+	versionName, _ := splitVersion(request.URL.EscapedPath())
+	if response.Path != 0 {
+		if versionName != "" {
+			versionName = "/" + versionName
+		}
+		writer.Header().Set("Location", fmt.Sprintf("%s%s/%d", versionName, ExamplesPath, response.Path))
+	}
+	if response.ETag != "" {
+		writer.Header().Set("ETag", response.ETag)
+	}
+	if !response.LastModified.IsZero() {
+		writer.Header().Set("Last-Modified", response.LastModified.UTC().Format(http.TimeFormat))
+	}
+	if response.Replayed {
+		writer.Header().Set(IdempotentReplayedHeader, "true")
+	}
+	if response.Code == http.StatusOK && request.Method == http.MethodGet && notModified(request, response) {
+		writer.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	if response.Body == nil {
+		writer.WriteHeader(response.Code)
+		return nil
+	}
+	if version, ok := restHandler.versionOf(request); ok {
+		response.Body = represent(version.Representation, response.Body)
+	}
+	if page, ok := response.Body.(api.Page); ok {
+		writer.Header().Set("Link", getLinks(request, page))
+	}
+	return writeBody(writer, request, response.Code, response.Body, restHandler.codecs(), restHandler.TS)
+}
 
-	// }
+// writeBody is responsible for writing the body in the media type negotiated among codecs,
+// the Problems being written in JSON without the Cache-Control of the successful responses,
+// which serves the handlers that have no RestHTTPHandler as well
+func writeBody(writer http.ResponseWriter, request *http.Request, code int, body interface{}, codecs *codec.Registry, ts chrono.TimeStamp) error {
+	var bodyCodec codec.Codec = codec.JSON{}
+	contentType := ""
+	if problem, ok := body.(api.Problem); ok {
+		if problem.Instance == "" {
+			problem.Instance = request.URL.RequestURI()
+		}
+		body = problem
+		contentType = "application/problem+json"
+		writer.Header().Del("Cache-Control")
+	} else {
+		if negotiated, ok := codecs.Negotiate(request.Header.Get("Accept"), isPage(body)); ok {
+			bodyCodec = negotiated
+		}
+		contentType = bodyCodec.ContentType()
+	}
+	var encoded bytes.Buffer
+	if err := bodyCodec.Encode(&encoded, body); err != nil {
+		if contentType == "application/problem+json" {
+			return err
+		}
+		log.Printf("Couldn't encode the response of %s %s in %s: %v", request.Method, request.URL.Path, contentType, err)
+		return writeProblem(writer, request, http.StatusInternalServerError, "The response couldn't be encoded", ts)
+	}
+	writer.Header().Set("Content-Type", contentType)
+	writer.WriteHeader(code)
+	_, err := writer.Write(encoded.Bytes())
+	return err
+}
+
+// versionOf is responsible for identifying the version of the API selected by the path of the
+// request, the first of the versions for the paths without a version
+func (restHandler *RestHTTPHandler) versionOf(request *http.Request) (api.Version, bool) {
+	versions := restHandler.Versions
+	if versions == nil {
+		versions = api.DefaultVersions()
+	}
+	name, _ := splitVersion(request.URL.EscapedPath())
+	if name == "" && len(versions) > 0 {
+		return versions[0], true
+	}
+	for _, version := range versions {
+		if version.Name == name {
+			return version, true
+		}
+	}
+	return api.Version{}, false
+}
+
+func (restHandler *RestHTTPHandler) codecs() *codec.Registry {
+	return getCodecs(restHandler.Codecs)
+}
+
+// getCodecs is responsible for providing the registry of the codecs, codec.Default when nil
+func getCodecs(codecs *codec.Registry) *codec.Registry {
+	if codecs == nil {
+		return codec.Default
+	}
+	return codecs
+}
+
+// decodeBody is responsible for decoding the request body with the codec of its Content-Type
+// among codecs
+func decodeBody(request *http.Request, value interface{}, codecs *codec.Registry) error {
+	mediaType := ""
+	if contentType := request.Header.Get("Content-Type"); contentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			return &unsupportedMediaTypeError{mediaType: contentType}
+		}
+	}
+	bodyCodec, ok := codecs.Lookup(mediaType)
+	if !ok {
+		return &unsupportedMediaTypeError{mediaType: mediaType}
+	}
+	if err := bodyCodec.Decode(request.Body, value); err != nil {
+		return fmt.Errorf("Couldn't decode request body: %w", err)
+	}
 	return nil
 }
+
+func (restHandler *RestHTTPHandler) writeError(writer http.ResponseWriter, request *http.Request, code int, message string) error {
+	return writeProblem(writer, request, code, message, restHandler.TS)
+}
+
+// writeProblem is responsible for reporting an error as a Problem of the blank type, timed by
+// ts, which serves the handlers that have no RestHTTPHandler as well
+func writeProblem(writer http.ResponseWriter, request *http.Request, code int, message string, ts chrono.TimeStamp) error {
+	problem := api.NewProblem(api.ProblemTypeBlank, code, message, ts.GetCurrentTime())
+	problem.Instance = request.URL.RequestURI()
+	var body bytes.Buffer
+	if err := (codec.JSON{}).Encode(&body, problem); err != nil {
+		return err
+	}
+	writer.Header().Del("Cache-Control")
+	writer.Header().Set("Content-Type", "application/problem+json")
+	writer.WriteHeader(code)
+	_, err := writer.Write(body.Bytes())
+	return err
+}
+
+// writeBodyError is responsible for reporting a request body that cannot be read, as too
+// large when it exceeds the limit of middleware.MaxBodySize, or as unsupported when no codec
+// among codecs decodes its media type
+func writeBodyError(writer http.ResponseWriter, request *http.Request, err error, codecs *codec.Registry, ts chrono.TimeStamp) error {
+	code := http.StatusBadRequest
+	var tooLargeErr *middleware.BodyTooLargeError
+	if _, ok := err.(*unsupportedMediaTypeError); ok {
+		code = http.StatusUnsupportedMediaType
+		writer.Header().Set("Accept", strings.Join(codecs.MediaTypes(false), ", "))
+	} else if errors.As(err, &tooLargeErr) {
+		code = http.StatusRequestEntityTooLarge
+		err = tooLargeErr
+	}
+	return writeProblem(writer, request, code, err.Error(), ts)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since in its absence, against the
+// validators of the response, according to RFC 7232
+func notModified(request *http.Request, response api.Response) bool {
+	if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if response.ETag == "" {
+			return false
+		}
+		for _, eTag := range strings.Split(ifNoneMatch, ",") {
+			eTag = strings.TrimSpace(eTag)
+			if eTag == "*" || strings.TrimPrefix(eTag, "W/") == strings.TrimPrefix(response.ETag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	ifModifiedSince, err := http.ParseTime(request.Header.Get("If-Modified-Since"))
+	if err != nil || response.LastModified.IsZero() {
+		return false
+	}
+	return !response.LastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// RouteOf is responsible for identifying the route of the request among Routes, APIKeyRoutes and
+// HealthRoutes, the routes of Routes being reached under any version of the API
+func RouteOf(request *http.Request) (Route, bool) {
+	_, versionedPath := splitVersion(request.URL.EscapedPath())
+	path, _, _, ok := match(versionedPath)
+	routes := Routes
+	if !ok && strings.TrimSuffix(request.URL.EscapedPath(), "/") == HealthPath {
+		path, ok, routes = HealthPath, true, HealthRoutes
+	}
+	if !ok {
+		path, _, ok = matchAPIKey(request.URL.EscapedPath())
+		routes = APIKeyRoutes
+	}
+	if !ok {
+		return Route{}, false
+	}
+	for _, route := range routes {
+		if route.Path == path && route.Method == request.Method {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// splitVersion is responsible for separating the version segment, such as v2, from the rest of
+// the escaped path of a request, the version being empty when the path has none
+func splitVersion(path string) (string, string) {
+	if !strings.HasPrefix(path, "/v") {
+		return "", path
+	}
+	end := strings.IndexByte(path[1:], '/') + 1
+	if end == 0 {
+		end = len(path)
+	}
+	if _, err := strconv.ParseUint(path[2:end], 10, 32); err != nil {
+		return "", path
+	}
+	return path[1:end], path[end:]
+}
+
+// match is responsible for identifying the route path of the escaped path of a request,
+// along with the ID or the name of the Example it holds
+func match(path string) (string, int64, string, bool) {
+	path = strings.TrimSuffix(path, "/")
+	switch path {
+	case ExamplesPath, ExamplesBatchPath, ActiveExamplesPath, ExamplesStreamPath:
+		return path, 0, "", true
+	}
+	if value := strings.TrimPrefix(path, ExamplesPath+"/name/"); value != path {
+		name, err := url.PathUnescape(value)
+		if err != nil || name == "" || strings.Contains(value, "/") {
+			return "", 0, "", false
+		}
+		return ExampleByNamePath, 0, name, true
+	}
+	if !strings.HasPrefix(path, ExamplesPath+"/") {
+		return "", 0, "", false
+	}
+	ID, err := strconv.ParseInt(strings.TrimPrefix(path, ExamplesPath+"/"), 10, 64)
+	if err != nil {
+		return "", 0, "", false
+	}
+	return ExamplePath, ID, "", true
+}
+
+func getPreconditions(request *http.Request) api.Preconditions {
+	return api.Preconditions{
+		IfMatch:     request.Header.Get("If-Match"),
+		IfNoneMatch: request.Header.Get("If-None-Match"),
+	}
+}
+
+func getPageParams(request *http.Request) (api.PageParams, error) {
+	query := request.URL.Query()
+	pageParams := api.PageParams{
+		Cursor: query.Get("cursor"),
+		Filter: query.Get("filter"),
+		Sort:   query.Get("sort"),
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return pageParams, fmt.Errorf("Invalid limit %q", value)
+		}
+		pageParams.Limit = limit
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return pageParams, fmt.Errorf("Invalid offset %q", value)
+		}
+		pageParams.Offset = offset
+	}
+	if value := query.Get("total"); value != "" {
+		total, err := strconv.ParseBool(value)
+		if err != nil {
+			return pageParams, fmt.Errorf("Invalid total %q", value)
+		}
+		pageParams.Total = total
+	}
+	return pageParams, nil
+}
+
+// getLinks is responsible for providing the Link header (RFC 8288) that navigates
+// through the pages of a listing
+func getLinks(request *http.Request, page api.Page) string {
+	links := []string{getLink(request, "first", "")}
+	if page.Previous != "" {
+		links = append(links, getLink(request, "prev", page.Previous))
+	}
+	if page.Next != "" {
+		links = append(links, getLink(request, "next", page.Next))
+	}
+	return strings.Join(links, ", ")
+}
+
+func getLink(request *http.Request, rel string, cursor string) string {
+	query := request.URL.Query()
+	query.Del("offset")
+	query.Del("cursor")
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	target := url.URL{Path: request.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf(`<%s>; rel="%s"`, target.String(), rel)
+}
+
+// setDeprecation is responsible for signalling the deprecation of the version with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers
+func setDeprecation(writer http.ResponseWriter, version api.Version) {
+	if !version.Deprecation.IsZero() {
+		writer.Header().Set("Deprecation", fmt.Sprintf("@%d", version.Deprecation.Unix()))
+	}
+	if !version.Sunset.IsZero() {
+		writer.Header().Set("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// represent is responsible for converting the Examples of a body into the representation, and
+// the fields of the problems into the names of its properties
+func represent(representation api.ExampleRepresentation, body interface{}) interface{} {
+	switch typed := body.(type) {
+	case model.Example:
+		return representation.FromExample(typed)
+	case api.Page:
+		if examples, ok := typed.Items.([]model.Example); ok {
+			itemType := reflect.TypeOf(representation.FromExample(model.Example{}))
+			items := reflect.Zero(reflect.SliceOf(itemType))
+			if examples != nil {
+				items = reflect.MakeSlice(items.Type(), len(examples), len(examples))
+			}
+			for i, example := range examples {
+				items.Index(i).Set(reflect.ValueOf(representation.FromExample(example)))
+			}
+			typed.Items = items.Interface()
+		}
+		return typed
+	case api.Problem:
+		return representProblem(representation, typed)
+	case api.BatchResponse:
+		results := make([]api.BatchResult, len(typed.Results))
+		for i, result := range typed.Results {
+			if result.Problem != nil {
+				problem := representProblem(representation, *result.Problem)
+				result.Problem = &problem
+			}
+			results[i] = result
+		}
+		typed.Results = results
+		return typed
+	}
+	return body
+}
+
+func representProblem(representation api.ExampleRepresentation, problem api.Problem) api.Problem {
+	if len(problem.Errors) == 0 {
+		return problem
+	}
+	names := api.PropertyNames(representation)
+	errors := make([]api.FieldError, len(problem.Errors))
+	for i, fieldError := range problem.Errors {
+		if name, ok := names[fieldError.Field]; ok {
+			fieldError.Field = name
+		}
+		errors[i] = fieldError
+	}
+	problem.Errors = errors
+	return problem
+}
+
+// renamePatch is responsible for applying the patch to the representation whose properties
+// have the given names, when they differ from the fields of the Example model
+func renamePatch(examplePatch patch.Patch, names map[string]string) patch.Patch {
+	for field, name := range names {
+		if field != name {
+			return patch.Renamed{Patch: examplePatch, Names: names}
+		}
+	}
+	return examplePatch
+}
+
+func parseMergePatch(data []byte) (patch.Patch, error) {
+	return patch.ParseMergePatch(data)
+}
+
+func parseJSONPatch(data []byte) (patch.Patch, error) {
+	return patch.ParseJSONPatch(data)
+}
+
+// isIdempotencyKey reports whether the key has up to MaxIdempotencyKeyLength visible ASCII characters
+func isIdempotencyKey(key string) bool {
+	if len(key) > MaxIdempotencyKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < '!' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// getIdempotencyKey is responsible for scoping the idempotency key to the subject of the
+// principal, so that the clients cannot get the responses of each other
+func getIdempotencyKey(request *http.Request, key string) string {
+	subject := ""
+	if principal := auth.GetPrincipal(request.Context()); principal != nil {
+		subject = principal.Subject
+	}
+	return subject + " " + key
+}
+
+// getFingerprint is responsible for identifying the content of the request by the decoded
+// Example, so that the same Example in another media type is the same request
+func getFingerprint(request *http.Request, example model.Example) string {
+	data, _ := json.Marshal(example)
+	hash := sha256.Sum256([]byte(request.Method + " " + request.URL.Path + "\n" + string(data)))
+	return hex.EncodeToString(hash[:])
+}
+
+// isListing reports whether the operation provides a listing, which the codecs of listings
+// also represent
+func isListing(operation string) bool {
+	return operation == ListExamples || operation == ListActiveExamples
+}
+
+func isPage(body interface{}) bool {
+	_, ok := body.(api.Page)
+	return ok
+}
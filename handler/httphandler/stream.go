@@ -0,0 +1,256 @@
+package httphandler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// DefaultKeepAlive is the interval of the messages that keep the streams open when KeepAlive
+// is not informed
+const DefaultKeepAlive time.Duration = 30 * time.Second
+
+const (
+	// LastEventIDHeader names the header by which the clients of Server-Sent Events resume a stream
+	LastEventIDHeader string = "Last-Event-ID"
+	// EventStreamType is the media type of Server-Sent Events
+	EventStreamType string = "text/event-stream"
+)
+
+// resetEvent notifies the subscribers that the changes after their Last-Event-ID were no
+// longer kept, so that they must read the Examples again
+const resetEvent string = "reset"
+
+// stream is responsible for streaming the changes to Examples over WebSocket when the request
+// opens one, and over Server-Sent Events otherwise
+func (restHandler *RestHTTPHandler) stream(writer http.ResponseWriter, request *http.Request, representation api.ExampleRepresentation) error {
+	if restHandler.ESAPI == nil {
+		return restHandler.writeError(writer, request, http.StatusNotFound, "Resource not found")
+	}
+	params, err := getStreamParams(request)
+	if err != nil {
+		return restHandler.writeError(writer, request, http.StatusBadRequest, err.Error())
+	}
+	if isWebSocketUpgrade(request) {
+		return restHandler.streamWebSocket(writer, request, representation, params)
+	}
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		return restHandler.writeError(writer, request, http.StatusInternalServerError, "The connection doesn't support streaming")
+	}
+	subscription, response := restHandler.ESAPI.Subscribe(request.Context(), params)
+	if subscription == nil {
+		return restHandler.write(writer, request, response)
+	}
+	defer subscription.Close()
+
+	writer.Header().Set("Content-Type", EventStreamType)
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.WriteHeader(http.StatusOK)
+	if params.LastEventID != "" && !subscription.Resumed() {
+		if err := writeServerSentEvent(writer, "", resetEvent, restHandler.resetBody()); err != nil {
+			return err
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(restHandler.keepAlive())
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+			if err := writeServerSentEvent(writer, strconv.FormatUint(event.ID, 10), string(event.Type), toEventBody(representation, event)); err != nil {
+				return err
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(writer, ": keep-alive\n\n"); err != nil {
+				return err
+			}
+		case <-request.Context().Done():
+			return nil
+		}
+		flusher.Flush()
+	}
+}
+
+// streamWebSocket is responsible for streaming the changes to Examples over WebSocket, where
+// every text message of the client is a StreamFilter that replaces the filter of the
+// subscription, which resumes after the last change sent. The filters that are not valid are
+// answered with a Problem, keeping the previous one
+func (restHandler *RestHTTPHandler) streamWebSocket(writer http.ResponseWriter, request *http.Request, representation api.ExampleRepresentation, params api.StreamParams) error {
+	if code, err := checkWebSocketHandshake(request); err != nil {
+		writer.Header().Set("Sec-WebSocket-Version", "13")
+		return restHandler.writeError(writer, request, code, err.Error())
+	}
+	if origin := request.Header.Get("Origin"); !allowsWebSocketOrigin(request, origin, restHandler.AllowedOrigins) {
+		return restHandler.writeError(writer, request, http.StatusForbidden, fmt.Sprintf("The origin %s is not allowed", origin))
+	}
+	subscription, response := restHandler.ESAPI.Subscribe(request.Context(), params)
+	if subscription == nil {
+		return restHandler.write(writer, request, response)
+	}
+	defer func() {
+		subscription.Close()
+	}()
+	ws, err := upgradeWebSocket(writer, request)
+	if err != nil {
+		return err
+	}
+	defer ws.conn.Close()
+
+	filters := make(chan api.StreamFilter, 1)
+	closed := make(chan error, 1)
+	go restHandler.readWebSocket(ws, filters, closed)
+
+	if params.LastEventID != "" && !subscription.Resumed() {
+		if err := ws.writeJSON(restHandler.resetBody()); err != nil {
+			return err
+		}
+	}
+	keepAlive := time.NewTicker(restHandler.keepAlive())
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok && subscription.Dropped() {
+				return ws.close(websocket.CloseTryAgainLater, "the subscription fell behind")
+			}
+			if !ok {
+				return ws.close(websocket.CloseGoingAway, "the stream ended")
+			}
+			params.LastEventID = strconv.FormatUint(event.ID, 10)
+			if err := ws.writeJSON(toEventBody(representation, event)); err != nil {
+				return err
+			}
+		case filter := <-filters:
+			refiltered := api.StreamParams{LastEventID: params.LastEventID, Types: filter.Types, IDs: filter.IDs}
+			replacement, response := restHandler.ESAPI.Subscribe(request.Context(), refiltered)
+			if replacement == nil {
+				if problem, ok := response.Body.(api.Problem); ok {
+					problem.Instance = request.URL.RequestURI()
+					response.Body = problem
+				}
+				if err := ws.writeJSON(response.Body); err != nil {
+					return err
+				}
+				continue
+			}
+			subscription.Close()
+			subscription, params = replacement, refiltered
+		case <-keepAlive.C:
+			if err := ws.ping(); err != nil {
+				return err
+			}
+		case err := <-closed:
+			if wsErr, ok := err.(*webSocketError); ok {
+				return ws.close(wsErr.code, wsErr.message)
+			}
+			return nil
+		}
+	}
+}
+
+// readWebSocket is responsible for reading the messages of the client until the connection
+// closes, providing the filters received
+func (restHandler *RestHTTPHandler) readWebSocket(ws *webSocket, filters chan api.StreamFilter, closed chan error) {
+	for {
+		message, err := ws.readMessage()
+		if err != nil {
+			closed <- err
+			return
+		}
+		var filter api.StreamFilter
+		if err := json.Unmarshal(message, &filter); err != nil {
+			closed <- &webSocketError{code: websocket.CloseUnsupportedData, message: "the messages must be filters"}
+			return
+		}
+		select {
+		case <-filters:
+		default:
+		}
+		filters <- filter
+	}
+}
+
+func (restHandler *RestHTTPHandler) keepAlive() time.Duration {
+	if restHandler.KeepAlive <= 0 {
+		return DefaultKeepAlive
+	}
+	return restHandler.KeepAlive
+}
+
+func (restHandler *RestHTTPHandler) resetBody() api.ExampleEventBody {
+	return api.ExampleEventBody{Type: resetEvent, Time: restHandler.TS.GetCurrentTime()}
+}
+
+// writeServerSentEvent is responsible for writing an event of the stream, whose data is the
+// JSON of the body
+func writeServerSentEvent(writer http.ResponseWriter, ID string, event string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		log.Printf("Couldn't encode the %s event %s: %v", event, ID, err)
+		return err
+	}
+	if ID != "" {
+		if _, err := fmt.Fprintf(writer, "id: %s\n", ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+func toEventBody(representation api.ExampleRepresentation, event model.ExampleEvent) api.ExampleEventBody {
+	return api.ExampleEventBody{
+		ID:      event.ID,
+		Type:    string(event.Type),
+		Time:    event.Time,
+		Example: representation.FromExample(event.Example),
+	}
+}
+
+// getStreamParams is responsible for reading the parameters of a stream, where type and id
+// are comma-separated lists. The clients that cannot inform the Last-Event-ID header, such as
+// the WebSocket ones, inform the lastEventId parameter
+func getStreamParams(request *http.Request) (api.StreamParams, error) {
+	query := request.URL.Query()
+	params := api.StreamParams{LastEventID: request.Header.Get(LastEventIDHeader)}
+	if params.LastEventID == "" {
+		params.LastEventID = query.Get("lastEventId")
+	}
+	for _, value := range query["type"] {
+		params.Types = append(params.Types, splitList(value)...)
+	}
+	for _, value := range query["id"] {
+		for _, item := range splitList(value) {
+			ID, err := strconv.ParseInt(item, 10, 64)
+			if err != nil {
+				return params, fmt.Errorf("Invalid id %q", item)
+			}
+			params.IDs = append(params.IDs, ID)
+		}
+	}
+	return params, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
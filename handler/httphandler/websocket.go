@@ -0,0 +1,121 @@
+package httphandler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/zeroberto/go-ms-template/handler/middleware"
+)
+
+// maxWebSocketMessage limits the size of the messages received from the clients
+const maxWebSocketMessage int64 = 4096
+
+// webSocketWriteTimeout limits the time to write a message, so that a stalled client cannot
+// hold the stream
+const webSocketWriteTimeout time.Duration = 10 * time.Second
+
+// webSocketUpgrader completes the opening handshakes, whose origin is checked before, so that
+// the rejections are answered with a Problem
+var webSocketUpgrader = websocket.Upgrader{
+	CheckOrigin: func(request *http.Request) bool {
+		return true
+	},
+}
+
+// webSocketError reports a message of the client that the stream doesn't accept, with the
+// close code that explains it
+type webSocketError struct {
+	code    int
+	message string
+}
+
+func (err *webSocketError) Error() string {
+	return err.message
+}
+
+// webSocket is responsible for sending the messages of the stream over a WebSocket connection.
+// Only the main loop of the stream writes the messages, the control messages being written
+// concurrently
+type webSocket struct {
+	conn *websocket.Conn
+}
+
+// isWebSocketUpgrade is responsible for checking whether the request opens a WebSocket
+func isWebSocketUpgrade(request *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(request)
+}
+
+// checkWebSocketHandshake is responsible for checking that the opening handshake is valid,
+// providing the status that rejects it otherwise
+func checkWebSocketHandshake(request *http.Request) (int, error) {
+	if request.Header.Get("Sec-WebSocket-Version") != "13" {
+		return http.StatusUpgradeRequired, errors.New("Only the version 13 of WebSocket is supported")
+	}
+	key, err := base64.StdEncoding.DecodeString(request.Header.Get("Sec-WebSocket-Key"))
+	if err != nil || len(key) != 16 {
+		return http.StatusBadRequest, errors.New("Invalid Sec-WebSocket-Key")
+	}
+	return 0, nil
+}
+
+// allowsWebSocketOrigin is responsible for checking that the page that opens the WebSocket
+// belongs to the service or to an allowed origin, so that other sites cannot read the stream
+// with the credentials of the browser. The clients that are not browsers send no Origin
+func allowsWebSocketOrigin(request *http.Request, origin string, allowedOrigins []string) bool {
+	if origin == "" {
+		return true
+	}
+	if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, request.Host) {
+		return true
+	}
+	return middleware.AllowsOrigin(allowedOrigins, origin)
+}
+
+// upgradeWebSocket is responsible for completing the opening handshake of a request checked by
+// checkWebSocketHandshake and allowsWebSocketOrigin, taking over its connection
+func upgradeWebSocket(writer http.ResponseWriter, request *http.Request) (*webSocket, error) {
+	conn, err := webSocketUpgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(maxWebSocketMessage)
+	return &webSocket{conn: conn}, nil
+}
+
+// readMessage is responsible for reading the next text message of the client, the pings being
+// answered and the close messages echoed while reading
+func (ws *webSocket) readMessage() ([]byte, error) {
+	messageType, message, err := ws.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	if messageType != websocket.TextMessage {
+		return nil, &webSocketError{code: websocket.CloseUnsupportedData, message: "the messages must be text"}
+	}
+	return message, nil
+}
+
+// writeJSON is responsible for sending the value as a text message
+func (ws *webSocket) writeJSON(value interface{}) error {
+	ws.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout))
+	return ws.conn.WriteJSON(value)
+}
+
+// ping is responsible for sending a ping, which keeps the connection open through proxies
+func (ws *webSocket) ping() error {
+	return ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(webSocketWriteTimeout))
+}
+
+// close is responsible for sending the close message with the code and the reason, then
+// closing the connection
+func (ws *webSocket) close(code int, reason string) error {
+	err := ws.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(webSocketWriteTimeout))
+	ws.conn.Close()
+	return err
+}
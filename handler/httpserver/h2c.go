@@ -0,0 +1,14 @@
+//go:build go1.24
+
+package httpserver
+
+import "net/http"
+
+// enableH2C is responsible for serving HTTP/2 without TLS along with HTTP/1.1
+func enableH2C(server *http.Server) error {
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	server.Protocols = protocols
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !go1.24
+
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+)
+
+// enableH2C reports that h2c requires the http.Protocols of Go 1.24
+func enableH2C(server *http.Server) error {
+	return errors.New("h2c requires a build with Go 1.24 or later")
+}
@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// Listener is responsible for serving the handler on an address, over TLS when TLS is
+// informed, as a component of the lifecycle of the application
+type Listener struct {
+	Address string
+	Handler http.Handler
+	// TLS holds the configuration of HTTPS, plaintext HTTP being served when nil
+	TLS *tls.Config
+	// HTTP2 serves HTTP/2 along with HTTP/1.1 over TLS, negotiated by ALPN
+	HTTP2 bool
+	// H2C serves HTTP/2 without TLS along with HTTP/1.1, to the clients with prior knowledge,
+	// which suits the internal traffic
+	H2C bool
+	// OnError is called with the error that stops the serving, if any
+	OnError func(err error)
+
+	server   *http.Server
+	listener net.Listener
+}
+
+// Start is responsible for listening on the address and serving the requests in background
+func (listener *Listener) Start() error {
+	if listener.H2C && listener.TLS != nil {
+		return errors.New("h2c is only served without TLS, where HTTP2 applies")
+	}
+	server := &http.Server{Addr: listener.Address, Handler: listener.Handler, TLSConfig: listener.TLS}
+	if listener.TLS != nil && !listener.HTTP2 {
+		// a non-nil empty map keeps HTTP/2 from being configured
+		server.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+	if listener.H2C {
+		if err := enableH2C(server); err != nil {
+			return err
+		}
+	}
+	netListener, err := net.Listen("tcp", listener.Address)
+	if err != nil {
+		return err
+	}
+	listener.server, listener.listener = server, netListener
+
+	go func() {
+		var err error
+		if listener.TLS != nil {
+			err = server.ServeTLS(netListener, "", "")
+		} else {
+			err = server.Serve(netListener)
+		}
+		if err != http.ErrServerClosed && listener.OnError != nil {
+			listener.OnError(err)
+		}
+	}()
+	return nil
+}
+
+// Stop is responsible for shutting the server down, waiting for the requests in progress
+// within the deadline of the context
+func (listener *Listener) Stop(ctx context.Context) error {
+	if listener.server == nil {
+		return nil
+	}
+	if err := listener.server.Shutdown(ctx); err != nil {
+		listener.server.Close()
+		return err
+	}
+	return nil
+}
+
+// Addr provides the address listened on, once started
+func (listener *Listener) Addr() net.Addr {
+	if listener.listener == nil {
+		return nil
+	}
+	return listener.listener.Addr()
+}
+
+// String describes the address and the protocols served
+func (listener *Listener) String() string {
+	address := listener.Address
+	if addr := listener.Addr(); addr != nil {
+		address = addr.String()
+	}
+	switch {
+	case listener.TLS != nil && listener.HTTP2:
+		return fmt.Sprintf("%s (HTTPS, HTTP/2)", address)
+	case listener.TLS != nil:
+		return fmt.Sprintf("%s (HTTPS)", address)
+	case listener.H2C:
+		return fmt.Sprintf("%s (HTTP, h2c)", address)
+	}
+	return fmt.Sprintf("%s (HTTP)", address)
+}
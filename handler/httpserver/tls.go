@@ -0,0 +1,203 @@
+package httpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+const (
+	// DefaultMinVersion is the oldest version of TLS accepted when MinVersion is empty
+	DefaultMinVersion string = "1.2"
+	// DefaultReloadInterval is the minimum interval between the checks of the certificate
+	// files when ReloadInterval is zero
+	DefaultReloadInterval time.Duration = time.Minute
+)
+
+const (
+	// RequireClientCertificate rejects the clients without a certificate signed by the client CAs
+	RequireClientCertificate string = "require"
+	// VerifyClientCertificateIfGiven rejects the clients with a certificate not signed by the
+	// client CAs, accepting the clients without a certificate
+	VerifyClientCertificateIfGiven string = "verifyIfGiven"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Certificates is responsible for providing the certificate of the server and the CAs of the
+// client certificates from their PEM files. The certificate is loaded again when its files
+// change, so that it can be renewed without restarting, while the client CAs are loaded once
+type Certificates struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile holds the CAs of the client certificates, empty when the clients are not
+	// authenticated by certificate
+	ClientCAFile string
+	// ReloadInterval is the minimum interval between the checks of the files of the certificate
+	ReloadInterval time.Duration
+	TS             chrono.TimeStamp
+
+	mutex       sync.Mutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+	files       [2]fileVersion
+	checkedAt   time.Time
+}
+
+// fileVersion identifies the content of a file by its size and the moment of its last change
+type fileVersion struct {
+	size    int64
+	modTime time.Time
+}
+
+// TLSPolicy represents the versions, the cipher suites and the authentication of the clients
+// accepted over TLS
+type TLSPolicy struct {
+	// MinVersion is the oldest version accepted, among 1.0, 1.1, 1.2 and 1.3
+	MinVersion string
+	// CipherSuites lists the names of the cipher suites accepted up to TLS 1.2, as named by
+	// tls.CipherSuiteName, the defaults of Go being accepted when empty. The cipher suites of
+	// TLS 1.3 are not configurable
+	CipherSuites []string
+	// ClientAuth is RequireClientCertificate, the default, or VerifyClientCertificateIfGiven,
+	// when the Certificates have client CAs
+	ClientAuth string
+}
+
+// Load is responsible for loading the certificate and the client CAs, failing when any of
+// them cannot be loaded
+func (certificates *Certificates) Load() error {
+	certificates.mutex.Lock()
+	defer certificates.mutex.Unlock()
+
+	if err := certificates.load(); err != nil {
+		return err
+	}
+	certificates.checkedAt = certificates.TS.GetCurrentTime()
+	if certificates.ClientCAFile == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(certificates.ClientCAFile)
+	if err != nil {
+		return err
+	}
+	certificates.clientCAs = x509.NewCertPool()
+	if !certificates.clientCAs.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no certificates found in %s", certificates.ClientCAFile)
+	}
+	return nil
+}
+
+// GetCertificate is responsible for providing the certificate of the server to the TLS
+// handshakes, checking whether its files changed at most once per ReloadInterval. The
+// current certificate is kept when the changed files cannot be loaded
+func (certificates *Certificates) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certificates.mutex.Lock()
+	defer certificates.mutex.Unlock()
+
+	if certificates.certificate == nil {
+		return nil, fmt.Errorf("the certificate of %s was not loaded", certificates.CertFile)
+	}
+	now := certificates.TS.GetCurrentTime()
+	reloadInterval := certificates.ReloadInterval
+	if reloadInterval <= 0 {
+		reloadInterval = DefaultReloadInterval
+	}
+	if now.Sub(certificates.checkedAt) >= reloadInterval {
+		certificates.checkedAt = now
+		if err := certificates.load(); err != nil {
+			log.Printf("Couldn't reload the certificate of %s, keeping the current one: %v", certificates.CertFile, err)
+		}
+	}
+	return certificates.certificate, nil
+}
+
+// load is responsible for loading the certificate when its files changed since the last load
+func (certificates *Certificates) load() error {
+	var files [2]fileVersion
+	for i, name := range []string{certificates.CertFile, certificates.KeyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		files[i] = fileVersion{size: info.Size(), modTime: info.ModTime()}
+	}
+	if certificates.certificate != nil && files == certificates.files {
+		return nil
+	}
+	certificate, err := tls.LoadX509KeyPair(certificates.CertFile, certificates.KeyFile)
+	if err != nil {
+		return err
+	}
+	if certificates.certificate != nil {
+		log.Printf("Reloaded the certificate of %s", certificates.CertFile)
+	}
+	certificates.certificate = &certificate
+	certificates.files = files
+	return nil
+}
+
+// Config is responsible for providing the configuration of TLS with the policy, whose
+// certificate is provided by the loaded Certificates
+func (policy TLSPolicy) Config(certificates *Certificates) (*tls.Config, error) {
+	minVersion := policy.MinVersion
+	if minVersion == "" {
+		minVersion = DefaultMinVersion
+	}
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, fmt.Errorf("unknown TLS version %q", minVersion)
+	}
+	config := &tls.Config{MinVersion: version, GetCertificate: certificates.GetCertificate}
+	for _, name := range policy.CipherSuites {
+		ID, err := cipherSuiteID(name)
+		if err != nil {
+			return nil, err
+		}
+		config.CipherSuites = append(config.CipherSuites, ID)
+	}
+
+	certificates.mutex.Lock()
+	defer certificates.mutex.Unlock()
+	if certificates.clientCAs == nil {
+		return config, nil
+	}
+	config.ClientCAs = certificates.clientCAs
+	switch policy.ClientAuth {
+	case "", RequireClientCertificate:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	case VerifyClientCertificateIfGiven:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		return nil, fmt.Errorf("unknown client authentication %q", policy.ClientAuth)
+	}
+	return config, nil
+}
+
+// cipherSuiteID is responsible for identifying a cipher suite by name, only the suites
+// without known security issues being accepted
+func cipherSuiteID(name string) (uint16, error) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, nil
+		}
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		if suite.Name == name {
+			return 0, fmt.Errorf("the cipher suite %s is not secure", name)
+		}
+	}
+	return 0, fmt.Errorf("unknown cipher suite %q", name)
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+const (
+	// BearerScheme is the authentication scheme of the OAuth 2.0 bearer tokens (RFC 6750)
+	BearerScheme string = "Bearer"
+	// APIKeyScheme is the authentication scheme of the API keys
+	APIKeyScheme string = "ApiKey"
+)
+
+// CredentialVerifier authenticates the principal of the credentials of a scheme
+type CredentialVerifier interface {
+	// Verify provides the principal of the credentials, or auth.Error when they are not valid
+	Verify(ctx context.Context, credentials string) (*auth.Principal, error)
+}
+
+// Authenticator is responsible for authenticating the requests of the operations by the
+// credentials of their Authorization header and for enforcing the scopes of the operations,
+// placing the principal in the context of the request
+type Authenticator struct {
+	// Schemes holds the verifier of the credentials of each scheme, such as Bearer
+	Schemes map[string]CredentialVerifier
+	// Scopes lists the scopes the principal must have been granted, by operation
+	Scopes map[string][]string
+	// Route identifies the operation of a request, the requests without operation being public
+	Route func(request *http.Request) string
+	Realm string
+	TS    chrono.TimeStamp
+}
+
+// Authenticate is responsible for rejecting with 401 the requests without valid credentials
+// and with 403 the requests whose principal lacks the scopes of the operation. When the
+// credentials cannot be verified, as with errors other than auth.Error, the request is
+// rejected with 503. The requests without Authorization header whose principal is already in
+// the context, as authenticated by ClientCertificate, are only checked for the scopes
+func (authenticator *Authenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		operation := authenticator.Route(request)
+		if operation == "" {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if principal := auth.GetPrincipal(request.Context()); principal != nil && request.Header.Get("Authorization") == "" {
+			if scopes := authenticator.Scopes[operation]; !principal.HasScopes(scopes...) {
+				writeProblem(writer, request, http.StatusForbidden, fmt.Sprintf("The scopes %s are required", strings.Join(scopes, ", ")), authenticator.TS)
+				return
+			}
+			next.ServeHTTP(writer, request)
+			return
+		}
+		scheme, credentials := splitAuthorization(request.Header.Get("Authorization"))
+		scheme, verifier, ok := authenticator.verifier(scheme)
+		if !ok {
+			authenticator.challenge(writer, request, "", "Authentication is required")
+			return
+		}
+		principal, err := verifier.Verify(request.Context(), credentials)
+		var authErr *auth.Error
+		if err != nil && !errors.As(err, &authErr) {
+			writeProblem(writer, request, http.StatusServiceUnavailable, "The credentials couldn't be verified, retry later", authenticator.TS)
+			return
+		}
+		if err != nil {
+			authenticator.challenge(writer, request, fmt.Sprintf(`%s realm="%s", error="invalid_token"`, scheme, authenticator.Realm), err.Error())
+			return
+		}
+		if scopes := authenticator.Scopes[operation]; !principal.HasScopes(scopes...) {
+			writer.Header().Set("WWW-Authenticate", fmt.Sprintf(`%s realm="%s", error="insufficient_scope", scope="%s"`, scheme, authenticator.Realm, strings.Join(scopes, " ")))
+			writeProblem(writer, request, http.StatusForbidden, fmt.Sprintf("The scopes %s are required", strings.Join(scopes, ", ")), authenticator.TS)
+			return
+		}
+		next.ServeHTTP(writer, request.WithContext(auth.WithPrincipal(request.Context(), principal)))
+	})
+}
+
+// ClientCertificate is responsible for placing in the context of the requests the principal
+// of the client certificate verified by the TLS handshake, granted the scopes of its subject.
+// The requests without a verified certificate are left anonymous
+func ClientCertificate(scopes map[string][]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+				next.ServeHTTP(writer, request)
+				return
+			}
+			principal := auth.CertificatePrincipal(request.TLS.VerifiedChains[0][0], scopes)
+			next.ServeHTTP(writer, request.WithContext(auth.WithPrincipal(request.Context(), principal)))
+		})
+	}
+}
+
+// verifier is responsible for providing the verifier of the scheme, whose name is compared
+// regardless of case, along with its name as configured
+func (authenticator *Authenticator) verifier(scheme string) (string, CredentialVerifier, bool) {
+	for name, verifier := range authenticator.Schemes {
+		if scheme != "" && strings.EqualFold(name, scheme) {
+			return name, verifier, true
+		}
+	}
+	return "", nil, false
+}
+
+// challenge is responsible for reporting 401 with a WWW-Authenticate challenge for every
+// scheme, or with the given challenge when the credentials of a scheme were rejected
+func (authenticator *Authenticator) challenge(writer http.ResponseWriter, request *http.Request, challenge string, detail string) {
+	if challenge != "" {
+		writer.Header().Set("WWW-Authenticate", challenge)
+	} else {
+		schemes := make([]string, 0, len(authenticator.Schemes))
+		for scheme := range authenticator.Schemes {
+			schemes = append(schemes, scheme)
+		}
+		sort.Strings(schemes)
+		for _, scheme := range schemes {
+			writer.Header().Add("WWW-Authenticate", fmt.Sprintf(`%s realm="%s"`, scheme, authenticator.Realm))
+		}
+	}
+	writeProblem(writer, request, http.StatusUnauthorized, detail, authenticator.TS)
+}
+
+func splitAuthorization(authorization string) (string, string) {
+	authorization = strings.TrimSpace(authorization)
+	i := strings.IndexByte(authorization, ' ')
+	if i < 0 {
+		return authorization, ""
+	}
+	return authorization[:i], strings.TrimSpace(authorization[i+1:])
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+// CORS is responsible for allowing the browsers to call the service from other origins,
+// according to the Fetch standard, answering the preflight requests itself
+type CORS struct {
+	// AllowedOrigins lists the origins allowed, where * matches any sequence of characters,
+	// such as https://*.example.com, and a single * matches every origin
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers allowed, where a single * allows every header
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers the scripts of the origins can read
+	ExposedHeaders []string
+	// AllowCredentials allows the requests with cookies or the Authorization header, the
+	// origin being informed instead of *
+	AllowCredentials bool
+	// MaxAge is how long the browsers may cache the result of a preflight, zero meaning
+	// their default
+	MaxAge time.Duration
+	TS     chrono.TimeStamp
+}
+
+// Handle is responsible for adding the CORS headers to the responses to the allowed origins
+// and for answering the preflight requests with 204, or with 403 when the origin, the method or
+// the headers are not allowed
+func (cors *CORS) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		origin := request.Header.Get("Origin")
+		preflight := request.Method == http.MethodOptions && origin != "" && request.Header.Get("Access-Control-Request-Method") != ""
+		if !cors.anyOrigin() {
+			writer.Header().Add("Vary", "Origin")
+		}
+		if preflight {
+			writer.Header().Add("Vary", "Access-Control-Request-Method")
+			writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			cors.preflight(writer, request, origin)
+			return
+		}
+		if origin != "" && cors.allowsOrigin(origin) {
+			cors.setOrigin(writer, origin)
+			if len(cors.ExposedHeaders) > 0 {
+				writer.Header().Set("Access-Control-Expose-Headers", strings.Join(cors.ExposedHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+func (cors *CORS) preflight(writer http.ResponseWriter, request *http.Request, origin string) {
+	if !cors.allowsOrigin(origin) {
+		writeProblem(writer, request, http.StatusForbidden, fmt.Sprintf("The origin %s is not allowed", origin), cors.TS)
+		return
+	}
+	method := request.Header.Get("Access-Control-Request-Method")
+	if !contains(cors.AllowedMethods, method) {
+		writeProblem(writer, request, http.StatusForbidden, fmt.Sprintf("The method %s is not allowed", method), cors.TS)
+		return
+	}
+	var headers []string
+	for _, header := range strings.Split(request.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header == "" {
+			continue
+		}
+		if !contains(cors.AllowedHeaders, "*") && !contains(cors.AllowedHeaders, header) {
+			writeProblem(writer, request, http.StatusForbidden, fmt.Sprintf("The header %s is not allowed", header), cors.TS)
+			return
+		}
+		headers = append(headers, header)
+	}
+
+	cors.setOrigin(writer, origin)
+	writer.Header().Set("Access-Control-Allow-Methods", strings.Join(cors.AllowedMethods, ", "))
+	if len(headers) > 0 {
+		writer.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if cors.MaxAge > 0 {
+		writer.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(cors.MaxAge.Seconds())))
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func (cors *CORS) setOrigin(writer http.ResponseWriter, origin string) {
+	if cors.anyOrigin() {
+		writer.Header().Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	writer.Header().Set("Access-Control-Allow-Origin", origin)
+	if cors.AllowCredentials {
+		writer.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// anyOrigin reports whether every origin is allowed without credentials, when the responses
+// do not depend on the origin
+func (cors *CORS) anyOrigin() bool {
+	return !cors.AllowCredentials && len(cors.AllowedOrigins) == 1 && cors.AllowedOrigins[0] == "*"
+}
+
+func (cors *CORS) allowsOrigin(origin string) bool {
+	return AllowsOrigin(cors.AllowedOrigins, origin)
+}
+
+// AllowsOrigin is responsible for checking whether the origin matches any of the patterns,
+// where * matches any sequence of characters, regardless of case
+func AllowsOrigin(patterns []string, origin string) bool {
+	for _, pattern := range patterns {
+		if matchOrigin(strings.ToLower(pattern), strings.ToLower(origin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOrigin is responsible for matching the origin against the pattern, where * matches
+// any sequence of characters
+func matchOrigin(pattern string, origin string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == origin
+	}
+	if !strings.HasPrefix(origin, parts[0]) {
+		return false
+	}
+	origin = origin[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(origin, part)
+		if i < 0 {
+			return false
+		}
+		origin = origin[i+len(part):]
+	}
+	return strings.HasSuffix(origin, parts[len(parts)-1])
+}
+
+// contains reports whether the values hold the value, compared regardless of case
+func contains(values []string, value string) bool {
+	for _, current := range values {
+		if strings.EqualFold(current, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+// RequestIDHeader is the header that usually carries the ID of a request
+const RequestIDHeader string = "X-Request-ID"
+
+// maxRequestIDLength limits the size of the request IDs accepted from clients
+const maxRequestIDLength int = 128
+
+// Middleware wraps an http.Handler with a cross-cutting concern
+type Middleware func(next http.Handler) http.Handler
+
+// Chain is responsible for wrapping the handler with the middlewares, the first one being
+// the outermost
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+type requestIDKey struct{}
+
+// GetRequestID is responsible for providing the ID of the request the context belongs to,
+// empty when the RequestID middleware is not in the chain
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// RequestID is responsible for identifying every request by the ID informed in the header,
+// or by a random ID when it is absent or invalid, and for returning the ID in the same header
+func RequestID(header string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			requestID := request.Header.Get(header)
+			if !isValidRequestID(requestID) {
+				requestID = newRequestID()
+				request.Header.Set(header, requestID)
+			}
+			writer.Header().Set(header, requestID)
+			next.ServeHTTP(writer, request.WithContext(context.WithValue(request.Context(), requestIDKey{}, requestID)))
+		})
+	}
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range requestID {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(data)
+}
+
+// AccessLogEntry represents the line of the access log that describes a request
+type AccessLogEntry struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"requestId,omitempty"`
+	Method     string    `json:"method"`
+	URI        string    `json:"uri"`
+	Status     int       `json:"status"`
+	Bytes      int64     `json:"bytes"`
+	LatencyMs  float64   `json:"latencyMs"`
+	RemoteAddr string    `json:"remoteAddr"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+}
+
+// AccessLog is responsible for writing an AccessLogEntry in JSON, one per line, when every
+// request completes
+func AccessLog(output io.Writer, ts chrono.TimeStamp) Middleware {
+	logger := log.New(output, "", 0)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			start := ts.GetCurrentTime()
+			recorder := &statusRecorder{ResponseWriter: writer}
+			defer func() {
+				entry := AccessLogEntry{
+					Time:       start,
+					RequestID:  GetRequestID(request.Context()),
+					Method:     request.Method,
+					URI:        request.URL.RequestURI(),
+					Status:     recorder.status,
+					Bytes:      recorder.bytes,
+					LatencyMs:  float64(ts.GetCurrentTime().Sub(start)) / float64(time.Millisecond),
+					RemoteAddr: request.RemoteAddr,
+					UserAgent:  request.UserAgent(),
+				}
+				if entry.Status == 0 {
+					entry.Status = http.StatusOK
+				}
+				data, _ := json.Marshal(entry)
+				logger.Println(string(data))
+			}()
+			next.ServeHTTP(recorder, request)
+		})
+	}
+}
+
+// Recover is responsible for stopping the panics of the handlers, logging them with their
+// stack and reporting a 500 problem when the response has not started yet
+func Recover(ts chrono.TimeStamp) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			recorder := &statusRecorder{ResponseWriter: writer}
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				log.Printf("Panic handling %s %s (request %s): %v\n%s", request.Method, request.URL.Path, GetRequestID(request.Context()), recovered, debug.Stack())
+				if recorder.status == 0 {
+					writeProblem(recorder, request, http.StatusInternalServerError, "The request could not be completed", ts)
+				}
+			}()
+			next.ServeHTTP(recorder, request)
+		})
+	}
+}
+
+// BodyTooLargeError reports a request body larger than the limit of MaxBodySize
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (err *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("The request body must have at most %d bytes", err.Limit)
+}
+
+// MaxBodySize is responsible for rejecting the request bodies larger than the limit, in bytes,
+// before they are read when they declare their length and as soon as they exceed it otherwise,
+// the reads failing with a BodyTooLargeError
+func MaxBodySize(limit int64, ts chrono.TimeStamp) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.ContentLength > limit {
+				writeProblem(writer, request, http.StatusRequestEntityTooLarge, (&BodyTooLargeError{Limit: limit}).Error(), ts)
+				return
+			}
+			request.Body = &limitedBody{ReadCloser: request.Body, writer: writer, limit: limit, remaining: limit}
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+// RequestTimeout is responsible for limiting the duration of the requests, through the
+// deadline of their context, by the route they match
+type RequestTimeout struct {
+	// Default limits the requests, zero meaning no limit
+	Default time.Duration
+	// Routes overrides Default for specific routes
+	Routes map[string]time.Duration
+	// Route identifies the route of a request
+	Route func(request *http.Request) string
+	// Exempt reports the requests limited only by the timeout of their route, such as the
+	// streams, which stay open
+	Exempt func(request *http.Request) bool
+}
+
+// Apply is responsible for placing the deadline of the route of the request in its context
+func (requestTimeout *RequestTimeout) Apply(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		timeout, ok := requestTimeout.Routes[requestTimeout.Route(request)]
+		if !ok && (requestTimeout.Exempt == nil || !requestTimeout.Exempt(request)) {
+			timeout = requestTimeout.Default
+		}
+		if timeout <= 0 {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		ctx, cancel := context.WithTimeout(request.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// limitedBody is responsible for failing the reads past the limit, the connection being
+// closed after the response since the rest of the body is left unread
+type limitedBody struct {
+	io.ReadCloser
+	writer    http.ResponseWriter
+	limit     int64
+	remaining int64
+	err       error
+}
+
+func (body *limitedBody) Read(data []byte) (int, error) {
+	if body.err != nil {
+		return 0, body.err
+	}
+	if int64(len(data)) > body.remaining+1 {
+		data = data[:body.remaining+1]
+	}
+	n, err := body.ReadCloser.Read(data)
+	if int64(n) <= body.remaining {
+		body.remaining -= int64(n)
+		return n, err
+	}
+	n, body.remaining = int(body.remaining), 0
+	body.err = &BodyTooLargeError{Limit: body.limit}
+	body.writer.Header().Set("Connection", "close")
+	return n, body.err
+}
+
+func writeProblem(writer http.ResponseWriter, request *http.Request, code int, detail string, ts chrono.TimeStamp) {
+	problem := api.NewProblem(api.ProblemTypeBlank, code, detail, ts.GetCurrentTime())
+	problem.Instance = request.URL.RequestURI()
+	writer.Header().Set("Content-Type", "application/problem+json")
+	writer.WriteHeader(code)
+	json.NewEncoder(writer).Encode(problem)
+}
+
+// statusRecorder keeps the status and the size of the response written by the handlers,
+// preserving the optional interfaces of the underlying writer
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (recorder *statusRecorder) WriteHeader(code int) {
+	if recorder.status == 0 {
+		recorder.status = code
+	}
+	recorder.ResponseWriter.WriteHeader(code)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	n, err := recorder.ResponseWriter.Write(data)
+	recorder.bytes += int64(n)
+	return n, err
+}
+
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		flusher.Flush()
+	}
+}
+
+func (recorder *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := recorder.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T does not support hijacking", recorder.ResponseWriter)
+	}
+	recorder.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
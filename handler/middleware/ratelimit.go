@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+// sweepInterval is the minimum interval between the removals of the idle buckets
+const sweepInterval time.Duration = time.Minute
+
+// KeyFunc identifies the client of a request, providing an empty key when it cannot
+type KeyFunc func(request *http.Request) string
+
+// SubjectKey identifies the clients by the subject of their principal, placed in the context
+// by the Authenticator. The API keys are identified this way once verified, so that made-up
+// keys cannot dodge the limits
+func SubjectKey(request *http.Request) string {
+	if principal := auth.GetPrincipal(request.Context()); principal != nil && principal.Subject != "" {
+		return "subject:" + principal.Subject
+	}
+	return ""
+}
+
+// ClientIPKey identifies the clients by the IP address of the connection
+func ClientIPKey(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// FirstKey identifies the clients by the first of the keys that is not empty
+func FirstKey(keys ...KeyFunc) KeyFunc {
+	return func(request *http.Request) string {
+		for _, key := range keys {
+			if value := key(request); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+}
+
+// RateLimit defines a token bucket, refilled with Rate tokens per second up to Burst
+// tokens, where every request takes a token. A zero Rate means no limit
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimiter is responsible for limiting the rate of the requests of every client, with a
+// token bucket per client and route, reporting the state of the bucket in the RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers
+type RateLimiter struct {
+	Default RateLimit
+	// Routes overrides Default for specific routes, which have buckets of their own
+	Routes map[string]RateLimit
+	// Route identifies the route of a request
+	Route func(request *http.Request) string
+	// Key identifies the client of a request, the requests without key not being limited
+	Key KeyFunc
+	TS  chrono.TimeStamp
+
+	mutex     sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+type bucketKey struct {
+	client string
+	route  string
+}
+
+type bucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// Limit is responsible for rejecting with 429 the requests of the clients that exhausted
+// their bucket, informing in Retry-After when the next token is available
+func (limiter *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		client := limiter.Key(request)
+		route := ""
+		if limiter.Route != nil {
+			route = limiter.Route(request)
+		}
+		limit, ok := limiter.Routes[route]
+		if !ok {
+			limit, route = limiter.Default, ""
+		}
+		if client == "" || limit.Rate <= 0 {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+
+		allowed, remaining, reset, retryAfter := limiter.take(bucketKey{client: client, route: route}, limit)
+		writer.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		writer.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		writer.Header().Set("RateLimit-Reset", strconv.Itoa(reset))
+		if !allowed {
+			writer.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeProblem(writer, request, http.StatusTooManyRequests, fmt.Sprintf("Too many requests, retry after %d seconds", retryAfter), limiter.TS)
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// take is responsible for taking a token from the bucket, providing the tokens remaining and
+// the seconds until the bucket is full and until the next token is available
+func (limiter *RateLimiter) take(key bucketKey, limit RateLimit) (bool, int, int, int) {
+	now := limiter.TS.GetCurrentTime()
+	burst := float64(limit.Burst)
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	limiter.sweep(now)
+
+	current, ok := limiter.buckets[key]
+	if !ok {
+		current = &bucket{limit: limit, tokens: burst, last: now}
+		limiter.buckets[key] = current
+	}
+	current.tokens = math.Min(burst, current.tokens+now.Sub(current.last).Seconds()*limit.Rate)
+	current.last = now
+
+	allowed := current.tokens >= 1
+	if allowed {
+		current.tokens--
+	}
+	reset := int(math.Ceil((burst - current.tokens) / limit.Rate))
+	retryAfter := int(math.Ceil((1 - current.tokens) / limit.Rate))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	return allowed, int(current.tokens), reset, retryAfter
+}
+
+// sweep is responsible for removing the buckets that are full again, which are the same as
+// new ones, at most once per sweepInterval
+func (limiter *RateLimiter) sweep(now time.Time) {
+	if limiter.buckets == nil {
+		limiter.buckets = map[bucketKey]*bucket{}
+	}
+	if now.Sub(limiter.lastSweep) < sweepInterval {
+		return
+	}
+	limiter.lastSweep = now
+	for key, idle := range limiter.buckets {
+		if idle.tokens+now.Sub(idle.last).Seconds()*idle.limit.Rate >= float64(idle.limit.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
+
+// LimitInFlight is responsible for shedding load, rejecting with 503 the requests that arrive
+// while the maximum number of requests is being handled. The requests that exempt reports,
+// such as the streams, which would hold a slot for as long as they are open, are not counted
+func LimitInFlight(max int, exempt func(request *http.Request) bool, ts chrono.TimeStamp) Middleware {
+	slots := make(chan struct{}, max)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if exempt != nil && exempt(request) {
+				next.ServeHTTP(writer, request)
+				return
+			}
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				next.ServeHTTP(writer, request)
+			default:
+				writer.Header().Set("Retry-After", "1")
+				writeProblem(writer, request, http.StatusServiceUnavailable, "The service is overloaded, retry later", ts)
+			}
+		})
+	}
+}
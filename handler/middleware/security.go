@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SecurityHeaders is responsible for setting the security headers on every response, the
+// handlers being able to override them, such as the page that needs a laxer policy
+type SecurityHeaders struct {
+	// HSTSMaxAge enables Strict-Transport-Security (RFC 6797), zero disabling it
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	// ContentTypeNosniff sets X-Content-Type-Options: nosniff
+	ContentTypeNosniff bool
+	// FrameOptions is the X-Frame-Options, such as DENY, empty not setting it
+	FrameOptions string
+	// ContentSecurityPolicy is the Content-Security-Policy, empty not setting it
+	ContentSecurityPolicy string
+	// ReferrerPolicy is the Referrer-Policy, empty not setting it
+	ReferrerPolicy string
+}
+
+// Apply is responsible for setting the headers before the handler writes the response
+func (headers *SecurityHeaders) Apply(next http.Handler) http.Handler {
+	values := map[string]string{
+		"X-Frame-Options":         headers.FrameOptions,
+		"Content-Security-Policy": headers.ContentSecurityPolicy,
+		"Referrer-Policy":         headers.ReferrerPolicy,
+	}
+	if headers.HSTSMaxAge > 0 {
+		values["Strict-Transport-Security"] = fmt.Sprintf("max-age=%d", int64(headers.HSTSMaxAge.Seconds()))
+		if headers.HSTSIncludeSubdomains {
+			values["Strict-Transport-Security"] += "; includeSubDomains"
+		}
+	}
+	if headers.ContentTypeNosniff {
+		values["X-Content-Type-Options"] = "nosniff"
+	}
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		for name, value := range values {
+			if value != "" {
+				writer.Header().Set(name, value)
+			}
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
@@ -0,0 +1,244 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+)
+
+// DefaultTimeout limits the checks when Timeout is not informed
+const DefaultTimeout time.Duration = 2 * time.Second
+
+// DefaultCacheTTL is how long the results of the checks are reused when CacheTTL is not informed
+const DefaultCacheTTL time.Duration = time.Second
+
+const (
+	// StatusUp reports that the application, or a dependency, can be used
+	StatusUp string = "up"
+	// StatusDown reports that a dependency cannot be used
+	StatusDown string = "down"
+	// StatusStarting reports that the application has not yet started accepting requests
+	StatusStarting string = "starting"
+	// StatusStopping reports that the application is shutting down, no longer accepting requests
+	StatusStopping string = "stopping"
+)
+
+// Checker is responsible for checking whether a dependency of the application can be used
+type Checker interface {
+	// Check is responsible for reporting why the dependency cannot be used, within the
+	// deadline of the given context
+	Check(ctx context.Context) error
+}
+
+// CheckFunc allows ordinary functions to be registered as a Checker
+type CheckFunc func(ctx context.Context) error
+
+// Check calls the function
+func (check CheckFunc) Check(ctx context.Context) error {
+	return check(ctx)
+}
+
+// Reporter is responsible for reporting the health of the application
+type Reporter interface {
+	// Alive is responsible for reporting whether the application runs
+	Alive() Report
+	// Report is responsible for checking the dependencies, reporting the application as up
+	// only when it accepts requests and every dependency can be used
+	Report(ctx context.Context) Report
+}
+
+// Report reflects the health of the application and of its dependencies
+type Report struct {
+	Status    string        `json:"status"`
+	Time      time.Time     `json:"time"`
+	StartedAt time.Time     `json:"startedAt"`
+	Checks    []CheckReport `json:"checks,omitempty"`
+}
+
+// CheckReport reflects the result of the check of a dependency
+type CheckReport struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latencyMs"`
+	TimeoutMS float64 `json:"timeoutMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Registry is responsible for checking the dependencies registered and for tracking whether
+// the application accepts requests. As a lifecycle.Component it is ready from Start to Stop,
+// so that, registered after the other components, its readiness fails as soon as the
+// shutdown begins
+type Registry struct {
+	// Timeout limits each check, DefaultTimeout when zero
+	Timeout time.Duration
+	// Timeouts overrides Timeout by the name of the checks
+	Timeouts map[string]time.Duration
+	// DrainDelay is how long Stop waits after failing the readiness, so that the load
+	// balancers stop sending requests before the listeners are closed
+	DrainDelay time.Duration
+	// CacheTTL is how long the results of the checks are reused by Report, DefaultCacheTTL
+	// when zero, so that frequent reports do not overload the dependencies
+	CacheTTL time.Duration
+	TS       chrono.TimeStamp
+
+	mutex     sync.RWMutex
+	checks    []namedChecker
+	status    string
+	startedAt time.Time
+
+	checkMutex sync.Mutex
+	results    []CheckReport
+	checkedAt  time.Time
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// Register is responsible for adding the check of a dependency, reported under name
+func (registry *Registry) Register(name string, checker Checker) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.checks = append(registry.checks, namedChecker{name: name, checker: checker})
+
+	registry.checkMutex.Lock()
+	defer registry.checkMutex.Unlock()
+	registry.results = nil
+}
+
+// Start is responsible for reporting the application as ready
+func (registry *Registry) Start() error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.status = StatusUp
+	registry.startedAt = registry.TS.GetCurrentTime()
+	return nil
+}
+
+// Stop is responsible for failing the readiness, then waiting DrainDelay within the deadline
+// of the given context
+func (registry *Registry) Stop(ctx context.Context) error {
+	registry.mutex.Lock()
+	registry.status = StatusStopping
+	registry.mutex.Unlock()
+
+	if registry.DrainDelay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(registry.DrainDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+// Alive is responsible for reporting the application as up while it runs, regardless of
+// its dependencies, whose failures are not fixed by restarting it
+func (registry *Registry) Alive() Report {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	return Report{Status: StatusUp, Time: registry.TS.GetCurrentTime(), StartedAt: registry.startedAt}
+}
+
+// Report is responsible for checking the dependencies concurrently, each within its timeout,
+// the results being reused for CacheTTL while the status is always the current one
+func (registry *Registry) Report(ctx context.Context) Report {
+	registry.mutex.RLock()
+	checks := registry.checks
+	report := Report{Status: registry.status, StartedAt: registry.startedAt}
+	registry.mutex.RUnlock()
+
+	if report.Status == "" {
+		report.Status = StatusStarting
+	}
+	report.Checks = registry.checkAll(ctx, checks)
+	for _, check := range report.Checks {
+		if check.Status != StatusUp && report.Status == StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	report.Time = registry.TS.GetCurrentTime()
+	return report
+}
+
+// checkAll is responsible for running the checks concurrently, unless they ran within CacheTTL,
+// the concurrent reports waiting for the running checks rather than running them again
+func (registry *Registry) checkAll(ctx context.Context, checks []namedChecker) []CheckReport {
+	registry.checkMutex.Lock()
+	defer registry.checkMutex.Unlock()
+
+	now := registry.TS.GetCurrentTime()
+	if registry.results == nil || now.Sub(registry.checkedAt) >= registry.cacheTTL() {
+		results := make([]CheckReport, len(checks))
+		var group sync.WaitGroup
+		for i := range checks {
+			group.Add(1)
+			go func(i int) {
+				defer group.Done()
+				results[i] = registry.check(ctx, checks[i])
+			}(i)
+		}
+		group.Wait()
+		registry.results = results
+		registry.checkedAt = now
+	}
+	return append([]CheckReport(nil), registry.results...)
+}
+
+// check is responsible for running a check within its timeout, even when the checker
+// ignores the deadline of the context
+func (registry *Registry) check(ctx context.Context, current namedChecker) CheckReport {
+	timeout := registry.timeout(current.name)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := registry.TS.GetCurrentTime()
+	result := make(chan error, 1)
+	go func() {
+		result <- current.checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %v", timeout)
+	}
+	report := CheckReport{
+		Name:      current.name,
+		Status:    StatusUp,
+		LatencyMS: toMilliseconds(registry.TS.GetCurrentTime().Sub(start)),
+		TimeoutMS: toMilliseconds(timeout),
+	}
+	if err != nil {
+		report.Status = StatusDown
+		report.Error = err.Error()
+	}
+	return report
+}
+
+func (registry *Registry) timeout(name string) time.Duration {
+	if timeout := registry.Timeouts[name]; timeout > 0 {
+		return timeout
+	}
+	if registry.Timeout <= 0 {
+		return DefaultTimeout
+	}
+	return registry.Timeout
+}
+
+func (registry *Registry) cacheTTL() time.Duration {
+	if registry.CacheTTL <= 0 {
+		return DefaultCacheTTL
+	}
+	return registry.CacheTTL
+}
+
+func toMilliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
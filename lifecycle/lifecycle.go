@@ -0,0 +1,187 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Component is responsible for providing the start and stop routines of a part of the application
+type Component interface {
+	// Start is responsible for starting the component without blocking
+	Start() error
+	// Stop is responsible for stopping the component within the deadline of the given context
+	Stop(ctx context.Context) error
+}
+
+// Hook allows ordinary functions to be registered as a Component
+type Hook struct {
+	OnStart func() error
+	OnStop  func(ctx context.Context) error
+}
+
+// Start calls OnStart, if any
+func (hook Hook) Start() error {
+	if hook.OnStart == nil {
+		return nil
+	}
+	return hook.OnStart()
+}
+
+// Stop calls OnStop, if any
+func (hook Hook) Stop(ctx context.Context) error {
+	if hook.OnStop == nil {
+		return nil
+	}
+	return hook.OnStop(ctx)
+}
+
+// Manager is responsible for starting the registered components in order and
+// stopping them in reverse order when the application is shut down
+type Manager struct {
+	ShutdownTimeout time.Duration
+	mu              sync.Mutex
+	components      []namedComponent
+	started         []namedComponent
+	failures        chan error
+}
+
+type namedComponent struct {
+	name      string
+	component Component
+}
+
+// Register is responsible for adding a component to the end of the start order
+func (manager *Manager) Register(name string, component Component) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	manager.components = append(manager.components, namedComponent{name: name, component: component})
+}
+
+// Start is responsible for starting all registered components in registration order,
+// stopping the already started ones if any of them fails
+func (manager *Manager) Start() error {
+	manager.mu.Lock()
+	components := manager.components
+	manager.mu.Unlock()
+
+	for _, c := range components {
+		log.Printf("Starting %s...", c.name)
+		if err := c.component.Start(); err != nil {
+			ctx, cancel := manager.shutdownContext()
+			defer cancel()
+			manager.Stop(ctx)
+			return &Error{Component: c.name, Cause: err}
+		}
+		manager.mu.Lock()
+		manager.started = append(manager.started, c)
+		manager.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop is responsible for stopping the started components in reverse start order
+func (manager *Manager) Stop(ctx context.Context) error {
+	manager.mu.Lock()
+	started := manager.started
+	manager.started = nil
+	manager.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		log.Printf("Stopping %s...", c.name)
+		if err := c.component.Stop(ctx); err != nil {
+			errs = append(errs, &Error{Component: c.name, Cause: err})
+		}
+	}
+	if len(errs) > 0 {
+		return &StopError{Causes: errs}
+	}
+	return nil
+}
+
+// Fail is responsible for notifying that a started component has failed,
+// which causes the application to shut down
+func (manager *Manager) Fail(name string, err error) {
+	select {
+	case manager.failureChannel() <- &Error{Component: name, Cause: err}:
+	default:
+	}
+}
+
+// Run is responsible for starting the components and waiting for them to be stopped
+func (manager *Manager) Run() error {
+	if err := manager.Start(); err != nil {
+		return err
+	}
+	return manager.Wait()
+}
+
+// Wait is responsible for waiting for SIGINT, SIGTERM or a component failure and
+// then stopping the started components within ShutdownTimeout
+func (manager *Manager) Wait() error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var runErr error
+	select {
+	case sig := <-signals:
+		log.Printf("Received %v, shutting down...", sig)
+	case runErr = <-manager.failureChannel():
+		log.Printf("Shutting down: %v", runErr)
+	}
+
+	ctx, cancel := manager.shutdownContext()
+	defer cancel()
+	if err := manager.Stop(ctx); err != nil && runErr == nil {
+		runErr = err
+	}
+	return runErr
+}
+
+func (manager *Manager) failureChannel() chan error {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	if manager.failures == nil {
+		manager.failures = make(chan error, 1)
+	}
+	return manager.failures
+}
+
+func (manager *Manager) shutdownContext() (context.Context, context.CancelFunc) {
+	if manager.ShutdownTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), manager.ShutdownTimeout)
+}
+
+// Error is responsible for encapsulating errors generated by a component
+type Error struct {
+	Component string
+	Cause     error
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%s: %v", err.Component, err.Cause)
+}
+
+// StopError is responsible for gathering the errors generated while stopping the components
+type StopError struct {
+	Causes []error
+}
+
+func (err *StopError) Error() string {
+	messages := make([]string, len(err.Causes))
+	for i, cause := range err.Causes {
+		messages[i] = cause.Error()
+	}
+	return strings.Join(messages, "; ")
+}
@@ -1,18 +1,497 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/zeroberto/go-ms-template/api"
+	"github.com/zeroberto/go-ms-template/api/rest"
+	"github.com/zeroberto/go-ms-template/auth"
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/chrono/provider"
+	"github.com/zeroberto/go-ms-template/config"
+	apikeydatamysql "github.com/zeroberto/go-ms-template/dataservice/apikeydata/datamysql"
+	"github.com/zeroberto/go-ms-template/dataservice/exampledata/datamysql"
+	"github.com/zeroberto/go-ms-template/dataservice/idempotencydata/datamemory"
+	idempotencydatamysql "github.com/zeroberto/go-ms-template/dataservice/idempotencydata/datamysql"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver"
+	"github.com/zeroberto/go-ms-template/driver/dbdriver/sqldbdriver"
+	"github.com/zeroberto/go-ms-template/handler/httphandler"
+	"github.com/zeroberto/go-ms-template/handler/httpserver"
+	"github.com/zeroberto/go-ms-template/handler/middleware"
+	"github.com/zeroberto/go-ms-template/health"
+	"github.com/zeroberto/go-ms-template/lifecycle"
+	"github.com/zeroberto/go-ms-template/openapi"
+	"github.com/zeroberto/go-ms-template/usecase"
+	"github.com/zeroberto/go-ms-template/usecase/apikey"
+	"github.com/zeroberto/go-ms-template/usecase/example/creation"
+	"github.com/zeroberto/go-ms-template/usecase/example/read"
+	"github.com/zeroberto/go-ms-template/usecase/example/removal"
+	"github.com/zeroberto/go-ms-template/usecase/idempotency"
+	"github.com/zeroberto/go-ms-template/usecase/stream"
 )
 
-func home(w http.ResponseWriter, r *http.Request) {
-	fmt.Fprint(w, "Home!")
+func main() {
+	configFileName := flag.String("config", "config/applicationDev.yml", "path of the application config file")
+	address := flag.String("address", "", "address the server listens on, overrides serverConfig.address")
+	flag.Parse()
+
+	appConfig, err := config.ReadConfig(*configFileName)
+	if err != nil {
+		fail("config", err)
+	}
+	if *address != "" {
+		appConfig.ServerConfig.Address = *address
+	}
+
+	manager := &lifecycle.Manager{ShutdownTimeout: appConfig.ServerConfig.ShutdownTimeout}
+
+	sqlDriver := &sqldbdriver.SQLDBDriver{}
+	manager.Register("sql database", lifecycle.Hook{
+		OnStart: func() (err error) {
+			sqlDriver.DB, err = openSQLDB(appConfig.SQLDBConfig)
+			return err
+		},
+		OnStop: func(ctx context.Context) error {
+			return sqlDriver.Close()
+		},
+	})
+
+	ts := &provider.TimeStampImpl{}
+	eds := &datamysql.ExampleDataServiceMySQL{SQLD: sqlDriver}
+	esuc := &stream.ExampleStreamUseCaseImpl{
+		TS:               ts,
+		ReplaySize:       appConfig.StreamConfig.ReplaySize,
+		SubscriberBuffer: appConfig.StreamConfig.SubscriberBuffer,
+		MaxSubscribers:   appConfig.StreamConfig.MaxSubscribers,
+	}
+	ermuc := &removal.ExampleRemovalUseCaseImpl{EDS: eds, TS: ts, EEP: esuc}
+	eapi := &rest.ExampleAPIRest{
+		ECUC:  &creation.ExampleCreationUseCaseImpl{EDS: eds, TS: ts, ERMUC: ermuc, EEP: esuc},
+		ERUC:  &read.ExampleReadUseCaseImpl{EDS: eds},
+		ERMUC: ermuc,
+		TS:    ts,
+
+		DefaultPageSize: appConfig.PaginationConfig.DefaultPageSize,
+		MaxPageSize:     appConfig.PaginationConfig.MaxPageSize,
+		RequireIfMatch:  appConfig.ServerConfig.RequireIfMatch,
+		MaxBatchSize:    appConfig.ServerConfig.MaxBatchSize,
+	}
+
+	iapi, err := getIdempotencyAPI(appConfig.IdempotencyConfig, sqlDriver, ts)
+	if err != nil {
+		fail("idempotency", err)
+	}
+
+	versions, err := getVersions(appConfig.ServerConfig.Versions)
+	if err != nil {
+		fail("versions", err)
+	}
+
+	mux := http.NewServeMux()
+	restHandler := &httphandler.RestHTTPHandler{
+		EAPI:         eapi,
+		TS:           ts,
+		CacheControl: appConfig.ServerConfig.CacheControl,
+		IAPI:         iapi,
+		Versions:     versions,
+		ESAPI:        &rest.ExampleStreamAPIRest{ESUC: esuc, TS: ts},
+		KeepAlive:    appConfig.StreamConfig.KeepAlive,
+
+		AllowedOrigins: appConfig.SecurityConfig.CORS.AllowedOrigins,
+	}
+	mux.Handle(httphandler.ExamplesPath, restHandler)
+	mux.Handle(httphandler.ExamplesPath+"/", restHandler)
+	mux.Handle(httphandler.ExamplesBatchPath, restHandler)
+	for _, version := range versions {
+		mux.Handle("/"+version.Name+"/", restHandler)
+	}
+	openAPIHandler := &httphandler.OpenAPIHTTPHandler{
+		Info:     openapi.Info{Title: "Example API", Version: "1.0.0"},
+		TS:       ts,
+		Versions: versions,
+	}
+	mux.Handle(httphandler.OpenAPIPath, openAPIHandler)
+	mux.Handle(httphandler.DocsPath, openAPIHandler)
+	mux.Handle(httphandler.DocsPath+"/", openAPIHandler)
+
+	registry := &health.Registry{
+		Timeout:    appConfig.HealthConfig.Timeout,
+		Timeouts:   appConfig.HealthConfig.Timeouts,
+		DrainDelay: appConfig.HealthConfig.DrainDelay,
+		CacheTTL:   appConfig.HealthConfig.CacheTTL,
+		TS:         ts,
+	}
+	registry.Register("sql database", sqlDriver)
+	registry.Register("example stream", esuc)
+	// the detailed report is served with the API, its errors being shown to authenticated clients
+	healthHandler := &httphandler.HealthHTTPHandler{HR: registry, ShowErrors: appConfig.AuthConfig.Enabled, TS: ts}
+	mux.Handle(httphandler.HealthPath, healthHandler)
+
+	akuc := &apikey.APIKeyUseCaseImpl{AKDS: &apikeydatamysql.APIKeyDataServiceMySQL{SQLD: sqlDriver}, TS: ts}
+	if appConfig.AuthConfig.Enabled && appConfig.AuthConfig.APIKeys {
+		apiKeyHandler := &httphandler.APIKeyHTTPHandler{
+			AKAPI: &rest.APIKeyAPIRest{AKUC: akuc, TS: ts},
+			TS:    ts,
+		}
+		mux.Handle(httphandler.APIKeysPath, apiKeyHandler)
+		mux.Handle(httphandler.APIKeysPath+"/", apiKeyHandler)
+	}
+
+	middlewares, err := getMiddlewares(appConfig, akuc, ts)
+	if err != nil {
+		fail("middlewares", err)
+	}
+	// the probes skip the authentication, the limits and the access log of the API, so that the
+	// orchestrators reach them even when the service sheds load
+	root := http.NewServeMux()
+	probeHandler := middleware.Chain(healthHandler, getProbeMiddlewares(appConfig.MiddlewareConfig, ts)...)
+	root.Handle(httphandler.LivenessPath, probeHandler)
+	root.Handle(httphandler.ReadinessPath, probeHandler)
+	root.Handle("/", middleware.Chain(mux, middlewares...))
+	listeners, err := getListeners(appConfig.ServerConfig, root, ts)
+	if err != nil {
+		fail("listeners", err)
+	}
+	for _, listener := range listeners {
+		listener := listener
+		name := "http server " + listener.Address
+		listener.OnError = func(err error) {
+			manager.Fail(name, err)
+		}
+		manager.Register(name, lifecycle.Hook{
+			OnStart: func() error {
+				if err := listener.Start(); err != nil {
+					return err
+				}
+				log.Printf("Listening on %s", listener)
+				return nil
+			},
+			OnStop: listener.Stop,
+		})
+	}
+	// the stream stops before the listeners, ending the open streams that would hold their
+	// shutdown
+	manager.Register("example stream", esuc)
+	// the readiness is the last to start and the first to fail, while the listeners still
+	// serve the requests in progress
+	manager.Register("health", registry)
+
+	if err := manager.Start(); err != nil {
+		if lerr, ok := err.(*lifecycle.Error); ok {
+			fail(lerr.Component, lerr.Cause)
+		}
+		fail("service", err)
+	}
+	if err := manager.Wait(); err != nil {
+		fmt.Fprintf(os.Stderr, "Service stopped with error: %v\n", err)
+		os.Exit(1)
+	}
+	log.Println("Service stopped")
 }
 
-func main() {
-	log.Println("Starting server...")
-	http.HandleFunc("/", home)
+// getProbeMiddlewares is responsible for providing the middlewares of the health probes, which
+// only identify the requests and recover from the panics
+func getProbeMiddlewares(middlewareConfig config.MiddlewareConfig, ts chrono.TimeStamp) []middleware.Middleware {
+	var middlewares []middleware.Middleware
+	if middlewareConfig.RequestIDHeader != "" {
+		middlewares = append(middlewares, middleware.RequestID(middlewareConfig.RequestIDHeader))
+	}
+	if middlewareConfig.RecoverPanics {
+		middlewares = append(middlewares, middleware.Recover(ts))
+	}
+	return middlewares
+}
+
+func getMiddlewares(appConfig *config.AppConfig, akuc usecase.APIKeyUseCase, ts chrono.TimeStamp) ([]middleware.Middleware, error) {
+	middlewareConfig := appConfig.MiddlewareConfig
+	var middlewares []middleware.Middleware
+	if middlewareConfig.RequestIDHeader != "" {
+		middlewares = append(middlewares, middleware.RequestID(middlewareConfig.RequestIDHeader))
+	}
+	if middlewareConfig.AccessLog {
+		middlewares = append(middlewares, middleware.AccessLog(os.Stdout, ts))
+	}
+	if middlewareConfig.RecoverPanics {
+		middlewares = append(middlewares, middleware.Recover(ts))
+	}
+	headersConfig := appConfig.SecurityConfig.Headers
+	securityHeaders := &middleware.SecurityHeaders{
+		HSTSMaxAge:            headersConfig.HSTSMaxAge,
+		HSTSIncludeSubdomains: headersConfig.HSTSIncludeSubdomains,
+		ContentTypeNosniff:    headersConfig.ContentTypeNosniff,
+		FrameOptions:          headersConfig.FrameOptions,
+		ContentSecurityPolicy: headersConfig.ContentSecurityPolicy,
+		ReferrerPolicy:        headersConfig.ReferrerPolicy,
+	}
+	middlewares = append(middlewares, securityHeaders.Apply)
+	if corsConfig := appConfig.SecurityConfig.CORS; len(corsConfig.AllowedOrigins) > 0 {
+		cors := &middleware.CORS{
+			AllowedOrigins:   corsConfig.AllowedOrigins,
+			AllowedMethods:   corsConfig.AllowedMethods,
+			AllowedHeaders:   corsConfig.AllowedHeaders,
+			ExposedHeaders:   corsConfig.ExposedHeaders,
+			AllowCredentials: corsConfig.AllowCredentials,
+			MaxAge:           corsConfig.MaxAge,
+			TS:               ts,
+		}
+		middlewares = append(middlewares, cors.Handle)
+	}
+	if middlewareConfig.MaxBodySize > 0 {
+		middlewares = append(middlewares, middleware.MaxBodySize(middlewareConfig.MaxBodySize, ts))
+	}
+	requestTimeout := &middleware.RequestTimeout{
+		Default: appConfig.ServerConfig.RequestTimeout,
+		Routes:  appConfig.ServerConfig.RouteTimeouts,
+		Route:   getOperation,
+		Exempt:  isStream,
+	}
+	middlewares = append(middlewares, requestTimeout.Apply)
+
+	rateLimitConfig := appConfig.RateLimitConfig
+	if rateLimitConfig.MaxInFlight > 0 {
+		middlewares = append(middlewares, middleware.LimitInFlight(rateLimitConfig.MaxInFlight, isStream, ts))
+	}
+	if usesClientCertificates(appConfig.ServerConfig.Listeners) {
+		middlewares = append(middlewares, middleware.ClientCertificate(appConfig.AuthConfig.ClientCertificateScopes))
+	}
+	if appConfig.AuthConfig.Enabled {
+		authenticator, err := getAuthenticator(appConfig.AuthConfig, akuc, ts)
+		if err != nil {
+			return nil, err
+		}
+		middlewares = append(middlewares, authenticator.Authenticate)
+	}
+	keys := make([]middleware.KeyFunc, 0, len(rateLimitConfig.Keys))
+	for _, key := range rateLimitConfig.Keys {
+		switch key {
+		case "subject":
+			keys = append(keys, middleware.SubjectKey)
+		case "ip":
+			keys = append(keys, middleware.ClientIPKey)
+		default:
+			return nil, fmt.Errorf("unknown rate limit key %q", key)
+		}
+	}
+	rateLimiter := &middleware.RateLimiter{
+		Default: middleware.RateLimit(rateLimitConfig.Default),
+		Routes:  make(map[string]middleware.RateLimit, len(rateLimitConfig.Routes)),
+		Route:   getOperation,
+		Key:     middleware.FirstKey(keys...),
+		TS:      ts,
+	}
+	for operation, limit := range rateLimitConfig.Routes {
+		rateLimiter.Routes[operation] = middleware.RateLimit(limit)
+	}
+	middlewares = append(middlewares, rateLimiter.Limit)
+	return middlewares, nil
+}
+
+func getAuthenticator(authConfig config.AuthConfig, akuc usecase.APIKeyUseCase, ts chrono.TimeStamp) (*middleware.Authenticator, error) {
+	schemes := map[string]middleware.CredentialVerifier{}
+	var keySets auth.KeySets
+	if authConfig.HMACSecretFile != "" {
+		secret, err := ioutil.ReadFile(authConfig.HMACSecretFile)
+		if err != nil {
+			return nil, err
+		}
+		keySets = append(keySets, auth.HMACSecret(strings.TrimSpace(string(secret))))
+	}
+	if authConfig.JWKSFile != "" {
+		jwks, err := auth.ReadJWKS(authConfig.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keySets = append(keySets, jwks)
+	}
+	if authConfig.JWKSURL != "" {
+		keySets = append(keySets, &auth.RemoteJWKS{
+			URL:             authConfig.JWKSURL,
+			Client:          &http.Client{Timeout: 10 * time.Second},
+			RefreshInterval: authConfig.JWKSRefreshInterval,
+			TS:              ts,
+		})
+	}
+	if len(keySets) > 0 {
+		schemes[middleware.BearerScheme] = &auth.Verifier{
+			Keys:     keySets,
+			Issuer:   authConfig.Issuer,
+			Audience: authConfig.Audience,
+			Leeway:   authConfig.Leeway,
+			TS:       ts,
+		}
+	}
+	if authConfig.APIKeys {
+		for _, route := range httphandler.APIKeyRoutes {
+			if len(authConfig.Scopes[route.Operation]) == 0 {
+				return nil, fmt.Errorf("the operation %s must require scopes", route.Operation)
+			}
+		}
+		schemes[middleware.APIKeyScheme] = &auth.APIKeyVerifier{AKUC: akuc, TTL: authConfig.APIKeyCacheTTL, TS: ts}
+	}
+	if len(schemes) == 0 && len(authConfig.ClientCertificateScopes) == 0 {
+		return nil, fmt.Errorf("no keys to verify the tokens")
+	}
+	return &middleware.Authenticator{
+		Schemes: schemes,
+		Scopes:  authConfig.Scopes,
+		Route:   getOperation,
+		Realm:   authConfig.Realm,
+		TS:      ts,
+	}, nil
+}
+
+// getListeners is responsible for providing the listeners of the addresses configured,
+// serving Address over plaintext HTTP/1.1 when none is
+func getListeners(serverConfig config.ServerConfig, handler http.Handler, ts chrono.TimeStamp) ([]*httpserver.Listener, error) {
+	if len(serverConfig.Listeners) == 0 {
+		return []*httpserver.Listener{{Address: serverConfig.Address, Handler: handler}}, nil
+	}
+	listeners := make([]*httpserver.Listener, 0, len(serverConfig.Listeners))
+	for _, listenerConfig := range serverConfig.Listeners {
+		listener := &httpserver.Listener{
+			Address: listenerConfig.Address,
+			Handler: handler,
+			HTTP2:   listenerConfig.HTTP2,
+			H2C:     listenerConfig.H2C,
+		}
+		if tlsConfig := listenerConfig.TLS; tlsConfig.CertFile != "" {
+			certificates := &httpserver.Certificates{
+				CertFile:       tlsConfig.CertFile,
+				KeyFile:        tlsConfig.KeyFile,
+				ClientCAFile:   tlsConfig.ClientCAFile,
+				ReloadInterval: tlsConfig.ReloadInterval,
+				TS:             ts,
+			}
+			if err := certificates.Load(); err != nil {
+				return nil, err
+			}
+			policy := httpserver.TLSPolicy{
+				MinVersion:   tlsConfig.MinVersion,
+				CipherSuites: tlsConfig.CipherSuites,
+				ClientAuth:   tlsConfig.ClientAuth,
+			}
+			var err error
+			if listener.TLS, err = policy.Config(certificates); err != nil {
+				return nil, err
+			}
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// usesClientCertificates is responsible for checking whether any listener authenticates the
+// clients by certificate
+func usesClientCertificates(listenerConfigs []config.ListenerConfig) bool {
+	for _, listenerConfig := range listenerConfigs {
+		if listenerConfig.TLS.ClientCAFile != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getIdempotencyAPI is responsible for providing the IdempotencyAPI of the store configured,
+// nil when the Idempotency-Key is ignored
+func getIdempotencyAPI(idempotencyConfig config.IdempotencyConfig, sqlDriver dbdriver.SQLDriver, ts chrono.TimeStamp) (api.IdempotencyAPI, error) {
+	iuc := &idempotency.IdempotencyUseCaseImpl{
+		TS:    ts,
+		TTL:   idempotencyConfig.TTL,
+		Lease: idempotencyConfig.Lease,
+		Wait:  idempotencyConfig.Wait,
+	}
+	switch idempotencyConfig.Store {
+	case "":
+		return nil, nil
+	case "memory":
+		iuc.IDS = &datamemory.IdempotencyDataServiceMemory{}
+	case "sql":
+		iuc.IDS = &idempotencydatamysql.IdempotencyDataServiceMySQL{SQLD: sqlDriver}
+	default:
+		return nil, fmt.Errorf("unknown idempotency store %q", idempotencyConfig.Store)
+	}
+	return &rest.IdempotencyAPIRest{IUC: iuc, TS: ts}, nil
+}
+
+// getVersions is responsible for providing the versions of the API, with the deprecations
+// configured
+func getVersions(versionConfigs map[string]config.VersionConfig) ([]api.Version, error) {
+	versions := api.DefaultVersions()
+	for name, versionConfig := range versionConfigs {
+		found := false
+		for i := range versions {
+			if versions[i].Name == name {
+				versions[i].Deprecation = versionConfig.Deprecation
+				versions[i].Sunset = versionConfig.Sunset
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown version %q", name)
+		}
+	}
+	return versions, nil
+}
+
+func getOperation(request *http.Request) string {
+	route, _ := httphandler.RouteOf(request)
+	return route.Operation
+}
+
+// isStream is responsible for identifying the streams, which are limited by the maximum number
+// of subscribers rather than by the requests in flight
+func isStream(request *http.Request) bool {
+	return getOperation(request) == httphandler.StreamExamples
+}
+
+func openSQLDB(sqlDBConfig config.SQLDBConfig) (*sql.DB, error) {
+	dataSourceName, err := getDataSourceName(sqlDBConfig)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(sqlDBConfig.Type, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("couldn't reach %s:%d: %v", sqlDBConfig.Host, sqlDBConfig.Port, err)
+	}
+	return db, nil
+}
+
+func getDataSourceName(sqlDBConfig config.SQLDBConfig) (string, error) {
+	if sqlDBConfig.Type != "mysql" {
+		return "", fmt.Errorf("unsupported database type %q", sqlDBConfig.Type)
+	}
+	mysqlConfig := mysql.NewConfig()
+	mysqlConfig.Net = "tcp"
+	mysqlConfig.Addr = fmt.Sprintf("%s:%d", sqlDBConfig.Host, sqlDBConfig.Port)
+	mysqlConfig.DBName = sqlDBConfig.Database
+	mysqlConfig.User = sqlDBConfig.User
+	mysqlConfig.ParseTime = true
+	if sqlDBConfig.PasswordFile != "" {
+		password, err := ioutil.ReadFile(sqlDBConfig.PasswordFile)
+		if err != nil {
+			return "", err
+		}
+		mysqlConfig.Passwd = strings.TrimSpace(string(password))
+	}
+	return mysqlConfig.FormatDSN(), nil
+}
 
-	log.Fatal(http.ListenAndServe(":8080", nil))
+func fail(component string, err error) {
+	fmt.Fprintf(os.Stderr, "Couldn't start %s: %v\n", component, err)
+	os.Exit(1)
 }
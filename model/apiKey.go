@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// APIKey represents a key that authenticates a client of the API. Only the salted hash of
+// its secret is kept, the key being identified by its Prefix
+type APIKey struct {
+	ID int64
+	// Name describes the client the key was issued to
+	Name string `validate:"required,max=100,pattern=^[^\\p{Cc}]*$" normalize:"trim,nfc"`
+	// Prefix is the public part of the key, unique among the keys
+	Prefix string
+	Salt   []byte
+	// Hash is the SHA-256 of the Salt followed by the secret of the key
+	Hash   []byte
+	Scopes []string
+	// CreatedAt is the moment the current secret was issued
+	CreatedAt time.Time
+	// ExpiresAt is zero when the key does not expire
+	ExpiresAt time.Time
+	// RevokedAt is zero while the key is not revoked
+	RevokedAt time.Time
+}
@@ -0,0 +1,40 @@
+package model
+
+// BatchMode defines how a batch behaves when some of its items fail
+type BatchMode string
+
+const (
+	// BatchAllOrNothing applies the items only when all of them succeed
+	BatchAllOrNothing BatchMode = "allOrNothing"
+	// BatchBestEffort applies the items that succeed, regardless of the others
+	BatchBestEffort BatchMode = "bestEffort"
+)
+
+// BatchOperation defines the change an item of a batch makes to an Example
+type BatchOperation string
+
+const (
+	// BatchCreate creates the Example of the item
+	BatchCreate BatchOperation = "create"
+	// BatchUpdate updates the complete Example of the item
+	BatchUpdate BatchOperation = "update"
+	// BatchDelete permanently removes the Example identified by the item
+	BatchDelete BatchOperation = "delete"
+)
+
+// BatchItem represents a change within a batch. Update and delete identify the Example
+// by Example.ID, provided it is still at Example.Version, zero meaning the current version
+type BatchItem struct {
+	Operation BatchOperation
+	Example   Example
+}
+
+// BatchResult represents the outcome of an item of a batch, at the same position
+type BatchResult struct {
+	Operation BatchOperation
+	ID        int64
+	// Version is the version of the Example after the change, zero when it was removed
+	Version int64
+	// Err is nil when the change was applied
+	Err error
+}
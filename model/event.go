@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// ExampleEventType defines the change an ExampleEvent notifies
+type ExampleEventType string
+
+const (
+	// ExampleCreated notifies that the Example was created
+	ExampleCreated ExampleEventType = "created"
+	// ExampleUpdated notifies that properties of the Example changed
+	ExampleUpdated ExampleEventType = "updated"
+	// ExampleDeactivated notifies that the Example was removed logically
+	ExampleDeactivated ExampleEventType = "deactivated"
+	// ExampleDeleted notifies that the Example was permanently removed
+	ExampleDeleted ExampleEventType = "deleted"
+)
+
+// ExampleEventTypes lists every type of ExampleEvent
+var ExampleEventTypes = []ExampleEventType{ExampleCreated, ExampleUpdated, ExampleDeactivated, ExampleDeleted}
+
+// ExampleEvent represents a change to an Example, once it was persisted
+type ExampleEvent struct {
+	// ID orders the events, increasing by one with every event published
+	ID   uint64
+	Type ExampleEventType
+	// Example is the Example after the change, or before it when it was deleted
+	Example Example
+	Time    time.Time
+}
+
+// ExampleEventFilter restricts the events delivered to a subscriber, the empty lists
+// accepting every event
+type ExampleEventFilter struct {
+	Types []ExampleEventType
+	// IDs lists the identifiers of the Examples whose events are delivered
+	IDs []int64
+}
+
+// Matches is responsible for checking whether the event passes the filter
+func (filter ExampleEventFilter) Matches(event ExampleEvent) bool {
+	typeMatches := len(filter.Types) == 0
+	for _, eventType := range filter.Types {
+		typeMatches = typeMatches || eventType == event.Type
+	}
+	IDMatches := len(filter.IDs) == 0
+	for _, ID := range filter.IDs {
+		IDMatches = IDMatches || ID == event.Example.ID
+	}
+	return typeMatches && IDMatches
+}
@@ -4,9 +4,14 @@ import "time"
 
 // Example represents... insert a comment about the model here
 type Example struct {
-	ID            int64
-	Name          string
+	ID int64
+	// Name is unique regardless of case, and cannot hold control characters
+	Name          string `validate:"required,max=100,pattern=^[^\\p{Cc}]*$" normalize:"trim,nfc"`
 	Useful        bool
 	CreatedAt     time.Time
 	DeactivatedAt time.Time
+	// Version is incremented on every change, allowing concurrent changes to be detected
+	Version int64
+	// UpdatedAt is the moment of the last change
+	UpdatedAt time.Time
 }
@@ -0,0 +1,40 @@
+package model
+
+import "github.com/zeroberto/go-ms-template/filter"
+
+// ExampleFields represents the fields of Example that can be used to filter and sort listings
+var ExampleFields = filter.Fields{
+	"id":            filter.Int,
+	"name":          filter.String,
+	"useful":        filter.Bool,
+	"createdAt":     filter.Time,
+	"deactivatedAt": filter.Time,
+	"updatedAt":     filter.Time,
+	"active":        filter.Bool,
+}
+
+// FilterValue provides the value of a field of ExampleFields, allowing filters to be
+// evaluated without a database. The deactivatedAt of an active Example is nil, as the NULL
+// of its column, so that the filters select the same Examples regardless of the store
+func (example Example) FilterValue(field string) interface{} {
+	switch field {
+	case "id":
+		return example.ID
+	case "name":
+		return example.Name
+	case "useful":
+		return example.Useful
+	case "createdAt":
+		return example.CreatedAt
+	case "deactivatedAt":
+		if example.DeactivatedAt.IsZero() {
+			return nil
+		}
+		return example.DeactivatedAt
+	case "updatedAt":
+		return example.UpdatedAt
+	case "active":
+		return example.DeactivatedAt.IsZero()
+	}
+	return nil
+}
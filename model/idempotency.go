@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// IdempotentRequest represents a request identified by an idempotency key, which is run once
+// and whose response is provided again to the retries with the same key
+type IdempotentRequest struct {
+	// Key is the SHA-256 of the idempotency key, hex encoded, scoped to the client
+	Key string
+	// Fingerprint identifies the content of the request, so that a key is not reused for
+	// another request
+	Fingerprint string
+	// Reservation identifies the request that holds the key while it is in progress, so that
+	// only that request releases it
+	Reservation string
+	// Completed is false while the request is in progress, the response being unknown
+	Completed bool
+	Code      int
+	Path      int64
+	ETag      string
+	// BodyType names the type of the Body, which holds the JSON representation of the body
+	BodyType  string
+	Body      []byte
+	CreatedAt time.Time
+	// ExpiresAt is the moment the key can be used again, for another request
+	ExpiresAt time.Time
+}
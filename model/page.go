@@ -0,0 +1,26 @@
+package model
+
+import "github.com/zeroberto/go-ms-template/filter"
+
+// PageRequest represents the parameters for obtaining a page of a listing. When
+// AfterID or BeforeID is informed, the page is obtained by keyset and Offset is ignored
+type PageRequest struct {
+	Limit      int
+	Offset     int64
+	AfterID    int64
+	BeforeID   int64
+	CountTotal bool
+	// Filter restricts the listing, nil means no restriction
+	Filter filter.Expression
+	// Sort orders the listing before the ID, keyset pagination is only available without it
+	Sort []filter.Order
+}
+
+// ExamplePage represents a page of a listing of Examples
+type ExamplePage struct {
+	Examples    []Example
+	HasNext     bool
+	HasPrevious bool
+	// Total is only informed when requested by PageRequest.CountTotal
+	Total *int64
+}
@@ -0,0 +1,234 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the version of the OpenAPI Specification the documents follow
+const Version string = "3.1.0"
+
+// Document represents an OpenAPI document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info represents the metadata of the API described by a Document
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path, by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation represents an HTTP method of a path
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+	Deprecated  bool                `json:"deprecated,omitempty"`
+}
+
+// Parameter represents a parameter of an Operation, where In is "path", "query" or "header"
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody represents the body accepted by an Operation, by media type
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// MediaType represents the schema of a body in a specific media type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Response represents a response of an Operation
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header represents a header of a Response
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced by the operations of a Document
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+
+	types map[reflect.Type]string
+}
+
+// Schema represents a JSON Schema, as adopted by OpenAPI 3.1
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf is responsible for providing the schema of the Go value, registering the structs
+// it refers to in the components and referencing them by name. Struct fields are named by
+// their json tags and constrained by their validate tags, as interpreted by the validation
+// package
+func (components *Components) SchemaOf(value interface{}) *Schema {
+	return components.schema(reflect.TypeOf(value))
+}
+
+func (components *Components) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Ptr:
+		return components.schema(t.Elem())
+	case t.Kind() == reflect.Struct:
+		return &Schema{Ref: "#/components/schemas/" + components.register(t)}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string", Format: "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return &Schema{Type: "array", Items: components.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: components.schema(t.Elem())}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: "integer", Minimum: &minimum}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	}
+	return &Schema{}
+}
+
+// register is responsible for adding the schema of the struct to the components, under the
+// name of the type, qualified by its package when the name is taken by another type
+func (components *Components) register(t reflect.Type) string {
+	if name, ok := components.types[t]; ok {
+		return name
+	}
+	if components.types == nil {
+		components.types = map[reflect.Type]string{}
+	}
+	if components.Schemas == nil {
+		components.Schemas = map[string]*Schema{}
+	}
+	name := t.Name()
+	if _, taken := components.Schemas[name]; taken || name == "" {
+		name = strings.Title(t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]) + name
+	}
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	components.types[t] = name
+	components.Schemas[name] = schema
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			// the fields of embedded structs are promoted, as encoding/json does
+			embedded := components.Schemas[components.register(field.Type)]
+			for fieldName, property := range embedded.Properties {
+				schema.Properties[fieldName] = property
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		fieldName := field.Name
+		if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+			continue
+		} else if tag != "" {
+			fieldName = tag
+		}
+		property := components.schema(field.Type)
+		if constrain(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, fieldName)
+		}
+		schema.Properties[fieldName] = property
+	}
+	return name
+}
+
+// constrain is responsible for applying the rules of the validate tag to the schema,
+// reporting whether the value is required
+func constrain(schema *Schema, tag string) bool {
+	required := false
+	for _, item := range strings.Split(tag, ",") {
+		name, argument := strings.TrimSpace(item), ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, argument = name[:i], name[i+1:]
+		}
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			bound, err := strconv.ParseFloat(argument, 64)
+			if err != nil {
+				continue
+			}
+			length := int(bound)
+			switch {
+			case schema.Type == "string" && name == "min":
+				schema.MinLength = &length
+			case schema.Type == "string":
+				schema.MaxLength = &length
+			case schema.Type != "integer" && schema.Type != "number":
+			case name == "min":
+				schema.Minimum = &bound
+			default:
+				schema.Maximum = &bound
+			}
+		case "pattern":
+			schema.Pattern = argument
+		case "enum":
+			schema.Enum = strings.Split(argument, "|")
+		}
+	}
+	return required
+}
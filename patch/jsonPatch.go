@@ -0,0 +1,246 @@
+package patch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPatch represents a JSON Patch, according to RFC 6902
+type JSONPatch []Operation
+
+// Operation represents an operation of a JSON Patch
+type Operation struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	From string `json:"from"`
+	// Value is nil when the member is absent, and null when it is null
+	Value json.RawMessage `json:"value"`
+}
+
+type operation struct {
+	op    string
+	path  []string
+	from  []string
+	value interface{}
+}
+
+// ParseJSONPatch is responsible for decoding a JSON Patch document, checking that its
+// operations are well formed
+func ParseJSONPatch(data []byte) (JSONPatch, error) {
+	var jsonPatch JSONPatch
+	if err := json.Unmarshal(data, &jsonPatch); err != nil {
+		return nil, &MalformedError{Message: fmt.Sprintf("Invalid JSON patch: %v", err)}
+	}
+	if _, err := jsonPatch.compile(); err != nil {
+		return nil, err
+	}
+	return jsonPatch, nil
+}
+
+// Apply is responsible for applying the operations in order, failing as a whole when any
+// of them fails
+func (jsonPatch JSONPatch) Apply(document interface{}) (interface{}, error) {
+	operations, err := jsonPatch.compile()
+	if err != nil {
+		return nil, err
+	}
+	document = clone(document)
+	for i, o := range operations {
+		path := jsonPatch[i].Path
+		switch o.op {
+		case "add":
+			document, err = add(document, o.path, clone(o.value))
+		case "remove":
+			document, _, err = remove(document, o.path)
+		case "replace":
+			if document, _, err = remove(document, o.path); err == nil {
+				document, err = add(document, o.path, clone(o.value))
+			}
+		case "move":
+			var value interface{}
+			if isPrefix(o.from, o.path) && len(o.from) < len(o.path) {
+				err = fmt.Errorf("cannot move %s into one of its children", jsonPatch[i].From)
+			} else if document, value, err = remove(document, o.from); err == nil {
+				document, err = add(document, o.path, value)
+			}
+		case "copy":
+			var value interface{}
+			if value, err = get(document, o.from); err == nil {
+				document, err = add(document, o.path, clone(value))
+			}
+		case "test":
+			var value interface{}
+			if value, err = get(document, o.path); err == nil && !equal(value, o.value) {
+				return nil, &TestFailedError{Index: i, Path: path}
+			}
+		}
+		if err != nil {
+			return nil, &Error{Index: i, Path: path, Message: err.Error()}
+		}
+	}
+	return document, nil
+}
+
+func (jsonPatch JSONPatch) compile() ([]operation, error) {
+	operations := make([]operation, 0, len(jsonPatch))
+	for i, o := range jsonPatch {
+		malformed := func(message string) error {
+			return &MalformedError{Message: fmt.Sprintf("Operation %d: %s", i, message)}
+		}
+		compiled := operation{op: o.Op}
+		var err error
+		if compiled.path, err = parsePointer(o.Path); err != nil {
+			return nil, malformed(err.Error())
+		}
+		switch o.Op {
+		case "add", "replace", "test":
+			if o.Value == nil {
+				return nil, malformed(fmt.Sprintf("%s requires a value", o.Op))
+			}
+			if compiled.value, err = decode(o.Value); err != nil {
+				return nil, malformed(err.Error())
+			}
+		case "move", "copy":
+			if compiled.from, err = parsePointer(o.From); err != nil {
+				return nil, malformed(err.Error())
+			}
+		case "remove":
+		default:
+			return nil, malformed(fmt.Sprintf("unknown operation %q", o.Op))
+		}
+		operations = append(operations, compiled)
+	}
+	return operations, nil
+}
+
+// parsePointer is responsible for splitting a JSON Pointer (RFC 6901) into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix []string, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func get(document interface{}, path []string) (interface{}, error) {
+	current := document
+	for _, token := range path {
+		switch container := current.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %s does not exist", token)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = container[index]
+		default:
+			return nil, fmt.Errorf("%s cannot be reached", token)
+		}
+	}
+	return current, nil
+}
+
+// add is responsible for providing the document with the value added at the path, where the
+// parent of the path must exist
+func add(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := get(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = value
+		return document, nil
+	case []interface{}:
+		index := len(container)
+		if token != "-" {
+			if index, err = arrayIndex(token, len(container)); err != nil {
+				return nil, err
+			}
+		}
+		container = append(container, nil)
+		copy(container[index+1:], container[index:])
+		container[index] = value
+		return set(document, path[:len(path)-1], container)
+	}
+	return nil, fmt.Errorf("%s cannot be reached", token)
+}
+
+// remove is responsible for providing the document without the value at the path, which must exist
+func remove(document interface{}, path []string) (interface{}, interface{}, error) {
+	value, err := get(document, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(path) == 0 {
+		return nil, value, nil
+	}
+	parent, _ := get(document, path[:len(path)-1])
+	token := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		delete(container, token)
+		return document, value, nil
+	case []interface{}:
+		index, _ := arrayIndex(token, len(container)-1)
+		container = append(container[:index:index], container[index+1:]...)
+		document, err = set(document, path[:len(path)-1], container)
+		return document, value, err
+	}
+	return nil, nil, fmt.Errorf("%s cannot be reached", token)
+}
+
+// set is responsible for replacing the value at an existing path, needed as arrays
+// change identity when they grow or shrink
+func set(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, _ := get(document, path[:len(path)-1])
+	token := path[len(path)-1]
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		container[token] = value
+	case []interface{}:
+		index, _ := arrayIndex(token, len(container)-1)
+		container[index] = value
+	}
+	return document, nil
+}
+
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("index %s is out of bounds", token)
+	}
+	return index, nil
+}
@@ -0,0 +1,234 @@
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+const (
+	// MergePatchType is the media type of JSON Merge Patch documents
+	MergePatchType string = "application/merge-patch+json"
+	// JSONPatchType is the media type of JSON Patch documents
+	JSONPatchType string = "application/json-patch+json"
+)
+
+// Patch represents a set of changes to a JSON document
+type Patch interface {
+	// Apply is responsible for providing the document resulting from the changes, without
+	// modifying the given document, which must be obtained by ToDocument
+	Apply(document interface{}) (interface{}, error)
+}
+
+// MergePatch represents a JSON Merge Patch, according to RFC 7396
+type MergePatch struct {
+	Value interface{}
+}
+
+// MalformedError must be reported when a patch document cannot be interpreted
+type MalformedError struct {
+	Message string
+}
+
+// Error must be reported when a patch cannot be applied to the document, such as
+// when a path does not exist
+type Error struct {
+	// Index is the position of the operation in a JSON Patch
+	Index   int
+	Path    string
+	Message string
+}
+
+// TestFailedError must be reported when a test operation of a JSON Patch is not satisfied
+type TestFailedError struct {
+	Index int
+	Path  string
+}
+
+func (err *MalformedError) Error() string {
+	return err.Message
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("Operation %d on %s: %s", err.Index, err.Path, err.Message)
+}
+
+func (err *TestFailedError) Error() string {
+	return fmt.Sprintf("Operation %d: test of %s failed", err.Index, err.Path)
+}
+
+// ParseMergePatch is responsible for decoding a JSON Merge Patch document
+func ParseMergePatch(data []byte) (MergePatch, error) {
+	value, err := decode(data)
+	if err != nil {
+		return MergePatch{}, &MalformedError{Message: fmt.Sprintf("Invalid merge patch: %v", err)}
+	}
+	return MergePatch{Value: value}, nil
+}
+
+// Apply is responsible for merging the patch into the document, where null members remove
+// the corresponding members of the document
+func (mergePatch MergePatch) Apply(document interface{}) (interface{}, error) {
+	return merge(document, mergePatch.Value), nil
+}
+
+func merge(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return clone(patch)
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	result := make(map[string]interface{}, len(targetObject))
+	for name, value := range targetObject {
+		result[name] = clone(value)
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(result, name)
+			continue
+		}
+		result[name] = merge(result[name], value)
+	}
+	return result
+}
+
+// Renamed represents a patch written against a representation of the document whose top-level
+// members are named differently, Names mapping the names of the document to the names of the
+// representation. Members without a name in Names are named alike
+type Renamed struct {
+	Patch Patch
+	Names map[string]string
+}
+
+// Apply is responsible for renaming the members of the document before applying the patch and
+// back afterwards, reporting the members the representation does not have under their names
+func (renamed Renamed) Apply(document interface{}) (interface{}, error) {
+	patched, err := renamed.Patch.Apply(rename(document, renamed.Names))
+	if err != nil {
+		return nil, err
+	}
+	patchedObject, ok := patched.(map[string]interface{})
+	if !ok {
+		return patched, nil
+	}
+	documentNames := make(map[string]string, len(renamed.Names))
+	for documentName, name := range renamed.Names {
+		documentNames[name] = documentName
+	}
+	for name := range patchedObject {
+		if _, ok := documentNames[name]; !ok && renamed.Names[name] != "" {
+			return nil, &MalformedError{Message: fmt.Sprintf("Property %s does not exist", name)}
+		}
+	}
+	return rename(patched, documentNames), nil
+}
+
+func rename(document interface{}, names map[string]string) interface{} {
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return document
+	}
+	result := make(map[string]interface{}, len(object))
+	for name, member := range object {
+		if newName, ok := names[name]; ok {
+			name = newName
+		}
+		result[name] = member
+	}
+	return result
+}
+
+// ToDocument is responsible for converting a value into the generic JSON document patches apply to
+func ToDocument(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decode(data)
+}
+
+// FromDocument is responsible for converting a generic JSON document into the target value,
+// reporting *json.UnmarshalTypeError when a member does not have the type of the target
+func FromDocument(document interface{}, target interface{}) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+func decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the document")
+	}
+	return value, nil
+}
+
+func clone(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for name, member := range typed {
+			result[name] = clone(member)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, element := range typed {
+			result[i] = clone(element)
+		}
+		return result
+	}
+	return value
+}
+
+// equal compares JSON values, considering numbers by their value rather than their text
+func equal(a interface{}, b interface{}) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if x == y {
+			return true
+		}
+		fx, errX := strconv.ParseFloat(string(x), 64)
+		fy, errY := strconv.ParseFloat(string(y), 64)
+		return errX == nil && errY == nil && fx == fy
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for name, member := range x {
+			other, ok := y[name]
+			if !ok || !equal(member, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
@@ -0,0 +1,224 @@
+package apikey
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+	"github.com/zeroberto/go-ms-template/validation"
+)
+
+const (
+	// KeyPrefix starts every key, so that leaked keys can be told apart by secret scanners
+	KeyPrefix string = "gms"
+	// prefixSize is the number of random bytes of the prefix, which is hex encoded
+	prefixSize = 6
+	// secretSize is the number of random bytes of the secret, which is base64url encoded
+	secretSize = 32
+	// saltSize is the number of random bytes of the salt of the hash
+	saltSize = 16
+	// maxScopesLength is the number of characters of the scopes joined by spaces, as they
+	// are kept
+	maxScopesLength = 1000
+)
+
+// apiKeyValidator checks the API keys against the validate tags of model.APIKey
+var apiKeyValidator = &validation.Validator{}
+
+// APIKeyUseCaseImpl corresponds to the implementation of the API key use case. The keys have
+// the form gms_<prefix>_<secret>, where only the prefix and a salted hash of the secret are kept
+type APIKeyUseCaseImpl struct {
+	AKDS dataservice.APIKeyDataService
+	TS   chrono.TimeStamp
+}
+
+// IssueAPIKey is responsible for issuing a new API key
+func (akuc *APIKeyUseCaseImpl) IssueAPIKey(ctx context.Context, apiKey *model.APIKey) (*model.APIKey, string, error) {
+	now := akuc.TS.GetCurrentTime()
+	if err := validateAPIKey(apiKey); err != nil {
+		return nil, "", err
+	}
+	if !apiKey.ExpiresAt.IsZero() && !apiKey.ExpiresAt.After(now) {
+		return nil, "", &usecase.ValidationError{
+			Message: "Property ExpiresAt must be in the future",
+			Fields:  []usecase.FieldError{{Field: "ExpiresAt", Message: "must be in the future"}},
+		}
+	}
+	key, err := newSecret(apiKey)
+	if err != nil {
+		return nil, "", usecase.Wrap(err)
+	}
+	apiKey.CreatedAt = now
+	apiKey.RevokedAt = time.Time{}
+	apiKey, err = akuc.AKDS.Create(ctx, apiKey)
+	if err != nil {
+		return nil, "", usecase.Wrap(err)
+	}
+	return apiKey, key, nil
+}
+
+// ListAPIKeys is responsible for obtaining all the API keys
+func (akuc *APIKeyUseCaseImpl) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	apiKeys, err := akuc.AKDS.FindAll(ctx)
+	if err != nil {
+		return nil, usecase.Wrap(err)
+	}
+	return apiKeys, nil
+}
+
+// RotateAPIKey is responsible for replacing the secret of an APIKey
+func (akuc *APIKeyUseCaseImpl) RotateAPIKey(ctx context.Context, ID int64) (*model.APIKey, string, error) {
+	apiKey, err := akuc.getCurrent(ctx, ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if !apiKey.RevokedAt.IsZero() {
+		return nil, "", &usecase.ConflictError{Cause: &dataservice.RevokedError{ID: ID}}
+	}
+	key, err := newSecret(apiKey)
+	if err != nil {
+		return nil, "", usecase.Wrap(err)
+	}
+	apiKey.CreatedAt = akuc.TS.GetCurrentTime()
+	if err := akuc.AKDS.UpdateSecret(ctx, apiKey); err != nil {
+		var revokedErr *dataservice.RevokedError
+		if errors.As(err, &revokedErr) {
+			return nil, "", &usecase.ConflictError{Cause: err}
+		}
+		return nil, "", usecase.Wrap(err)
+	}
+	return apiKey, key, nil
+}
+
+// RevokeAPIKey is responsible for revoking an APIKey
+func (akuc *APIKeyUseCaseImpl) RevokeAPIKey(ctx context.Context, ID int64) error {
+	apiKey, err := akuc.getCurrent(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if !apiKey.RevokedAt.IsZero() {
+		return nil
+	}
+	if err := akuc.AKDS.Revoke(ctx, ID, akuc.TS.GetCurrentTime()); err != nil {
+		return usecase.Wrap(err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey is responsible for obtaining the APIKey of a key, comparing the hash of
+// its secret in constant time
+func (akuc *APIKeyUseCaseImpl) AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	prefix, secret, ok := splitKey(key)
+	if !ok {
+		return nil, &usecase.InvalidCredentialsError{Reason: "malformed API key"}
+	}
+	apiKey, err := akuc.AKDS.FindByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, usecase.Wrap(err)
+	}
+	if apiKey == nil {
+		// the hash is computed anyway, so that unknown prefixes take as long as wrong secrets
+		hashSecret(make([]byte, saltSize), secret)
+		return nil, &usecase.InvalidCredentialsError{Reason: "unknown API key"}
+	}
+	if subtle.ConstantTimeCompare(hashSecret(apiKey.Salt, secret), apiKey.Hash) != 1 {
+		return nil, &usecase.InvalidCredentialsError{Reason: "unknown API key"}
+	}
+	now := akuc.TS.GetCurrentTime()
+	if !apiKey.RevokedAt.IsZero() && !now.Before(apiKey.RevokedAt) {
+		return nil, &usecase.InvalidCredentialsError{Reason: "the API key was revoked"}
+	}
+	if !apiKey.ExpiresAt.IsZero() && !now.Before(apiKey.ExpiresAt) {
+		return nil, &usecase.InvalidCredentialsError{Reason: "the API key expired"}
+	}
+	return apiKey, nil
+}
+
+func (akuc *APIKeyUseCaseImpl) getCurrent(ctx context.Context, ID int64) (*model.APIKey, error) {
+	apiKey, err := akuc.AKDS.FindByID(ctx, ID)
+	if err != nil {
+		return nil, usecase.Wrap(err)
+	}
+	if apiKey == nil {
+		return nil, &usecase.NotExistsError{ID: ID, Resource: "API keys"}
+	}
+	return apiKey, nil
+}
+
+// validateAPIKey is responsible for normalising the APIKey and checking it against the
+// validate tags of model.APIKey, the syntax of the scopes (RFC 6749) and their length
+func validateAPIKey(apiKey *model.APIKey) error {
+	validation.Normalize(apiKey)
+	err := apiKeyValidator.Validate(apiKey)
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		fields := make([]usecase.FieldError, 0, len(validationErr.Fields))
+		for _, field := range validationErr.Fields {
+			fields = append(fields, usecase.FieldError{Field: field.Field, Message: field.Message})
+		}
+		return &usecase.ValidationError{Message: err.Error(), Fields: fields}
+	}
+	if err != nil {
+		return usecase.Wrap(err)
+	}
+	for i, scope := range apiKey.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \t\r\n\"\\") {
+			field := fmt.Sprintf("Scopes[%d]", i)
+			return &usecase.ValidationError{
+				Message: fmt.Sprintf("Property %s must be a scope token", field),
+				Fields:  []usecase.FieldError{{Field: field, Message: "must be a scope token"}},
+			}
+		}
+	}
+	if utf8.RuneCountInString(strings.Join(apiKey.Scopes, " ")) > maxScopesLength {
+		message := fmt.Sprintf("must have at most %d characters, joined by spaces", maxScopesLength)
+		return &usecase.ValidationError{
+			Message: "Property Scopes " + message,
+			Fields:  []usecase.FieldError{{Field: "Scopes", Message: message}},
+		}
+	}
+	return nil
+}
+
+// newSecret is responsible for generating a new prefix, salt and secret for the APIKey,
+// providing the key, whose secret is kept only as a hash
+func newSecret(apiKey *model.APIKey) (string, error) {
+	random := make([]byte, prefixSize+secretSize+saltSize)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.Wrap(err, "couldn't generate the API key")
+	}
+	prefix := hex.EncodeToString(random[:prefixSize])
+	secret := base64.RawURLEncoding.EncodeToString(random[prefixSize : prefixSize+secretSize])
+	apiKey.Prefix = prefix
+	apiKey.Salt = random[prefixSize+secretSize:]
+	apiKey.Hash = hashSecret(apiKey.Salt, secret)
+	return fmt.Sprintf("%s_%s_%s", KeyPrefix, prefix, secret), nil
+}
+
+// splitKey is responsible for providing the prefix and the secret of a key
+func splitKey(key string) (string, string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != KeyPrefix || len(parts[1]) != 2*prefixSize || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+func hashSecret(salt []byte, secret string) []byte {
+	hash := sha256.Sum256(bytes.Join([][]byte{salt, []byte(secret)}, nil))
+	return hash[:]
+}
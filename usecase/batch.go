@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+// BatchAbortedError must be reported for the items of an all-or-nothing batch that were
+// not applied because another item failed
+type BatchAbortedError struct {
+	Index int
+}
+
+func (err *BatchAbortedError) Error() string {
+	return fmt.Sprintf("Not applied as item %d of the batch failed", err.Index)
+}
+
+// RunBatch is responsible for executing the items of a batch within a single transaction
+// started by batch, skipping the items whose results already report an error. In the
+// all-or-nothing mode nothing is executed when an item was rejected beforehand, the
+// transaction is undone as soon as an item fails and the other items are reported as aborted.
+// The events published by the items are only delivered once the transaction is committed
+func RunBatch(
+	ctx context.Context,
+	batch func(ctx context.Context, fn func(ctx context.Context) error) error,
+	mode model.BatchMode,
+	results []model.BatchResult,
+	execute func(ctx context.Context, i int) error,
+) error {
+	failed := -1
+	if mode == model.BatchAllOrNothing {
+		for i := range results {
+			if results[i].Err != nil {
+				AbortBatch(results, i)
+				return nil
+			}
+		}
+	}
+	ctx, pending := withPendingEvents(ctx)
+	err := batch(ctx, func(ctx context.Context) error {
+		for i := range results {
+			if results[i].Err != nil {
+				continue
+			}
+			if err := execute(ctx, i); err != nil {
+				results[i].Err = err
+				if mode == model.BatchAllOrNothing {
+					failed = i
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if failed >= 0 {
+		AbortBatch(results, failed)
+		return nil
+	}
+	if err != nil {
+		return Wrap(err)
+	}
+	pending.publish()
+	return nil
+}
+
+// ValidateBatchMode is responsible for checking that the mode of a batch is known
+func ValidateBatchMode(mode model.BatchMode) error {
+	if mode != model.BatchAllOrNothing && mode != model.BatchBestEffort {
+		return &ValidationError{
+			Message: fmt.Sprintf("Unknown batch mode %q", mode),
+			Fields:  []FieldError{{Field: "mode", Message: "is not a known batch mode"}},
+		}
+	}
+	return nil
+}
+
+// ValidateBatchItem is responsible for checking that the item requests one of the supported
+// operations and identifies the Example when it changes an existing one
+func ValidateBatchItem(item model.BatchItem, supported []model.BatchOperation) error {
+	known := false
+	for _, operation := range supported {
+		known = known || operation == item.Operation
+	}
+	if !known {
+		return &ValidationError{
+			Message: fmt.Sprintf("Unsupported batch operation %q", item.Operation),
+			Fields:  []FieldError{{Field: "operation", Message: "is not a supported operation"}},
+		}
+	}
+	if item.Operation != model.BatchCreate && item.Example.ID <= 0 {
+		return &ValidationError{
+			Message: fmt.Sprintf("The ID is required to %s an Example", item.Operation),
+			Fields:  []FieldError{{Field: "ID", Message: "must be informed"}},
+		}
+	}
+	return nil
+}
+
+// AbortBatch is responsible for reporting the items of a batch that did not fail as aborted
+// by the failure of the given item
+func AbortBatch(results []model.BatchResult, failed int) {
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		results[i].Err = &BatchAbortedError{Index: failed}
+		results[i].Version = 0
+		if results[i].Operation == model.BatchCreate {
+			results[i].ID = 0
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zeroberto/go-ms-template/model"
+)
+
+type pendingEventsKey struct{}
+
+// pendingEvents holds the events of a batch, which are only published once it is committed
+type pendingEvents struct {
+	mutex  sync.Mutex
+	events []pendingEvent
+}
+
+type pendingEvent struct {
+	publisher ExampleEventPublisher
+	event     model.ExampleEvent
+}
+
+// PublishExampleEvent is responsible for publishing the event through the publisher, if any.
+// The events of the changes made within RunBatch are held until the batch is committed, and
+// discarded when it is undone
+func PublishExampleEvent(ctx context.Context, publisher ExampleEventPublisher, event model.ExampleEvent) {
+	if publisher == nil {
+		return
+	}
+	if pending, ok := ctx.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		pending.mutex.Lock()
+		defer pending.mutex.Unlock()
+		pending.events = append(pending.events, pendingEvent{publisher: publisher, event: event})
+		return
+	}
+	publisher.Publish(event)
+}
+
+// withPendingEvents is responsible for providing a copy of the context that holds the events
+// published, unless it already does, in which case the events are left to the outer batch
+func withPendingEvents(ctx context.Context) (context.Context, *pendingEvents) {
+	if _, ok := ctx.Value(pendingEventsKey{}).(*pendingEvents); ok {
+		return ctx, nil
+	}
+	pending := &pendingEvents{}
+	return context.WithValue(ctx, pendingEventsKey{}, pending), pending
+}
+
+// publish is responsible for publishing the events held, in the order they were published
+func (pending *pendingEvents) publish() {
+	if pending == nil {
+		return
+	}
+	pending.mutex.Lock()
+	defer pending.mutex.Unlock()
+	for _, current := range pending.events {
+		current.publisher.Publish(current.event)
+	}
+	pending.events = nil
+}
@@ -1,95 +1,307 @@
 package creation
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/chrono"
 	"github.com/zeroberto/go-ms-template/dataservice"
 	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/patch"
 	"github.com/zeroberto/go-ms-template/tool"
 	"github.com/zeroberto/go-ms-template/usecase"
+	"github.com/zeroberto/go-ms-template/validation"
 )
 
 // ExampleCreationUseCaseImpl corresponds to the implementation of the example model creation use case
 type ExampleCreationUseCaseImpl struct {
 	EDS dataservice.ExampleDataService
+	TS  chrono.TimeStamp
+	// ERMUC removes the Examples of the delete items of SaveExamples, which are not supported without it
+	ERMUC usecase.ExampleRemovalUseCase
+	// EEP notifies the changes to the Examples, which are not notified when nil
+	EEP usecase.ExampleEventPublisher
 }
 
+// exampleValidator checks the Examples against the validate tags of model.Example
+var exampleValidator = &validation.Validator{}
+
 // CreateExample is responsible for creating a new Example
-func (ecuc *ExampleCreationUseCaseImpl) CreateExample(example *model.Example) (*model.Example, error) {
-	if err := ecuc.existsByName(example.Name, example.ID); err != nil {
+func (ecuc *ExampleCreationUseCaseImpl) CreateExample(ctx context.Context, example *model.Example) (*model.Example, error) {
+	if err := validateExample(example); err != nil {
 		return nil, err
 	}
-	example, err := ecuc.EDS.Create(example)
+	if err := ecuc.existsByName(ctx, example.Name, example.ID); err != nil {
+		return nil, err
+	}
+	example.CreatedAt = ecuc.TS.GetCurrentTime()
+	example.UpdatedAt = example.CreatedAt
+	example, err := ecuc.EDS.Create(ctx, example)
 	if err != nil {
-		return nil, &usecase.Error{Cause: err}
+		return nil, wrapDuplicate(err)
 	}
+	usecase.PublishExampleEvent(ctx, ecuc.EEP, model.ExampleEvent{Type: model.ExampleCreated, Example: *example, Time: example.CreatedAt})
 	return example, nil
 }
 
 // UpdateExample is responsible for updating the complete Example model
-func (ecuc *ExampleCreationUseCaseImpl) UpdateExample(example *model.Example) (*model.Example, error) {
-	if err := ecuc.notExistsByID(example.ID); err != nil {
+func (ecuc *ExampleCreationUseCaseImpl) UpdateExample(ctx context.Context, example *model.Example) (*model.Example, error) {
+	current, err := ecuc.getCurrent(ctx, example.ID, example.Version)
+	if err != nil {
 		return nil, err
 	}
-	if err := ecuc.existsByName(example.Name, example.ID); err != nil {
+	if err := validateExample(example); err != nil {
 		return nil, err
 	}
-	example, err := ecuc.EDS.Update(example)
+	example.Version = current.Version
+	example.CreatedAt = current.CreatedAt
+	example.DeactivatedAt = current.DeactivatedAt
+	example.UpdatedAt = ecuc.TS.GetCurrentTime()
+	if err := ecuc.existsByName(ctx, example.Name, example.ID); err != nil {
+		return nil, err
+	}
+	example, err = ecuc.EDS.Update(ctx, example)
 	if err != nil {
-		return nil, &usecase.Error{Cause: err}
+		return nil, wrapDuplicate(err)
 	}
+	usecase.PublishExampleEvent(ctx, ecuc.EEP, model.ExampleEvent{Type: model.ExampleUpdated, Example: *example, Time: example.UpdatedAt})
 	return example, nil
 }
 
-// UpdateExampleProperties is responsible for updating partial properties of the Example model
-func (ecuc *ExampleCreationUseCaseImpl) UpdateExampleProperties(ID int64, properties map[string]interface{}) (*model.Example, error) {
-	if err := ecuc.notExistsByID(ID); err != nil {
+// PatchExample is responsible for applying a patch to the Example model
+func (ecuc *ExampleCreationUseCaseImpl) PatchExample(ctx context.Context, ID int64, version int64, examplePatch patch.Patch) (*model.Example, error) {
+	current, err := ecuc.getCurrent(ctx, ID, version)
+	if err != nil {
 		return nil, err
 	}
-	propertyNames := getUpgradeableProperties()
-	for k := range properties {
-		if !tool.ContainsString(k, propertyNames) {
-			return nil, &usecase.Error{Cause: fmt.Errorf("Property %s does not exist or cannot be updated", k)}
-		}
+	properties, err := getPatchedProperties(current, examplePatch)
+	if err != nil {
+		return nil, err
+	}
+	if len(properties) == 0 {
+		return current, nil
 	}
 	if tool.ContainsStringKey("Name", properties) {
-		if err := ecuc.existsByName(properties["Name"].(string), ID); err != nil {
+		if err := ecuc.existsByName(ctx, properties["Name"].(string), ID); err != nil {
 			return nil, err
 		}
 	}
-	if err := ecuc.EDS.UpdateProperties(ID, properties); err != nil {
-		return nil, &usecase.Error{Cause: err}
+	updatedAt := ecuc.TS.GetCurrentTime()
+	if err := ecuc.EDS.UpdateProperties(ctx, ID, current.Version, updatedAt, properties); err != nil {
+		return nil, wrapDuplicate(err)
 	}
-	example, err := ecuc.EDS.FindByID(ID)
+	example, err := ecuc.EDS.FindByID(ctx, ID)
 	if err != nil {
-		return nil, &usecase.Error{Cause: err}
+		return nil, usecase.Wrap(err)
+	}
+	if example != nil {
+		usecase.PublishExampleEvent(ctx, ecuc.EEP, model.ExampleEvent{Type: model.ExampleUpdated, Example: *example, Time: updatedAt})
 	}
 	return example, nil
 }
 
-func (ecuc *ExampleCreationUseCaseImpl) existsByName(name string, ID int64) error {
-	example, err := ecuc.EDS.FindByName(name)
+// SaveExamples is responsible for creating, updating and removing Examples within a single transaction
+func (ecuc *ExampleCreationUseCaseImpl) SaveExamples(ctx context.Context, items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+	if err := usecase.ValidateBatchMode(mode); err != nil {
+		return nil, err
+	}
+	supported := []model.BatchOperation{model.BatchCreate, model.BatchUpdate}
+	if ecuc.ERMUC != nil {
+		supported = append(supported, model.BatchDelete)
+	}
+	results := make([]model.BatchResult, len(items))
+	names := map[string]int{}
+	for i, item := range items {
+		results[i] = model.BatchResult{Operation: item.Operation, ID: item.Example.ID}
+		if results[i].Err = usecase.ValidateBatchItem(item, supported); results[i].Err != nil || item.Operation == model.BatchDelete {
+			continue
+		}
+		example := item.Example
+		if results[i].Err = validateExample(&example); results[i].Err != nil {
+			continue
+		}
+		key := validation.Fold(example.Name)
+		if j, ok := names[key]; ok {
+			results[i].Err = &usecase.ConflictError{Cause: errors.Errorf("Example %s is also saved by item %d", example.Name, j)}
+			continue
+		}
+		names[key] = i
+	}
+
+	err := usecase.RunBatch(ctx, ecuc.EDS.Batch, mode, results, func(ctx context.Context, i int) error {
+		example := items[i].Example
+		var saved *model.Example
+		var err error
+		switch items[i].Operation {
+		case model.BatchCreate:
+			saved, err = ecuc.CreateExample(ctx, &example)
+		case model.BatchUpdate:
+			saved, err = ecuc.UpdateExample(ctx, &example)
+		case model.BatchDelete:
+			return ecuc.ERMUC.DeleteExample(ctx, example.ID, example.Version)
+		}
+		if err != nil {
+			return err
+		}
+		results[i].ID = saved.ID
+		results[i].Version = saved.Version
+		return nil
+	})
+	return results, err
+}
+
+func (ecuc *ExampleCreationUseCaseImpl) existsByName(ctx context.Context, name string, ID int64) error {
+	example, err := ecuc.EDS.FindByName(ctx, name)
 	if err != nil {
-		return &usecase.Error{Cause: err}
+		return usecase.Wrap(err)
 	}
 	if example != nil && example.ID != ID {
-		return &usecase.Error{Cause: errors.New("Example already exists")}
+		return &usecase.ConflictError{Cause: errors.New("Example already exists")}
 	}
 	return nil
 }
 
-func (ecuc *ExampleCreationUseCaseImpl) notExistsByID(ID int64) error {
-	example, err := ecuc.EDS.FindByID(ID)
+// wrapDuplicate is responsible for reporting the name taken by a concurrent request, after
+// existsByName, as the same ConflictError, without the message of the database
+func wrapDuplicate(err error) error {
+	err = usecase.Wrap(err)
+	var conflictErr *usecase.ConflictError
+	if errors.As(err, &conflictErr) {
+		return &usecase.ConflictError{Cause: errors.New("Example already exists")}
+	}
+	return err
+}
+
+func (ecuc *ExampleCreationUseCaseImpl) getCurrent(ctx context.Context, ID int64, version int64) (*model.Example, error) {
+	example, err := ecuc.EDS.FindByID(ctx, ID)
 	if err != nil {
-		return &usecase.Error{Cause: err}
+		return nil, usecase.Wrap(err)
 	}
 	if example == nil {
-		return &usecase.NotExistsError{ID: ID}
+		return nil, &usecase.NotExistsError{ID: ID}
+	}
+	if version != 0 && example.Version != version {
+		return nil, usecase.Wrap(&dataservice.StaleVersionError{ID: ID, Version: version})
+	}
+	return example, nil
+}
+
+// getPatchedProperties is responsible for applying the patch to the JSON representation of
+// the Example, providing the changed properties converted to the types of the model
+func getPatchedProperties(current *model.Example, examplePatch patch.Patch) (map[string]interface{}, error) {
+	document, err := patch.ToDocument(current)
+	if err != nil {
+		return nil, usecase.Wrap(err)
+	}
+	patched, err := examplePatch.Apply(document)
+	if err != nil {
+		return nil, toPatchError(err)
+	}
+	patchedObject, ok := patched.(map[string]interface{})
+	if !ok {
+		return nil, &usecase.ValidationError{Message: "The patched Example must be an object"}
+	}
+	original := document.(map[string]interface{})
+	changed := map[string]interface{}{}
+	for k, v := range patchedObject {
+		if originalValue, ok := original[k]; !ok || !reflect.DeepEqual(originalValue, v) {
+			changed[k] = v
+		}
+	}
+	for k := range original {
+		if _, ok := patchedObject[k]; !ok {
+			changed[k] = nil
+		}
+	}
+
+	propertyNames := getUpgradeableProperties()
+	var fields []usecase.FieldError
+	for k, v := range changed {
+		switch {
+		case !tool.ContainsString(k, propertyNames):
+			fields = append(fields, usecase.FieldError{Field: k, Message: "does not exist or cannot be updated"})
+		case v == nil && !tool.ContainsString(k, getNullableProperties()):
+			fields = append(fields, usecase.FieldError{Field: k, Message: "must not be null"})
+		}
+	}
+	if len(fields) > 0 {
+		sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		message := fmt.Sprintf("Property %s does not exist or cannot be updated", fields[0].Field)
+		if fields[0].Message != "does not exist or cannot be updated" {
+			message = fmt.Sprintf("Property %s %s", fields[0].Field, fields[0].Message)
+		}
+		return nil, &usecase.ValidationError{Message: message, Fields: fields}
+	}
+
+	var decoded model.Example
+	if err := patch.FromDocument(changed, &decoded); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, &usecase.ValidationError{
+				Message: fmt.Sprintf("Property %s must be of type %s", typeErr.Field, typeErr.Type.Kind()),
+				Fields:  []usecase.FieldError{{Field: typeErr.Field, Message: fmt.Sprintf("must be of type %s", typeErr.Type.Kind())}},
+			}
+		}
+		return nil, usecase.Wrap(err)
+	}
+	example := *current
+	value := reflect.ValueOf(&example).Elem()
+	for k := range changed {
+		value.FieldByName(k).Set(reflect.ValueOf(decoded).FieldByName(k))
+	}
+	if err := validateExample(&example); err != nil {
+		return nil, err
+	}
+	properties := make(map[string]interface{}, len(changed))
+	for k := range changed {
+		properties[k] = value.FieldByName(k).Interface()
+	}
+	return properties, nil
+}
+
+// validateExample is responsible for normalising the Example and checking it against the
+// validate tags of model.Example
+func validateExample(example *model.Example) error {
+	validation.Normalize(example)
+	err := exampleValidator.Validate(example)
+	var validationErr *validation.Error
+	if errors.As(err, &validationErr) {
+		fields := make([]usecase.FieldError, 0, len(validationErr.Fields))
+		for _, field := range validationErr.Fields {
+			fields = append(fields, usecase.FieldError{Field: field.Field, Message: field.Message})
+		}
+		return &usecase.ValidationError{Message: err.Error(), Fields: fields}
+	}
+	if err != nil {
+		return usecase.Wrap(err)
 	}
 	return nil
 }
 
+func toPatchError(err error) error {
+	var testFailedErr *patch.TestFailedError
+	var patchErr *patch.Error
+	switch {
+	case errors.As(err, &testFailedErr):
+		return &usecase.ConflictError{Cause: err}
+	case errors.As(err, &patchErr):
+		return &usecase.ValidationError{
+			Message: err.Error(),
+			Fields:  []usecase.FieldError{{Field: patchErr.Path, Message: patchErr.Message}},
+		}
+	}
+	return &usecase.ValidationError{Message: err.Error()}
+}
+
 func getUpgradeableProperties() []string {
 	return []string{"Name", "Useful"}
 }
+
+func getNullableProperties() []string {
+	return []string{"Useful"}
+}
@@ -1,6 +1,8 @@
 package read
 
 import (
+	"context"
+
 	"github.com/zeroberto/go-ms-template/dataservice"
 	"github.com/zeroberto/go-ms-template/model"
 	"github.com/zeroberto/go-ms-template/usecase"
@@ -11,38 +13,70 @@ type ExampleReadUseCaseImpl struct {
 	EDS dataservice.ExampleDataService
 }
 
-// ListExamples is responsible for obtaining all registered Examples
-func (eruc *ExampleReadUseCaseImpl) ListExamples() ([]model.Example, error) {
-	examples, err := eruc.EDS.FindAll()
+// ListExamples is responsible for obtaining a page of the registered Examples
+func (eruc *ExampleReadUseCaseImpl) ListExamples(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	if err := validatePageRequest(pageRequest); err != nil {
+		return nil, err
+	}
+	page, err := eruc.EDS.FindPage(ctx, pageRequest)
 	if err != nil {
-		return nil, &usecase.Error{Cause: err}
+		return nil, usecase.Wrap(err)
 	}
-	return examples, nil
+	return page, nil
 }
 
-// ListActiveExamples is responsible for obtaining all active Examples
-func (eruc *ExampleReadUseCaseImpl) ListActiveExamples() ([]model.Example, error) {
-	examples, err := eruc.EDS.FindActives()
+// ListActiveExamples is responsible for obtaining a page of the active Examples
+func (eruc *ExampleReadUseCaseImpl) ListActiveExamples(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error) {
+	if err := validatePageRequest(pageRequest); err != nil {
+		return nil, err
+	}
+	page, err := eruc.EDS.FindActivesPage(ctx, pageRequest)
 	if err != nil {
-		return nil, &usecase.Error{Cause: err}
+		return nil, usecase.Wrap(err)
 	}
-	return examples, nil
+	return page, nil
 }
 
 // GetExample is responsible for obtaining an Example according to the given identifier
-func (eruc *ExampleReadUseCaseImpl) GetExample(ID int64) (*model.Example, error) {
-	example, err := eruc.EDS.FindByID(ID)
+func (eruc *ExampleReadUseCaseImpl) GetExample(ctx context.Context, ID int64) (*model.Example, error) {
+	example, err := eruc.EDS.FindByID(ctx, ID)
 	if err != nil {
-		return nil, &usecase.Error{Cause: err}
+		return nil, usecase.Wrap(err)
+	}
+	if example == nil {
+		return nil, &usecase.NotExistsError{ID: ID}
 	}
 	return example, nil
 }
 
 // GetExampleByName is responsible for obtaining an Example according to the given name
-func (eruc *ExampleReadUseCaseImpl) GetExampleByName(name string) (*model.Example, error) {
-	example, err := eruc.EDS.FindByName(name)
+func (eruc *ExampleReadUseCaseImpl) GetExampleByName(ctx context.Context, name string) (*model.Example, error) {
+	example, err := eruc.EDS.FindByName(ctx, name)
 	if err != nil {
-		return nil, &usecase.Error{Cause: err}
+		return nil, usecase.Wrap(err)
+	}
+	if example == nil {
+		return nil, &usecase.NotExistsError{Name: name}
 	}
 	return example, nil
 }
+
+func validatePageRequest(pageRequest model.PageRequest) error {
+	var fields []usecase.FieldError
+	if pageRequest.Limit <= 0 {
+		fields = append(fields, usecase.FieldError{Field: "limit", Message: "must be greater than zero"})
+	}
+	if pageRequest.Offset < 0 {
+		fields = append(fields, usecase.FieldError{Field: "offset", Message: "must not be negative"})
+	}
+	if pageRequest.AfterID > 0 && pageRequest.BeforeID > 0 {
+		fields = append(fields, usecase.FieldError{Field: "cursor", Message: "must not point both after and before"})
+	}
+	if len(pageRequest.Sort) > 0 && (pageRequest.AfterID > 0 || pageRequest.BeforeID > 0) {
+		fields = append(fields, usecase.FieldError{Field: "cursor", Message: "must not point to an ID when sorting"})
+	}
+	if len(fields) > 0 {
+		return &usecase.ValidationError{Message: "Invalid page request", Fields: fields}
+	}
+	return nil
+}
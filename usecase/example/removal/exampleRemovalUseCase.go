@@ -1,48 +1,87 @@
 package removal
 
 import (
-	"time"
+	"context"
 
+	"github.com/zeroberto/go-ms-template/chrono"
 	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/model"
 	"github.com/zeroberto/go-ms-template/usecase"
 )
 
 // ExampleRemovalUseCaseImpl corresponds to the implementation of the example model removal use case
 type ExampleRemovalUseCaseImpl struct {
 	EDS dataservice.ExampleDataService
+	TS  chrono.TimeStamp
+	// EEP notifies the removals of the Examples, which are not notified when nil
+	EEP usecase.ExampleEventPublisher
 }
 
 // DeleteExample is responsible for permanently removing an Example model
-func (eruc *ExampleRemovalUseCaseImpl) DeleteExample(ID int64) error {
-	if err := eruc.notExistsByID(ID); err != nil {
+func (eruc *ExampleRemovalUseCaseImpl) DeleteExample(ctx context.Context, ID int64, version int64) error {
+	current, err := eruc.getCurrent(ctx, ID, version)
+	if err != nil {
 		return err
 	}
-	err := eruc.EDS.Delete(ID)
+	err = eruc.EDS.Delete(ctx, ID, current.Version)
 	if err != nil {
-		return &usecase.Error{Cause: err}
+		return usecase.Wrap(err)
 	}
+	usecase.PublishExampleEvent(ctx, eruc.EEP, model.ExampleEvent{Type: model.ExampleDeleted, Example: *current})
 	return nil
 }
 
-// DeleteExampleLogically is responsible for removing the Example model logically (deactivation)
-func (eruc *ExampleRemovalUseCaseImpl) DeleteExampleLogically(ID int64, deactivationDatetime time.Time) error {
-	if err := eruc.notExistsByID(ID); err != nil {
+// DeleteExampleLogically is responsible for removing the Example model logically (deactivation),
+// keeping the moment of the deactivation when it was already removed
+func (eruc *ExampleRemovalUseCaseImpl) DeleteExampleLogically(ctx context.Context, ID int64, version int64) error {
+	current, err := eruc.getCurrent(ctx, ID, version)
+	if err != nil {
 		return err
 	}
-	err := eruc.EDS.LogicalDeletion(ID, deactivationDatetime)
+	if !current.DeactivatedAt.IsZero() {
+		return nil
+	}
+	deactivatedAt := eruc.TS.GetCurrentTime()
+	err = eruc.EDS.LogicalDeletion(ctx, ID, current.Version, deactivatedAt)
 	if err != nil {
-		return &usecase.Error{Cause: err}
+		return usecase.Wrap(err)
 	}
+	// the deactivation changes the Example as LogicalDeletion does in the repository
+	deactivated := *current
+	deactivated.DeactivatedAt = deactivatedAt
+	deactivated.UpdatedAt = deactivatedAt
+	deactivated.Version++
+	usecase.PublishExampleEvent(ctx, eruc.EEP, model.ExampleEvent{Type: model.ExampleDeactivated, Example: deactivated, Time: deactivatedAt})
 	return nil
 }
 
-func (eruc *ExampleRemovalUseCaseImpl) notExistsByID(ID int64) error {
-	example, err := eruc.EDS.FindByID(ID)
+// DeleteExamples is responsible for permanently removing Examples within a single transaction
+func (eruc *ExampleRemovalUseCaseImpl) DeleteExamples(ctx context.Context, items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error) {
+	if err := usecase.ValidateBatchMode(mode); err != nil {
+		return nil, err
+	}
+	results := make([]model.BatchResult, len(items))
+	for i, item := range items {
+		results[i] = model.BatchResult{Operation: item.Operation, ID: item.Example.ID}
+		results[i].Err = usecase.ValidateBatchItem(item, []model.BatchOperation{model.BatchDelete})
+	}
+
+	err := usecase.RunBatch(ctx, eruc.EDS.Batch, mode, results, func(ctx context.Context, i int) error {
+		return eruc.DeleteExample(ctx, items[i].Example.ID, items[i].Example.Version)
+	})
+	return results, err
+}
+
+func (eruc *ExampleRemovalUseCaseImpl) getCurrent(ctx context.Context, ID int64, version int64) (*model.Example, error) {
+	example, err := eruc.EDS.FindByID(ctx, ID)
 	if err != nil {
-		return &usecase.Error{Cause: err}
+		return nil, usecase.Wrap(err)
 	}
 	if example == nil {
-		return &usecase.NotExistsError{ID: ID}
+		return nil, &usecase.NotExistsError{ID: ID}
 	}
-	return nil
+	if version != 0 && example.Version != version {
+		return nil, usecase.Wrap(&dataservice.StaleVersionError{ID: ID, Version: version})
+	}
+	return example, nil
 }
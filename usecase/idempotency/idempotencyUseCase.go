@@ -0,0 +1,151 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/dataservice"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+const (
+	// DefaultTTL is how long the responses are provided again when TTL is zero
+	DefaultTTL time.Duration = 24 * time.Hour
+	// DefaultLease is how long a request in progress holds its key when Lease is zero
+	DefaultLease time.Duration = time.Minute
+	// DefaultPollInterval is the interval between the checks of a request in progress when
+	// PollInterval is zero
+	DefaultPollInterval time.Duration = 100 * time.Millisecond
+	// purgeInterval is the minimum interval between the removals of the expired requests
+	purgeInterval time.Duration = time.Minute
+)
+
+// IdempotencyUseCaseImpl corresponds to the implementation of the idempotency use case,
+// where the keys are kept as SHA-256 hashes and expire after TTL, measured by TS
+type IdempotencyUseCaseImpl struct {
+	IDS dataservice.IdempotencyDataService
+	TS  chrono.TimeStamp
+	// TTL is how long the response to a request is provided again to the requests with its key
+	TTL time.Duration
+	// Lease is how long a request in progress holds its key, so that the key of a request
+	// interrupted by a crash becomes available again
+	Lease time.Duration
+	// Wait is how long the requests wait for a request in progress with their key, zero
+	// reporting the conflict at once
+	Wait         time.Duration
+	PollInterval time.Duration
+
+	mutex     sync.Mutex
+	lastPurge time.Time
+}
+
+// BeginRequest is responsible for reserving the key for a request, or providing the request
+// completed with it
+func (iuc *IdempotencyUseCaseImpl) BeginRequest(ctx context.Context, key string, fingerprint string) (*model.IdempotentRequest, string, error) {
+	reservation, err := newReservation()
+	if err != nil {
+		return nil, "", err
+	}
+	now := iuc.TS.GetCurrentTime()
+	iuc.purge(ctx, now)
+	deadline := now.Add(iuc.Wait)
+	for {
+		request := &model.IdempotentRequest{
+			Key:         hashKey(key),
+			Fingerprint: fingerprint,
+			Reservation: reservation,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(durationOr(iuc.Lease, DefaultLease)),
+		}
+		existing, err := iuc.IDS.Reserve(ctx, request)
+		if err != nil {
+			return nil, "", usecase.Wrap(err)
+		}
+		if existing == nil {
+			return nil, reservation, nil
+		}
+		if existing.Fingerprint != fingerprint {
+			return nil, "", &usecase.ValidationError{
+				Message: "The Idempotency-Key was used for another request",
+				Fields:  []usecase.FieldError{{Field: "Idempotency-Key", Message: "was used for another request"}},
+			}
+		}
+		if existing.Completed {
+			return existing, "", nil
+		}
+		if !now.Before(deadline) {
+			return nil, "", &usecase.ConflictError{Cause: errors.New("A request with the Idempotency-Key is in progress")}
+		}
+
+		timer := time.NewTimer(durationOr(iuc.PollInterval, DefaultPollInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, "", usecase.Wrap(ctx.Err())
+		case <-timer.C:
+		}
+		now = iuc.TS.GetCurrentTime()
+	}
+}
+
+// CompleteRequest is responsible for storing the response to a request, until TTL elapses
+func (iuc *IdempotencyUseCaseImpl) CompleteRequest(ctx context.Context, request *model.IdempotentRequest) error {
+	completed := *request
+	completed.Key = hashKey(request.Key)
+	completed.Completed = true
+	completed.ExpiresAt = iuc.TS.GetCurrentTime().Add(durationOr(iuc.TTL, DefaultTTL))
+	if err := iuc.IDS.Complete(ctx, &completed); err != nil {
+		return usecase.Wrap(err)
+	}
+	return nil
+}
+
+// AbandonRequest is responsible for releasing the key of a request in progress
+func (iuc *IdempotencyUseCaseImpl) AbandonRequest(ctx context.Context, key string, reservation string) error {
+	if err := iuc.IDS.Release(ctx, hashKey(key), reservation); err != nil {
+		return usecase.Wrap(err)
+	}
+	return nil
+}
+
+// purge is responsible for removing the expired requests, at most once per purgeInterval.
+// Its failures are ignored, the expired requests being removed by the next purge
+func (iuc *IdempotencyUseCaseImpl) purge(ctx context.Context, now time.Time) {
+	iuc.mutex.Lock()
+	if now.Sub(iuc.lastPurge) < purgeInterval {
+		iuc.mutex.Unlock()
+		return
+	}
+	iuc.lastPurge = now
+	iuc.mutex.Unlock()
+	iuc.IDS.DeleteExpired(ctx, now)
+}
+
+// newReservation is responsible for generating the random identifier of a reservation
+func newReservation() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", errors.Wrap(err, "couldn't reserve the idempotency key")
+	}
+	return hex.EncodeToString(random), nil
+}
+
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+func durationOr(duration time.Duration, defaultDuration time.Duration) time.Duration {
+	if duration <= 0 {
+		return defaultDuration
+	}
+	return duration
+}
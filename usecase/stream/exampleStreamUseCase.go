@@ -0,0 +1,204 @@
+package stream
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/zeroberto/go-ms-template/chrono"
+	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/usecase"
+)
+
+const (
+	// DefaultReplaySize is the number of events kept to resume the subscriptions when
+	// ReplaySize is not informed
+	DefaultReplaySize int = 1000
+	// DefaultSubscriberBuffer is the number of events waiting for a subscriber when
+	// SubscriberBuffer is not informed
+	DefaultSubscriberBuffer int = 64
+)
+
+// ExampleStreamUseCaseImpl corresponds to the implementation of the example stream use case, an
+// in-process broker that delivers the events published to the subscribers. Publishing never
+// waits for the subscribers: a subscriber whose buffer is full is dropped, and may subscribe
+// again from the last event it received
+type ExampleStreamUseCaseImpl struct {
+	TS chrono.TimeStamp
+	// ReplaySize limits the events kept to resume the subscriptions
+	ReplaySize int
+	// SubscriberBuffer limits the events waiting for a subscriber
+	SubscriberBuffer int
+	// MaxSubscribers limits the subscriptions open at once, zero meaning no limit
+	MaxSubscribers int
+
+	mutex       sync.Mutex
+	lastID      uint64
+	replay      []model.ExampleEvent
+	subscribers map[*subscription]bool
+	stopped     bool
+}
+
+// subscription delivers the events that pass its filter through a buffered channel
+type subscription struct {
+	broker  *ExampleStreamUseCaseImpl
+	filter  model.ExampleEventFilter
+	events  chan model.ExampleEvent
+	resumed bool
+	dropped bool
+}
+
+// Start is responsible for accepting subscriptions
+func (esuc *ExampleStreamUseCaseImpl) Start() error {
+	esuc.mutex.Lock()
+	defer esuc.mutex.Unlock()
+	esuc.stopped = false
+	return nil
+}
+
+// Stop is responsible for closing every subscription, so that the streams end before the
+// server shuts down, and for refusing the new ones
+func (esuc *ExampleStreamUseCaseImpl) Stop(ctx context.Context) error {
+	esuc.mutex.Lock()
+	defer esuc.mutex.Unlock()
+	esuc.stopped = true
+	for current := range esuc.subscribers {
+		esuc.remove(current)
+	}
+	return nil
+}
+
+// Check is responsible for reporting that the subscriptions are refused, once stopped
+func (esuc *ExampleStreamUseCaseImpl) Check(ctx context.Context) error {
+	esuc.mutex.Lock()
+	defer esuc.mutex.Unlock()
+	if esuc.stopped {
+		return errors.New("the stream is stopped")
+	}
+	return nil
+}
+
+// Publish is responsible for delivering the event to the subscribers whose filter it passes,
+// dropping the ones whose buffer is full
+func (esuc *ExampleStreamUseCaseImpl) Publish(event model.ExampleEvent) {
+	esuc.mutex.Lock()
+	defer esuc.mutex.Unlock()
+
+	esuc.lastID++
+	event.ID = esuc.lastID
+	if event.Time.IsZero() {
+		event.Time = esuc.TS.GetCurrentTime()
+	}
+	replaySize := esuc.ReplaySize
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	esuc.replay = append(esuc.replay, event)
+	if len(esuc.replay) >= 2*replaySize {
+		esuc.replay = append([]model.ExampleEvent{}, esuc.replay[len(esuc.replay)-replaySize:]...)
+	}
+
+	for current := range esuc.subscribers {
+		if !current.filter.Matches(event) {
+			continue
+		}
+		select {
+		case current.events <- event:
+		default:
+			current.dropped = true
+			esuc.remove(current)
+		}
+	}
+}
+
+// Subscribe is responsible for delivering the events that pass the filter, starting with the
+// ones kept after the event lastEventID. The subscription is not resumed when lastEventID is
+// older than the events kept, or newer than the last event, as when it was published before
+// a restart. The subscriptions beyond MaxSubscribers are refused with an UnavailableError
+func (esuc *ExampleStreamUseCaseImpl) Subscribe(lastEventID uint64, filter model.ExampleEventFilter) (usecase.ExampleSubscription, error) {
+	esuc.mutex.Lock()
+	defer esuc.mutex.Unlock()
+
+	if esuc.MaxSubscribers > 0 && len(esuc.subscribers) >= esuc.MaxSubscribers {
+		return nil, &usecase.UnavailableError{Cause: errors.Errorf("the maximum of %d subscribers is reached", esuc.MaxSubscribers)}
+	}
+
+	kept := esuc.kept()
+	var replayed []model.ExampleEvent
+	resumed := lastEventID == 0
+	if lastEventID != 0 && lastEventID <= esuc.lastID {
+		resumed = len(kept) == 0 || kept[0].ID <= lastEventID+1
+		for _, event := range kept {
+			if event.ID > lastEventID && filter.Matches(event) {
+				replayed = append(replayed, event)
+			}
+		}
+	}
+	subscriberBuffer := esuc.SubscriberBuffer
+	if subscriberBuffer <= 0 {
+		subscriberBuffer = DefaultSubscriberBuffer
+	}
+	created := &subscription{
+		broker:  esuc,
+		filter:  filter,
+		events:  make(chan model.ExampleEvent, subscriberBuffer+len(replayed)),
+		resumed: resumed,
+	}
+	for _, event := range replayed {
+		created.events <- event
+	}
+	if esuc.stopped {
+		close(created.events)
+		return created, nil
+	}
+	if esuc.subscribers == nil {
+		esuc.subscribers = map[*subscription]bool{}
+	}
+	esuc.subscribers[created] = true
+	return created, nil
+}
+
+// kept is responsible for providing the events kept to resume the subscriptions
+func (esuc *ExampleStreamUseCaseImpl) kept() []model.ExampleEvent {
+	replaySize := esuc.ReplaySize
+	if replaySize <= 0 {
+		replaySize = DefaultReplaySize
+	}
+	if len(esuc.replay) > replaySize {
+		return esuc.replay[len(esuc.replay)-replaySize:]
+	}
+	return esuc.replay
+}
+
+// remove is responsible for ending the subscription, unless it already ended
+func (esuc *ExampleStreamUseCaseImpl) remove(current *subscription) {
+	if esuc.subscribers[current] {
+		delete(esuc.subscribers, current)
+		close(current.events)
+	}
+}
+
+// Events provides the events of the subscription
+func (current *subscription) Events() <-chan model.ExampleEvent {
+	return current.events
+}
+
+// Resumed reports whether every event after the lastEventID of the subscription is delivered
+func (current *subscription) Resumed() bool {
+	return current.resumed
+}
+
+// Dropped reports whether the subscription was dropped for not keeping up with the events
+func (current *subscription) Dropped() bool {
+	current.broker.mutex.Lock()
+	defer current.broker.mutex.Unlock()
+	return current.dropped
+}
+
+// Close is responsible for ending the subscription
+func (current *subscription) Close() {
+	current.broker.mutex.Lock()
+	defer current.broker.mutex.Unlock()
+	current.broker.remove(current)
+}
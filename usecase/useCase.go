@@ -1,43 +1,122 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
-	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/zeroberto/go-ms-template/model"
+	"github.com/zeroberto/go-ms-template/patch"
 )
 
 // ExampleCreationUseCase is responsible for providing the business methods for
 // creating the Example model
 type ExampleCreationUseCase interface {
 	// CreateExample is responsible for creating a new Example
-	CreateExample(example *model.Example) (*model.Example, error)
-	// UpdateExample is responsible for updating the complete Example model
-	UpdateExample(example *model.Example) (*model.Example, error)
-	// UpdateExampleProperties is responsible for updating partial properties of the Example model
-	UpdateExampleProperties(ID int64, properties map[string]interface{}) (*model.Example, error)
+	CreateExample(ctx context.Context, example *model.Example) (*model.Example, error)
+	// UpdateExample is responsible for updating the complete Example model, provided it is
+	// still at the version of the given Example, zero meaning the current version
+	UpdateExample(ctx context.Context, example *model.Example) (*model.Example, error)
+	// PatchExample is responsible for applying a patch to the JSON representation of the Example
+	// model, provided it is still at the given version, zero meaning the current version
+	PatchExample(ctx context.Context, ID int64, version int64, examplePatch patch.Patch) (*model.Example, error)
+	// SaveExamples is responsible for creating, updating and removing Examples within a single
+	// transaction, providing the result of each item at the same position
+	SaveExamples(ctx context.Context, items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error)
 }
 
 // ExampleReadUseCase is responsible for providing the business methods for
 // reading the Example model
 type ExampleReadUseCase interface {
-	// ListExamples is responsible for obtaining all registered Examples
-	ListExamples() ([]model.Example, error)
-	// ListActiveExamples is responsible for obtaining all active Examples
-	ListActiveExamples() ([]model.Example, error)
+	// ListExamples is responsible for obtaining a page of the registered Examples
+	ListExamples(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error)
+	// ListActiveExamples is responsible for obtaining a page of the active Examples
+	ListActiveExamples(ctx context.Context, pageRequest model.PageRequest) (*model.ExamplePage, error)
 	// GetExample is responsible for obtaining an Example according to the given identifier
-	GetExample(ID int64) (*model.Example, error)
-	// GetExampleByName is responsible for obtaining an Example according to the given name
-	GetExampleByName(name string) (*model.Example, error)
+	GetExample(ctx context.Context, ID int64) (*model.Example, error)
+	// GetExampleByName is responsible for obtaining an Example according to the given name,
+	// reporting NotExistsError when there is none
+	GetExampleByName(ctx context.Context, name string) (*model.Example, error)
 }
 
 // ExampleRemovalUseCase is responsible for providing the business methods for
 // removing the Example model
 type ExampleRemovalUseCase interface {
-	// DeleteExample is responsible for permanently removing an Example model
-	DeleteExample(ID int64) error
-	// DeleteExampleLogically is responsible for removing the Example model logically (deactivation)
-	DeleteExampleLogically(ID int64, deactivationDatetime time.Time) error
+	// DeleteExample is responsible for permanently removing an Example model, provided it is
+	// still at the given version, zero meaning the current version
+	DeleteExample(ctx context.Context, ID int64, version int64) error
+	// DeleteExampleLogically is responsible for removing the Example model logically (deactivation) at
+	// the current time, provided it is still at the given version, zero meaning the current version
+	DeleteExampleLogically(ctx context.Context, ID int64, version int64) error
+	// DeleteExamples is responsible for permanently removing Examples within a single transaction,
+	// providing the result of each item at the same position
+	DeleteExamples(ctx context.Context, items []model.BatchItem, mode model.BatchMode) ([]model.BatchResult, error)
+}
+
+// APIKeyUseCase is responsible for providing the business methods for managing the
+// API keys and authenticating the clients by them
+type APIKeyUseCase interface {
+	// IssueAPIKey is responsible for issuing a key with the name, the scopes and the expiry of
+	// the given APIKey, providing the key itself, which cannot be obtained again
+	IssueAPIKey(ctx context.Context, apiKey *model.APIKey) (*model.APIKey, string, error)
+	// ListAPIKeys is responsible for obtaining all the API keys, revoked ones included
+	ListAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	// RotateAPIKey is responsible for replacing the secret of an APIKey that is not revoked,
+	// providing the new key, the previous one no longer authenticating
+	RotateAPIKey(ctx context.Context, ID int64) (*model.APIKey, string, error)
+	// RevokeAPIKey is responsible for revoking an APIKey at the current time, keeping the moment
+	// of the revocation when it was already revoked
+	RevokeAPIKey(ctx context.Context, ID int64) error
+	// AuthenticateAPIKey is responsible for obtaining the APIKey of a key, reporting
+	// InvalidCredentialsError when the key is unknown, expired or revoked
+	AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error)
+}
+
+// IdempotencyUseCase is responsible for providing the business methods for running at most
+// once the requests identified by an idempotency key
+type IdempotencyUseCase interface {
+	// BeginRequest is responsible for reserving the key for a request of the fingerprint,
+	// providing the reservation, or the request completed with the key instead, if any, and
+	// waiting while another request with the key is in progress. It reports ConflictError when
+	// that request does not complete in time and ValidationError when the key was used for
+	// another request
+	BeginRequest(ctx context.Context, key string, fingerprint string) (previous *model.IdempotentRequest, reservation string, err error)
+	// CompleteRequest is responsible for storing the response to the request reserved with
+	// BeginRequest, which is provided to the next requests with the key until it expires
+	CompleteRequest(ctx context.Context, request *model.IdempotentRequest) error
+	// AbandonRequest is responsible for releasing the key reserved with BeginRequest, whose
+	// request failed in a way that it may be retried, unless the reservation was replaced
+	AbandonRequest(ctx context.Context, key string, reservation string) error
+}
+
+// ExampleEventPublisher is responsible for notifying the changes to Examples
+type ExampleEventPublisher interface {
+	// Publish is responsible for delivering the event to the subscribers without waiting for
+	// them, numbering it and timing it when its ID and Time are zero
+	Publish(event model.ExampleEvent)
+}
+
+// ExampleStreamUseCase is responsible for providing the changes to Examples as they happen
+type ExampleStreamUseCase interface {
+	// Subscribe is responsible for delivering the events that pass the filter, starting with
+	// the ones kept after the event lastEventID when it is not zero, reporting an
+	// UnavailableError when no more subscribers are accepted
+	Subscribe(lastEventID uint64, filter model.ExampleEventFilter) (ExampleSubscription, error)
+}
+
+// ExampleSubscription is responsible for delivering the events of a subscriber
+type ExampleSubscription interface {
+	// Events provides the events in the order they were published, being closed when the
+	// subscription is closed or dropped
+	Events() <-chan model.ExampleEvent
+	// Resumed reports whether every event after the lastEventID of the subscription is
+	// delivered, which fails when they were no longer kept
+	Resumed() bool
+	// Dropped reports whether the subscription was dropped for not keeping up with the events
+	Dropped() bool
+	// Close is responsible for ending the subscription
+	Close()
 }
 
 // Error is responsible for encapsulating errors generated by business methods
@@ -45,15 +124,119 @@ type Error struct {
 	Cause error
 }
 
-// NotExistsError must be reported when the ID is not registered
+// NotExistsError must be reported when the ID, or the Name when informed, is not registered
 type NotExistsError struct {
-	ID int64
+	ID   int64
+	Name string
+	// Resource names the kind of resource that was not found, examples when empty
+	Resource string
+}
+
+// ConflictError must be reported when the operation conflicts with the current
+// state of the Example, such as a duplicate name
+type ConflictError struct {
+	Cause error
+}
+
+// ValidationError must be reported when the data provided to the operation is invalid
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+// FieldError describes why a specific field is invalid
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// PreconditionFailedError must be reported when a condition required by the
+// operation does not hold
+type PreconditionFailedError struct {
+	Cause error
+}
+
+// InvalidCredentialsError must be reported when the credentials do not authenticate a client
+type InvalidCredentialsError struct {
+	Reason string
+}
+
+// UnavailableError must be reported when a dependency of the operation cannot be reached
+type UnavailableError struct {
+	Cause error
+}
+
+// Wrap is responsible for encapsulating an error generated by the lower layers,
+// reporting the errors of dependencies that cannot be reached as UnavailableError,
+// the errors caused by concurrent changes as PreconditionFailedError and the changes
+// that violate a unique constraint as ConflictError
+func Wrap(err error) error {
+	var u interface{ Unavailable() bool }
+	if errors.As(err, &u) && u.Unavailable() {
+		return &UnavailableError{Cause: err}
+	}
+	var s interface{ Stale() bool }
+	if errors.As(err, &s) && s.Stale() {
+		return &PreconditionFailedError{Cause: err}
+	}
+	var c interface{ Conflict() bool }
+	if errors.As(err, &c) && c.Conflict() {
+		return &ConflictError{Cause: err}
+	}
+	return &Error{Cause: err}
 }
 
 func (err *Error) Error() string {
 	return err.Cause.Error()
 }
 
+// Unwrap provides the cause of the error
+func (err *Error) Unwrap() error {
+	return err.Cause
+}
+
 func (err *NotExistsError) Error() string {
-	return fmt.Sprintf("No examples found for ID %d", err.ID)
+	resource := err.Resource
+	if resource == "" {
+		resource = "examples"
+	}
+	if err.Name != "" {
+		return fmt.Sprintf("No %s found for name %s", resource, err.Name)
+	}
+	return fmt.Sprintf("No %s found for ID %d", resource, err.ID)
+}
+
+func (err *ConflictError) Error() string {
+	return err.Cause.Error()
+}
+
+// Unwrap provides the cause of the error
+func (err *ConflictError) Unwrap() error {
+	return err.Cause
+}
+
+func (err *ValidationError) Error() string {
+	return err.Message
+}
+
+func (err *PreconditionFailedError) Error() string {
+	return err.Cause.Error()
+}
+
+// Unwrap provides the cause of the error
+func (err *PreconditionFailedError) Unwrap() error {
+	return err.Cause
+}
+
+func (err *InvalidCredentialsError) Error() string {
+	return fmt.Sprintf("Invalid credentials: %s", err.Reason)
+}
+
+func (err *UnavailableError) Error() string {
+	return err.Cause.Error()
+}
+
+// Unwrap provides the cause of the error
+func (err *UnavailableError) Unwrap() error {
+	return err.Cause
 }
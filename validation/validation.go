@@ -0,0 +1,211 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Rule is responsible for checking the value of a field, providing the message of the
+// error, or an empty message when the value is valid
+type Rule func(value interface{}) string
+
+// Validator is responsible for checking structs against the rules of their validate tags,
+// a comma-separated list of:
+//
+//	required       the field must not have its zero value
+//	min=N, max=N   the length of strings and slices, or the value of numbers, must be within the bound
+//	pattern=RE     strings must match the regular expression, which must not contain commas
+//	enum=A|B       the field must have one of the values
+//	rule=NAME      the field must satisfy the custom rule NAME of Rules
+type Validator struct {
+	// Rules holds the custom rules, by the name used in the tags
+	Rules map[string]Rule
+
+	patterns sync.Map
+}
+
+// FieldError describes why a specific field is invalid
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error must be reported when the fields of a struct do not satisfy their rules
+type Error struct {
+	Fields []FieldError
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("Property %s %s", err.Fields[0].Field, err.Fields[0].Message)
+}
+
+// Validate is responsible for checking every field of the struct, reporting Error with all
+// the fields that do not satisfy their rules
+func (validator *Validator) Validate(value interface{}) error {
+	structValue := reflect.Indirect(reflect.ValueOf(value))
+	if structValue.Kind() != reflect.Struct {
+		return fmt.Errorf("Cannot validate %T, a struct is required", value)
+	}
+	var fields []FieldError
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		tag, ok := structType.Field(i).Tag.Lookup("validate")
+		if !ok || tag == "" {
+			continue
+		}
+		message, err := validator.check(structValue.Field(i), tag)
+		if err != nil {
+			return fmt.Errorf("Invalid validate tag of %s: %v", structType.Field(i).Name, err)
+		}
+		if message != "" {
+			fields = append(fields, FieldError{Field: structType.Field(i).Name, Message: message})
+		}
+	}
+	if len(fields) > 0 {
+		return &Error{Fields: fields}
+	}
+	return nil
+}
+
+// check is responsible for providing the message of the first rule of the tag the field
+// does not satisfy
+func (validator *Validator) check(field reflect.Value, tag string) (string, error) {
+	for _, item := range strings.Split(tag, ",") {
+		name, argument := strings.TrimSpace(item), ""
+		if i := strings.Index(name, "="); i >= 0 {
+			name, argument = name[:i], name[i+1:]
+		}
+		var message string
+		var err error
+		switch name {
+		case "required":
+			if field.IsZero() {
+				message = "is required"
+			}
+		case "min", "max":
+			message, err = checkBound(field, name, argument)
+		case "pattern":
+			message, err = validator.checkPattern(field, argument)
+		case "enum":
+			value := fmt.Sprint(field.Interface())
+			allowed := strings.Split(argument, "|")
+			message = fmt.Sprintf("must be one of %s", strings.Join(allowed, ", "))
+			for _, option := range allowed {
+				if option == value {
+					message = ""
+				}
+			}
+		case "rule":
+			rule, ok := validator.Rules[argument]
+			if !ok {
+				return "", fmt.Errorf("unknown rule %q", argument)
+			}
+			message = rule(field.Interface())
+		default:
+			return "", fmt.Errorf("unknown validation %q", name)
+		}
+		if err != nil || message != "" {
+			return message, err
+		}
+	}
+	return "", nil
+}
+
+func checkBound(field reflect.Value, name string, argument string) (string, error) {
+	bound, err := strconv.ParseFloat(argument, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid bound %q", argument)
+	}
+	var size float64
+	var message string
+	switch field.Kind() {
+	case reflect.String:
+		size = float64(utf8.RuneCountInString(field.String()))
+		message = "characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size = float64(field.Len())
+		message = "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(field.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = field.Float()
+	default:
+		return "", fmt.Errorf("%s is not supported by %s", name, field.Kind())
+	}
+	switch {
+	case name == "min" && size < bound && message != "":
+		return fmt.Sprintf("must have at least %s %s", argument, message), nil
+	case name == "max" && size > bound && message != "":
+		return fmt.Sprintf("must have at most %s %s", argument, message), nil
+	case name == "min" && size < bound:
+		return fmt.Sprintf("must be at least %s", argument), nil
+	case name == "max" && size > bound:
+		return fmt.Sprintf("must be at most %s", argument), nil
+	}
+	return "", nil
+}
+
+func (validator *Validator) checkPattern(field reflect.Value, argument string) (string, error) {
+	if field.Kind() != reflect.String {
+		return "", fmt.Errorf("pattern is not supported by %s", field.Kind())
+	}
+	cached, ok := validator.patterns.Load(argument)
+	if !ok {
+		pattern, err := regexp.Compile(argument)
+		if err != nil {
+			return "", err
+		}
+		cached, _ = validator.patterns.LoadOrStore(argument, pattern)
+	}
+	if !cached.(*regexp.Regexp).MatchString(field.String()) {
+		return fmt.Sprintf("must match %s", argument), nil
+	}
+	return "", nil
+}
+
+// Normalize is responsible for rewriting the string fields of the struct the pointer refers
+// to according to their normalize tags, a comma-separated list of:
+//
+//	trim   removes the leading and trailing white space
+//	nfc    converts to the Unicode Normalization Form C
+func Normalize(value interface{}) {
+	structValue := reflect.ValueOf(value)
+	if structValue.Kind() != reflect.Ptr || structValue.Elem().Kind() != reflect.Struct {
+		return
+	}
+	structValue = structValue.Elem()
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		tag, ok := structType.Field(i).Tag.Lookup("normalize")
+		field := structValue.Field(i)
+		if !ok || field.Kind() != reflect.String || !field.CanSet() {
+			continue
+		}
+		text := field.String()
+		for _, item := range strings.Split(tag, ",") {
+			switch strings.TrimSpace(item) {
+			case "trim":
+				text = strings.TrimSpace(text)
+			case "nfc":
+				text = norm.NFC.String(text)
+			}
+		}
+		field.SetString(text)
+	}
+}
+
+// Fold is responsible for providing the key by which normalised values are compared when
+// they must be unique regardless of case
+func Fold(value string) string {
+	return cases.Fold().String(norm.NFC.String(value))
+}